@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+func newRebuildAggregatesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rebuild-aggregates",
+		Short: "Build the monthly charges of the reports again from the subscriptions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, db, err := openDB(cmd)
+			if err != nil {
+				return err
+			}
+			aggregateService := service.NewAggregateService(repository.NewChargeRepository(db), cfg.Aggregates.MonthsAhead)
+
+			if err := aggregateService.Rebuild(cmd.Context()); err != nil {
+				return err
+			}
+
+			slog.InfoContext(cmd.Context(), "aggregates rebuilt")
+			return nil
+		},
+	}
+}
+
+// chargeDiff is the output line of the check-aggregates command.
+type chargeDiff struct {
+	TenantID    string    `json:"tenant_id"`
+	Month       string    `json:"month"`
+	UserID      uuid.UUID `json:"user_id"`
+	ServiceName string    `json:"service_name"`
+	CategoryID  *uint     `json:"category_id"`
+	Expected    int64     `json:"expected"`
+	Actual      int64     `json:"actual"`
+}
+
+func newCheckAggregatesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-aggregates",
+		Short: "Compare the monthly charges of the reports with the ones calculated from the subscriptions",
+		Long: "Compare the monthly charges of the reports with the ones calculated from the subscriptions.\n" +
+			"The differing charges are printed as JSON lines and the command fails if there are any.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, db, err := openDB(cmd)
+			if err != nil {
+				return err
+			}
+			aggregateService := service.NewAggregateService(repository.NewChargeRepository(db), cfg.Aggregates.MonthsAhead)
+
+			diffs, err := aggregateService.Check(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			for _, diff := range diffs {
+				if err := encoder.Encode(chargeDiff(diff)); err != nil {
+					return err
+				}
+			}
+
+			if len(diffs) > 0 {
+				return fmt.Errorf("%d monthly charges differ from the subscriptions, run the rebuild-aggregates command", len(diffs))
+			}
+
+			slog.InfoContext(cmd.Context(), "aggregates are consistent")
+			return nil
+		},
+	}
+}
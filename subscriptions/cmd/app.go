@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/config"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/service"
+	"subscriptions/rest-service/internal/tenant"
+	"subscriptions/rest-service/pkg/database"
+	"subscriptions/rest-service/pkg/logger"
+
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+const tenantFlag = "tenant"
+
+// loadConfig loads and validates the config of the command and makes the default logger write to w.
+func loadConfig(cmd *cobra.Command, w io.Writer) (config.Config, error) {
+	cfg, err := config.Load(cmd.Flags())
+	if err != nil {
+		return config.Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return config.Config{}, err
+	}
+
+	appLogger, err := logger.New(w, logger.Config{
+		Format: cfg.Log.Format,
+		Level:  cfg.Log.Level,
+	})
+	if err != nil {
+		return config.Config{}, err
+	}
+	slog.SetDefault(appLogger)
+
+	return cfg, nil
+}
+
+func connect(cfg config.Config) (*gorm.DB, error) {
+	return database.Connect(database.Config{
+		Host:               cfg.DB.Host,
+		Port:               cfg.DB.Port,
+		User:               cfg.DB.User,
+		Password:           cfg.DB.Password,
+		Name:               cfg.DB.Name,
+		SlowQueryThreshold: cfg.DB.SlowQueryThreshold,
+		Replicas:           cfg.DB.Replicas,
+		ReadYourWrites:     cfg.DB.ReadYourWritesWindow,
+		Session:            dbSession,
+	})
+}
+
+// dbSession identifies the client whose reads follow its writes, as the rate limits do:
+// the principal within its tenant, the tenant when authentication is disabled.
+func dbSession(ctx context.Context) string {
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if principal := auth.FromContext(ctx); principal != nil {
+		return tenantID + "/" + principal.Subject
+	}
+	return tenantID
+}
+
+// addTenantFlag adds the flag selecting the tenant the data command works with.
+func addTenantFlag(cmd *cobra.Command) {
+	cmd.Flags().String(tenantFlag, "", "tenant of the data (default TENANT_DEFAULT)")
+}
+
+// openDB bootstraps the commands working with the data: it loads the config, logging to
+// stderr so the output of the command stays clean, and connects to the migrated database.
+func openDB(cmd *cobra.Command) (config.Config, *gorm.DB, error) {
+	cfg, err := loadConfig(cmd, cmd.ErrOrStderr())
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+
+	db, err := connect(cfg)
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+
+	if err := database.CheckMigrations(cmd.Context(), db); err != nil {
+		return config.Config{}, nil, fmt.Errorf("database isn't migrated, run the migrate command: %w", err)
+	}
+
+	return cfg, db, nil
+}
+
+// openData bootstraps the commands working with the data of a tenant as openDB does and
+// returns the context of the tenant selected by the tenant flag.
+func openData(cmd *cobra.Command) (context.Context, config.Config, *gorm.DB, error) {
+	cfg, db, err := openDB(cmd)
+	if err != nil {
+		return nil, config.Config{}, nil, err
+	}
+
+	ctx := cmd.Context()
+	tenantID, _ := cmd.Flags().GetString(tenantFlag)
+	if tenantID == "" {
+		tenantID = cfg.Tenants.Default
+	}
+
+	exists, err := repository.NewTenantRepository(db).TenantExists(ctx, tenantID)
+	if err != nil {
+		return nil, config.Config{}, nil, err
+	}
+	if !exists {
+		return nil, config.Config{}, nil, fmt.Errorf("tenant %q doesn't exist", tenantID)
+	}
+
+	return tenant.WithID(ctx, tenantID), cfg, db, nil
+}
+
+// newSubscriptionService returns the service of the commands. Their writes invalidate the
+// cache of the servers: the shared one in postgres, or the memory ones through the
+// broadcast, the memory cache of a command caches nothing since it doesn't listen.
+func newSubscriptionService(cfg config.Config, db *gorm.DB) service.SubscriptionService {
+	subsCache := newCache(cfg.Cache, db)
+
+	return service.NewService(
+		repository.NewRepository(db),
+		repository.NewCategoryRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewBudgetRepository(db),
+		cfg.Users.AutoCreate,
+		subsCache,
+	)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"subscriptions/rest-service/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "print",
+		Short: "Print the effective configuration with the secrets redacted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := config.Load(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			if err := cfg.Print(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
+			// the config is printed even if it is invalid, to see what is wrong with it
+			return cfg.Validate()
+		},
+	})
+
+	return configCmd
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRootCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "help", args: []string{"--help"}},
+		{name: "unknown command", args: []string{"restart"}, wantErr: `unknown command "restart"`},
+		{name: "import without file", args: []string{"import"}, wantErr: "accepts 1 arg(s)"},
+		{name: "migrate with argument", args: []string{"migrate", "now"}, wantErr: "unknown command"},
+		{name: "unknown flag", args: []string{"serve", "--no-such-flag"}, wantErr: "unknown flag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newRootCommand()
+			var out bytes.Buffer
+			root.SetOut(&out)
+			root.SetErr(&out)
+			root.SetArgs(tt.args)
+
+			err := root.Execute()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Execute() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRootCommandTree(t *testing.T) {
+	root := newRootCommand()
+
+	for _, path := range [][]string{
+		{"serve"}, {"migrate"}, {"import"}, {"export"}, {"report", "sum"}, {"seed"}, {"config", "print"},
+	} {
+		cmd, rest, err := root.Find(path)
+		if err != nil || len(rest) > 0 || cmd.Name() != path[len(path)-1] {
+			t.Errorf("command %q not found: %v", strings.Join(path, " "), err)
+		}
+	}
+
+	if root.PersistentFlags().Lookup("db-host") == nil {
+		t.Error("config flags are not added to the root command")
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log/slog"
+	"subscriptions/rest-service/pkg/database"
+
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Create and update the database schema",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig(cmd, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+
+			db, err := connect(cfg)
+			if err != nil {
+				return err
+			}
+
+			if err := database.Migrate(cmd.Context(), db); err != nil {
+				return err
+			}
+
+			slog.InfoContext(cmd.Context(), "database migrated")
+			return nil
+		},
+	}
+}
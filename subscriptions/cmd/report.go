@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"subscriptions/rest-service/internal/helpers"
+	"subscriptions/rest-service/internal/schemas"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+func newReportCommand() *cobra.Command {
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Print the reports of the API",
+	}
+
+	reportCmd.AddCommand(newReportSumCommand())
+
+	return reportCmd
+}
+
+func newReportSumCommand() *cobra.Command {
+	var startDate, endDate, userIDInput, serviceName string
+	var categoryIDInput uint
+
+	sumCmd := &cobra.Command{
+		Use:   "sum",
+		Short: "Print the sum of the subscriptions for the period, as GET /subs/sub_sum does",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if !helpers.ValidateDateMMYYYYFormat(startDate) {
+				return errors.New("invalid start date")
+			}
+			if !helpers.ValidateDateMMYYYYFormat(endDate) {
+				return errors.New("invalid end date")
+			}
+			if !helpers.CheckStartDateBeforeEndDate(startDate, endDate) {
+				return errors.New("start date cannot be after end date")
+			}
+
+			var userID *uuid.UUID
+			if userIDInput != "" {
+				userIDParse, err := uuid.Parse(userIDInput)
+				if err != nil {
+					return errors.New("invalid user id format")
+				}
+				userID = &userIDParse
+			}
+
+			var categoryID *uint
+			if cmd.Flags().Changed("category-id") {
+				categoryID = &categoryIDInput
+			}
+
+			ctx, cfg, db, err := openData(cmd)
+			if err != nil {
+				return err
+			}
+			subsService := newSubscriptionService(cfg, db)
+
+			totalSum, err := subsService.GetSubSum(ctx, userID, &serviceName, categoryID, startDate, endDate)
+			if err != nil {
+				return err
+			}
+
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(schemas.SumReturn{TotalSum: totalSum})
+		},
+	}
+
+	flags := sumCmd.Flags()
+	flags.StringVar(&startDate, "start-date", "", "period start date ('mm-yyyy')")
+	flags.StringVar(&endDate, "end-date", "", "period end date ('mm-yyyy')")
+	flags.StringVar(&userIDInput, "user-id", "", "user ID")
+	flags.StringVar(&serviceName, "service-name", "", "service name")
+	flags.UintVar(&categoryIDInput, "category-id", 0, "category ID (subcategories included)")
+	sumCmd.MarkFlagRequired("start-date")
+	sumCmd.MarkFlagRequired("end-date")
+	addTenantFlag(sumCmd)
+
+	return sumCmd
+}
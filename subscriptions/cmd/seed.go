@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// seedServices are the services of the generated subscriptions with their monthly prices.
+var seedServices = []struct {
+	name  string
+	price uint
+}{
+	{"Yandex Plus", 400},
+	{"Kinopoisk", 300},
+	{"Okko", 350},
+	{"Spotify", 200},
+	{"YouTube Premium", 250},
+	{"Telegram Premium", 300},
+	{"VK Music", 200},
+	{"iCloud", 150},
+}
+
+var seedTags = []string{"music", "video", "work", "family", "cloud"}
+
+func newSeedCommand() *cobra.Command {
+	var users, subscriptions int
+	var seed uint64
+
+	seedCmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Fill the database with random users and subscriptions for development",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if users <= 0 || subscriptions < 0 {
+				return fmt.Errorf("invalid number of users %d or subscriptions %d", users, subscriptions)
+			}
+
+			ctx, cfg, db, err := openData(cmd)
+			if err != nil {
+				return err
+			}
+			userService := service.NewUserService(repository.NewUserRepository(db))
+			subsService := newSubscriptionService(cfg, db)
+
+			if !cmd.Flags().Changed("random-seed") {
+				seed = rand.Uint64()
+			}
+			random := rand.New(rand.NewPCG(seed, seed))
+
+			userIDs := make([]uuid.UUID, users)
+			for i := range userIDs {
+				userIDs[i], err = userService.CreateUser(ctx, schemas.CreateUser{
+					DisplayName: fmt.Sprintf("Seed user %d", i+1),
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			now := time.Now().UTC()
+			for range subscriptions {
+				picked := seedServices[random.IntN(len(seedServices))]
+				// subscriptions started during the last two years, some of them already ended
+				start := time.Date(now.Year(), now.Month()-time.Month(random.IntN(24)), 1, 0, 0, 0, 0, time.UTC)
+
+				data := schemas.CreateSub{
+					ServiceName: picked.name,
+					Price:       picked.price,
+					UserID:      userIDs[random.IntN(len(userIDs))],
+					StartDate:   start.Format("01-2006"),
+					Tags:        []string{seedTags[random.IntN(len(seedTags))]},
+				}
+				if random.IntN(3) == 0 {
+					endDate := start.AddDate(0, 1+random.IntN(12), 0).Format("01-2006")
+					data.EndDate = &endDate
+				}
+
+				if _, err := subsService.CreateSub(ctx, data); err != nil {
+					return err
+				}
+			}
+
+			slog.InfoContext(ctx, "database seeded", "users", users, "subscriptions", subscriptions, "random_seed", seed)
+			return nil
+		},
+	}
+
+	flags := seedCmd.Flags()
+	flags.IntVar(&users, "users", 5, "number of users to create")
+	flags.IntVar(&subscriptions, "subscriptions", 50, "number of subscriptions to create")
+	flags.Uint64Var(&seed, "random-seed", 0, "seed of the generated data (default random)")
+	addTenantFlag(seedCmd)
+
+	return seedCmd
+}
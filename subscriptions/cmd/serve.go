@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"subscriptions/rest-service/docs"
+	"subscriptions/rest-service/internal/api/gql"
+	"subscriptions/rest-service/internal/api/grpcserver"
+	"subscriptions/rest-service/internal/api/handlers"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/api/routers"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/cache"
+	"subscriptions/rest-service/internal/config"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/health"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/outbox"
+	"subscriptions/rest-service/internal/ratelimit"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/service"
+	"subscriptions/rest-service/internal/tracing"
+	"subscriptions/rest-service/pkg/database"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the HTTP and gRPC servers and the background workers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadConfig(cmd, os.Stdout)
+			if err != nil {
+				return err
+			}
+
+			serve(cfg)
+			return nil
+		},
+	}
+}
+
+// serve runs the HTTP and gRPC servers and the background workers until SIGINT or SIGTERM.
+func serve(cfg config.Config) {
+	address := net.JoinHostPort(cfg.App.Host, strconv.Itoa(cfg.App.Port))
+
+	docs.SwaggerInfo.Host = "localhost:" + strconv.Itoa(cfg.App.Port)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		ServiceName: cfg.Tracing.ServiceName,
+		Endpoint:    cfg.Tracing.OTLPEndpoint,
+		Insecure:    cfg.Tracing.OTLPInsecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("error configuring tracing", err)
+	}
+
+	db, err := connect(cfg)
+	if err != nil {
+		fatal("error connect to db", err)
+	}
+	if cfg.DB.AutoMigrate {
+		if err := database.Migrate(context.Background(), db); err != nil {
+			fatal("error migrating db", err)
+		}
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal("error getting db pool", err)
+	}
+	if err := metrics.RegisterDBStats(sqlDB, "main"); err != nil {
+		fatal("error registering db metrics", err)
+	}
+	replicas := database.ReplicasOf(db)
+	if replicas != nil {
+		for name, pool := range replicas.Pools() {
+			if err := metrics.RegisterDBStats(pool, name); err != nil {
+				fatal("error registering db metrics", err)
+			}
+		}
+	}
+
+	subsRepo := repository.NewRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	budgetRepo := repository.NewBudgetRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	tenantRepo := repository.NewTenantRepository(db)
+
+	tenantService := service.NewTenantService(tenantRepo, cfg.Tenants.Default)
+	if err := tenantService.EnsureDefaultTenant(context.Background()); err != nil {
+		fatal("error creating default tenant", err)
+	}
+
+	webhookService := service.NewWebhookService(
+		webhookRepo, cfg.Webhooks.Timeout, cfg.Webhooks.MaxAttempts, cfg.Webhooks.Workers,
+	)
+
+	bus := events.NewBus()
+	bus.Subscribe(events.LogHandler)
+	bus.Subscribe(webhookService.HandleEvent)
+
+	hub := events.NewHub(cfg.Stream.ReplaySize)
+	listener := outbox.NewListener(outboxRepo, hub, cfg.Stream.ReplaySize)
+
+	relay := outbox.NewRelay(
+		outboxRepo, newPublisher(cfg.Outbox, bus, outboxRepo), cfg.Outbox.PollInterval, cfg.Outbox.Retention, cfg.Outbox.MaxAttempts,
+	)
+
+	subsCache := newCache(cfg.Cache, db)
+	subsService := service.NewService(
+		subsRepo, categoryRepo, userRepo, budgetRepo, cfg.Users.AutoCreate, subsCache,
+	)
+	categoryService := service.NewCategoryService(categoryRepo, subsCache)
+	tagService := service.NewTagService(tagRepo, subsCache)
+	userService := service.NewUserService(userRepo)
+	budgetService := service.NewBudgetService(budgetRepo, subsRepo)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	aggregateService := service.NewAggregateService(repository.NewChargeRepository(db), cfg.Aggregates.MonthsAhead)
+
+	subsHandler := handlers.NewHandler(subsService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	userHandler := handlers.NewUserHandler(userService, subsService)
+	budgetHandler := handlers.NewBudgetHandler(budgetService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	tenantHandler := handlers.NewTenantHandler(tenantService)
+	streamHandler := handlers.NewStreamHandler(hub, cfg.Stream.HeartbeatInterval)
+	graphqlHandler := gql.NewHandler(subsService, userService)
+	metricsHandler := handlers.NewMetricsHandler(cfg.Metrics.Token)
+
+	checker := health.NewChecker(cfg.App.ReadinessTimeout)
+	checker.AddCheck("database", sqlDB.PingContext)
+	checker.AddCheck("migrations", func(ctx context.Context) error {
+		return database.CheckMigrations(ctx, db)
+	})
+	healthHandler := handlers.NewHealthHandler(checker)
+
+	var authenticator *auth.Authenticator
+	if cfg.Auth.Enabled {
+		verifier, err := auth.NewJWTVerifier(auth.JWTConfig{
+			Issuer:        cfg.Auth.Issuer,
+			Audience:      cfg.Auth.Audience,
+			HMACSecret:    cfg.Auth.HMACSecret,
+			PublicKeyFile: cfg.Auth.PublicKeyFile,
+			JWKSFile:      cfg.Auth.JWKSFile,
+			AdminRole:     cfg.Auth.AdminRole,
+		})
+		if err != nil {
+			fatal("error configuring authentication", err)
+		}
+		authenticator = auth.NewAuthenticator(verifier, &apiKeyService)
+	}
+	authMiddleware := middleware.NewAuthMiddleware(authenticator, tenantService)
+
+	limiter := newLimiter(cfg.RateLimit, repository.NewRateLimitRepository(db))
+	rateLimitMiddleware := middleware.NewRateLimitMiddleware(limiter)
+
+	router := routers.SetupRouter(
+		subsHandler, categoryHandler, tagHandler, userHandler, budgetHandler, webhookHandler, apiKeyHandler,
+		tenantHandler, streamHandler, graphqlHandler, metricsHandler, healthHandler, authMiddleware, rateLimitMiddleware,
+	)
+	// without trusted proxies the client address used by the limits can't be spoofed with X-Forwarded-For
+	if err := router.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		fatal("error configuring trusted proxies", err)
+	}
+
+	server := &http.Server{
+		Addr:    address,
+		Handler: router,
+	}
+
+	grpcServer, grpcHealth := grpcserver.NewServer(subsService, authenticator, tenantService, limiter)
+	grpcAddress := net.JoinHostPort(cfg.App.Host, strconv.Itoa(cfg.App.GRPCPort))
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// workerCtx reports the health of the worker to the readiness probe, the worker is
+	// unhealthy if it hasn't finished an iteration for a few intervals
+	workerCtx := func(name string, interval time.Duration) context.Context {
+		return health.WithWorker(workersCtx, checker.Worker(name, max(3*interval, time.Minute)))
+	}
+
+	go relay.Run(workerCtx("outbox_relay", cfg.Outbox.PollInterval))
+	// the listener waits for notifications, it is healthy while it is connected
+	go listener.Run(health.WithWorker(workersCtx, checker.Worker("outbox_listener", 0)))
+	webhooksPollInterval := cfg.Webhooks.PollInterval
+	go webhookService.RunDeliveries(workerCtx("webhook_deliveries", webhooksPollInterval), webhooksPollInterval)
+	metricsRefreshInterval := cfg.Metrics.RefreshInterval
+	go subsService.WatchActiveSubs(workerCtx("active_subscriptions", metricsRefreshInterval), metricsRefreshInterval)
+	if limiter != nil {
+		pruneInterval := cfg.RateLimit.PruneInterval
+		go limiter.Run(workerCtx("rate_limit_prune", pruneInterval), pruneInterval)
+	}
+	if subsCache != nil {
+		pruneInterval := cfg.Cache.PruneInterval
+		go subsCache.Run(workerCtx("cache_prune", pruneInterval), pruneInterval)
+		if cfg.Cache.Store == "memory" {
+			// the memory cache caches nothing while the listener is disconnected
+			go subsCache.Listen(health.WithWorker(workersCtx, checker.Worker("cache_listener", 0)))
+		}
+	}
+	endingSoonInterval := cfg.Reminders.CheckInterval
+	go subsService.WatchEndingSoon(
+		workerCtx("ending_soon", endingSoonInterval), endingSoonInterval, cfg.Reminders.Window,
+	)
+	// the first run builds the monthly charges if they have never been built
+	aggregatesInterval := cfg.Aggregates.ExtendInterval
+	go aggregateService.Run(workerCtx("monthly_charges", aggregatesInterval), aggregatesInterval)
+	// the replicas get the reads once they are checked
+	if replicas != nil {
+		replicaCheckInterval := cfg.DB.ReplicaCheckInterval
+		go replicas.Run(workerCtx("db_replicas", replicaCheckInterval), replicaCheckInterval)
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		slog.Info("starting server", "address", address)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fatal("error starting server", err)
+		}
+	}()
+
+	go func() {
+		grpcListener, err := net.Listen("tcp", grpcAddress)
+		if err != nil {
+			fatal("error listening gRPC address", err)
+		}
+
+		slog.Info("starting gRPC server", "address", grpcAddress)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			fatal("error starting gRPC server", err)
+		}
+	}()
+
+	checker.SetStarted()
+
+	<-quit
+	slog.Info("shutting down server")
+
+	// the instance stops receiving new traffic once the probes notice it isn't ready
+	checker.SetStopping()
+	grpcHealth.Shutdown()
+	time.Sleep(cfg.App.ShutdownDelay)
+
+	stopWorkers()
+	// streams never end on their own, so they are closed before waiting for connections
+	hub.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	if err := server.Shutdown(ctx); err != nil {
+		fatal("server forced to shutdown", err)
+	}
+
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+		slog.Warn("gRPC server forced to shutdown")
+	}
+
+	// the spans of the last requests are still in the batch
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("error flushing traces", "error", err)
+	}
+
+	slog.Info("server stopped gracefully")
+}
+
+// fatal logs the error and exits, it is used for the errors the service can't start with.
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
+}
+
+// newPublisher builds the outbox publisher from the configured publishers.
+func newPublisher(cfg config.Outbox, bus *events.Bus, outboxRepo repository.OutboxRepo) outbox.Publisher {
+	var publishers outbox.MultiPublisher
+
+	for _, name := range cfg.Publishers {
+		switch name {
+		case "bus":
+			publishers = append(publishers, outbox.NewBusPublisher(bus))
+		case "notify":
+			publishers = append(publishers, outbox.NewNotifyPublisher(outboxRepo))
+		case "log":
+			publishers = append(publishers, outbox.NewLogPublisher(os.Stdout))
+		case "http":
+			publishers = append(publishers, outbox.NewHTTPPublisher(cfg.HTTPURL, cfg.HTTPTimeout))
+		}
+	}
+
+	if len(publishers) == 1 {
+		return publishers[0]
+	}
+
+	return publishers
+}
+
+// newCache returns the configured cache, nil if caching is disabled.
+// The memory caches of the instances broadcast their invalidations to each other.
+func newCache(cfg config.Cache, db *gorm.DB) *cache.Cache {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.Store == "postgres" {
+		return cache.New(repository.NewCacheRepository(db), cfg.TTL)
+	}
+	return cache.NewLocal(cache.NewMemoryStore(cfg.Size), cfg.TTL, repository.NewCacheBroadcaster(db))
+}
+
+// newLimiter returns the configured limiter, nil if rate limiting is disabled.
+func newLimiter(cfg config.RateLimit, sharedStore ratelimit.Store) *ratelimit.Limiter {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.Store == "postgres" {
+		store = sharedStore
+	}
+
+	// the limits are parsed when the config is validated
+	ipLimit, _ := cfg.IPLimit()
+	clientLimit, _ := cfg.ClientLimit()
+	routes, _ := ratelimit.ParseRouteLimits(cfg.Routes)
+
+	return ratelimit.NewLimiter(store, ratelimit.Config{
+		IP:     ipLimit,
+		Client: clientLimit,
+		Routes: routes,
+	})
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"subscriptions/rest-service/internal/helpers"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+const (
+	formatCSV  = "csv"
+	formatJSON = "json"
+)
+
+// exportPageSize is the number of subscriptions read from the database at once.
+const exportPageSize = 500
+
+// tagsSeparator joins the tags in one CSV column.
+const tagsSeparator = ";"
+
+// csvHeader are the columns of the CSV files, the id is ignored by import.
+var csvHeader = []string{"id", "service_name", "price", "user_id", "start_date", "end_date", "category_id", "tags"}
+
+// subscriptionRecord is a subscription in the import and export files, in the format of the API requests.
+type subscriptionRecord struct {
+	ID uint `json:"id,omitempty"`
+	schemas.CreateSub
+}
+
+func newImportCommand() *cobra.Command {
+	var format string
+
+	importCmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Create the subscriptions of a CSV or JSON file, '-' reads stdin",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fileType, err := fileFormat(format, args[0])
+			if err != nil {
+				return err
+			}
+
+			input := cmd.InOrStdin()
+			if args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				input = file
+			}
+
+			var records []subscriptionRecord
+			if fileType == formatCSV {
+				records, err = readCSV(input)
+			} else {
+				err = json.NewDecoder(input).Decode(&records)
+			}
+			if err != nil {
+				return fmt.Errorf("read %s: %w", args[0], err)
+			}
+
+			// nothing is created unless every record is valid
+			if err := validateRecords(records); err != nil {
+				return err
+			}
+
+			ctx, cfg, db, err := openData(cmd)
+			if err != nil {
+				return err
+			}
+			subsService := newSubscriptionService(cfg, db)
+
+			for i, record := range records {
+				if _, err := subsService.CreateSub(ctx, record.CreateSub); err != nil {
+					return fmt.Errorf("record %d: %w, %d of %d records imported", i+1, err, i, len(records))
+				}
+			}
+
+			slog.InfoContext(ctx, "subscriptions imported", "count", len(records))
+			return nil
+		},
+	}
+
+	importCmd.Flags().StringVar(&format, "format", "", "file format: csv or json (default from the file extension)")
+	addTenantFlag(importCmd)
+
+	return importCmd
+}
+
+func newExportCommand() *cobra.Command {
+	var format, output, userIDInput, tag string
+	var categoryIDInput uint
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the subscriptions to a CSV or JSON file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			fileType, err := fileFormat(format, output)
+			if err != nil {
+				return err
+			}
+
+			var filter repository.SubsFilter
+			if userIDInput != "" {
+				userID, err := uuid.Parse(userIDInput)
+				if err != nil {
+					return errors.New("invalid user id format")
+				}
+				filter.UserID = &userID
+			}
+			if cmd.Flags().Changed("category-id") {
+				filter.CategoryID = &categoryIDInput
+			}
+			if tag != "" {
+				filter.Tag = &tag
+			}
+
+			ctx, cfg, db, err := openData(cmd)
+			if err != nil {
+				return err
+			}
+			subsService := newSubscriptionService(cfg, db)
+
+			out := cmd.OutOrStdout()
+			if output != "-" {
+				file, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				out = file
+			}
+
+			var records []subscriptionRecord
+			for page := 1; ; page++ {
+				result, err := subsService.GetAllSubs(ctx, page, exportPageSize, filter)
+				if err != nil {
+					return err
+				}
+
+				for _, sub := range result.Subscriptions {
+					records = append(records, toSubscriptionRecord(sub))
+				}
+
+				if !result.Pagination.HasNext {
+					break
+				}
+			}
+
+			if fileType == formatCSV {
+				err = writeCSV(out, records)
+			} else {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				err = encoder.Encode(records)
+			}
+			if err != nil {
+				return err
+			}
+
+			slog.InfoContext(ctx, "subscriptions exported", "count", len(records))
+			return nil
+		},
+	}
+
+	flags := exportCmd.Flags()
+	flags.StringVarP(&output, "output", "o", "-", "output file, '-' writes to stdout")
+	flags.StringVar(&format, "format", "", "file format: csv or json (default from the file extension, json for stdout)")
+	flags.StringVar(&userIDInput, "user-id", "", "export the subscriptions of the user")
+	flags.UintVar(&categoryIDInput, "category-id", 0, "export the subscriptions of the category and its subcategories")
+	flags.StringVar(&tag, "tag", "", "export the subscriptions with the tag")
+	addTenantFlag(exportCmd)
+
+	return exportCmd
+}
+
+// fileFormat returns the format given by the flag or by the extension of the file.
+func fileFormat(format, path string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if path == "-" {
+			format = formatJSON
+		}
+	}
+
+	if format != formatCSV && format != formatJSON {
+		return "", fmt.Errorf("unknown file format %q, expected csv or json", format)
+	}
+
+	return format, nil
+}
+
+func toSubscriptionRecord(sub schemas.FullSubInfo) subscriptionRecord {
+	record := subscriptionRecord{
+		ID: sub.ID,
+		CreateSub: schemas.CreateSub{
+			ServiceName: sub.ServiceName,
+			Price:       sub.Price,
+			UserID:      sub.UserID,
+			StartDate:   sub.StartDate.Format("01-2006"),
+			CategoryID:  sub.CategoryID,
+			Tags:        sub.Tags,
+		},
+	}
+
+	if sub.EndDate != nil {
+		endDate := sub.EndDate.Format("01-2006")
+		record.EndDate = &endDate
+	}
+
+	return record
+}
+
+// validateRecords checks the records as the API checks the created subscriptions and
+// returns the errors of all invalid records.
+func validateRecords(records []subscriptionRecord) error {
+	validate := validator.New()
+	validate.RegisterValidation("mm_yyyy_date", helpers.ValidateDateMMYYYYFormatValidator)
+
+	var errs []error
+	for i, record := range records {
+		if err := validate.Struct(record.CreateSub); err != nil {
+			errs = append(errs, fmt.Errorf("record %d: %w", i+1, err))
+			continue
+		}
+
+		if record.EndDate != nil && !helpers.CheckStartDateBeforeEndDate(record.StartDate, *record.EndDate) {
+			errs = append(errs, fmt.Errorf("record %d: start date cannot be after end date", i+1))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func readCSV(r io.Reader) ([]subscriptionRecord, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"service_name", "price", "user_id", "start_date"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var records []subscriptionRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		// the columns of the optional fields may be missing
+		value := func(name string) string {
+			column, ok := columns[name]
+			if !ok {
+				return ""
+			}
+			return strings.TrimSpace(row[column])
+		}
+
+		var record subscriptionRecord
+		record.ServiceName = value("service_name")
+		record.StartDate = value("start_date")
+
+		price, err := strconv.ParseUint(value("price"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid price %q", line, value("price"))
+		}
+		record.Price = uint(price)
+
+		if record.UserID, err = uuid.Parse(value("user_id")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid user id %q", line, value("user_id"))
+		}
+
+		if endDate := value("end_date"); endDate != "" {
+			record.EndDate = &endDate
+		}
+
+		if categoryIDInput := value("category_id"); categoryIDInput != "" {
+			categoryID, err := strconv.ParseUint(categoryIDInput, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid category id %q", line, categoryIDInput)
+			}
+			categoryIDUint := uint(categoryID)
+			record.CategoryID = &categoryIDUint
+		}
+
+		if tags := value("tags"); tags != "" {
+			record.Tags = strings.Split(tags, tagsSeparator)
+		}
+
+		records = append(records, record)
+	}
+}
+
+func writeCSV(w io.Writer, records []subscriptionRecord) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		var endDate, categoryID string
+		if record.EndDate != nil {
+			endDate = *record.EndDate
+		}
+		if record.CategoryID != nil {
+			categoryID = strconv.FormatUint(uint64(*record.CategoryID), 10)
+		}
+
+		err := writer.Write([]string{
+			strconv.FormatUint(uint64(record.ID), 10),
+			record.ServiceName,
+			strconv.FormatUint(uint64(record.Price), 10),
+			record.UserID.String(),
+			record.StartDate,
+			endDate,
+			categoryID,
+			strings.Join(record.Tags, tagsSeparator),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"subscriptions/rest-service/internal/schemas"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testUserID = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+
+func TestFileFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "csv extension", path: "subs.csv", want: formatCSV},
+		{name: "upper case extension", path: "SUBS.JSON", want: formatJSON},
+		{name: "stdin", path: "-", want: formatJSON},
+		{name: "flag over extension", format: formatCSV, path: "subs.json", want: formatCSV},
+		{name: "unknown extension", path: "subs.xml", wantErr: true},
+		{name: "unknown flag", format: "xml", path: "subs.csv", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fileFormat(tt.format, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fileFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("fileFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	categoryID := uint(3)
+	endDate := "12-2025"
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []subscriptionRecord
+		wantErr string
+	}{
+		{
+			name:  "required columns only",
+			input: "service_name,price,user_id,start_date\nNetflix,400," + testUserID + ",07-2025\n",
+			want: []subscriptionRecord{{CreateSub: schemas.CreateSub{
+				ServiceName: "Netflix", Price: 400, UserID: uuid.MustParse(testUserID), StartDate: "07-2025",
+			}}},
+		},
+		{
+			name: "all columns",
+			input: "id,service_name,price,user_id,start_date,end_date,category_id,tags\n" +
+				"9, Yandex Plus ,400," + testUserID + ",07-2025,12-2025,3,music;family\n",
+			want: []subscriptionRecord{{CreateSub: schemas.CreateSub{
+				ServiceName: "Yandex Plus", Price: 400, UserID: uuid.MustParse(testUserID), StartDate: "07-2025",
+				EndDate: &endDate, CategoryID: &categoryID, Tags: []string{"music", "family"},
+			}}},
+		},
+		{
+			name:  "no records",
+			input: "service_name,price,user_id,start_date\n",
+		},
+		{
+			name:    "missing column",
+			input:   "service_name,price,start_date\nNetflix,400,07-2025\n",
+			wantErr: `missing column "user_id"`,
+		},
+		{
+			name:    "invalid price",
+			input:   "service_name,price,user_id,start_date\nNetflix,-1," + testUserID + ",07-2025\n",
+			wantErr: `line 2: invalid price "-1"`,
+		},
+		{
+			name:    "invalid user id",
+			input:   "service_name,price,user_id,start_date\nNetflix,400,42,07-2025\n",
+			wantErr: `line 2: invalid user id "42"`,
+		},
+		{
+			name:    "invalid category id",
+			input:   "service_name,price,user_id,start_date,category_id\nNetflix,400," + testUserID + ",07-2025,music\n",
+			wantErr: `line 2: invalid category id "music"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readCSV(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readCSV() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readCSV() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readCSV() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	categoryID := uint(3)
+	endDate := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	subs := []schemas.FullSubInfo{
+		{
+			ID: 1, ServiceName: "Netflix", Price: 400, UserID: uuid.MustParse(testUserID),
+			StartDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			ID: 2, ServiceName: "Yandex, Plus", Price: 299, UserID: uuid.MustParse(testUserID),
+			StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &endDate,
+			CategoryID: &categoryID, Tags: []string{"music", "family"},
+		},
+	}
+
+	records := make([]subscriptionRecord, len(subs))
+	for i, sub := range subs {
+		records[i] = toSubscriptionRecord(sub)
+	}
+
+	var buf bytes.Buffer
+	if err := writeCSV(&buf, records); err != nil {
+		t.Fatalf("writeCSV() error = %v", err)
+	}
+
+	got, err := readCSV(&buf)
+	if err != nil {
+		t.Fatalf("readCSV() error = %v", err)
+	}
+
+	// the id is written for reference only, import creates new subscriptions
+	for i := range records {
+		records[i].ID = 0
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("read records = %+v, want %+v", got, records)
+	}
+}
+
+func TestValidateRecords(t *testing.T) {
+	endDate := "06-2025"
+	earlyEndDate := "01-2025"
+	invalidDate := "2025-06"
+
+	valid := schemas.CreateSub{ServiceName: "Netflix", Price: 400, UserID: uuid.MustParse(testUserID), StartDate: "02-2025"}
+	with := func(change func(sub *schemas.CreateSub)) subscriptionRecord {
+		sub := valid
+		change(&sub)
+		return subscriptionRecord{CreateSub: sub}
+	}
+
+	tests := []struct {
+		name    string
+		records []subscriptionRecord
+		wantErr []string
+	}{
+		{name: "valid", records: []subscriptionRecord{{CreateSub: valid}, with(func(s *schemas.CreateSub) { s.EndDate = &endDate })}},
+		{name: "no service name", records: []subscriptionRecord{with(func(s *schemas.CreateSub) { s.ServiceName = "" })}, wantErr: []string{"record 1"}},
+		{name: "invalid date", records: []subscriptionRecord{with(func(s *schemas.CreateSub) { s.StartDate = invalidDate })}, wantErr: []string{"record 1"}},
+		{
+			name:    "end before start",
+			records: []subscriptionRecord{{CreateSub: valid}, with(func(s *schemas.CreateSub) { s.EndDate = &earlyEndDate })},
+			wantErr: []string{"record 2: start date cannot be after end date"},
+		},
+		{
+			name: "every invalid record",
+			records: []subscriptionRecord{
+				with(func(s *schemas.CreateSub) { s.Price = 0 }),
+				{CreateSub: valid},
+				with(func(s *schemas.CreateSub) { s.Tags = []string{""} }),
+			},
+			wantErr: []string{"record 1", "record 3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRecords(tt.records)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("validateRecords() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("validateRecords() error = nil")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("validateRecords() error = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
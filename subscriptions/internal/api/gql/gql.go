@@ -0,0 +1,111 @@
+/*
+Package gql serves the subscription API over GraphQL on top of the same
+services as the REST handlers.
+*/
+package gql
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"net/http"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/requestid"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// maxPageSize limits page sizes, lists up to it are resolved in parallel
+// so their objects are loaded in one batch.
+const maxPageSize = 100
+
+//go:embed schema.graphql
+var schemaSDL string
+
+type Handler struct {
+	schema      *graphql.Schema
+	subsService service.SubscriptionService
+	userService service.UserService
+}
+
+func NewHandler(subsService service.SubscriptionService, userService service.UserService) Handler {
+	resolver := &Resolver{
+		subsService: subsService,
+		userService: userService,
+	}
+
+	return Handler{
+		schema:      graphql.MustParseSchema(schemaSDL, &rootResolver{resolver}, graphql.MaxParallelism(maxPageSize)),
+		subsService: subsService,
+		userService: userService,
+	}
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Query executes the GraphQL request, errors of the query are returned in the
+// errors field of the response along with the request ID in the extensions.
+func (h *Handler) Query(c *gin.Context) {
+	var req request
+
+	if err := c.ShouldBindJSON(&req); err != nil || req.Query == "" {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidGraphQLRequest)
+		return
+	}
+
+	ctx := c.Request.Context()
+	ctx = context.WithValue(ctx, loadersKey{}, newLoaders(ctx, h.subsService, h.userService))
+
+	res := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	if len(res.Errors) > 0 {
+		res.Extensions = map[string]any{"request_id": requestid.FromContext(ctx)}
+	}
+	for _, resErr := range res.Errors {
+		var queryErr *queryError
+		if errors.As(resErr.ResolverError, &queryErr) {
+			resErr.Message = i18n.Translate(ctx, queryErr.message)
+		}
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// queryError is a service error with the HTTP status code and the code of the message
+// in the extensions, Query translates the message to the language of the request.
+type queryError struct {
+	code    int
+	message i18n.Message
+}
+
+func (e *queryError) Error() string {
+	return e.message.String()
+}
+
+func (e *queryError) Extensions() map[string]any {
+	return map[string]any{"code": e.code, "error_code": string(e.message)}
+}
+
+func toQueryError(err error) error {
+	var serviceErr *schemas.AppError
+	if errors.As(err, &serviceErr) {
+		return &queryError{code: serviceErr.Code, message: serviceErr.Message}
+	}
+
+	return &queryError{code: http.StatusInternalServerError, message: i18n.InternalError}
+}
+
+func badRequest(message i18n.Message) error {
+	return &queryError{code: http.StatusBadRequest, message: message}
+}
+
+func forbidden() error {
+	return &queryError{code: http.StatusForbidden, message: i18n.Forbidden}
+}
@@ -0,0 +1,267 @@
+package gql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"subscriptions/rest-service/internal/api/gql"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/service"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	alice = uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	bob   = uuid.MustParse("2f1c3d5e-8b7a-4c6d-9e0f-1a2b3c4d5e6f")
+)
+
+// subsRepo keeps the subscriptions and returns sum for every sum query. The sums per
+// users are counted, so the tests can check they are loaded in one query.
+type subsRepo struct {
+	repository.SubscriptionRepo
+
+	records []models.Subscription
+	sum     uint
+
+	mu           sync.Mutex
+	usersQueries int
+}
+
+func (r *subsRepo) GetRecords(_ context.Context, offset, size int, filter repository.SubsFilter) ([]models.Subscription, *int, error) {
+	var records []models.Subscription
+	for _, record := range r.records {
+		if filter.UserID == nil || record.UserID == *filter.UserID {
+			records = append(records, record)
+		}
+	}
+
+	totalPages := 1
+	return records, &totalPages, nil
+}
+
+func (r *subsRepo) GetRecord(_ context.Context, id uint) (*models.Subscription, error) {
+	for _, record := range r.records {
+		if record.ID == id {
+			return &record, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *subsRepo) GetSubsSum(context.Context, *uuid.UUID, *string, *uint, string, string) *uint {
+	return &r.sum
+}
+
+func (r *subsRepo) GetSubsSumByUsers(_ context.Context, userIDs []uuid.UUID, _ *string, _ *uint, _, _ string) ([]repository.UserSum, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.usersQueries++
+
+	sums := make([]repository.UserSum, len(userIDs))
+	for i, userID := range userIDs {
+		sums[i] = repository.UserSum{UserID: userID, TotalSum: r.sum}
+	}
+	return sums, nil
+}
+
+// userRepo keeps the users, the loads by ids are counted.
+type userRepo struct {
+	repository.UserRepo
+
+	users []models.User
+
+	mu        sync.Mutex
+	idQueries int
+}
+
+func (r *userRepo) GetUsers(context.Context, int, int) ([]models.User, *int, error) {
+	totalPages := 1
+	return r.users, &totalPages, nil
+}
+
+func (r *userRepo) GetUsersByIDs(_ context.Context, ids []uuid.UUID) ([]models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.idQueries++
+
+	var users []models.User
+	for _, user := range r.users {
+		for _, id := range ids {
+			if user.ID == id {
+				users = append(users, user)
+			}
+		}
+	}
+	return users, nil
+}
+
+type response struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func query(t *testing.T, handler gql.Handler, query string) response {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	body, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Query(c)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body)
+	}
+
+	var res response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return res
+}
+
+func newHandler(subs *subsRepo, users *userRepo) gql.Handler {
+	return gql.NewHandler(
+		service.NewService(subs, nil, users, nil, false, nil),
+		service.NewUserService(users),
+	)
+}
+
+func TestQuery(t *testing.T) {
+	subs := &subsRepo{
+		records: []models.Subscription{
+			{ID: 1, ServiceName: "Yandex Plus", Price: 3_000_000_000, UserID: alice, StartDate: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		// the sum doesn't fit the 32-bit Int
+		sum: 5_000_000_000,
+	}
+	handler := newHandler(subs, &userRepo{})
+
+	tests := []struct {
+		name  string
+		query string
+		// expected is the JSON of the data, code is the expected code of the error
+		expected string
+		code     float64
+	}{
+		{
+			name:     "spending out of the range of Int",
+			query:    `{ spending(from: "01-2025", to: "12-2025") }`,
+			expected: `{"spending":5000000000}`,
+		},
+		{
+			name:     "price out of the range of Int",
+			query:    `{ subscription(id: 1) { serviceName price startDate } }`,
+			expected: `{"subscription":{"price":3000000000,"serviceName":"Yandex Plus","startDate":"07-2025"}}`,
+		},
+		{name: "invalid period", query: `{ spending(from: "12-2025", to: "01-2025") }`, code: http.StatusBadRequest},
+		{name: "invalid id", query: `{ subscription(id: "one") { id } }`, code: http.StatusBadRequest},
+		{name: "missing subscription", query: `{ subscription(id: 2) { id } }`, code: http.StatusNotFound},
+		{name: "invalid page size", query: `{ subscriptions(size: 1000) { subscriptions { id } } }`, code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := query(t, handler, tt.query)
+
+			if tt.code != 0 {
+				if len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != tt.code {
+					t.Fatalf("expected error with code %v, got %+v", tt.code, res.Errors)
+				}
+				return
+			}
+
+			if len(res.Errors) != 0 {
+				t.Fatalf("unexpected errors %+v", res.Errors)
+			}
+			data, err := json.Marshal(res.Data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected data %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestQueryBatching(t *testing.T) {
+	users := &userRepo{users: []models.User{{ID: alice, DisplayName: "Alice"}, {ID: bob, DisplayName: "Bob"}}}
+	start := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	subs := &subsRepo{
+		records: []models.Subscription{
+			{ID: 1, ServiceName: "Yandex Plus", Price: 400, UserID: alice, StartDate: start},
+			{ID: 2, ServiceName: "Netflix", Price: 800, UserID: bob, StartDate: start},
+			{ID: 3, ServiceName: "Spotify", Price: 200, UserID: alice, StartDate: start},
+			// the owner of the subscription wasn't created in the users resource
+			{ID: 4, ServiceName: "Okko", Price: 300, UserID: uuid.New(), StartDate: start},
+		},
+		sum: 1200,
+	}
+	handler := newHandler(subs, users)
+
+	t.Run("users of the subscriptions", func(t *testing.T) {
+		res := query(t, handler, `{ subscriptions { subscriptions { id user { displayName } } } }`)
+		if len(res.Errors) != 0 {
+			t.Fatalf("unexpected errors %+v", res.Errors)
+		}
+
+		page := res.Data["subscriptions"].(map[string]any)["subscriptions"].([]any)
+		var names []string
+		for _, sub := range page {
+			user, _ := sub.(map[string]any)["user"].(map[string]any)
+			if user == nil {
+				names = append(names, "")
+				continue
+			}
+			names = append(names, user["displayName"].(string))
+		}
+
+		if strings.Join(names, ",") != "Alice,Bob,Alice," {
+			t.Errorf("expected users Alice,Bob,Alice and none, got %v", names)
+		}
+		if users.idQueries != 1 {
+			t.Errorf("expected the users loaded in 1 query, got %d", users.idQueries)
+		}
+	})
+
+	t.Run("spending of the users", func(t *testing.T) {
+		res := query(t, handler, `{ users { users { displayName spending(from: "01-2025", to: "12-2025") } } }`)
+		if len(res.Errors) != 0 {
+			t.Fatalf("unexpected errors %+v", res.Errors)
+		}
+
+		for _, user := range res.Data["users"].(map[string]any)["users"].([]any) {
+			if spending := user.(map[string]any)["spending"]; spending != float64(1200) {
+				t.Errorf("expected spending 1200, got %v", spending)
+			}
+		}
+		if subs.usersQueries != 1 {
+			t.Errorf("expected the spending loaded in 1 query, got %d", subs.usersQueries)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package gql
+
+import (
+	"sync"
+	"time"
+)
+
+// batchWait is how long a loader collects keys before loading them.
+const batchWait = 2 * time.Millisecond
+
+type batch[K comparable, V any] struct {
+	keys   []K
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+// loader collects the keys requested by resolvers running in parallel and loads
+// them with one fetch call, so a list of objects doesn't run a query per object.
+// Loaded values are cached for the lifetime of the loader, which is one request.
+type loader[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+
+	mu    sync.Mutex
+	batch *batch[K, V]
+	cache map[K]V
+}
+
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch: fetch,
+		cache: make(map[K]V),
+	}
+}
+
+// Load returns the value of the key, false if fetch returned no value for it.
+func (l *loader[K, V]) Load(key K) (V, bool, error) {
+	l.mu.Lock()
+	if value, ok := l.cache[key]; ok {
+		l.mu.Unlock()
+		return value, true, nil
+	}
+
+	b := l.batch
+	if b == nil {
+		b = &batch[K, V]{done: make(chan struct{})}
+		l.batch = b
+		time.AfterFunc(batchWait, func() { l.run(b) })
+	}
+	b.keys = append(b.keys, key)
+	l.mu.Unlock()
+
+	<-b.done
+
+	value, ok := b.values[key]
+	return value, ok, b.err
+}
+
+func (l *loader[K, V]) run(b *batch[K, V]) {
+	l.mu.Lock()
+	l.batch = nil
+	keys := uniqueKeys(b.keys)
+	l.mu.Unlock()
+
+	b.values, b.err = l.fetch(keys)
+
+	if b.err == nil {
+		l.mu.Lock()
+		for key, value := range b.values {
+			l.cache[key] = value
+		}
+		l.mu.Unlock()
+	}
+	close(b.done)
+}
+
+func uniqueKeys[K comparable](keys []K) []K {
+	seen := make(map[K]struct{}, len(keys))
+	result := make([]K, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
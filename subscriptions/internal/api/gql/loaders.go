@@ -0,0 +1,84 @@
+package gql
+
+import (
+	"context"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+
+	"github.com/google/uuid"
+)
+
+type loadersKey struct{}
+
+// spendingKey identifies the spending of a user for the period and filters.
+type spendingKey struct {
+	userID      uuid.UUID
+	from, to    string
+	serviceName string
+	categoryID  uint
+	hasCategory bool
+}
+
+// spendingParams is the part of the key shared by the users of one sum query.
+type spendingParams struct {
+	from, to    string
+	serviceName string
+	categoryID  uint
+	hasCategory bool
+}
+
+type loaders struct {
+	users    *loader[uuid.UUID, schemas.UserInfo]
+	spending *loader[spendingKey, uint]
+}
+
+// newLoaders creates the loaders of one request, they load data with its context.
+func newLoaders(ctx context.Context, subsService service.SubscriptionService, userService service.UserService) *loaders {
+	return &loaders{
+		users: newLoader(func(ids []uuid.UUID) (map[uuid.UUID]schemas.UserInfo, error) {
+			users, err := userService.GetUsersByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			result := make(map[uuid.UUID]schemas.UserInfo, len(users))
+			for _, user := range users {
+				result[user.ID] = user
+			}
+
+			return result, nil
+		}),
+		spending: newLoader(func(keys []spendingKey) (map[spendingKey]uint, error) {
+			groups := make(map[spendingParams][]uuid.UUID)
+			for _, key := range keys {
+				params := spendingParams{key.from, key.to, key.serviceName, key.categoryID, key.hasCategory}
+				groups[params] = append(groups[params], key.userID)
+			}
+
+			result := make(map[spendingKey]uint, len(keys))
+			for params, userIDs := range groups {
+				var categoryID *uint
+				if params.hasCategory {
+					categoryID = &params.categoryID
+				}
+
+				sums, err := subsService.GetSubSumByUsers(
+					ctx, userIDs, &params.serviceName, categoryID, params.from, params.to,
+				)
+				if err != nil {
+					return nil, err
+				}
+
+				for userID, sum := range sums {
+					result[spendingKey{userID, params.from, params.to, params.serviceName, params.categoryID, params.hasCategory}] = sum
+				}
+			}
+
+			return result, nil
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
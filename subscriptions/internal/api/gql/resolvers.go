@@ -0,0 +1,635 @@
+package gql
+
+import (
+	"context"
+	"strconv"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/helpers"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+)
+
+var validate *validator.Validate
+
+func init() {
+	validate = validator.New()
+	validate.RegisterValidation("mm_yyyy_date", helpers.ValidateDateMMYYYYFormatValidator)
+}
+
+// rootResolver passes queries and mutations to Resolver. Without it graphql-go would
+// take the subscription query field for the root of the GraphQL subscription operation.
+type rootResolver struct {
+	resolver *Resolver
+}
+
+func (r *rootResolver) Query() *Resolver    { return r.resolver }
+func (r *rootResolver) Mutation() *Resolver { return r.resolver }
+
+// Resolver resolves the query and mutation fields.
+type Resolver struct {
+	subsService service.SubscriptionService
+	userService service.UserService
+}
+
+func parseID(id graphql.ID) (uint, error) {
+	res, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil {
+		return 0, badRequest(i18n.InvalidID)
+	}
+
+	return uint(res), nil
+}
+
+func parseOptionalID(id *graphql.ID) (*uint, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	res, err := parseID(*id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func parseUserID(id graphql.ID) (uuid.UUID, error) {
+	res, err := uuid.Parse(string(id))
+	if err != nil {
+		return uuid.Nil, badRequest(i18n.InvalidUserID)
+	}
+
+	return res, nil
+}
+
+func parsePage(page, size int32) (int, int, error) {
+	if page < 1 {
+		return 0, 0, badRequest(i18n.InvalidPage)
+	}
+	if size <= 0 || size > maxPageSize {
+		return 0, 0, badRequest(i18n.InvalidPageSize)
+	}
+
+	return int(page), int(size), nil
+}
+
+// scopeUserID limits the optional user filter to the caller.
+func scopeUserID(ctx context.Context, userID *uuid.UUID) (*uuid.UUID, error) {
+	scope := auth.UserScope(ctx)
+	if scope == nil {
+		return userID, nil
+	}
+
+	if userID != nil && *userID != *scope {
+		return nil, forbidden()
+	}
+
+	return scope, nil
+}
+
+func requireScope(ctx context.Context, scope string) error {
+	if !auth.HasScope(ctx, scope) {
+		return forbidden()
+	}
+
+	return nil
+}
+
+func checkUserAccess(ctx context.Context, userID uuid.UUID) error {
+	if !auth.CanAccessUser(ctx, userID) {
+		return forbidden()
+	}
+
+	return nil
+}
+
+// checkSubAccess checks that the caller may access the existing subscription.
+func (r *Resolver) checkSubAccess(ctx context.Context, id uint) error {
+	if auth.UserScope(ctx) == nil {
+		return nil
+	}
+
+	res, err := r.subsService.GetSub(ctx, id)
+	if err != nil {
+		return toQueryError(err)
+	}
+
+	return checkUserAccess(ctx, res.UserID)
+}
+
+func checkPeriod(from, to string) error {
+	if !helpers.ValidateDateMMYYYYFormat(from) {
+		return badRequest(i18n.InvalidStartDate)
+	}
+	if !helpers.ValidateDateMMYYYYFormat(to) {
+		return badRequest(i18n.InvalidEndDate)
+	}
+	if !helpers.CheckStartDateBeforeEndDate(from, to) {
+		return badRequest(i18n.InvalidPeriod)
+	}
+
+	return nil
+}
+
+type subscriptionsArgs struct {
+	Page       int32
+	Size       int32
+	UserID     *graphql.ID
+	CategoryID *graphql.ID
+	Tag        *string
+}
+
+func (r *Resolver) Subscriptions(ctx context.Context, args subscriptionsArgs) (*subscriptionPageResolver, error) {
+	pageNumber, pageSize, err := parsePage(args.Page, args.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter repository.SubsFilter
+
+	if args.UserID != nil {
+		userID, err := parseUserID(*args.UserID)
+		if err != nil {
+			return nil, err
+		}
+		filter.UserID = &userID
+	}
+
+	if filter.UserID, err = scopeUserID(ctx, filter.UserID); err != nil {
+		return nil, err
+	}
+
+	if filter.CategoryID, err = parseOptionalID(args.CategoryID); err != nil {
+		return nil, err
+	}
+	filter.Tag = args.Tag
+
+	res, err := r.subsService.GetAllSubs(ctx, pageNumber, pageSize, filter)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+
+	return &subscriptionPageResolver{resolver: r, page: res}, nil
+}
+
+func (r *Resolver) Subscription(ctx context.Context, args struct{ ID graphql.ID }) (*subscriptionResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := r.getSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkUserAccess(ctx, sub.sub.UserID); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+func (r *Resolver) getSubscription(ctx context.Context, id uint) (*subscriptionResolver, error) {
+	res, err := r.subsService.GetSub(ctx, id)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+
+	return &subscriptionResolver{resolver: r, sub: *res}, nil
+}
+
+func (r *Resolver) Users(ctx context.Context, args struct{ Page, Size int32 }) (*userPageResolver, error) {
+	if err := requireScope(ctx, auth.ScopeAdmin); err != nil {
+		return nil, err
+	}
+
+	pageNumber, pageSize, err := parsePage(args.Page, args.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.userService.GetAllUsers(ctx, pageNumber, pageSize)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+
+	return &userPageResolver{resolver: r, page: res}, nil
+}
+
+func (r *Resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	id, err := parseUserID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkUserAccess(ctx, id); err != nil {
+		return nil, err
+	}
+
+	res, err := r.userService.GetUser(ctx, id)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+
+	return &userResolver{resolver: r, user: *res}, nil
+}
+
+type spendingArgs struct {
+	From        string
+	To          string
+	UserID      *graphql.ID
+	ServiceName *string
+	CategoryID  *graphql.ID
+}
+
+func (r *Resolver) Spending(ctx context.Context, args spendingArgs) (Int64, error) {
+	if err := requireScope(ctx, auth.ScopeReportsRead); err != nil {
+		return 0, err
+	}
+
+	if err := checkPeriod(args.From, args.To); err != nil {
+		return 0, err
+	}
+
+	var userID *uuid.UUID
+	if args.UserID != nil {
+		userIDParse, err := parseUserID(*args.UserID)
+		if err != nil {
+			return 0, err
+		}
+		userID = &userIDParse
+	}
+
+	userID, err := scopeUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	categoryID, err := parseOptionalID(args.CategoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	serviceName := ""
+	if args.ServiceName != nil {
+		serviceName = *args.ServiceName
+	}
+
+	res, err := r.subsService.GetSubSum(ctx, userID, &serviceName, categoryID, args.From, args.To)
+	if err != nil {
+		return 0, toQueryError(err)
+	}
+
+	return toInt64(res)
+}
+
+type subscriptionInput struct {
+	ServiceName string
+	Price       int32
+	UserID      graphql.ID
+	StartDate   string
+	EndDate     *string
+	CategoryID  *graphql.ID
+	Tags        *[]string
+}
+
+func (input subscriptionInput) toFullUpdateSub() (*schemas.FullUpdateSub, error) {
+	userID, err := parseUserID(input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryID, err := parseOptionalID(input.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Price <= 0 {
+		return nil, badRequest(i18n.InvalidPrice)
+	}
+
+	data := schemas.FullUpdateSub{
+		ServiceName: input.ServiceName,
+		Price:       uint(input.Price),
+		UserID:      userID,
+		StartDate:   input.StartDate,
+		EndDate:     input.EndDate,
+		CategoryID:  categoryID,
+	}
+	if input.Tags != nil {
+		data.Tags = *input.Tags
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return nil, badRequest(i18n.InvalidSubscription)
+	}
+
+	if data.EndDate != nil && !helpers.CheckStartDateBeforeEndDate(data.StartDate, *data.EndDate) {
+		return nil, badRequest(i18n.InvalidPeriod)
+	}
+
+	return &data, nil
+}
+
+func (r *Resolver) CreateSubscription(ctx context.Context, args struct{ Input subscriptionInput }) (*subscriptionResolver, error) {
+	if err := requireScope(ctx, auth.ScopeSubsWrite); err != nil {
+		return nil, err
+	}
+
+	data, err := args.Input.toFullUpdateSub()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkUserAccess(ctx, data.UserID); err != nil {
+		return nil, err
+	}
+
+	id, err := r.subsService.CreateSub(ctx, schemas.CreateSub(*data))
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+
+	return r.getSubscription(ctx, id)
+}
+
+func (r *Resolver) UpdateSubscription(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input subscriptionInput
+}) (*subscriptionResolver, error) {
+	if err := requireScope(ctx, auth.ScopeSubsWrite); err != nil {
+		return nil, err
+	}
+
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := args.Input.toFullUpdateSub()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkUserAccess(ctx, data.UserID); err != nil {
+		return nil, err
+	}
+	if err := r.checkSubAccess(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := r.subsService.FullUpdateSub(ctx, id, *data); err != nil {
+		return nil, toQueryError(err)
+	}
+
+	return r.getSubscription(ctx, id)
+}
+
+type subscriptionPatch struct {
+	ServiceName *string
+	Price       *int32
+	UserID      *graphql.ID
+	StartDate   *string
+	EndDate     *string
+	CategoryID  *graphql.ID
+	Tags        *[]string
+}
+
+func (r *Resolver) PatchSubscription(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input subscriptionPatch
+}) (*subscriptionResolver, error) {
+	if err := requireScope(ctx, auth.ScopeSubsWrite); err != nil {
+		return nil, err
+	}
+
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	input := args.Input
+	data := schemas.PatchUpdateSub{
+		ServiceName: input.ServiceName,
+		StartDate:   input.StartDate,
+		EndDate:     input.EndDate,
+		Tags:        input.Tags,
+	}
+
+	if input.Price != nil {
+		if *input.Price <= 0 {
+			return nil, badRequest(i18n.InvalidPrice)
+		}
+		price := uint(*input.Price)
+		data.Price = &price
+	}
+
+	if input.UserID != nil {
+		userID, err := parseUserID(*input.UserID)
+		if err != nil {
+			return nil, err
+		}
+		data.UserID = &userID
+
+		if err := checkUserAccess(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if data.CategoryID, err = parseOptionalID(input.CategoryID); err != nil {
+		return nil, err
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return nil, badRequest(i18n.InvalidSubscription)
+	}
+
+	if data.EndDate != nil && data.StartDate != nil {
+		if !helpers.CheckStartDateBeforeEndDate(*data.StartDate, *data.EndDate) {
+			return nil, badRequest(i18n.InvalidPeriod)
+		}
+	}
+
+	if err := r.checkSubAccess(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if err := r.subsService.PatchUpdateSub(ctx, id, data); err != nil {
+		return nil, toQueryError(err)
+	}
+
+	return r.getSubscription(ctx, id)
+}
+
+func (r *Resolver) DeleteSubscription(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	if err := requireScope(ctx, auth.ScopeSubsWrite); err != nil {
+		return false, err
+	}
+
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.checkSubAccess(ctx, id); err != nil {
+		return false, err
+	}
+
+	if err := r.subsService.DeleteSub(ctx, id); err != nil {
+		return false, toQueryError(err)
+	}
+
+	return true, nil
+}
+
+type paginationResolver struct {
+	pagination schemas.Pagination
+}
+
+func (r *paginationResolver) PageNumber() int32 { return int32(r.pagination.PageNumber) }
+func (r *paginationResolver) Size() int32       { return int32(r.pagination.Size) }
+func (r *paginationResolver) TotalPages() int32 { return int32(r.pagination.TotalPages) }
+func (r *paginationResolver) HasNext() bool     { return r.pagination.HasNext }
+func (r *paginationResolver) HasPrev() bool     { return r.pagination.HasPrev }
+
+type subscriptionPageResolver struct {
+	resolver *Resolver
+	page     *schemas.PaginationResponse
+}
+
+func (r *subscriptionPageResolver) Subscriptions() []*subscriptionResolver {
+	result := make([]*subscriptionResolver, len(r.page.Subscriptions))
+	for i, sub := range r.page.Subscriptions {
+		result[i] = &subscriptionResolver{resolver: r.resolver, sub: sub}
+	}
+
+	return result
+}
+
+func (r *subscriptionPageResolver) Pagination() *paginationResolver {
+	return &paginationResolver{r.page.Pagination}
+}
+
+type subscriptionResolver struct {
+	resolver *Resolver
+	sub      schemas.FullSubInfo
+}
+
+func (r *subscriptionResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(r.sub.ID), 10))
+}
+
+func (r *subscriptionResolver) ServiceName() string { return r.sub.ServiceName }
+func (r *subscriptionResolver) UserID() graphql.ID  { return graphql.ID(r.sub.UserID.String()) }
+func (r *subscriptionResolver) StartDate() string   { return r.sub.StartDate.Format("01-2006") }
+func (r *subscriptionResolver) Tags() []string      { return r.sub.Tags }
+
+func (r *subscriptionResolver) Price() (Int64, error) {
+	return toInt64(r.sub.Price)
+}
+
+func (r *subscriptionResolver) EndDate() *string {
+	if r.sub.EndDate == nil {
+		return nil
+	}
+
+	endDate := r.sub.EndDate.Format("01-2006")
+	return &endDate
+}
+
+func (r *subscriptionResolver) CategoryID() *graphql.ID {
+	if r.sub.CategoryID == nil {
+		return nil
+	}
+
+	id := graphql.ID(strconv.FormatUint(uint64(*r.sub.CategoryID), 10))
+	return &id
+}
+
+func (r *subscriptionResolver) User(ctx context.Context) (*userResolver, error) {
+	user, ok, err := loadersFrom(ctx).users.Load(r.sub.UserID)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	return &userResolver{resolver: r.resolver, user: user}, nil
+}
+
+type userPageResolver struct {
+	resolver *Resolver
+	page     *schemas.UsersPaginationResponse
+}
+
+func (r *userPageResolver) Users() []*userResolver {
+	result := make([]*userResolver, len(r.page.Users))
+	for i, user := range r.page.Users {
+		result[i] = &userResolver{resolver: r.resolver, user: user}
+	}
+
+	return result
+}
+
+func (r *userPageResolver) Pagination() *paginationResolver {
+	return &paginationResolver{r.page.Pagination}
+}
+
+type userResolver struct {
+	resolver *Resolver
+	user     schemas.UserInfo
+}
+
+func (r *userResolver) ID() graphql.ID            { return graphql.ID(r.user.ID.String()) }
+func (r *userResolver) DisplayName() string       { return r.user.DisplayName }
+func (r *userResolver) Email() *string            { return r.user.Email }
+func (r *userResolver) Locale() string            { return r.user.Locale }
+func (r *userResolver) PreferredCurrency() string { return r.user.PreferredCurrency }
+
+func (r *userResolver) Subscriptions(ctx context.Context, args struct{ Page, Size int32 }) (*subscriptionPageResolver, error) {
+	userID := graphql.ID(r.user.ID.String())
+	return r.resolver.Subscriptions(ctx, subscriptionsArgs{Page: args.Page, Size: args.Size, UserID: &userID})
+}
+
+func (r *userResolver) Spending(ctx context.Context, args struct {
+	From        string
+	To          string
+	ServiceName *string
+	CategoryID  *graphql.ID
+}) (Int64, error) {
+	if err := requireScope(ctx, auth.ScopeReportsRead); err != nil {
+		return 0, err
+	}
+
+	if err := checkPeriod(args.From, args.To); err != nil {
+		return 0, err
+	}
+
+	categoryID, err := parseOptionalID(args.CategoryID)
+	if err != nil {
+		return 0, err
+	}
+
+	key := spendingKey{userID: r.user.ID, from: args.From, to: args.To}
+	if args.ServiceName != nil {
+		key.serviceName = *args.ServiceName
+	}
+	if categoryID != nil {
+		key.categoryID, key.hasCategory = *categoryID, true
+	}
+
+	sum, _, err := loadersFrom(ctx).spending.Load(key)
+	if err != nil {
+		return 0, toQueryError(err)
+	}
+
+	return toInt64(sum)
+}
@@ -0,0 +1,50 @@
+package gql
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// Int64 is the Int64 scalar of the schema. Prices and sums are returned as it, the
+// sums of many subscriptions don't fit the 32-bit GraphQL Int.
+type Int64 int64
+
+// toInt64 converts the price or the sum of the service, values out of the range
+// of Int64 are an error instead of wrapping around.
+func toInt64(value uint) (Int64, error) {
+	if uint64(value) > math.MaxInt64 {
+		return 0, &queryError{code: http.StatusInternalServerError, message: "value out of range of Int64"}
+	}
+
+	return Int64(value), nil
+}
+
+func (Int64) ImplementsGraphQLType(name string) bool {
+	return name == "Int64"
+}
+
+func (n *Int64) UnmarshalGraphQL(input any) error {
+	switch input := input.(type) {
+	case int32:
+		*n = Int64(input)
+	case int64:
+		*n = Int64(input)
+	case float64:
+		if input != math.Trunc(input) || input < math.MinInt64 || input >= math.MaxInt64 {
+			return fmt.Errorf("invalid Int64 value: %v", input)
+		}
+		*n = Int64(input)
+	case string:
+		value, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid Int64 value: %q", input)
+		}
+		*n = Int64(value)
+	default:
+		return fmt.Errorf("wrong type for Int64: %T", input)
+	}
+
+	return nil
+}
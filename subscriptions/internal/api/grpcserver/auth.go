@@ -0,0 +1,130 @@
+package grpcserver
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/service"
+	"subscriptions/rest-service/internal/tenant"
+	"subscriptions/rest-service/pkg/logger"
+	subscriptionsv1 "subscriptions/rest-service/pkg/pb/subscriptions/v1"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// methodScopes are the scopes required by the methods of the subscription service.
+var methodScopes = map[string]string{
+	subscriptionsv1.SubscriptionService_ListSubscriptions_FullMethodName:  auth.ScopeSubsRead,
+	subscriptionsv1.SubscriptionService_GetSubscription_FullMethodName:    auth.ScopeSubsRead,
+	subscriptionsv1.SubscriptionService_CreateSubscription_FullMethodName: auth.ScopeSubsWrite,
+	subscriptionsv1.SubscriptionService_UpdateSubscription_FullMethodName: auth.ScopeSubsWrite,
+	subscriptionsv1.SubscriptionService_PatchSubscription_FullMethodName:  auth.ScopeSubsWrite,
+	subscriptionsv1.SubscriptionService_DeleteSubscription_FullMethodName: auth.ScopeSubsWrite,
+	subscriptionsv1.SubscriptionService_GetSubscriptionSum_FullMethodName: auth.ScopeReportsRead,
+}
+
+// authInterceptor authenticates the calls of the subscription service with the bearer
+// token of the authorization metadata or the API key of the x-api-key metadata, checks
+// the scope of the method and puts the tenant of the call to the context. Super admins
+// and unauthenticated calls select the tenant with the x-tenant-id metadata. Health
+// checking and reflection stay public.
+func authInterceptor(authenticator *auth.Authenticator, tenants service.TenantService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !strings.HasPrefix(info.FullMethod, "/subscriptions.v1.") {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		var principal *auth.Principal
+		if authenticator != nil {
+			var token string
+			if values := md.Get("authorization"); len(values) > 0 {
+				scheme, value, ok := strings.Cut(values[0], " ")
+				if ok && strings.EqualFold(scheme, "Bearer") {
+					token = strings.TrimSpace(value)
+				}
+			}
+			if values := md.Get("x-api-key"); token == "" && len(values) > 0 {
+				token = strings.TrimSpace(values[0])
+			}
+
+			if token == "" {
+				return nil, errorStatus(codes.Unauthenticated, i18n.MissingCredentials)
+			}
+
+			var err error
+			principal, err = authenticator.Authenticate(ctx, token)
+			if err != nil {
+				slog.WarnContext(ctx, "authenticate failed", "error", err)
+				return nil, errorStatus(codes.Unauthenticated, i18n.InvalidCredentials)
+			}
+
+			// methods missing from the map are denied rather than left unprotected
+			scope, ok := methodScopes[info.FullMethod]
+			if !ok {
+				return nil, errorStatus(codes.PermissionDenied, i18n.Forbidden)
+			}
+			if !principal.HasScope(scope) {
+				return nil, errorStatus(codes.PermissionDenied, i18n.InsufficientScope, scope)
+			}
+
+			setAccessLogClient(ctx, principal)
+			ctx = auth.WithPrincipal(logger.WithAttrs(ctx, slog.String("user", principal.Subject)), principal)
+		}
+
+		var requested string
+		if values := md.Get(tenant.Header); len(values) > 0 {
+			requested = values[0]
+		}
+
+		tenantID, err := tenants.ResolveTenant(ctx, principal, requested)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+
+		ctx = logger.WithAttrs(ctx, slog.String("tenant", tenantID))
+		return handler(tenant.WithID(ctx, tenantID), req)
+	}
+}
+
+func checkUserAccess(ctx context.Context, userID uuid.UUID) error {
+	if !auth.CanAccessUser(ctx, userID) {
+		return errorStatus(codes.PermissionDenied, i18n.Forbidden)
+	}
+
+	return nil
+}
+
+// scopeUserID limits the optional user filter to the caller.
+func scopeUserID(ctx context.Context, userID *uuid.UUID) (*uuid.UUID, error) {
+	scope := auth.UserScope(ctx)
+	if scope == nil {
+		return userID, nil
+	}
+
+	if userID != nil && *userID != *scope {
+		return nil, errorStatus(codes.PermissionDenied, i18n.Forbidden)
+	}
+
+	return scope, nil
+}
+
+// checkSubAccess checks that the caller may access the existing subscription.
+func (s *SubscriptionServer) checkSubAccess(ctx context.Context, id uint) error {
+	if auth.UserScope(ctx) == nil {
+		return nil
+	}
+
+	res, err := s.service.GetSub(ctx, id)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	return checkUserAccess(ctx, res.UserID)
+}
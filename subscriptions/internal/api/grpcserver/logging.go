@@ -0,0 +1,76 @@
+package grpcserver
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/requestid"
+	"subscriptions/rest-service/pkg/logger"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type accessLogKey struct{}
+
+// accessLogEntry collects the fields of the access log line that are known only to the
+// following interceptors.
+type accessLogEntry struct {
+	client string
+}
+
+// setAccessLogClient records the authenticated caller in the access log line of the call.
+func setAccessLogClient(ctx context.Context, principal *auth.Principal) {
+	if entry, ok := ctx.Value(accessLogKey{}).(*accessLogEntry); ok {
+		entry.client = principal.Subject
+	}
+}
+
+// logInterceptor takes the x-request-id of the caller or generates one and returns it in
+// the response header. The ID and the method are put to the context, so every log line
+// of the call has them, and one access log line is written per call.
+func logInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var requested string
+	if values := md.Get(requestid.Header); len(values) > 0 {
+		requested = values[0]
+	}
+
+	id := requestid.Resolve(requested)
+	grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(requestid.Header), id))
+
+	ctx = logger.WithAttrs(
+		requestid.WithID(ctx, id),
+		slog.String("request_id", id),
+		slog.String("route", info.FullMethod),
+	)
+
+	entry := &accessLogEntry{client: "anonymous"}
+	resp, err := handler(context.WithValue(ctx, accessLogKey{}, entry), req)
+
+	code := status.Code(err)
+	attrs := []slog.Attr{
+		slog.String("code", code.String()),
+		slog.Duration("latency", time.Since(start)),
+		slog.String("client_ip", peerIP(ctx)),
+		slog.String("client", entry.client),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	level := slog.LevelInfo
+	if code == codes.Internal || code == codes.Unknown || code == codes.Unavailable {
+		level = slog.LevelError
+	}
+
+	slog.LogAttrs(ctx, level, "request", attrs...)
+	return resp, err
+}
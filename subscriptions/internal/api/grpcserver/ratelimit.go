@@ -0,0 +1,81 @@
+package grpcserver
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/ratelimit"
+	"subscriptions/rest-service/internal/tenant"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ipRateLimitInterceptor limits the calls of the subscription service from the client
+// address, it runs before authentication.
+func ipRateLimitInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if limiter == nil || !strings.HasPrefix(info.FullMethod, "/subscriptions.v1.") {
+			return handler(ctx, req)
+		}
+
+		if err := checkRateLimit(ctx, limiter.TakeIP(ctx, peerIP(ctx))); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// clientRateLimitInterceptor limits the calls of the authenticated caller, overall
+// and to the method. It runs after authentication.
+func clientRateLimitInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if limiter == nil || !strings.HasPrefix(info.FullMethod, "/subscriptions.v1.") {
+			return handler(ctx, req)
+		}
+
+		client := "ip:" + peerIP(ctx)
+		if principal := auth.FromContext(ctx); principal != nil {
+			tenantID, _ := tenant.FromContext(ctx)
+			client = tenantID + "/" + principal.Subject
+		}
+
+		if err := checkRateLimit(ctx, limiter.TakeClient(ctx, client, info.FullMethod)); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func checkRateLimit(ctx context.Context, result *ratelimit.Result) error {
+	if result == nil || result.Allowed {
+		return nil
+	}
+
+	retryAfter := max(int(math.Ceil(result.RetryAfter.Seconds())), 1)
+	grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(retryAfter)))
+
+	return errorStatus(codes.ResourceExhausted, i18n.RateLimited)
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
@@ -0,0 +1,101 @@
+/*
+Package grpcserver serves the subscription API over gRPC on top of the same
+services as the REST handlers.
+*/
+package grpcserver
+
+import (
+	"errors"
+	"net/http"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/ratelimit"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+	subscriptionsv1 "subscriptions/rest-service/pkg/pb/subscriptions/v1"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer returns the gRPC server with the subscription service, health checking
+// and reflection registered. A nil authenticator disables authentication and a nil
+// limiter disables rate limiting. The health server is returned to report the serving status.
+func NewServer(
+	subsService service.SubscriptionService,
+	authenticator *auth.Authenticator,
+	tenants service.TenantService,
+	limiter *ratelimit.Limiter,
+) (*grpc.Server, *health.Server) {
+	server := grpc.NewServer(
+		// the spans continue the W3C trace context of the metadata
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			logInterceptor,
+			ipRateLimitInterceptor(limiter),
+			authInterceptor(authenticator, tenants),
+			clientRateLimitInterceptor(limiter),
+		),
+	)
+
+	subscriptionsv1.RegisterSubscriptionServiceServer(server, NewSubscriptionServer(subsService))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(
+		subscriptionsv1.SubscriptionService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING,
+	)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+
+	return server, healthServer
+}
+
+// errorDomain is the domain of the codes of the messages in the error details.
+const errorDomain = "subscriptions"
+
+// errorStatus returns the gRPC status with the message in English and the params in its
+// placeholders, the code of the message is the reason of the ErrorInfo details so the
+// clients can localize it themselves.
+func errorStatus(code codes.Code, message i18n.Message, params ...string) error {
+	text := i18n.Text(i18n.English, message, params...)
+
+	st, err := status.New(code, text).WithDetails(&errdetails.ErrorInfo{
+		Reason: string(message),
+		Domain: errorDomain,
+	})
+	if err != nil {
+		return status.Error(code, text)
+	}
+
+	return st.Err()
+}
+
+// toStatus converts the service error to a gRPC status, other errors are reported as
+// internal errors.
+func toStatus(err error) error {
+	var serviceErr *schemas.AppError
+	if !errors.As(err, &serviceErr) {
+		return errorStatus(codes.Internal, i18n.InternalError)
+	}
+
+	code := codes.Internal
+	switch serviceErr.Code {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		code = codes.InvalidArgument
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.AlreadyExists
+	}
+
+	return errorStatus(code, serviceErr.Message)
+}
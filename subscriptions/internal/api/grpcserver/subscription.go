@@ -0,0 +1,336 @@
+package grpcserver
+
+import (
+	"context"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/helpers"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+	subscriptionsv1 "subscriptions/rest-service/pkg/pb/subscriptions/v1"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var validate *validator.Validate
+
+func init() {
+	validate = validator.New()
+	validate.RegisterValidation("mm_yyyy_date", helpers.ValidateDateMMYYYYFormatValidator)
+}
+
+type SubscriptionServer struct {
+	subscriptionsv1.UnimplementedSubscriptionServiceServer
+	service service.SubscriptionService
+}
+
+func NewSubscriptionServer(serviceInput service.SubscriptionService) *SubscriptionServer {
+	return &SubscriptionServer{
+		service: serviceInput,
+	}
+}
+
+func toSubscription(info schemas.FullSubInfo) *subscriptionsv1.Subscription {
+	sub := &subscriptionsv1.Subscription{
+		Id:          uint64(info.ID),
+		ServiceName: info.ServiceName,
+		Price:       uint64(info.Price),
+		UserId:      info.UserID.String(),
+		StartDate:   info.StartDate.Format("01-2006"),
+		Tags:        info.Tags,
+	}
+
+	if info.EndDate != nil {
+		endDate := info.EndDate.Format("01-2006")
+		sub.EndDate = &endDate
+	}
+
+	if info.CategoryID != nil {
+		categoryID := uint64(*info.CategoryID)
+		sub.CategoryId = &categoryID
+	}
+
+	return sub
+}
+
+func parseUserID(input string) (uuid.UUID, error) {
+	userID, err := uuid.Parse(input)
+	if err != nil {
+		return uuid.Nil, errorStatus(codes.InvalidArgument, i18n.InvalidUserID)
+	}
+
+	return userID, nil
+}
+
+func optionalUint(value *uint64) *uint {
+	if value == nil {
+		return nil
+	}
+
+	res := uint(*value)
+	return &res
+}
+
+// toFullUpdateSub converts and validates the input, it is used for both create and full update.
+func toFullUpdateSub(input *subscriptionsv1.SubscriptionInput) (*schemas.FullUpdateSub, error) {
+	if input == nil {
+		return nil, errorStatus(codes.InvalidArgument, i18n.InvalidSubscription)
+	}
+
+	userID, err := parseUserID(input.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	data := schemas.FullUpdateSub{
+		ServiceName: input.GetServiceName(),
+		Price:       uint(input.GetPrice()),
+		UserID:      userID,
+		StartDate:   input.GetStartDate(),
+		EndDate:     input.EndDate,
+		CategoryID:  optionalUint(input.CategoryId),
+		Tags:        input.GetTags(),
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return nil, errorStatus(codes.InvalidArgument, i18n.InvalidSubscription)
+	}
+
+	if data.EndDate != nil && !helpers.CheckStartDateBeforeEndDate(data.StartDate, *data.EndDate) {
+		return nil, errorStatus(codes.InvalidArgument, i18n.InvalidPeriod)
+	}
+
+	return &data, nil
+}
+
+func (s *SubscriptionServer) getSubscription(ctx context.Context, id uint) (*subscriptionsv1.Subscription, error) {
+	res, err := s.service.GetSub(ctx, id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toSubscription(*res), nil
+}
+
+func (s *SubscriptionServer) ListSubscriptions(
+	ctx context.Context, req *subscriptionsv1.ListSubscriptionsRequest,
+) (*subscriptionsv1.ListSubscriptionsResponse, error) {
+	page, size := int(req.GetPage()), int(req.GetSize())
+	if page == 0 {
+		page = 1
+	}
+	if size == 0 {
+		size = 10
+	}
+
+	filter := repository.SubsFilter{
+		UserID:     auth.UserScope(ctx),
+		CategoryID: optionalUint(req.CategoryId),
+		Tag:        req.Tag,
+	}
+
+	res, err := s.service.GetAllSubs(ctx, page, size, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	subs := make([]*subscriptionsv1.Subscription, len(res.Subscriptions))
+	for i, info := range res.Subscriptions {
+		subs[i] = toSubscription(info)
+	}
+
+	return &subscriptionsv1.ListSubscriptionsResponse{
+		Subscriptions: subs,
+		Pagination: &subscriptionsv1.Pagination{
+			PageNumber: uint32(res.Pagination.PageNumber),
+			Size:       uint32(res.Pagination.Size),
+			TotalPages: uint32(res.Pagination.TotalPages),
+			HasNext:    res.Pagination.HasNext,
+			HasPrev:    res.Pagination.HasPrev,
+		},
+	}, nil
+}
+
+func (s *SubscriptionServer) GetSubscription(
+	ctx context.Context, req *subscriptionsv1.GetSubscriptionRequest,
+) (*subscriptionsv1.Subscription, error) {
+	res, err := s.service.GetSub(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := checkUserAccess(ctx, res.UserID); err != nil {
+		return nil, err
+	}
+
+	return toSubscription(*res), nil
+}
+
+func (s *SubscriptionServer) CreateSubscription(
+	ctx context.Context, req *subscriptionsv1.CreateSubscriptionRequest,
+) (*subscriptionsv1.Subscription, error) {
+	data, err := toFullUpdateSub(req.GetSubscription())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkUserAccess(ctx, data.UserID); err != nil {
+		return nil, err
+	}
+
+	id, err := s.service.CreateSub(ctx, schemas.CreateSub(*data))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return s.getSubscription(ctx, id)
+}
+
+func (s *SubscriptionServer) UpdateSubscription(
+	ctx context.Context, req *subscriptionsv1.UpdateSubscriptionRequest,
+) (*subscriptionsv1.Subscription, error) {
+	data, err := toFullUpdateSub(req.GetSubscription())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkUserAccess(ctx, data.UserID); err != nil {
+		return nil, err
+	}
+	if err := s.checkSubAccess(ctx, uint(req.GetId())); err != nil {
+		return nil, err
+	}
+
+	if err := s.service.FullUpdateSub(ctx, uint(req.GetId()), *data); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return s.getSubscription(ctx, uint(req.GetId()))
+}
+
+func (s *SubscriptionServer) PatchSubscription(
+	ctx context.Context, req *subscriptionsv1.PatchSubscriptionRequest,
+) (*subscriptionsv1.Subscription, error) {
+	input := req.GetSubscription()
+	if input == nil || len(req.GetUpdateMask().GetPaths()) == 0 {
+		return nil, errorStatus(codes.InvalidArgument, i18n.UpdateMaskRequired)
+	}
+
+	var data schemas.PatchUpdateSub
+
+	for _, path := range req.GetUpdateMask().GetPaths() {
+		switch path {
+		case "service_name":
+			data.ServiceName = &input.ServiceName
+		case "price":
+			price := uint(input.GetPrice())
+			data.Price = &price
+		case "user_id":
+			userID, err := parseUserID(input.GetUserId())
+			if err != nil {
+				return nil, err
+			}
+			if err := checkUserAccess(ctx, userID); err != nil {
+				return nil, err
+			}
+			data.UserID = &userID
+		case "start_date":
+			data.StartDate = &input.StartDate
+		case "end_date":
+			if input.EndDate == nil {
+				return nil, errorStatus(codes.InvalidArgument, i18n.UpdatePathNotClearable, path)
+			}
+			data.EndDate = input.EndDate
+		case "category_id":
+			if input.CategoryId == nil {
+				return nil, errorStatus(codes.InvalidArgument, i18n.UpdatePathNotClearable, path)
+			}
+			data.CategoryID = optionalUint(input.CategoryId)
+		case "tags":
+			tags := input.GetTags()
+			if tags == nil {
+				tags = []string{}
+			}
+			data.Tags = &tags
+		default:
+			return nil, errorStatus(codes.InvalidArgument, i18n.UnknownUpdatePath, path)
+		}
+	}
+
+	if err := validate.Struct(data); err != nil {
+		return nil, errorStatus(codes.InvalidArgument, i18n.InvalidUpdate)
+	}
+
+	if data.EndDate != nil && data.StartDate != nil {
+		if !helpers.CheckStartDateBeforeEndDate(*data.StartDate, *data.EndDate) {
+			return nil, errorStatus(codes.InvalidArgument, i18n.InvalidPeriod)
+		}
+	}
+
+	if err := s.checkSubAccess(ctx, uint(req.GetId())); err != nil {
+		return nil, err
+	}
+
+	if err := s.service.PatchUpdateSub(ctx, uint(req.GetId()), data); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return s.getSubscription(ctx, uint(req.GetId()))
+}
+
+func (s *SubscriptionServer) DeleteSubscription(
+	ctx context.Context, req *subscriptionsv1.DeleteSubscriptionRequest,
+) (*emptypb.Empty, error) {
+	if err := s.checkSubAccess(ctx, uint(req.GetId())); err != nil {
+		return nil, err
+	}
+
+	if err := s.service.DeleteSub(ctx, uint(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *SubscriptionServer) GetSubscriptionSum(
+	ctx context.Context, req *subscriptionsv1.GetSubscriptionSumRequest,
+) (*subscriptionsv1.GetSubscriptionSumResponse, error) {
+	startDate, endDate := req.GetStartDate(), req.GetEndDate()
+
+	if !helpers.ValidateDateMMYYYYFormat(startDate) {
+		return nil, errorStatus(codes.InvalidArgument, i18n.InvalidStartDate)
+	}
+	if !helpers.ValidateDateMMYYYYFormat(endDate) {
+		return nil, errorStatus(codes.InvalidArgument, i18n.InvalidEndDate)
+	}
+	if !helpers.CheckStartDateBeforeEndDate(startDate, endDate) {
+		return nil, errorStatus(codes.InvalidArgument, i18n.InvalidPeriod)
+	}
+
+	var userID *uuid.UUID
+	if req.UserId != nil {
+		userIDParse, err := parseUserID(req.GetUserId())
+		if err != nil {
+			return nil, err
+		}
+		userID = &userIDParse
+	}
+
+	userID, err := scopeUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := req.GetServiceName()
+
+	totalSum, err := s.service.GetSubSum(ctx, userID, &serviceName, optionalUint(req.CategoryId), startDate, endDate)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &subscriptionsv1.GetSubscriptionSumResponse{TotalSum: uint64(totalSum)}, nil
+}
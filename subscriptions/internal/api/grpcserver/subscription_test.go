@@ -0,0 +1,411 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+	"subscriptions/rest-service/internal/api/grpcserver"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/service"
+	subscriptionsv1 "subscriptions/rest-service/pkg/pb/subscriptions/v1"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"gorm.io/gorm"
+)
+
+// subsRepo keeps one subscription, the reads fail with err if it is set and the writes
+// are recorded. The methods the tests don't reach panic.
+type subsRepo struct {
+	repository.SubscriptionRepo
+
+	record models.Subscription
+	err    error
+	sum    *uint
+
+	created []string
+	fields  map[string]any
+}
+
+func (r *subsRepo) GetRecord(_ context.Context, id uint) (*models.Subscription, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if id != r.record.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &r.record, nil
+}
+
+func (r *subsRepo) CreateRecord(_ context.Context, serviceName string, _ time.Time, _ uint, _ uuid.UUID, _ *time.Time, _ *uint, _ []string, hook repository.WriteHook) (*uint, error) {
+	r.created = append(r.created, serviceName)
+	return &r.record.ID, hook(repository.TxRepos{Subscriptions: r, Budgets: budgetRepo{}}, r.record.ID)
+}
+
+func (r *subsRepo) UpdateRecord(_ context.Context, id uint, fields map[string]any, _ *[]string, _ repository.WriteHook) (uuid.UUID, error) {
+	r.fields = fields
+	return r.record.UserID, nil
+}
+
+func (r *subsRepo) DeleteRecord(_ context.Context, id uint) (uuid.UUID, error) {
+	if id != r.record.ID {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	return r.record.UserID, r.err
+}
+
+func (r *subsRepo) GetSubsSum(context.Context, *uuid.UUID, *string, *uint, string, string) *uint {
+	return r.sum
+}
+
+// userRepo creates the users on the writes.
+type userRepo struct {
+	repository.UserRepo
+}
+
+func (userRepo) EnsureUser(context.Context, uuid.UUID) error {
+	return nil
+}
+
+// budgetRepo has no budgets.
+type budgetRepo struct {
+	repository.BudgetRepo
+}
+
+func (budgetRepo) GetBudgets(context.Context, *uuid.UUID) ([]models.Budget, error) {
+	return nil, nil
+}
+
+func newServer(repo *subsRepo) *grpcserver.SubscriptionServer {
+	return grpcserver.NewSubscriptionServer(service.NewService(repo, nil, userRepo{}, budgetRepo{}, true, nil))
+}
+
+func subscription() models.Subscription {
+	endDate := time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC)
+	categoryID := uint(3)
+
+	return models.Subscription{
+		ID:          7,
+		ServiceName: "Yandex Plus",
+		Price:       400,
+		UserID:      uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba"),
+		StartDate:   time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     &endDate,
+		CategoryID:  &categoryID,
+		Tags:        []models.Tag{{Name: "music"}},
+	}
+}
+
+func input() *subscriptionsv1.SubscriptionInput {
+	return &subscriptionsv1.SubscriptionInput{
+		ServiceName: "Yandex Plus",
+		Price:       400,
+		UserId:      "60601fee-2bf1-4721-ae6f-7636e79a0cba",
+		StartDate:   "07-2025",
+	}
+}
+
+// reason returns the reason of the ErrorInfo details of the status error.
+func reason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == "subscriptions" {
+			return info.Reason
+		}
+	}
+
+	return ""
+}
+
+func TestSubscriptionErrors(t *testing.T) {
+	str := func(value string) *string { return &value }
+
+	invalidUser := input()
+	invalidUser.UserId = "user"
+	endBeforeStart := input()
+	endBeforeStart.EndDate = str("06-2025")
+	invalidDate := input()
+	invalidDate.StartDate = "2025-07"
+
+	patch := func(paths ...string) *subscriptionsv1.PatchSubscriptionRequest {
+		return &subscriptionsv1.PatchSubscriptionRequest{
+			Id:           7,
+			Subscription: input(),
+			UpdateMask:   &fieldmaskpb.FieldMask{Paths: paths},
+		}
+	}
+
+	tests := []struct {
+		name string
+		// repoErr fails the reads and the delete of the subscription
+		repoErr error
+		call    func(server *grpcserver.SubscriptionServer) error
+		code    codes.Code
+		// reason is the code of the message in the ErrorInfo details
+		reason i18n.Message
+	}{
+		{
+			name: "missing subscription",
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.GetSubscription(context.Background(), &subscriptionsv1.GetSubscriptionRequest{Id: 1})
+				return err
+			},
+			code:   codes.NotFound,
+			reason: i18n.SubscriptionNotFound,
+		},
+		{
+			name:    "repository failure",
+			repoErr: errors.New("connection refused"),
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.GetSubscription(context.Background(), &subscriptionsv1.GetSubscriptionRequest{Id: 7})
+				return err
+			},
+			code:   codes.Internal,
+			reason: i18n.RetrieveSubscriptionFailed,
+		},
+		{
+			name: "delete missing subscription",
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.DeleteSubscription(context.Background(), &subscriptionsv1.DeleteSubscriptionRequest{Id: 1})
+				return err
+			},
+			code:   codes.NotFound,
+			reason: i18n.SubscriptionNotFound,
+		},
+		{
+			name: "create without subscription",
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.CreateSubscription(context.Background(), &subscriptionsv1.CreateSubscriptionRequest{})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: i18n.InvalidSubscription,
+		},
+		{
+			name: "create with invalid user id",
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.CreateSubscription(context.Background(), &subscriptionsv1.CreateSubscriptionRequest{Subscription: invalidUser})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: i18n.InvalidUserID,
+		},
+		{
+			name: "create with invalid date",
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.CreateSubscription(context.Background(), &subscriptionsv1.CreateSubscriptionRequest{Subscription: invalidDate})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: i18n.InvalidSubscription,
+		},
+		{
+			name: "update with end before start",
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.UpdateSubscription(context.Background(), &subscriptionsv1.UpdateSubscriptionRequest{Id: 7, Subscription: endBeforeStart})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: i18n.InvalidPeriod,
+		},
+		{
+			name: "patch without update mask",
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.PatchSubscription(context.Background(), patch())
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: i18n.UpdateMaskRequired,
+		},
+		{
+			name: "patch unknown path",
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.PatchSubscription(context.Background(), patch("service_name", "owner"))
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: i18n.UnknownUpdatePath,
+		},
+		{
+			name: "patch clearing end date",
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.PatchSubscription(context.Background(), patch("end_date"))
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: i18n.UpdatePathNotClearable,
+		},
+		{
+			name: "sum with invalid period",
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.GetSubscriptionSum(context.Background(), &subscriptionsv1.GetSubscriptionSumRequest{StartDate: "08-2025", EndDate: "07-2025"})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: i18n.InvalidPeriod,
+		},
+		{
+			name: "sum not calculated",
+			call: func(server *grpcserver.SubscriptionServer) error {
+				_, err := server.GetSubscriptionSum(context.Background(), &subscriptionsv1.GetSubscriptionSumRequest{StartDate: "07-2025", EndDate: "08-2025"})
+				return err
+			},
+			code:   codes.InvalidArgument,
+			reason: i18n.SumFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &subsRepo{record: subscription(), err: tt.repoErr}
+
+			err := tt.call(newServer(repo))
+			if status.Code(err) != tt.code {
+				t.Errorf("expected code %s, got %v", tt.code, err)
+			}
+			if got := reason(err); got != string(tt.reason) {
+				t.Errorf("expected reason %s, got %q", tt.reason, got)
+			}
+			if len(repo.created) != 0 || repo.fields != nil {
+				t.Errorf("expected no writes, got created %v and updated %v", repo.created, repo.fields)
+			}
+		})
+	}
+}
+
+func TestSubscriptionCalls(t *testing.T) {
+	server := newServer(&subsRepo{record: subscription()})
+
+	t.Run("get converts the subscription", func(t *testing.T) {
+		sub, err := server.GetSubscription(context.Background(), &subscriptionsv1.GetSubscriptionRequest{Id: 7})
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+
+		if sub.GetId() != 7 || sub.GetPrice() != 400 || sub.GetStartDate() != "07-2025" || sub.GetEndDate() != "12-2025" ||
+			sub.GetCategoryId() != 3 || len(sub.GetTags()) != 1 || sub.GetTags()[0] != "music" {
+			t.Errorf("unexpected subscription %v", sub)
+		}
+	})
+
+	t.Run("create returns the created subscription", func(t *testing.T) {
+		repo := &subsRepo{record: subscription()}
+
+		sub, err := newServer(repo).CreateSubscription(context.Background(), &subscriptionsv1.CreateSubscriptionRequest{Subscription: input()})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if len(repo.created) != 1 || sub.GetId() != 7 {
+			t.Errorf("expected subscription 7 created once, got %v and %v", sub, repo.created)
+		}
+	})
+
+	t.Run("patch updates the fields of the mask", func(t *testing.T) {
+		repo := &subsRepo{record: subscription()}
+
+		_, err := newServer(repo).PatchSubscription(context.Background(), &subscriptionsv1.PatchSubscriptionRequest{
+			Id:           7,
+			Subscription: &subscriptionsv1.SubscriptionInput{Price: 500, ServiceName: "ignored"},
+			UpdateMask:   &fieldmaskpb.FieldMask{Paths: []string{"price"}},
+		})
+		if err != nil {
+			t.Fatalf("patch: %v", err)
+		}
+		// the fields pass through JSON in the service, so the numbers are float64
+		if len(repo.fields) != 1 || repo.fields["price"] != float64(500) {
+			t.Errorf("expected only the price updated, got %v", repo.fields)
+		}
+	})
+
+	t.Run("sum", func(t *testing.T) {
+		sum := uint(1200)
+
+		res, err := newServer(&subsRepo{sum: &sum}).GetSubscriptionSum(context.Background(), &subscriptionsv1.GetSubscriptionSumRequest{
+			StartDate: "07-2025",
+			EndDate:   "09-2025",
+		})
+		if err != nil {
+			t.Fatalf("sum: %v", err)
+		}
+		if res.GetTotalSum() != 1200 {
+			t.Errorf("expected sum 1200, got %d", res.GetTotalSum())
+		}
+	})
+}
+
+func TestSubscriptionAccess(t *testing.T) {
+	owner := subscription().UserID
+	other := uuid.New()
+	sum := uint(400)
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		call      func(ctx context.Context, server *grpcserver.SubscriptionServer) error
+		code      codes.Code
+	}{
+		{
+			name:      "owner gets the subscription",
+			principal: &auth.Principal{UserID: &owner},
+			call: func(ctx context.Context, server *grpcserver.SubscriptionServer) error {
+				_, err := server.GetSubscription(ctx, &subscriptionsv1.GetSubscriptionRequest{Id: 7})
+				return err
+			},
+			code: codes.OK,
+		},
+		{
+			name:      "other user gets the subscription",
+			principal: &auth.Principal{UserID: &other},
+			call: func(ctx context.Context, server *grpcserver.SubscriptionServer) error {
+				_, err := server.GetSubscription(ctx, &subscriptionsv1.GetSubscriptionRequest{Id: 7})
+				return err
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name:      "other user deletes the subscription",
+			principal: &auth.Principal{UserID: &other},
+			call: func(ctx context.Context, server *grpcserver.SubscriptionServer) error {
+				_, err := server.DeleteSubscription(ctx, &subscriptionsv1.DeleteSubscriptionRequest{Id: 7})
+				return err
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name:      "admin gets the subscription",
+			principal: &auth.Principal{UserID: &other, Admin: true},
+			call: func(ctx context.Context, server *grpcserver.SubscriptionServer) error {
+				_, err := server.GetSubscription(ctx, &subscriptionsv1.GetSubscriptionRequest{Id: 7})
+				return err
+			},
+			code: codes.OK,
+		},
+		{
+			name:      "sum of another user",
+			principal: &auth.Principal{UserID: &other},
+			call: func(ctx context.Context, server *grpcserver.SubscriptionServer) error {
+				ownerID := owner.String()
+				_, err := server.GetSubscriptionSum(ctx, &subscriptionsv1.GetSubscriptionSumRequest{StartDate: "07-2025", EndDate: "08-2025", UserId: &ownerID})
+				return err
+			},
+			code: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &subsRepo{record: subscription(), sum: &sum}
+
+			err := tt.call(auth.WithPrincipal(context.Background(), tt.principal), newServer(repo))
+			if status.Code(err) != tt.code {
+				t.Errorf("expected code %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
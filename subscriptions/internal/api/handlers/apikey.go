@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	service service.APIKeyService
+}
+
+func NewAPIKeyHandler(serviceInput service.APIKeyService) APIKeyHandler {
+	return APIKeyHandler{
+		service: serviceInput,
+	}
+}
+
+// GetAllAPIKeys	godoc
+// @Summary 	Get API keys
+// @Description Get all API keys including revoked ones, optionally of the user
+// @Tags		API keys
+// @Produce		json
+// @Param 		user_id	query 	string 	false 	"User ID" 	Format(uuid)
+// @Success 	200 	{array} 	schemas.APIKeyInfo
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/api-keys	[get]
+func (h *APIKeyHandler) GetAllAPIKeys(c *gin.Context) {
+	var userID *uuid.UUID
+	if userIDInput := c.Query("user_id"); userIDInput != "" {
+		userIDParse, err := uuid.Parse(userIDInput)
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+			return
+		}
+		userID = &userIDParse
+	}
+
+	res, err := h.service.GetAllAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetAPIKeyByID	godoc
+// @Summary 	Get API key info
+// @Description Get API key by id, the key itself is never returned
+// @Tags		API keys
+// @Produce		json
+// @Param       id    	path     	uint  	true  	"API key ID"	Format(uint)
+// @Success 	200 	{object} 	schemas.APIKeyInfo
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/api-keys/{id} 	[get]
+func (h *APIKeyHandler) GetAPIKeyByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	res, err := h.service.GetAPIKey(c.Request.Context(), uint(id))
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateAPIKey	godoc
+// @Summary 	Create API key
+// @Description Issue API key with the scopes subs:read, subs:write, reports:read or admin.
+// @Description Keys bound to a user access only the user's data, keys without user are service accounts.
+// @Description The key is returned only once, pass it as "Bearer <key>" or in the X-API-Key header.
+// @Tags		API keys
+// @Accept		json
+// @Produce 	json
+// @Param       newAPIKey   	body     	schemas.CreateAPIKey 	true  	"API key data"
+// @Success 	201 	{object} 	schemas.CreateAPIKeyReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/api-keys 	[post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var newAPIKey schemas.CreateAPIKey
+
+	if err := c.ShouldBindJSON(&newAPIKey); err != nil {
+		respond.Invalid(c, i18n.InvalidAPIKey, err)
+		return
+	}
+
+	if err := validate.Struct(newAPIKey); err != nil {
+		respond.Invalid(c, i18n.InvalidAPIKey, err)
+		return
+	}
+
+	res, err := h.service.CreateAPIKey(c.Request.Context(), newAPIKey)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+// RotateAPIKey	godoc
+// @Summary 	Rotate API key
+// @Description Replace the key keeping its name and scopes, the old key stops working at once.
+// @Description The new key is returned only once.
+// @Tags		API keys
+// @Produce 	json
+// @Param       id    	path     	uint  	true  	"API key ID"	Format(uint)
+// @Success 	200 	{object} 	schemas.CreateAPIKeyReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	409 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/api-keys/{id}/rotate 	[post]
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	res, err := h.service.RotateAPIKey(c.Request.Context(), uint(id))
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// RevokeAPIKey	godoc
+// @Summary 	Revoke API key
+// @Description Revoke API key, revoked keys are kept for audit
+// @Tags		API keys
+// @Produce 	json
+// @Param       id    	path     	uint  	true  	"API key ID"	Format(uint)
+// @Success 	200 	{object} 	schemas.MessageReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/api-keys/{id} 	[delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(c.Request.Context(), uint(id)); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "api key revoked"})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func forbidden(c *gin.Context) {
+	respond.Error(c, http.StatusForbidden, i18n.Forbidden)
+}
+
+// checkUserAccess writes the forbidden response if the caller may not access the
+// data of the user.
+func checkUserAccess(c *gin.Context, userID uuid.UUID) bool {
+	if !auth.CanAccessUser(c.Request.Context(), userID) {
+		forbidden(c)
+		return false
+	}
+
+	return true
+}
+
+// scopeUserID limits the optional user filter to the caller. It writes the forbidden
+// response if the caller asks for the data of another user.
+func scopeUserID(c *gin.Context, userID *uuid.UUID) (*uuid.UUID, bool) {
+	scope := auth.UserScope(c.Request.Context())
+	if scope == nil {
+		return userID, true
+	}
+
+	if userID != nil && *userID != *scope {
+		forbidden(c)
+		return nil, false
+	}
+
+	return scope, true
+}
+
+// checkSubAccess loads the subscription and checks that the caller may access it,
+// writing the error response otherwise.
+func (h *SubHandler) checkSubAccess(c *gin.Context, id uint) bool {
+	if auth.UserScope(c.Request.Context()) == nil {
+		return true
+	}
+
+	res, err := h.service.GetSub(c.Request.Context(), id)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return false
+	}
+
+	return checkUserAccess(c, res.UserID)
+}
+
+// checkSuperAdmin writes the forbidden response if the caller is bound to a tenant.
+func checkSuperAdmin(c *gin.Context) bool {
+	if !auth.IsSuperAdmin(c.Request.Context()) {
+		forbidden(c)
+		return false
+	}
+
+	return true
+}
+
+// checkTenantAccess writes the forbidden response if the caller may not manage the tenant.
+func checkTenantAccess(c *gin.Context, tenantID string) bool {
+	if !auth.CanAccessTenant(c.Request.Context(), tenantID) {
+		forbidden(c)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/helpers"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BudgetHandler struct {
+	service service.BudgetService
+}
+
+func NewBudgetHandler(serviceInput service.BudgetService) BudgetHandler {
+	return BudgetHandler{
+		service: serviceInput,
+	}
+}
+
+// GetAllBudgets	godoc
+// @Summary 	Get budgets
+// @Description Get all budgets, optionally of one user
+// @Tags		Budgets
+// @Produce		json
+// @Param       user_id    	query     	string  	false  	"User ID"	Format(uuid)
+// @Success 	200 	{array} 	schemas.BudgetInfo
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/budgets	[get]
+func (h *BudgetHandler) GetAllBudgets(c *gin.Context) {
+	var userID *uuid.UUID
+	if userIDInput := c.Query("user_id"); userIDInput != "" {
+		userIDParse, err := uuid.Parse(userIDInput)
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+			return
+		}
+		userID = &userIDParse
+	}
+
+	res, err := h.service.GetBudgets(c.Request.Context(), userID)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetBudgetByID	godoc
+// @Summary 	Get budget info
+// @Description Get budget by id
+// @Tags		Budgets
+// @Produce		json
+// @Param       id    	path     	uint  	true  	"Budget ID"	Format(uint)
+// @Success 	200 	{object} 	schemas.BudgetInfo
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/budgets/{id} 	[get]
+func (h *BudgetHandler) GetBudgetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	res, err := h.service.GetBudget(c.Request.Context(), uint(id))
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateBudget	godoc
+// @Summary 	Create budget
+// @Description Create monthly budget of the user, optionally limited to a category or a service
+// @Tags		Budgets
+// @Accept		json
+// @Produce 	json
+// @Param       newBudget   	body     	schemas.CreateBudget 	true  	"Budget data"
+// @Success 	201 	{object} 	schemas.CreateReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/budgets 	[post]
+func (h *BudgetHandler) CreateBudget(c *gin.Context) {
+	var newBudget schemas.CreateBudget
+
+	if err := c.ShouldBindJSON(&newBudget); err != nil {
+		respond.Invalid(c, i18n.InvalidBudget, err)
+		return
+	}
+
+	if err := validate.Struct(newBudget); err != nil {
+		respond.Invalid(c, i18n.InvalidBudget, err)
+		return
+	}
+
+	res, err := h.service.CreateBudget(c.Request.Context(), newBudget)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": res})
+}
+
+// UpdateBudget	godoc
+// @Summary 	Update budget
+// @Description Update limit and scope of the budget
+// @Tags		Budgets
+// @Accept		json
+// @Produce 	json
+// @Param       id    			path    uint  	true  	"Budget ID"	Format(uint)
+// @Param       updateFields    body    schemas.UpdateBudget  	true  	"Budget data"
+// @Success 	200				{object} 	schemas.MessageReturn
+// @Failure 	400 			{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 			{object}  	schemas.Problem
+// @Failure 	500 			{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/budgets/{id} 	[put]
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	var fields schemas.UpdateBudget
+
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		respond.Invalid(c, i18n.InvalidBudget, err)
+		return
+	}
+
+	if err := validate.Struct(fields); err != nil {
+		respond.Invalid(c, i18n.InvalidBudget, err)
+		return
+	}
+
+	if err := h.service.UpdateBudget(c.Request.Context(), uint(id), fields); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "budget updated"})
+}
+
+// DeleteBudget	godoc
+// @Summary 	Delete budget
+// @Description Delete budget
+// @Tags		Budgets
+// @Produce 	json
+// @Param       id    	path     	uint  	true  	"Budget ID"	Format(uint)
+// @Success 	200 	{object} 	schemas.MessageReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/budgets/{id} 	[delete]
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	if err := h.service.DeleteBudget(c.Request.Context(), uint(id)); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "budget deleted"})
+}
+
+// GetUserBudgetStatus	godoc
+// @Summary 	Get user budget status
+// @Description Compare the monthly charge of user subscriptions with every budget of the user
+// @Tags		Users
+// @Produce		json
+// @Param       id    	path     	string  	true  	"User ID"	Format(uuid)
+// @Param       month   query     	string  	false  	"Month('mm-yyyy'), current month by default"	Format(string)
+// @Success 	200 	{object} 	schemas.BudgetStatusReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	422 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/users/{id}/budget-status	[get]
+func (h *BudgetHandler) GetUserBudgetStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	if !checkUserAccess(c, id) {
+		return
+	}
+
+	month := c.DefaultQuery("month", time.Now().UTC().Format("01-2006"))
+	if !helpers.ValidateDateMMYYYYFormat(month) {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidMonth)
+		return
+	}
+
+	res, err := h.service.GetBudgetStatus(c.Request.Context(), id, month)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
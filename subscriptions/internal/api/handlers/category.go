@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	service service.CategoryService
+}
+
+func NewCategoryHandler(serviceInput service.CategoryService) CategoryHandler {
+	return CategoryHandler{
+		service: serviceInput,
+	}
+}
+
+// GetAllCategories	godoc
+// @Summary 	Get categories
+// @Description Get all categories, subcategories reference their parent by parent_id
+// @Tags		Categories
+// @Produce		json
+// @Success 	200 	{array} 	schemas.CategoryInfo
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/categories	[get]
+func (h *CategoryHandler) GetAllCategories(c *gin.Context) {
+	res, err := h.service.GetAllCategories(c.Request.Context())
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetCategoryByID	godoc
+// @Summary 	Get category info
+// @Description Get category by id
+// @Tags		Categories
+// @Produce		json
+// @Param       id    	path     	uint  	true  	"Category ID"	Format(uint)
+// @Success 	200 	{object} 	schemas.CategoryInfo
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/categories/{id} 	[get]
+func (h *CategoryHandler) GetCategoryByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	res, err := h.service.GetCategory(c.Request.Context(), uint(id))
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateCategory	godoc
+// @Summary 	Create category
+// @Description Create new category, pass parent_id to create a subcategory
+// @Tags		Categories
+// @Accept		json
+// @Produce 	json
+// @Param       newCategory   	body     	schemas.CreateCategory 	true  	"Category data"
+// @Success 	201 	{object} 	schemas.CreateReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	409 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/categories 	[post]
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var newCategory schemas.CreateCategory
+
+	if err := c.ShouldBindJSON(&newCategory); err != nil {
+		respond.Invalid(c, i18n.InvalidCategory, err)
+		return
+	}
+
+	if err := validate.Struct(newCategory); err != nil {
+		respond.Invalid(c, i18n.InvalidCategory, err)
+		return
+	}
+
+	res, err := h.service.CreateCategory(c.Request.Context(), newCategory)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": res})
+}
+
+// UpdateCategory	godoc
+// @Summary 	Update category
+// @Description Rename category or move it under another parent
+// @Tags		Categories
+// @Accept		json
+// @Produce 	json
+// @Param       id    			path    uint  	true  	"Category ID"	Format(uint)
+// @Param       updateFields    body    schemas.CreateCategory  	true  	"Category data"
+// @Success 	200				{object} 	schemas.MessageReturn
+// @Failure 	400 			{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 			{object}  	schemas.Problem
+// @Failure 	409 			{object}  	schemas.Problem
+// @Failure 	500 			{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/categories/{id} 	[put]
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	var fields schemas.CreateCategory
+
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		respond.Invalid(c, i18n.InvalidCategory, err)
+		return
+	}
+
+	if err := validate.Struct(fields); err != nil {
+		respond.Invalid(c, i18n.InvalidCategory, err)
+		return
+	}
+
+	if err := h.service.UpdateCategory(c.Request.Context(), uint(id), fields); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category updated"})
+}
+
+// DeleteCategory	godoc
+// @Summary 	Delete category
+// @Description Delete category without subcategories, its subscriptions become uncategorized
+// @Tags		Categories
+// @Produce 	json
+// @Param       id    	path     	uint  	true  	"Category ID"	Format(uint)
+// @Success 	200 	{object} 	schemas.MessageReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	409 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/categories/{id} 	[delete]
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	if err := h.service.DeleteCategory(c.Request.Context(), uint(id)); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category deleted"})
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"subscriptions/rest-service/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) HealthHandler {
+	return HealthHandler{
+		checker: checker,
+	}
+}
+
+// Livez answers the liveness probe, the instance is alive while it serves requests.
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, h.checker.Live())
+}
+
+// Readyz answers the readiness probe with the result of every check, it returns 503
+// during startup and shutdown and while a dependency or a background worker fails.
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report, ready := h.checker.Ready(c.Request.Context())
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
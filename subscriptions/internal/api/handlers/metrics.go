@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type MetricsHandler struct {
+	// token is required as the bearer token to read the metrics, empty leaves them public.
+	token   string
+	handler http.Handler
+}
+
+func NewMetricsHandler(token string) MetricsHandler {
+	return MetricsHandler{
+		token:   token,
+		handler: promhttp.Handler(),
+	}
+}
+
+// Metrics serves the metrics in the Prometheus text format.
+func (h *MetricsHandler) Metrics(c *gin.Context) {
+	if h.token != "" {
+		token, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			respond.Error(c, http.StatusUnauthorized, i18n.InvalidMetricsToken)
+			return
+		}
+	}
+
+	h.handler.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/tenant"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// streamRetry is the reconnect delay in milliseconds suggested to the clients.
+const streamRetry = 3000
+
+type StreamHandler struct {
+	hub       *events.Hub
+	heartbeat time.Duration
+}
+
+func NewStreamHandler(hub *events.Hub, heartbeat time.Duration) StreamHandler {
+	return StreamHandler{
+		hub:       hub,
+		heartbeat: heartbeat,
+	}
+}
+
+type streamFilter struct {
+	tenantID    string
+	userID      *uuid.UUID
+	serviceName string
+}
+
+func (f streamFilter) match(event events.Event) bool {
+	payload, ok := event.Data.(events.SubscriptionPayload)
+	if !ok || event.TenantID != f.tenantID {
+		return false
+	}
+
+	if f.userID != nil && payload.UserID != *f.userID {
+		return false
+	}
+
+	return f.serviceName == "" || strings.EqualFold(payload.ServiceName, f.serviceName)
+}
+
+// StreamSubscriptionEvents	godoc
+// @Summary 	Stream subscription events
+// @Description Server-Sent Events stream of created, updated and deleted subscriptions.
+// @Description The event IDs are the stream sequences of the events. Send the Last-Event-ID header (or last_event_id query parameter) to resume after a reconnect.
+// @Tags		Subs
+// @Produce		text/event-stream
+// @Param 		user_id 		query 	string 	false 	"User ID" 		Format(string)
+// @Param 		service_name 	query 	string 	false 	"Service name" 	Format(string)
+// @Param 		last_event_id 	query 	uint 	false 	"Sequence of the last received event" 	Format(uint)
+// @Param 		Last-Event-ID 	header 	uint 	false 	"Sequence of the last received event" 	Format(uint)
+// @Success 	200 	{object} 	events.Event
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/subs/events 	[get]
+func (h *StreamHandler) StreamSubscriptionEvents(c *gin.Context) {
+	var filter streamFilter
+	filter.tenantID, _ = tenant.FromContext(c.Request.Context())
+
+	if userIDInput := c.Query("user_id"); userIDInput != "" {
+		userID, err := uuid.Parse(userIDInput)
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.InvalidUserID)
+			return
+		}
+		filter.userID = &userID
+	}
+	filter.serviceName = c.Query("service_name")
+
+	var ok bool
+	if filter.userID, ok = scopeUserID(c, filter.userID); !ok {
+		return
+	}
+
+	var lastSequence *uint64
+
+	lastEventIDInput := c.GetHeader("Last-Event-ID")
+	if lastEventIDInput == "" {
+		lastEventIDInput = c.Query("last_event_id")
+	}
+	if lastEventIDInput != "" {
+		seq, err := strconv.ParseUint(lastEventIDInput, 10, 64)
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.InvalidLastEventID)
+			return
+		}
+		lastSequence = &seq
+	}
+
+	replay, stream, cancel := h.hub.Subscribe(lastSequence)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry)
+	for _, event := range replay {
+		if filter.match(event) && !writeStreamEvent(c, event) {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-stream:
+			if !ok {
+				return
+			}
+
+			if filter.match(event) && !writeStreamEvent(c, event) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeStreamEvent(c *gin.Context, event events.Event) bool {
+	data, err := json.Marshal(event)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "write stream event failed", "error", err)
+		return true
+	}
+
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, data)
+	return err == nil
+}
@@ -1,10 +1,15 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
+	"reflect"
 	"strconv"
+	"strings"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/auth"
 	"subscriptions/rest-service/internal/helpers"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/repository"
 	"subscriptions/rest-service/internal/schemas"
 	"subscriptions/rest-service/internal/service"
 	"time"
@@ -18,7 +23,21 @@ var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+	// the errors name the fields as the request bodies do
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
 	validate.RegisterValidation("mm_yyyy_date", helpers.ValidateDateMMYYYYFormatValidator)
+	validate.RegisterValidation("event_type", helpers.ValidateEventTypeValidator)
+	validate.RegisterValidation("scope", helpers.ValidateScopeValidator)
+	validate.RegisterValidation("tenant_id", helpers.ValidateTenantIDValidator)
+	if err := i18n.RegisterValidator(validate); err != nil {
+		panic(err)
+	}
 }
 
 func checkStartDateBeforeEndDate(startDate, endDate string) bool {
@@ -28,6 +47,15 @@ func checkStartDateBeforeEndDate(startDate, endDate string) bool {
 	return !endDateDate.Before(startDateDate)
 }
 
+// invalidPeriod aborts the request with the subscription ending before its start.
+func invalidPeriod(c *gin.Context) {
+	respond.InvalidFields(c, i18n.InvalidPeriod, schemas.FieldError{
+		Field:   "end_date",
+		Rule:    "gtefield",
+		Message: i18n.Translate(c.Request.Context(), i18n.EndDateBeforeStartDate),
+	})
+}
+
 type SubHandler struct {
 	service service.SubscriptionService
 }
@@ -45,11 +73,17 @@ func NewHandler(serviceInput service.SubscriptionService) SubHandler {
 // @Produce		json
 // @Param page query uint false "Current page number" Format(uint) default(1)
 // @Param size query uint false "Number of subscriptions per page" Format(uint) default(10)
+// @Param category_id query uint false "Category ID (subcategories included)" Format(uint)
+// @Param tag query string false "Tag name" Format(string)
 // @Success 	200 	{object} 	schemas.PaginationResponse
 //
-//	@Failure 	400 	{object}  	schemas.APIError
+//	@Failure 	400 	{object}  	schemas.Problem
 //
-// @Failure 	500 	{object}  	schemas.APIError
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
 // @Router 		/subs	[get]
 func (h *SubHandler) GetAllSubscriptions(c *gin.Context) {
 	pageNumber := c.DefaultQuery("page", "1")
@@ -57,24 +91,37 @@ func (h *SubHandler) GetAllSubscriptions(c *gin.Context) {
 
 	pageNumberInt, err := strconv.Atoi(pageNumber)
 	if err != nil || pageNumberInt < 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page number"})
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidPage)
 		return
 	}
 
 	subsCountInt, err := strconv.Atoi(subsCount)
 	if err != nil || subsCountInt <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid size number"})
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidPageSize)
 		return
 	}
 
-	res, err := h.service.GetAllSubs(pageNumberInt, subsCountInt)
-	if err != nil {
-		if serviceErr, ok := err.(*schemas.AppError); ok {
-			c.JSON(serviceErr.Code, gin.H{"error": serviceErr.Message})
+	var filter repository.SubsFilter
+
+	if categoryIDInput := c.Query("category_id"); categoryIDInput != "" {
+		categoryID, err := strconv.ParseUint(categoryIDInput, 10, 64)
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.InvalidCategoryID)
 			return
 		}
+		categoryIDUint := uint(categoryID)
+		filter.CategoryID = &categoryIDUint
+	}
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	if tag := c.Query("tag"); tag != "" {
+		filter.Tag = &tag
+	}
+
+	filter.UserID = auth.UserScope(c.Request.Context())
+
+	res, err := h.service.GetAllSubs(c.Request.Context(), pageNumberInt, subsCountInt, filter)
+	if err != nil {
+		respond.ServiceError(c, err)
 		return
 	}
 
@@ -88,27 +135,30 @@ func (h *SubHandler) GetAllSubscriptions(c *gin.Context) {
 // @Produce		json
 // @Param       id    	path     	uint  	true  	"Sub ID"	Format(uint)
 // @Success 	200 	{object} 	schemas.FullSubInfo
-// @Failure 	400 	{object}  	schemas.APIError
-// @Failure 	404 	{object}  	schemas.APIError
-// @Failure 	500 	{object}  	schemas.APIError
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
 // @Router 		/subs/{id} 	[get]
 func (h *SubHandler) GetSubscriptionByID(c *gin.Context) {
 	idStr := c.Param("id")
 
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid unsigned integer parameter"})
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
 		return
 	}
 
-	res, err := h.service.GetSub(uint(id))
+	res, err := h.service.GetSub(c.Request.Context(), uint(id))
 	if err != nil {
-		if serviceErr, ok := err.(*schemas.AppError); ok {
-			c.JSON(serviceErr.Code, gin.H{"error": serviceErr.Message})
-			return
-		}
+		respond.ServiceError(c, err)
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	if !checkUserAccess(c, res.UserID) {
 		return
 	}
 
@@ -123,37 +173,40 @@ func (h *SubHandler) GetSubscriptionByID(c *gin.Context) {
 // @Produce 	json
 // @Param       newSubscription   	body     	schemas.CreateSub 	true  	"Subscription data"
 // @Success 	201 	{object} 	schemas.CreateReturn
-// @Failure 	400 	{object}  	schemas.APIError
-// @Failure 	500 	{object}  	schemas.APIError
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
 // @Router 		/subs 	[post]
 func (h *SubHandler) CreateSubscription(c *gin.Context) {
 	var newSub schemas.CreateSub
 
 	if err := c.ShouldBindJSON(&newSub); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription data"})
+		respond.Invalid(c, i18n.InvalidSubscription, err)
 		return
 	}
 
 	if err := validate.Struct(newSub); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format input (must be 'mm-yyyy')"})
+		respond.Invalid(c, i18n.InvalidSubscription, err)
 		return
 	}
 
 	if newSub.EndDate != nil {
 		if !checkStartDateBeforeEndDate(newSub.StartDate, *newSub.EndDate) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "startDate cannot be after endDate"})
+			invalidPeriod(c)
 			return
 		}
 	}
 
-	res, err := h.service.CreateSub(newSub)
-	if err != nil {
-		if serviceErr, ok := err.(*schemas.AppError); ok {
-			c.JSON(serviceErr.Code, gin.H{"error": serviceErr.Message})
-			return
-		}
+	if !checkUserAccess(c, newSub.UserID) {
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	res, err := h.service.CreateSub(c.Request.Context(), newSub)
+	if err != nil {
+		respond.ServiceError(c, err)
 		return
 	}
 
@@ -169,45 +222,48 @@ func (h *SubHandler) CreateSubscription(c *gin.Context) {
 // @Param       id    				path    uint  	true  	"Subscription ID"	Format(uint)
 // @Param       updateFields    	body    schemas.FullUpdateSub  	true  	"Subscription data"
 // @Success 	200					{object} 	schemas.MessageReturn
-// @Failure 	400 				{object}  	schemas.APIError
-// @Failure 	500 				{object}  	schemas.APIError
+// @Failure 	400 				{object}  	schemas.Problem
+// @Failure 	500 				{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
 // @Router 		/subs/{id} 	[put]
 func (h *SubHandler) FullUpdateSubscription(c *gin.Context) {
 	idStr := c.Param("id")
 
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid unsigned integer parameter"})
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
 		return
 	}
 
 	var subFields schemas.FullUpdateSub
 
 	if err := c.ShouldBindJSON(&subFields); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid params to update subscription"})
+		respond.Invalid(c, i18n.InvalidSubscription, err)
 		return
 	}
 
 	if err := validate.Struct(subFields); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format input (must be 'mm-yyyy')"})
+		respond.Invalid(c, i18n.InvalidSubscription, err)
 		return
 	}
 
 	if subFields.EndDate != nil {
 		if !checkStartDateBeforeEndDate(subFields.StartDate, *subFields.EndDate) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "startDate cannot be after endDate"})
+			invalidPeriod(c)
 			return
 		}
 	}
 
-	err = h.service.FullUpdateSub(uint(id), subFields)
-	if err != nil {
-		if serviceErr, ok := err.(*schemas.AppError); ok {
-			c.JSON(serviceErr.Code, gin.H{"error": serviceErr.Message})
-			return
-		}
+	if !checkUserAccess(c, subFields.UserID) || !h.checkSubAccess(c, uint(id)) {
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	err = h.service.FullUpdateSub(c.Request.Context(), uint(id), subFields)
+	if err != nil {
+		respond.ServiceError(c, err)
 		return
 	}
 
@@ -223,46 +279,52 @@ func (h *SubHandler) FullUpdateSubscription(c *gin.Context) {
 // @Param       id    				path    uint  	true  	"Subscription ID"	Format(uint)
 // @Param       updateFields    	body    schemas.PatchUpdateSub  	true  	"Subscription data"
 // @Success 	200 				{object} 	schemas.MessageReturn
-// @Failure 	400 				{object}  	schemas.APIError
-// @Failure 	500 				{object}  	schemas.APIError
+// @Failure 	400 				{object}  	schemas.Problem
+// @Failure 	500 				{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
 // @Router 		/subs/{id} 	[patch]
 func (h *SubHandler) PatchUpdateSubscription(c *gin.Context) {
 	idStr := c.Param("id")
 
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid unsigned integer parameter"})
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
 		return
 	}
 
 	var subFields schemas.PatchUpdateSub
 
 	if err := c.ShouldBindJSON(&subFields); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid params to update subscription"})
+		respond.Invalid(c, i18n.InvalidSubscription, err)
 		return
 	}
 
 	if err := validate.Struct(subFields); err != nil {
-		log.Printf("%T\n", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format input (must be 'mm-yyyy')"})
+		respond.Invalid(c, i18n.InvalidSubscription, err)
 		return
 	}
 
 	if subFields.EndDate != nil && subFields.StartDate != nil {
 		if !checkStartDateBeforeEndDate(*subFields.StartDate, *subFields.EndDate) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "startDate cannot be after endDate"})
+			invalidPeriod(c)
 			return
 		}
 	}
 
-	err = h.service.PatchUpdateSub(uint(id), subFields)
-	if err != nil {
-		if serviceErr, ok := err.(*schemas.AppError); ok {
-			c.JSON(serviceErr.Code, gin.H{"error": serviceErr.Message})
-			return
-		}
+	if subFields.UserID != nil && !checkUserAccess(c, *subFields.UserID) {
+		return
+	}
+
+	if !h.checkSubAccess(c, uint(id)) {
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	err = h.service.PatchUpdateSub(c.Request.Context(), uint(id), subFields)
+	if err != nil {
+		respond.ServiceError(c, err)
 		return
 	}
 
@@ -276,27 +338,30 @@ func (h *SubHandler) PatchUpdateSubscription(c *gin.Context) {
 // @Produce 	json
 // @Param       id    	path     	uint  	true  	"Subscription ID"	Format(uint)
 // @Success 	200 	{object} 	schemas.MessageReturn
-// @Failure 	400 	{object}  	schemas.APIError
-// @Failure 	404 	{object}  	schemas.APIError
-// @Failure 	500 	{object}  	schemas.APIError
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
 // @Router 		/subs/{id} 	[delete]
 func (h *SubHandler) DeleteSubscription(c *gin.Context) {
 	idStr := c.Param("id")
 
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid unsigned integer parameter"})
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
 		return
 	}
 
-	err = h.service.DeleteSub(uint(id))
-	if err != nil {
-		if serviceErr, ok := err.(*schemas.AppError); ok {
-			c.JSON(serviceErr.Code, gin.H{"error": serviceErr.Message})
-			return
-		}
+	if !h.checkSubAccess(c, uint(id)) {
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	err = h.service.DeleteSub(c.Request.Context(), uint(id))
+	if err != nil {
+		respond.ServiceError(c, err)
 		return
 	}
 
@@ -305,57 +370,168 @@ func (h *SubHandler) DeleteSubscription(c *gin.Context) {
 
 // GetSubscriptionSumInfo	godoc
 // @Summary 	Get subscription price
-// @Description Get subscription price for period and filtered by userID or(and) serviceName
+// @Description Get subscription price for period and filtered by userID or(and) serviceName. Subscriptions without end date are charged until the end of the period
 // @Tags		Subs
 // @Produce 	json
 // @Param       startDate    	query     	string  	true  	"Period start date('mm-yyyy')"	Format(string)
 // @Param       endDate    		query     	string  	true  	"Period end date('mm-yyyy')"	Format(string)
 // @Param       userID    		query     	string  	false  	"User ID"						Format(string)
 // @Param       serviceName    	query     	string  	false  	"Service name"					Format(string)
+// @Param       categoryID    	query     	uint  		false  	"Category ID (subcategories included)"	Format(uint)
 // @Success 	200 	{object} 	schemas.SumReturn
-// @Failure 	400 	{object}  	schemas.APIError
-// @Failure 	422 	{object}  	schemas.APIError
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	422 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
 // @Router 		/subs/sub_sum 	[get]
 func (h *SubHandler) GetSubscriptionSumInfo(c *gin.Context) {
 	startDate := c.Query("startDate")
 	endDate := c.Query("endDate")
-	userIDInput := c.Query("userID")
 	serviceNameInput := c.Query("serviceName")
 
-	if !helpers.ValidateDateMMYYYYFormat(startDate) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start date"})
+	userID, ok := parseSumParams(c, startDate, endDate)
+	if !ok {
 		return
 	}
-	if !helpers.ValidateDateMMYYYYFormat(endDate) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end date"})
+
+	categoryID, ok := parseSumCategoryID(c)
+	if !ok {
 		return
 	}
 
-	if !checkStartDateBeforeEndDate(startDate, endDate) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "startDate cannot be after endDate"})
+	resultSum, err := h.service.GetSubSum(c.Request.Context(), userID, &serviceNameInput, categoryID, startDate, endDate)
+	if err != nil {
+		respond.ServiceError(c, err)
 		return
 	}
 
-	var userID *uuid.UUID
-	if userIDInput != "" {
-		userIDParse, err := uuid.Parse(userIDInput)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id format"})
-			return
-		}
-		userID = &userIDParse
+	c.JSON(http.StatusOK, gin.H{"total_sum": resultSum})
+}
+
+// GetSubscriptionSumByCategory	godoc
+// @Summary 	Get subscription price by category
+// @Description Get subscription price for period per category, filtered by userID or(and) serviceName
+// @Tags		Subs
+// @Produce 	json
+// @Param       startDate    	query     	string  	true  	"Period start date('mm-yyyy')"	Format(string)
+// @Param       endDate    		query     	string  	true  	"Period end date('mm-yyyy')"	Format(string)
+// @Param       userID    		query     	string  	false  	"User ID"						Format(string)
+// @Param       serviceName    	query     	string  	false  	"Service name"					Format(string)
+// @Success 	200 	{object} 	schemas.CategorySumReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	422 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/subs/sub_sum/by_category 	[get]
+func (h *SubHandler) GetSubscriptionSumByCategory(c *gin.Context) {
+	startDate := c.Query("startDate")
+	endDate := c.Query("endDate")
+	serviceNameInput := c.Query("serviceName")
+
+	userID, ok := parseSumParams(c, startDate, endDate)
+	if !ok {
+		return
 	}
 
-	resultSum, err := h.service.GetSubSum(userID, &serviceNameInput, startDate, endDate)
+	res, err := h.service.GetSubSumByCategory(c.Request.Context(), userID, &serviceNameInput, startDate, endDate)
 	if err != nil {
-		if serviceErr, ok := err.(*schemas.AppError); ok {
-			c.JSON(serviceErr.Code, gin.H{"error": serviceErr.Message})
-			return
-		}
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
 
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+// GetSubscriptionSumByMonth	godoc
+// @Summary 	Get subscription price by month
+// @Description Get subscription price for each month of the period, the end month excluded, filtered by userID, serviceName or(and) categoryID
+// @Tags		Subs
+// @Produce 	json
+// @Param       startDate    	query     	string  	true  	"Period start date('mm-yyyy')"	Format(string)
+// @Param       endDate    		query     	string  	true  	"Period end date('mm-yyyy')"	Format(string)
+// @Param       userID    		query     	string  	false  	"User ID"						Format(string)
+// @Param       serviceName    	query     	string  	false  	"Service name"					Format(string)
+// @Param       categoryID    	query     	uint  		false  	"Category ID (subcategories included)"	Format(uint)
+// @Success 	200 	{object} 	schemas.MonthSumReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	422 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/subs/sub_sum/by_month 	[get]
+func (h *SubHandler) GetSubscriptionSumByMonth(c *gin.Context) {
+	startDate := c.Query("startDate")
+	endDate := c.Query("endDate")
+	serviceNameInput := c.Query("serviceName")
+
+	userID, ok := parseSumParams(c, startDate, endDate)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"total_sum": resultSum})
+	categoryID, ok := parseSumCategoryID(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetSubSumByMonth(c.Request.Context(), userID, &serviceNameInput, categoryID, startDate, endDate)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// parseSumCategoryID parses the optional categoryID of the sum report. It writes the error
+// response itself and returns false if the categoryID is invalid.
+func parseSumCategoryID(c *gin.Context) (*uint, bool) {
+	categoryIDInput := c.Query("categoryID")
+	if categoryIDInput == "" {
+		return nil, true
+	}
+
+	categoryID, err := strconv.ParseUint(categoryIDInput, 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidCategoryID)
+		return nil, false
+	}
+
+	categoryIDUint := uint(categoryID)
+	return &categoryIDUint, true
+}
+
+// parseSumParams validates the period of the sum report and parses the optional userID,
+// limiting it to the caller. It writes the error response itself and returns false if the params are invalid.
+func parseSumParams(c *gin.Context, startDate, endDate string) (*uuid.UUID, bool) {
+	if !helpers.ValidateDateMMYYYYFormat(startDate) {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidStartDate)
+		return nil, false
+	}
+	if !helpers.ValidateDateMMYYYYFormat(endDate) {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidEndDate)
+		return nil, false
+	}
+
+	if !checkStartDateBeforeEndDate(startDate, endDate) {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidPeriod)
+		return nil, false
+	}
+
+	var userID *uuid.UUID
+	if userIDInput := c.Query("userID"); userIDInput != "" {
+		userIDParse, err := uuid.Parse(userIDInput)
+		if err != nil {
+			respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+			return nil, false
+		}
+		userID = &userIDParse
+	}
+
+	return scopeUserID(c, userID)
 }
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	service service.TagService
+}
+
+func NewTagHandler(serviceInput service.TagService) TagHandler {
+	return TagHandler{
+		service: serviceInput,
+	}
+}
+
+// GetAllTags	godoc
+// @Summary 	Get tags
+// @Description Get all tags
+// @Tags		Tags
+// @Produce		json
+// @Success 	200 	{array} 	schemas.TagInfo
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/tags	[get]
+func (h *TagHandler) GetAllTags(c *gin.Context) {
+	res, err := h.service.GetAllTags(c.Request.Context())
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateTag	godoc
+// @Summary 	Create tag
+// @Description Create new tag, tag names are stored lowercased
+// @Tags		Tags
+// @Accept		json
+// @Produce 	json
+// @Param       newTag   	body     	schemas.CreateTag 	true  	"Tag data"
+// @Success 	201 	{object} 	schemas.CreateReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	409 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/tags 	[post]
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var newTag schemas.CreateTag
+
+	if err := c.ShouldBindJSON(&newTag); err != nil {
+		respond.Invalid(c, i18n.InvalidTag, err)
+		return
+	}
+
+	if err := validate.Struct(newTag); err != nil {
+		respond.Invalid(c, i18n.InvalidTag, err)
+		return
+	}
+
+	res, err := h.service.CreateTag(c.Request.Context(), newTag)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": res})
+}
+
+// UpdateTag	godoc
+// @Summary 	Rename tag
+// @Description Rename tag, subscriptions keep the renamed tag
+// @Tags		Tags
+// @Accept		json
+// @Produce 	json
+// @Param       id    			path    uint  	true  	"Tag ID"	Format(uint)
+// @Param       updateFields    body    schemas.CreateTag  	true  	"Tag data"
+// @Success 	200				{object} 	schemas.MessageReturn
+// @Failure 	400 			{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 			{object}  	schemas.Problem
+// @Failure 	409 			{object}  	schemas.Problem
+// @Failure 	500 			{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/tags/{id} 	[put]
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	var fields schemas.CreateTag
+
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		respond.Invalid(c, i18n.InvalidTag, err)
+		return
+	}
+
+	if err := validate.Struct(fields); err != nil {
+		respond.Invalid(c, i18n.InvalidTag, err)
+		return
+	}
+
+	if err := h.service.UpdateTag(c.Request.Context(), uint(id), fields); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tag updated"})
+}
+
+// DeleteTag	godoc
+// @Summary 	Delete tag
+// @Description Delete tag and remove it from all subscriptions
+// @Tags		Tags
+// @Produce 	json
+// @Param       id    	path     	uint  	true  	"Tag ID"	Format(uint)
+// @Success 	200 	{object} 	schemas.MessageReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/tags/{id} 	[delete]
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	if err := h.service.DeleteTag(c.Request.Context(), uint(id)); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tag deleted"})
+}
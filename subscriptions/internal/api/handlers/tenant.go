@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"net/http"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	service service.TenantService
+}
+
+func NewTenantHandler(serviceInput service.TenantService) TenantHandler {
+	return TenantHandler{
+		service: serviceInput,
+	}
+}
+
+// GetAllTenants	godoc
+// @Summary 	Get tenants
+// @Description Get all tenants, available to admins not bound to a tenant
+// @Tags		Tenants
+// @Produce		json
+// @Success 	200 	{array} 	schemas.TenantInfo
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/tenants	[get]
+func (h *TenantHandler) GetAllTenants(c *gin.Context) {
+	if !checkSuperAdmin(c) {
+		return
+	}
+
+	res, err := h.service.GetAllTenants(c.Request.Context())
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetTenantByID	godoc
+// @Summary 	Get tenant info
+// @Description Get tenant by id
+// @Tags		Tenants
+// @Produce		json
+// @Param       id    	path     	string  	true  	"Tenant ID"	Format(string)
+// @Success 	200 	{object} 	schemas.TenantInfo
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/tenants/{id} 	[get]
+func (h *TenantHandler) GetTenantByID(c *gin.Context) {
+	id := c.Param("id")
+	if !checkTenantAccess(c, id) {
+		return
+	}
+
+	res, err := h.service.GetTenant(c.Request.Context(), id)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateTenant	godoc
+// @Summary 	Create tenant
+// @Description Create tenant, available to admins not bound to a tenant.
+// @Description The id consists of lowercase letters, digits, '-' and '_', up to 64 characters.
+// @Tags		Tenants
+// @Accept		json
+// @Produce 	json
+// @Param       newTenant   	body     	schemas.CreateTenant 	true  	"Tenant data"
+// @Success 	201 	{object} 	schemas.CreateTenantReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	409 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/tenants 	[post]
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	if !checkSuperAdmin(c) {
+		return
+	}
+
+	var newTenant schemas.CreateTenant
+
+	if err := c.ShouldBindJSON(&newTenant); err != nil {
+		respond.Invalid(c, i18n.InvalidTenant, err)
+		return
+	}
+
+	if err := validate.Struct(newTenant); err != nil {
+		respond.Invalid(c, i18n.InvalidTenant, err)
+		return
+	}
+
+	res, err := h.service.CreateTenant(c.Request.Context(), newTenant)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": res})
+}
+
+// UpdateTenant	godoc
+// @Summary 	Update tenant
+// @Description Rename tenant
+// @Tags		Tenants
+// @Accept		json
+// @Produce 	json
+// @Param       id    			path    string  	true  	"Tenant ID"	Format(string)
+// @Param       updateFields    body    schemas.UpdateTenant  	true  	"Tenant data"
+// @Success 	200				{object} 	schemas.MessageReturn
+// @Failure 	400 			{object}  	schemas.Problem
+// @Failure 	401 			{object}  	schemas.Problem
+// @Failure 	403 			{object}  	schemas.Problem
+// @Failure 	404 			{object}  	schemas.Problem
+// @Failure 	500 			{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/tenants/{id} 	[put]
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	id := c.Param("id")
+	if !checkTenantAccess(c, id) {
+		return
+	}
+
+	var fields schemas.UpdateTenant
+
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		respond.Invalid(c, i18n.InvalidTenant, err)
+		return
+	}
+
+	if err := validate.Struct(fields); err != nil {
+		respond.Invalid(c, i18n.InvalidTenant, err)
+		return
+	}
+
+	if err := h.service.UpdateTenant(c.Request.Context(), id, fields); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tenant updated"})
+}
+
+// GetTenantStats	godoc
+// @Summary 	Get tenant usage
+// @Description Count users, subscriptions, categories, webhooks and API keys of the tenant
+// @Tags		Tenants
+// @Produce		json
+// @Param       id    	path     	string  	true  	"Tenant ID"	Format(string)
+// @Success 	200 	{object} 	schemas.TenantStats
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/tenants/{id}/stats 	[get]
+func (h *TenantHandler) GetTenantStats(c *gin.Context) {
+	id := c.Param("id")
+	if !checkTenantAccess(c, id) {
+		return
+	}
+
+	res, err := h.service.GetTenantStats(c.Request.Context(), id)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
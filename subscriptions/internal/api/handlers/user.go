@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/helpers"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type UserHandler struct {
+	service     service.UserService
+	subsService service.SubscriptionService
+}
+
+func NewUserHandler(serviceInput service.UserService, subsService service.SubscriptionService) UserHandler {
+	return UserHandler{
+		service:     serviceInput,
+		subsService: subsService,
+	}
+}
+
+// GetAllUsers	godoc
+// @Summary 	Get users
+// @Description Get all users from database
+// @Tags		Users
+// @Produce		json
+// @Param page query uint false "Current page number" Format(uint) default(1)
+// @Param size query uint false "Number of users per page" Format(uint) default(10)
+// @Success 	200 	{object} 	schemas.UsersPaginationResponse
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/users	[get]
+func (h *UserHandler) GetAllUsers(c *gin.Context) {
+	pageNumber, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || pageNumber < 1 {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidPage)
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "10"))
+	if err != nil || size <= 0 {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidPageSize)
+		return
+	}
+
+	res, err := h.service.GetAllUsers(c.Request.Context(), pageNumber, size)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetUserByID	godoc
+// @Summary 	Get user info
+// @Description Get user from database by id
+// @Tags		Users
+// @Produce		json
+// @Param       id    	path     	string  	true  	"User ID"	Format(uuid)
+// @Success 	200 	{object} 	schemas.UserInfo
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/users/{id} 	[get]
+func (h *UserHandler) GetUserByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	res, err := h.service.GetUser(c.Request.Context(), id)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateUser	godoc
+// @Summary 	Create user
+// @Description Create new user, id is generated if not passed
+// @Tags		Users
+// @Accept		json
+// @Produce 	json
+// @Param       newUser   	body     	schemas.CreateUser 	true  	"User data"
+// @Success 	201 	{object} 	schemas.CreateUserReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	409 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/users 	[post]
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var newUser schemas.CreateUser
+
+	if err := c.ShouldBindJSON(&newUser); err != nil {
+		respond.Invalid(c, i18n.InvalidUser, err)
+		return
+	}
+
+	if err := validate.Struct(newUser); err != nil {
+		respond.Invalid(c, i18n.InvalidUser, err)
+		return
+	}
+
+	res, err := h.service.CreateUser(c.Request.Context(), newUser)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": res})
+}
+
+// UpdateUser	godoc
+// @Summary 	Update user
+// @Description Update all fields of user
+// @Tags		Users
+// @Accept		json
+// @Produce 	json
+// @Param       id    			path    string  	true  	"User ID"	Format(uuid)
+// @Param       updateFields    body    schemas.UpdateUser  	true  	"User data"
+// @Success 	200				{object} 	schemas.MessageReturn
+// @Failure 	400 			{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 			{object}  	schemas.Problem
+// @Failure 	409 			{object}  	schemas.Problem
+// @Failure 	500 			{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/users/{id} 	[put]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	var fields schemas.UpdateUser
+
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		respond.Invalid(c, i18n.InvalidUser, err)
+		return
+	}
+
+	if err := validate.Struct(fields); err != nil {
+		respond.Invalid(c, i18n.InvalidUser, err)
+		return
+	}
+
+	if err := h.service.UpdateUser(c.Request.Context(), id, fields); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user updated"})
+}
+
+// DeleteUser	godoc
+// @Summary 	Delete user
+// @Description Delete user without subscriptions
+// @Tags		Users
+// @Produce 	json
+// @Param       id    	path     	string  	true  	"User ID"	Format(uuid)
+// @Success 	200 	{object} 	schemas.MessageReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	409 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/users/{id} 	[delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	if err := h.service.DeleteUser(c.Request.Context(), id); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user deleted"})
+}
+
+// GetUserSubscriptions	godoc
+// @Summary 	Get user subscriptions
+// @Description Get subscriptions of the user
+// @Tags		Users
+// @Produce		json
+// @Param       id    	path     	string  	true  	"User ID"	Format(uuid)
+// @Param page query uint false "Current page number" Format(uint) default(1)
+// @Param size query uint false "Number of subscriptions per page" Format(uint) default(10)
+// @Success 	200 	{object} 	schemas.PaginationResponse
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/users/{id}/subscriptions	[get]
+func (h *UserHandler) GetUserSubscriptions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	if !checkUserAccess(c, id) {
+		return
+	}
+
+	pageNumber, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || pageNumber < 1 {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidPage)
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "10"))
+	if err != nil || size <= 0 {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidPageSize)
+		return
+	}
+
+	if _, err := h.service.GetUser(c.Request.Context(), id); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	res, err := h.subsService.GetAllSubs(c.Request.Context(), pageNumber, size, repository.SubsFilter{UserID: &id})
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetUserSpending	godoc
+// @Summary 	Get user spending
+// @Description Get the charge of all user subscriptions for the period
+// @Tags		Users
+// @Produce		json
+// @Param       id    	path     	string  	true  	"User ID"	Format(uuid)
+// @Param       from    query     	string  	true  	"Period start date('mm-yyyy')"	Format(string)
+// @Param       to    	query     	string  	true  	"Period end date('mm-yyyy')"	Format(string)
+// @Success 	200 	{object} 	schemas.UserSpendingReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	422 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/users/{id}/spending	[get]
+func (h *UserHandler) GetUserSpending(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	if !checkUserAccess(c, id) {
+		return
+	}
+
+	from := c.Query("from")
+	to := c.Query("to")
+
+	if !helpers.ValidateDateMMYYYYFormat(from) {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidStartDate)
+		return
+	}
+	if !helpers.ValidateDateMMYYYYFormat(to) {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidEndDate)
+		return
+	}
+
+	if !checkStartDateBeforeEndDate(from, to) {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidPeriod)
+		return
+	}
+
+	if _, err := h.service.GetUser(c.Request.Context(), id); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	serviceName := ""
+	totalSum, err := h.subsService.GetSubSum(c.Request.Context(), &id, &serviceName, nil, from, to)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.UserSpendingReturn{
+		UserID:   id,
+		From:     from,
+		To:       to,
+		TotalSum: totalSum,
+	})
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	service service.WebhookService
+}
+
+func NewWebhookHandler(serviceInput service.WebhookService) WebhookHandler {
+	return WebhookHandler{
+		service: serviceInput,
+	}
+}
+
+// GetAllWebhooks	godoc
+// @Summary 	Get webhooks
+// @Description Get all registered webhook endpoints
+// @Tags		Webhooks
+// @Produce		json
+// @Success 	200 	{array} 	schemas.WebhookInfo
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/webhooks	[get]
+func (h *WebhookHandler) GetAllWebhooks(c *gin.Context) {
+	res, err := h.service.GetAllWebhooks(c.Request.Context())
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetWebhookByID	godoc
+// @Summary 	Get webhook info
+// @Description Get webhook endpoint by id
+// @Tags		Webhooks
+// @Produce		json
+// @Param       id    	path     	uint  	true  	"Webhook ID"	Format(uint)
+// @Success 	200 	{object} 	schemas.WebhookInfo
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/webhooks/{id} 	[get]
+func (h *WebhookHandler) GetWebhookByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	res, err := h.service.GetWebhook(c.Request.Context(), uint(id))
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateWebhook	godoc
+// @Summary 	Create webhook
+// @Description Register webhook endpoint for the event types. Deliveries are signed with
+// @Description HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" in the X-Webhook-Signature header.
+// @Description The secret is generated if not passed and is returned only once.
+// @Tags		Webhooks
+// @Accept		json
+// @Produce 	json
+// @Param       newWebhook   	body     	schemas.CreateWebhook 	true  	"Webhook data"
+// @Success 	201 	{object} 	schemas.CreateWebhookReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/webhooks 	[post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var newWebhook schemas.CreateWebhook
+
+	if err := c.ShouldBindJSON(&newWebhook); err != nil {
+		respond.Invalid(c, i18n.InvalidWebhook, err)
+		return
+	}
+
+	if err := validate.Struct(newWebhook); err != nil {
+		respond.Invalid(c, i18n.InvalidWebhook, err)
+		return
+	}
+
+	res, err := h.service.CreateWebhook(c.Request.Context(), newWebhook)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+// UpdateWebhook	godoc
+// @Summary 	Update webhook
+// @Description Update webhook endpoint, the secret is replaced only if passed
+// @Tags		Webhooks
+// @Accept		json
+// @Produce 	json
+// @Param       id    			path    uint  	true  	"Webhook ID"	Format(uint)
+// @Param       updateFields    body    schemas.CreateWebhook  	true  	"Webhook data"
+// @Success 	200				{object} 	schemas.MessageReturn
+// @Failure 	400 			{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 			{object}  	schemas.Problem
+// @Failure 	500 			{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/webhooks/{id} 	[put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	var fields schemas.CreateWebhook
+
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		respond.Invalid(c, i18n.InvalidWebhook, err)
+		return
+	}
+
+	if err := validate.Struct(fields); err != nil {
+		respond.Invalid(c, i18n.InvalidWebhook, err)
+		return
+	}
+
+	if err := h.service.UpdateWebhook(c.Request.Context(), uint(id), fields); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "webhook updated"})
+}
+
+// DeleteWebhook	godoc
+// @Summary 	Delete webhook
+// @Description Delete webhook endpoint together with its delivery log
+// @Tags		Webhooks
+// @Produce 	json
+// @Param       id    	path     	uint  	true  	"Webhook ID"	Format(uint)
+// @Success 	200 	{object} 	schemas.MessageReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/webhooks/{id} 	[delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	if err := h.service.DeleteWebhook(c.Request.Context(), uint(id)); err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "webhook deleted"})
+}
+
+// GetWebhookDeliveries	godoc
+// @Summary 	Get webhook deliveries
+// @Description Get delivery log of the webhook, newest first
+// @Tags		Webhooks
+// @Produce		json
+// @Param       id    	path     	uint  	true  	"Webhook ID"	Format(uint)
+// @Param page query uint false "Current page number" Format(uint) default(1)
+// @Param size query uint false "Number of deliveries per page" Format(uint) default(10)
+// @Success 	200 	{object} 	schemas.DeliveriesPaginationResponse
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/webhooks/{id}/deliveries	[get]
+func (h *WebhookHandler) GetWebhookDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	pageNumber, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || pageNumber < 1 {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidPage)
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "10"))
+	if err != nil || size <= 0 {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidPageSize)
+		return
+	}
+
+	res, err := h.service.GetDeliveries(c.Request.Context(), uint(id), pageNumber, size)
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// RedeliverWebhookDelivery	godoc
+// @Summary 	Redeliver webhook delivery
+// @Description Schedule a new delivery with the payload of the existing one
+// @Tags		Webhooks
+// @Produce		json
+// @Param       id    			path     	uint  	true  	"Webhook ID"	Format(uint)
+// @Param       delivery_id    	path     	uint  	true  	"Delivery ID"	Format(uint)
+// @Success 	202 	{object} 	schemas.CreateReturn
+// @Failure 	400 	{object}  	schemas.Problem
+// @Failure 	401 	{object}  	schemas.Problem
+// @Failure 	403 	{object}  	schemas.Problem
+// @Failure 	404 	{object}  	schemas.Problem
+// @Failure 	500 	{object}  	schemas.Problem
+// @Failure 	429 	{object}  	schemas.Problem
+// @Security 	BearerAuth
+// @Router 		/webhooks/{id}/deliveries/{delivery_id}/redeliver	[post]
+func (h *WebhookHandler) RedeliverWebhookDelivery(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	deliveryID, err := strconv.ParseUint(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		respond.Error(c, http.StatusBadRequest, i18n.InvalidID)
+		return
+	}
+
+	res, err := h.service.Redeliver(c.Request.Context(), uint(id), uint(deliveryID))
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"id": res})
+}
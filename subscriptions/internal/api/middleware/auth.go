@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/service"
+	"subscriptions/rest-service/internal/tenant"
+	"subscriptions/rest-service/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthMiddleware struct {
+	// authenticator is nil when authentication is disabled.
+	authenticator *auth.Authenticator
+	tenants       service.TenantService
+}
+
+func NewAuthMiddleware(authenticator *auth.Authenticator, tenants service.TenantService) AuthMiddleware {
+	return AuthMiddleware{
+		authenticator: authenticator,
+		tenants:       tenants,
+	}
+}
+
+// Authenticate requires a valid bearer token or API key and puts its principal to the
+// request context. API keys are accepted as bearer tokens and in the X-API-Key header.
+// The tenant of the request is put to the context as well, see withTenant.
+func (m *AuthMiddleware) Authenticate(c *gin.Context) {
+	m.authenticate(c, credential(c))
+}
+
+// AuthenticateStream is Authenticate that also accepts the credential in the access_token
+// query parameter, since browsers can't set headers of EventSource requests.
+func (m *AuthMiddleware) AuthenticateStream(c *gin.Context) {
+	token := credential(c)
+	if token == "" {
+		token = c.Query("access_token")
+	}
+
+	m.authenticate(c, token)
+}
+
+// Require returns the middleware that lets in only the callers granted the scope.
+// It must follow Authenticate, requests pass when authentication is disabled.
+func (m *AuthMiddleware) Require(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.HasScope(c.Request.Context(), scope) {
+			respond.Error(c, http.StatusForbidden, i18n.InsufficientScope, scope)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func (m *AuthMiddleware) authenticate(c *gin.Context, token string) {
+	if m.authenticator == nil {
+		m.withTenant(c, nil)
+		return
+	}
+
+	if token == "" {
+		unauthorized(c, i18n.MissingCredentials)
+		return
+	}
+
+	principal, err := m.authenticator.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "authenticate failed", "error", err)
+		unauthorized(c, i18n.InvalidCredentials)
+		return
+	}
+
+	ctx := logger.WithAttrs(c.Request.Context(), slog.String("user", principal.Subject))
+	c.Request = c.Request.WithContext(auth.WithPrincipal(ctx, principal))
+	m.withTenant(c, principal)
+}
+
+// withTenant puts the tenant of the principal to the request context. Super admins
+// and unauthenticated requests select the tenant with the X-Tenant-ID header.
+func (m *AuthMiddleware) withTenant(c *gin.Context, principal *auth.Principal) {
+	tenantID, err := m.tenants.ResolveTenant(c.Request.Context(), principal, c.GetHeader(tenant.Header))
+	if err != nil {
+		respond.ServiceError(c, err)
+		return
+	}
+
+	ctx := logger.WithAttrs(c.Request.Context(), slog.String("tenant", tenantID))
+	c.Request = c.Request.WithContext(tenant.WithID(ctx, tenantID))
+	c.Next()
+}
+
+func credential(c *gin.Context) string {
+	if token := bearerToken(c.GetHeader("Authorization")); token != "" {
+		return token
+	}
+
+	return strings.TrimSpace(c.GetHeader("X-API-Key"))
+}
+
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+func unauthorized(c *gin.Context, message i18n.Message) {
+	c.Header("WWW-Authenticate", `Bearer realm="subscriptions"`)
+	respond.Error(c, http.StatusUnauthorized, message)
+}
@@ -0,0 +1,255 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/service"
+	"subscriptions/rest-service/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const secret = "test-secret"
+
+func signedToken(t *testing.T, subject string) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": subject,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+// apiKeys accepts the only key, its principal reads the subscriptions.
+type apiKeys struct{}
+
+func (apiKeys) VerifyAPIKey(_ context.Context, key string) (*auth.Principal, error) {
+	if key != apiKey {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return &auth.Principal{Subject: "api-key:1", ServiceAccount: true, Scopes: []string{auth.ScopeSubsRead}}, nil
+}
+
+const apiKey = "sk_0123456789ab_secret"
+
+// tenantRepo knows the default and the acme tenants.
+type tenantRepo struct {
+	repository.TenantRepo
+}
+
+func (tenantRepo) TenantExists(_ context.Context, id string) (bool, error) {
+	return slices.Contains([]string{"default", "acme"}, id), nil
+}
+
+func newAuthMiddleware(authenticator *auth.Authenticator) middleware.AuthMiddleware {
+	return middleware.NewAuthMiddleware(authenticator, service.NewTenantService(tenantRepo{}, "default"))
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: secret})
+	if err != nil {
+		t.Fatalf("create verifier: %v", err)
+	}
+	authenticator := auth.NewAuthenticator(verifier, apiKeys{})
+	token := signedToken(t, "60601fee-2bf1-4721-ae6f-7636e79a0cba")
+
+	tests := []struct {
+		name          string
+		authenticator *auth.Authenticator
+		stream        bool
+		header        string
+		apiKeyHeader  string
+		query         string
+		status        int
+		// subject is the expected subject of the principal, empty without principal
+		subject string
+	}{
+		{name: "authentication disabled", status: http.StatusOK},
+		{name: "missing token", authenticator: authenticator, status: http.StatusUnauthorized},
+		{name: "invalid token", authenticator: authenticator, header: "Bearer token", status: http.StatusUnauthorized},
+		{name: "other scheme", authenticator: authenticator, header: "Basic " + token, status: http.StatusUnauthorized},
+		{name: "valid token", authenticator: authenticator, header: "Bearer " + token, status: http.StatusOK, subject: "60601fee-2bf1-4721-ae6f-7636e79a0cba"},
+		{name: "scheme in lower case", authenticator: authenticator, header: "bearer " + token, status: http.StatusOK, subject: "60601fee-2bf1-4721-ae6f-7636e79a0cba"},
+		{name: "token in query", authenticator: authenticator, query: token, status: http.StatusUnauthorized},
+		{name: "stream token in query", authenticator: authenticator, stream: true, query: token, status: http.StatusOK, subject: "60601fee-2bf1-4721-ae6f-7636e79a0cba"},
+		{name: "api key as bearer token", authenticator: authenticator, header: "Bearer " + apiKey, status: http.StatusOK, subject: "api-key:1"},
+		{name: "api key header", authenticator: authenticator, apiKeyHeader: apiKey, status: http.StatusOK, subject: "api-key:1"},
+		{name: "unknown api key", authenticator: authenticator, apiKeyHeader: "sk_0123456789ab_other", status: http.StatusUnauthorized},
+		{name: "api keys disabled", authenticator: auth.NewAuthenticator(verifier, nil), apiKeyHeader: apiKey, status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authMiddleware := newAuthMiddleware(tt.authenticator)
+			handler := authMiddleware.Authenticate
+			if tt.stream {
+				handler = authMiddleware.AuthenticateStream
+			}
+
+			var subject string
+			router := gin.New()
+			router.GET("/", handler, func(c *gin.Context) {
+				if principal := auth.FromContext(c.Request.Context()); principal != nil {
+					subject = principal.Subject
+				}
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/?access_token="+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.apiKeyHeader != "" {
+				req.Header.Set("X-API-Key", tt.apiKeyHeader)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, recorder.Code)
+			}
+			if subject != tt.subject {
+				t.Errorf("expected subject %q, got %q", tt.subject, subject)
+			}
+			if tt.status == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authMiddleware := newAuthMiddleware(auth.NewAuthenticator(nil, apiKeys{}))
+
+	tests := []struct {
+		name   string
+		scope  string
+		key    string
+		status int
+	}{
+		{name: "granted scope", scope: auth.ScopeSubsRead, key: apiKey, status: http.StatusOK},
+		{name: "missing scope", scope: auth.ScopeSubsWrite, key: apiKey, status: http.StatusForbidden},
+		{name: "not authenticated", scope: auth.ScopeSubsRead, status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", authMiddleware.Authenticate, authMiddleware.Require(tt.scope), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, recorder.Code)
+			}
+		})
+	}
+
+	t.Run("authentication disabled", func(t *testing.T) {
+		disabled := newAuthMiddleware(nil)
+
+		router := gin.New()
+		router.GET("/", disabled.Authenticate, disabled.Require(auth.ScopeAdmin), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
+		}
+	})
+}
+
+func TestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: secret, AdminRole: "admin"})
+	if err != nil {
+		t.Fatalf("create verifier: %v", err)
+	}
+	authenticator := auth.NewAuthenticator(verifier, nil)
+
+	token := func(claims jwt.MapClaims) string {
+		claims["sub"] = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name          string
+		authenticator *auth.Authenticator
+		token         string
+		header        string
+		status        int
+		// tenant is the expected tenant of the request context
+		tenant string
+	}{
+		{name: "default tenant", authenticator: authenticator, token: token(jwt.MapClaims{}), status: http.StatusOK, tenant: "default"},
+		{name: "tenant of the token", authenticator: authenticator, token: token(jwt.MapClaims{"tenant_id": "acme"}), status: http.StatusOK, tenant: "acme"},
+		{name: "other tenant requested", authenticator: authenticator, token: token(jwt.MapClaims{"tenant_id": "acme"}), header: "default", status: http.StatusForbidden},
+		{name: "unknown tenant of the token", authenticator: authenticator, token: token(jwt.MapClaims{"tenant_id": "other"}), status: http.StatusNotFound},
+		{name: "super admin selects the tenant", authenticator: authenticator, token: token(jwt.MapClaims{"roles": []string{"admin"}}), header: "acme", status: http.StatusOK, tenant: "acme"},
+		{name: "tenant admin", authenticator: authenticator, token: token(jwt.MapClaims{"roles": []string{"admin"}, "tenant_id": "acme"}), header: "default", status: http.StatusForbidden},
+		{name: "authentication disabled", header: "acme", status: http.StatusOK, tenant: "acme"},
+		{name: "invalid tenant id", header: "Acme!", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authMiddleware := newAuthMiddleware(tt.authenticator)
+
+			var tenantID string
+			router := gin.New()
+			router.GET("/", authMiddleware.Authenticate, func(c *gin.Context) {
+				tenantID, _ = tenant.FromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.header != "" {
+				req.Header.Set(tenant.Header, tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, recorder.Code)
+			}
+			if tenantID != tt.tenant {
+				t.Errorf("expected tenant %q, got %q", tt.tenant, tenantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"subscriptions/rest-service/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Language puts the language negotiated from the Accept-Language header to the request
+// context, the error messages of the response are in it.
+func Language(c *gin.Context) {
+	lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", lang)
+	c.Header("Vary", "Accept-Language")
+
+	c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), lang))
+	c.Next()
+}
@@ -0,0 +1,54 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/i18n"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "missing header", want: i18n.English},
+		{name: "russian", acceptLanguage: "ru-RU,ru;q=0.9", want: i18n.Russian},
+		{name: "unsupported", acceptLanguage: "de", want: i18n.English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lang string
+
+			router := gin.New()
+			router.Use(middleware.Language)
+			router.GET("/subs", func(c *gin.Context) {
+				lang = i18n.FromContext(c.Request.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/subs", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if lang != tt.want {
+				t.Errorf("language = %q, want %q", lang, tt.want)
+			}
+			if got := w.Header().Get("Content-Language"); got != tt.want {
+				t.Errorf("Content-Language = %q, want %q", got, tt.want)
+			}
+			if got := w.Header().Get("Vary"); got != "Accept-Language" {
+				t.Errorf("Vary = %q, want Accept-Language", got)
+			}
+		})
+	}
+}
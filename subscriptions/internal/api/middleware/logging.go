@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/requestid"
+	"subscriptions/rest-service/pkg/logger"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID takes the X-Request-ID of the caller or generates one, returns it in the
+// response header and puts it to the request context and its log lines.
+func RequestID(c *gin.Context) {
+	id := requestid.Resolve(c.GetHeader(requestid.Header))
+	c.Header(requestid.Header, id)
+
+	ctx := logger.WithAttrs(requestid.WithID(c.Request.Context(), id), slog.String("request_id", id))
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
+
+// LogContext puts the route of the request to the request context, so every log line
+// written while handling the request has it.
+func LogContext(c *gin.Context) {
+	ctx := logger.WithAttrs(c.Request.Context(), slog.String("route", c.Request.Method+" "+route(c)))
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
+
+// AccessLog writes one log line per request after it is handled. The line has the fields
+// of the request context, so the user and tenant are there once the request is authenticated.
+// Probes are logged at debug level, since they are polled every few seconds.
+func AccessLog(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	status := c.Writer.Status()
+	attrs := []slog.Attr{
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.Request.URL.Path),
+		slog.Int("status", status),
+		slog.Duration("latency", time.Since(start)),
+		slog.Int("size", max(c.Writer.Size(), 0)),
+		slog.String("client_ip", c.ClientIP()),
+		slog.String("client", client(c)),
+		slog.String("user_agent", c.Request.UserAgent()),
+	}
+	if len(c.Errors) > 0 {
+		attrs = append(attrs, slog.String("error", strings.Join(c.Errors.Errors(), "; ")))
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case isProbe(c.Request.URL.Path):
+		level = slog.LevelDebug
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelError
+	}
+
+	slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+}
+
+// Recovery turns the panics of the handlers into internal errors, the panic is logged
+// with the fields of the request.
+func Recovery(c *gin.Context, recovered any) {
+	slog.ErrorContext(c.Request.Context(), "panic while handling request", "panic", recovered, "stack", string(debug.Stack()))
+	respond.Error(c, http.StatusInternalServerError, i18n.InternalError)
+}
+
+// client identifies the caller in the access log, anonymous callers by their address.
+func client(c *gin.Context) string {
+	if principal := auth.FromContext(c.Request.Context()); principal != nil {
+		return principal.Subject
+	}
+
+	return "anonymous"
+}
+
+// route returns the route pattern of the request, requests that match no route
+// share the "unmatched" route.
+func route(c *gin.Context) string {
+	if path := c.FullPath(); path != "" {
+		return path
+	}
+
+	return "unmatched"
+}
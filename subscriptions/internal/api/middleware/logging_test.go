@@ -0,0 +1,194 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/requestid"
+	"subscriptions/rest-service/pkg/logger"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jsonLogger returns the logger writing JSON records to buf.
+func jsonLogger(t *testing.T, buf *bytes.Buffer) *slog.Logger {
+	t.Helper()
+
+	log, err := logger.New(buf, logger.Config{Format: logger.FormatJSON, Level: "info"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	return log
+}
+
+// decodeRecord decodes the last JSON record of buf.
+func decodeRecord(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+
+	var fields map[string]any
+	if err := json.Unmarshal(lines[len(lines)-1], &fields); err != nil {
+		t.Fatalf("decode record %q: %v", buf.String(), err)
+	}
+
+	return fields
+}
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	log := jsonLogger(t, &buf)
+
+	var ctxID string
+	router := gin.New()
+	router.Use(middleware.RequestID)
+	router.GET("/subs", func(c *gin.Context) {
+		ctxID = requestid.FromContext(c.Request.Context())
+		log.InfoContext(c.Request.Context(), "handled")
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name      string
+		requestID string
+		keep      bool
+	}{
+		{"ID of caller", "abc-123", true},
+		{"no ID", "", false},
+		{"invalid ID", "bad id\nwith newline", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+
+			req := httptest.NewRequest(http.MethodGet, "/subs", nil)
+			if tt.requestID != "" {
+				req.Header.Set(requestid.Header, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			got := w.Header().Get(requestid.Header)
+			if !requestid.Valid(got) {
+				t.Fatalf("response %s = %q, want valid ID", requestid.Header, got)
+			}
+			if tt.keep && got != tt.requestID {
+				t.Errorf("response %s = %q, want %q", requestid.Header, got, tt.requestID)
+			}
+			if !tt.keep && got == tt.requestID {
+				t.Errorf("response %s = %q, want generated ID", requestid.Header, got)
+			}
+			if ctxID != got {
+				t.Errorf("context ID = %q, want %q", ctxID, got)
+			}
+			if fields := decodeRecord(t, &buf); fields["request_id"] != got {
+				t.Errorf("log request_id = %v, want %q", fields["request_id"], got)
+			}
+		})
+	}
+}
+
+func TestLogContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	log := jsonLogger(t, &buf)
+
+	handler := func(c *gin.Context) {
+		log.InfoContext(c.Request.Context(), "handled")
+		c.Status(http.StatusOK)
+	}
+
+	router := gin.New()
+	router.Use(middleware.LogContext)
+	router.GET("/subs/:id", handler)
+	router.NoRoute(handler)
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		wantRoute string
+	}{
+		{"route pattern", http.MethodGet, "/subs/1", "GET /subs/:id"},
+		{"no route", http.MethodGet, "/unknown", "GET unmatched"},
+		{"method without route", http.MethodPost, "/subs/1", "POST unmatched"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+			if fields := decodeRecord(t, &buf); fields["route"] != tt.wantRoute {
+				t.Errorf("route = %v, want %v", fields["route"], tt.wantRoute)
+			}
+		})
+	}
+}
+
+var errFailed = errors.New("database is down")
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(jsonLogger(t, &buf))
+	defer slog.SetDefault(defaultLogger)
+
+	router := gin.New()
+	router.Use(middleware.RequestID, middleware.AccessLog)
+	router.GET("/ok", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	router.GET("/fail", func(c *gin.Context) {
+		c.Error(errFailed)
+		c.Status(http.StatusInternalServerError)
+	})
+
+	tests := []struct {
+		name      string
+		path      string
+		wantLevel string
+		status    float64
+		wantError any
+	}{
+		{"success", "/ok", "INFO", http.StatusOK, nil},
+		{"server error", "/fail", "ERROR", http.StatusInternalServerError, errFailed.Error()},
+		{"not found", "/unknown", "INFO", http.StatusNotFound, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			fields := decodeRecord(t, &buf)
+			if fields["msg"] != "request" || fields["level"] != tt.wantLevel {
+				t.Errorf("record = %v %v, want %v request", fields["level"], fields["msg"], tt.wantLevel)
+			}
+			if fields["status"] != tt.status || fields["path"] != tt.path {
+				t.Errorf("status, path = %v, %v, want %v, %v", fields["status"], fields["path"], tt.status, tt.path)
+			}
+			if fields["client"] != "anonymous" {
+				t.Errorf("client = %v, want anonymous", fields["client"])
+			}
+			if fields["error"] != tt.wantError {
+				t.Errorf("error = %v, want %v", fields["error"], tt.wantError)
+			}
+			if fields["request_id"] == nil {
+				t.Error("request_id is missing")
+			}
+		})
+	}
+}
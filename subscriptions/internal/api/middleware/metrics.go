@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"subscriptions/rest-service/internal/metrics"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records the count and duration of the requests by route and status.
+func Metrics(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	metrics.ObserveHTTPRequest(c.Request.Method, route(c), c.Writer.Status(), time.Since(start))
+}
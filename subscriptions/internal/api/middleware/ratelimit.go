@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/ratelimit"
+	"subscriptions/rest-service/internal/tenant"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitResultKey keeps the strictest result of the request in the gin context.
+const rateLimitResultKey = "rateLimitResult"
+
+type RateLimitMiddleware struct {
+	// limiter is nil when rate limiting is disabled.
+	limiter *ratelimit.Limiter
+}
+
+func NewRateLimitMiddleware(limiter *ratelimit.Limiter) RateLimitMiddleware {
+	return RateLimitMiddleware{
+		limiter: limiter,
+	}
+}
+
+// LimitIP limits the requests from the client address. It runs before authentication,
+// so requests with invalid credentials are limited too.
+func (m *RateLimitMiddleware) LimitIP(c *gin.Context) {
+	if m.limiter == nil {
+		c.Next()
+		return
+	}
+
+	m.apply(c, m.limiter.TakeIP(c.Request.Context(), c.ClientIP()))
+}
+
+// LimitClient limits the requests of the authenticated API key or user, overall and
+// to the route. It must follow Authenticate, without authentication the client is
+// the address of the request.
+func (m *RateLimitMiddleware) LimitClient(c *gin.Context) {
+	if m.limiter == nil {
+		c.Next()
+		return
+	}
+
+	route := c.Request.Method + " " + c.FullPath()
+	m.apply(c, m.limiter.TakeClient(c.Request.Context(), rateLimitClient(c), route))
+}
+
+func (m *RateLimitMiddleware) apply(c *gin.Context, result *ratelimit.Result) {
+	if previous, ok := c.Get(rateLimitResultKey); ok {
+		result = ratelimit.Stricter(previous.(*ratelimit.Result), result)
+	}
+
+	if result == nil {
+		c.Next()
+		return
+	}
+	c.Set(rateLimitResultKey, result)
+
+	header := c.Writer.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit.Burst))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	header.Set("RateLimit-Policy", strconv.Itoa(result.Limit.Burst)+";w="+strconv.Itoa(ceilSeconds(result.Limit.Window())))
+
+	if !result.Allowed {
+		header.Set("Retry-After", strconv.Itoa(max(ceilSeconds(result.RetryAfter), 1)))
+		respond.Error(c, http.StatusTooManyRequests, i18n.RateLimited)
+		return
+	}
+
+	c.Next()
+}
+
+// rateLimitClient identifies the caller, subjects are unique within a tenant only.
+func rateLimitClient(c *gin.Context) string {
+	principal := auth.FromContext(c.Request.Context())
+	if principal == nil {
+		return "ip:" + c.ClientIP()
+	}
+
+	tenantID, _ := tenant.FromContext(c.Request.Context())
+	return tenantID + "/" + principal.Subject
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
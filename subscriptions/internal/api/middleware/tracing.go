@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Tracing starts the span of every request, continuing the W3C trace context of the caller.
+// The probes, metrics scrapes and docs are not traced.
+func Tracing(serviceName string) gin.HandlerFunc {
+	return otelgin.Middleware(serviceName, otelgin.WithFilter(func(r *http.Request) bool {
+		path := r.URL.Path
+		return !isProbe(path) && path != "/metrics" && !strings.HasPrefix(path, "/docs/")
+	}))
+}
+
+// isProbe reports whether the path is polled by the orchestrator.
+func isProbe(path string) bool {
+	return path == "/health" || path == "/livez" || path == "/readyz"
+}
@@ -0,0 +1,72 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"subscriptions/rest-service/internal/api/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	router := gin.New()
+	router.Use(middleware.Tracing("subscriptions-test"))
+	for _, path := range []string{"/health", "/metrics", "/docs/*any", "/subs/:id"} {
+		router.GET(path, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		wantSpan    bool
+	}{
+		{"health check", "/health", "", false},
+		{"metrics scrape", "/metrics", "", false},
+		{"docs", "/docs/index.html", "", false},
+		{"new trace", "/subs/1", "", true},
+		{"trace of caller", "/subs/1", "00-" + traceID + "-00f067aa0ba902b7-01", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(recorder.Ended())
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()[before:]
+			if got := len(spans) > 0; got != tt.wantSpan {
+				t.Fatalf("span recorded = %v, want %v", got, tt.wantSpan)
+			}
+			if !tt.wantSpan {
+				return
+			}
+
+			span := spans[0]
+			if span.Name() != "GET /subs/:id" {
+				t.Errorf("span name = %q, want %q", span.Name(), "GET /subs/:id")
+			}
+			if tt.traceparent != "" && span.SpanContext().TraceID().String() != traceID {
+				t.Errorf("trace ID = %s, want %s", span.SpanContext().TraceID(), traceID)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package respond
+
+import (
+	"reflect"
+	"strings"
+	"subscriptions/rest-service/internal/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// fieldPath returns the path of the field in the request body, without the struct name
+// the validator starts the namespace with.
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+
+	return namespace
+}
+
+// ruleMessage describes the rule the field breaks in the language, with the translations
+// registered by i18n.RegisterValidator.
+func ruleMessage(lang string, fieldErr validator.FieldError) string {
+	message := fieldErr.Translate(i18n.Translator(lang))
+	if message == fieldErr.Error() {
+		// the rule has no translation
+		return i18n.Text(lang, i18n.FieldRule, fieldErr.Field(), fieldErr.Tag())
+	}
+
+	return message
+}
+
+// jsonType names the JSON type of the Go kind.
+func jsonType(kind reflect.Kind) i18n.Message {
+	switch kind {
+	case reflect.String:
+		return i18n.TypeString
+	case reflect.Bool:
+		return i18n.TypeBoolean
+	case reflect.Slice, reflect.Array:
+		return i18n.TypeArray
+	case reflect.Map, reflect.Struct:
+		return i18n.TypeObject
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return i18n.TypeUnsigned
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return i18n.TypeInteger
+	case reflect.Float32, reflect.Float64:
+		return i18n.TypeNumber
+	default:
+		return i18n.TypeValue
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Package respond writes the error responses of the REST API as RFC 7807 problems, every
+problem has the ID of the request so the errors can be matched to the logs. The details
+and field messages are in the language negotiated by middleware.Language, the code of
+the detail stays the same in every language.
+*/
+package respond
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/requestid"
+	"subscriptions/rest-service/internal/schemas"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// ContentType is the media type of the error responses.
+const ContentType = "application/problem+json"
+
+const (
+	// TypeDefault is the type of the problems described by their status.
+	TypeDefault = "about:blank"
+	// TypeValidation is the type of the problems with invalid request fields listed in errors.
+	TypeValidation = "/problems/validation-error"
+)
+
+func write(c *gin.Context, problem schemas.Problem) {
+	problem.Instance = c.Request.URL.Path
+	problem.RequestID = requestid.FromContext(c.Request.Context())
+
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// Error aborts the request with the problem of the status, message is its detail with
+// the params in its placeholders.
+func Error(c *gin.Context, code int, message i18n.Message, params ...string) {
+	write(c, schemas.Problem{
+		Type:   TypeDefault,
+		Title:  http.StatusText(code),
+		Status: code,
+		Code:   string(message),
+		Detail: i18n.Translate(c.Request.Context(), message, params...),
+	})
+}
+
+// ServiceError aborts the request with the message of the service error, other errors
+// are reported as internal errors. The cause is added to the access log, and the
+// causes of internal errors are logged.
+func ServiceError(c *gin.Context, err error) {
+	code, message := http.StatusInternalServerError, i18n.InternalError
+
+	var serviceErr *schemas.AppError
+	if errors.As(err, &serviceErr) {
+		code, message = serviceErr.Code, serviceErr.Message
+	}
+
+	c.Error(err)
+	if code >= http.StatusInternalServerError {
+		slog.ErrorContext(c.Request.Context(), "request failed", "status", code, "error", err)
+	}
+
+	Error(c, code, message)
+}
+
+// Invalid aborts the request with the validation problem listing the fields of err, the
+// validator.ValidationErrors of the body or the JSON decoding error of a field.
+func Invalid(c *gin.Context, message i18n.Message, err error) {
+	InvalidFields(c, message, FieldErrors(i18n.FromContext(c.Request.Context()), err)...)
+}
+
+// InvalidFields aborts the request with the validation problem listing the fields.
+func InvalidFields(c *gin.Context, message i18n.Message, fields ...schemas.FieldError) {
+	write(c, schemas.Problem{
+		Type:   TypeValidation,
+		Title:  "Validation failed",
+		Status: http.StatusBadRequest,
+		Code:   string(message),
+		Detail: i18n.Translate(c.Request.Context(), message),
+		Errors: fields,
+	})
+}
+
+// FieldErrors returns the invalid fields of the validation or JSON decoding error in the
+// language, nil for other errors.
+func FieldErrors(lang string, err error) []schemas.FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]schemas.FieldError, len(validationErrs))
+		for i, fieldErr := range validationErrs {
+			fields[i] = schemas.FieldError{
+				Field:   fieldPath(fieldErr),
+				Rule:    fieldErr.Tag(),
+				Message: ruleMessage(lang, fieldErr),
+			}
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []schemas.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: i18n.Text(lang, i18n.FieldType, typeErr.Field, i18n.Text(lang, jsonType(typeErr.Type.Kind()))),
+		}}
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package respond_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/helpers"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/requestid"
+	"subscriptions/rest-service/internal/schemas"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// validate names the fields as the request bodies do, like the validator of the handlers.
+// The translations are registered once, the catalogs of the languages are shared.
+var validate = validator.New()
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		return name
+	})
+	validate.RegisterValidation("mm_yyyy_date", helpers.ValidateDateMMYYYYFormatValidator)
+	if err := i18n.RegisterValidator(validate); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestServiceError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		lang        string
+		err         error
+		wantStatus  int
+		wantCode    i18n.Message
+		wantMessage string
+	}{
+		{
+			name:        "service error",
+			lang:        i18n.English,
+			err:         &schemas.AppError{Code: http.StatusNotFound, Message: i18n.SubscriptionNotFound},
+			wantStatus:  http.StatusNotFound,
+			wantCode:    i18n.SubscriptionNotFound,
+			wantMessage: "subscription not found",
+		},
+		{
+			name:        "wrapped service error",
+			lang:        i18n.English,
+			err:         errors.Join(errors.New("context"), &schemas.AppError{Code: http.StatusConflict, Message: i18n.TagExists}),
+			wantStatus:  http.StatusConflict,
+			wantCode:    i18n.TagExists,
+			wantMessage: i18n.Text(i18n.English, i18n.TagExists),
+		},
+		{
+			name:        "other error",
+			lang:        i18n.English,
+			err:         errors.New("connection refused"),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    i18n.InternalError,
+			wantMessage: "internal server error",
+		},
+		{
+			name:        "service error in russian",
+			lang:        i18n.Russian,
+			err:         &schemas.AppError{Code: http.StatusInternalServerError, Message: i18n.InternalError},
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    i18n.InternalError,
+			wantMessage: "внутренняя ошибка сервера",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			ctx := requestid.WithID(context.Background(), "req-1")
+			c.Request = httptest.NewRequestWithContext(i18n.WithLanguage(ctx, tt.lang), http.MethodGet, "/subs", nil)
+
+			respond.ServiceError(c, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if !c.IsAborted() {
+				t.Error("request is not aborted")
+			}
+			if len(c.Errors) != 1 {
+				t.Errorf("errors = %v, want the cause", c.Errors)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != respond.ContentType {
+				t.Errorf("content type = %q, want %q", got, respond.ContentType)
+			}
+
+			var body schemas.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body %q: %v", w.Body.String(), err)
+			}
+			if body.Type != respond.TypeDefault || body.Status != tt.wantStatus {
+				t.Errorf("body = %+v, want %d problem", body, tt.wantStatus)
+			}
+			if body.Code != string(tt.wantCode) || body.Detail != tt.wantMessage {
+				t.Errorf("body = %+v, want %s %q", body, tt.wantCode, tt.wantMessage)
+			}
+			if body.Instance != "/subs" || body.RequestID != "req-1" {
+				t.Errorf("body = %+v, want instance /subs with request ID req-1", body)
+			}
+		})
+	}
+}
+
+type request struct {
+	Name  string   `json:"service_name" validate:"required"`
+	Price int      `json:"price" validate:"gt=0"`
+	Tags  []string `json:"tags" validate:"max=2,dive,max=3"`
+	Date  string   `json:"start_date" validate:"mm_yyyy_date"`
+	Code  string   `json:"code" validate:"startsnotwith=y"`
+}
+
+func TestFieldErrors(t *testing.T) {
+	valid := request{Name: "Netflix", Price: 1, Date: "07-2025", Code: "x"}
+	invalid := func(change func(r *request)) error {
+		r := valid
+		change(&r)
+		return validate.Struct(r)
+	}
+
+	typeErr := json.Unmarshal([]byte(`{"price":"free"}`), &request{})
+
+	tests := []struct {
+		name string
+		lang string
+		err  error
+		want []schemas.FieldError
+	}{
+		{
+			name: "required",
+			lang: i18n.English,
+			err:  invalid(func(r *request) { r.Name = "" }),
+			want: []schemas.FieldError{{Field: "service_name", Rule: "required", Message: "service_name is a required field"}},
+		},
+		{
+			name: "required in russian",
+			lang: i18n.Russian,
+			err:  invalid(func(r *request) { r.Name = "" }),
+			want: []schemas.FieldError{{Field: "service_name", Rule: "required", Message: "service_name обязательное поле"}},
+		},
+		{
+			name: "list item",
+			lang: i18n.English,
+			err:  invalid(func(r *request) { r.Tags = []string{"a", "long"} }),
+			want: []schemas.FieldError{{Field: "tags[1]", Rule: "max", Message: "tags[1] must be a maximum of 3 characters in length"}},
+		},
+		{
+			name: "rule of the catalogs",
+			lang: i18n.English,
+			err:  invalid(func(r *request) { r.Date = "2025-07" }),
+			want: []schemas.FieldError{{Field: "start_date", Rule: "mm_yyyy_date", Message: "start_date must be a date in 'mm-yyyy' format"}},
+		},
+		{
+			name: "untranslated rule",
+			lang: i18n.English,
+			err:  invalid(func(r *request) { r.Code = "y" }),
+			want: []schemas.FieldError{{Field: "code", Rule: "startsnotwith", Message: "code breaks the startsnotwith rule"}},
+		},
+		{
+			name: "json type",
+			lang: i18n.English,
+			err:  typeErr,
+			want: []schemas.FieldError{{Field: "price", Rule: "type", Message: "price must be an integer"}},
+		},
+		{
+			name: "json type in russian",
+			lang: i18n.Russian,
+			err:  typeErr,
+			want: []schemas.FieldError{{Field: "price", Rule: "type", Message: "price должно быть целым числом"}},
+		},
+		{
+			name: "other error",
+			lang: i18n.English,
+			err:  errors.New("unexpected EOF"),
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := respond.FieldErrors(tt.lang, tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FieldErrors() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/subs", nil)
+
+	respond.Invalid(c, i18n.InvalidSubscription, validate.Struct(request{Price: 1, Date: "07-2025"}))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	var body schemas.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", w.Body.String(), err)
+	}
+	if body.Type != respond.TypeValidation || body.Code != string(i18n.InvalidSubscription) {
+		t.Errorf("body = %+v, want validation problem %s", body, i18n.InvalidSubscription)
+	}
+	if len(body.Errors) != 1 || body.Errors[0].Field != "service_name" {
+		t.Errorf("errors = %+v, want service_name", body.Errors)
+	}
+}
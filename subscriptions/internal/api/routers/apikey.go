@@ -0,0 +1,27 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"subscriptions/rest-service/internal/api/handlers"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/auth"
+)
+
+func apiKeyRouter(
+	router *gin.RouterGroup,
+	handler handlers.APIKeyHandler,
+	authMiddleware middleware.AuthMiddleware,
+	rateLimit middleware.RateLimitMiddleware,
+) {
+	admin := authMiddleware.Require(auth.ScopeAdmin)
+
+	apiKeysRouter := router.Group("/api-keys", authMiddleware.Authenticate, rateLimit.LimitClient)
+	{
+		apiKeysRouter.GET("/", admin, handler.GetAllAPIKeys)
+		apiKeysRouter.GET("/:id", admin, handler.GetAPIKeyByID)
+		apiKeysRouter.POST("/", admin, handler.CreateAPIKey)
+		apiKeysRouter.POST("/:id/rotate", admin, handler.RotateAPIKey)
+		apiKeysRouter.DELETE("/:id", admin, handler.RevokeAPIKey)
+	}
+}
@@ -1,20 +1,72 @@
 package routers
 
 import (
+	"io"
+	"net/http"
+	"subscriptions/rest-service/internal/api/gql"
 	"subscriptions/rest-service/internal/api/handlers"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/api/respond"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/i18n"
 
 	"github.com/gin-gonic/gin"
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
 )
 
-func SetupRouter(handler handlers.SubHandler) *gin.Engine {
-	router := gin.Default()
-	api := router.Group("/api/v1")
+func SetupRouter(
+	handler handlers.SubHandler,
+	categoryHandler handlers.CategoryHandler,
+	tagHandler handlers.TagHandler,
+	userHandler handlers.UserHandler,
+	budgetHandler handlers.BudgetHandler,
+	webhookHandler handlers.WebhookHandler,
+	apiKeyHandler handlers.APIKeyHandler,
+	tenantHandler handlers.TenantHandler,
+	streamHandler handlers.StreamHandler,
+	graphqlHandler gql.Handler,
+	metricsHandler handlers.MetricsHandler,
+	healthHandler handlers.HealthHandler,
+	authMiddleware middleware.AuthMiddleware,
+	rateLimit middleware.RateLimitMiddleware,
+) *gin.Engine {
+	router := gin.New()
+	router.Use(
+		middleware.Tracing("subscriptions"),
+		middleware.RequestID,
+		middleware.Language,
+		middleware.LogContext,
+		middleware.AccessLog,
+		// the stack is logged by middleware.Recovery
+		gin.CustomRecoveryWithWriter(io.Discard, middleware.Recovery),
+		middleware.Metrics,
+	)
+	router.NoRoute(func(c *gin.Context) {
+		respond.Error(c, http.StatusNotFound, i18n.RouteNotFound)
+	})
+
+	api := router.Group("/api/v1", rateLimit.LimitIP)
 	{
-		subscriptionRouter(api, handler)
+		subscriptionRouter(api, handler, streamHandler, authMiddleware, rateLimit)
+		categoryRouter(api, categoryHandler, authMiddleware, rateLimit)
+		tagRouter(api, tagHandler, authMiddleware, rateLimit)
+		userRouter(api, userHandler, budgetHandler, authMiddleware, rateLimit)
+		budgetRouter(api, budgetHandler, authMiddleware, rateLimit)
+		webhookRouter(api, webhookHandler, authMiddleware, rateLimit)
+		apiKeyRouter(api, apiKeyHandler, authMiddleware, rateLimit)
+		tenantRouter(api, tenantHandler, authMiddleware, rateLimit)
 	}
 
+	// mutations and report fields check their scopes in the resolvers
+	router.POST(
+		"/graphql",
+		rateLimit.LimitIP, authMiddleware.Authenticate, rateLimit.LimitClient, authMiddleware.Require(auth.ScopeSubsRead),
+		graphqlHandler.Query,
+	)
+	router.GET("/metrics", metricsHandler.Metrics)
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	router.GET("/health", func(ctx *gin.Context) {
 		ctx.IndentedJSON(200, gin.H{"message": "service good"})
@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"subscriptions/rest-service/internal/api/handlers"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/auth"
+)
+
+// budgetRouter keeps budgets to admins, since the budget endpoints are not limited
+// to the caller's user. Users read their budgets through the budget status.
+func budgetRouter(
+	router *gin.RouterGroup,
+	handler handlers.BudgetHandler,
+	authMiddleware middleware.AuthMiddleware,
+	rateLimit middleware.RateLimitMiddleware,
+) {
+	admin := authMiddleware.Require(auth.ScopeAdmin)
+
+	budgetsRouter := router.Group("/budgets", authMiddleware.Authenticate, rateLimit.LimitClient)
+	{
+		budgetsRouter.GET("/", admin, handler.GetAllBudgets)
+		budgetsRouter.GET("/:id", admin, handler.GetBudgetByID)
+		budgetsRouter.POST("/", admin, handler.CreateBudget)
+		budgetsRouter.PUT("/:id", admin, handler.UpdateBudget)
+		budgetsRouter.DELETE("/:id", admin, handler.DeleteBudget)
+	}
+}
@@ -0,0 +1,28 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"subscriptions/rest-service/internal/api/handlers"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/auth"
+)
+
+func categoryRouter(
+	router *gin.RouterGroup,
+	handler handlers.CategoryHandler,
+	authMiddleware middleware.AuthMiddleware,
+	rateLimit middleware.RateLimitMiddleware,
+) {
+	read := authMiddleware.Require(auth.ScopeSubsRead)
+	admin := authMiddleware.Require(auth.ScopeAdmin)
+
+	categoriesRouter := router.Group("/categories", authMiddleware.Authenticate, rateLimit.LimitClient)
+	{
+		categoriesRouter.GET("/", read, handler.GetAllCategories)
+		categoriesRouter.GET("/:id", read, handler.GetCategoryByID)
+		categoriesRouter.POST("/", admin, handler.CreateCategory)
+		categoriesRouter.PUT("/:id", admin, handler.UpdateCategory)
+		categoriesRouter.DELETE("/:id", admin, handler.DeleteCategory)
+	}
+}
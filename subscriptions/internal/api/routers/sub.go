@@ -4,17 +4,33 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"subscriptions/rest-service/internal/api/handlers"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/auth"
 )
 
-func subscriptionRouter(router *gin.RouterGroup, handler handlers.SubHandler) {
-	subsRouter := router.Group("/subs")
+func subscriptionRouter(
+	router *gin.RouterGroup,
+	handler handlers.SubHandler,
+	streamHandler handlers.StreamHandler,
+	authMiddleware middleware.AuthMiddleware,
+	rateLimit middleware.RateLimitMiddleware,
+) {
+	read := authMiddleware.Require(auth.ScopeSubsRead)
+	write := authMiddleware.Require(auth.ScopeSubsWrite)
+	reports := authMiddleware.Require(auth.ScopeReportsRead)
+
+	subsRouter := router.Group("/subs", authMiddleware.Authenticate, rateLimit.LimitClient)
 	{
-		subsRouter.GET("/", handler.GetAllSubscriptions)
-		subsRouter.GET("/:id", handler.GetSubscriptionByID)
-		subsRouter.POST("/", handler.CreateSubscription)
-		subsRouter.PUT("/:id", handler.FullUpdateSubscription)
-		subsRouter.PATCH("/:id", handler.PatchUpdateSubscription)
-		subsRouter.DELETE("/:id", handler.DeleteSubscription)
-		subsRouter.GET("/sub_sum", handler.GetSubscriptionSumInfo)
+		subsRouter.GET("/", read, handler.GetAllSubscriptions)
+		subsRouter.GET("/:id", read, handler.GetSubscriptionByID)
+		subsRouter.POST("/", write, handler.CreateSubscription)
+		subsRouter.PUT("/:id", write, handler.FullUpdateSubscription)
+		subsRouter.PATCH("/:id", write, handler.PatchUpdateSubscription)
+		subsRouter.DELETE("/:id", write, handler.DeleteSubscription)
+		subsRouter.GET("/sub_sum", reports, handler.GetSubscriptionSumInfo)
+		subsRouter.GET("/sub_sum/by_category", reports, handler.GetSubscriptionSumByCategory)
+		subsRouter.GET("/sub_sum/by_month", reports, handler.GetSubscriptionSumByMonth)
 	}
+
+	router.GET("/subs/events", authMiddleware.AuthenticateStream, rateLimit.LimitClient, read, streamHandler.StreamSubscriptionEvents)
 }
@@ -0,0 +1,27 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"subscriptions/rest-service/internal/api/handlers"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/auth"
+)
+
+func tagRouter(
+	router *gin.RouterGroup,
+	handler handlers.TagHandler,
+	authMiddleware middleware.AuthMiddleware,
+	rateLimit middleware.RateLimitMiddleware,
+) {
+	read := authMiddleware.Require(auth.ScopeSubsRead)
+	admin := authMiddleware.Require(auth.ScopeAdmin)
+
+	tagsRouter := router.Group("/tags", authMiddleware.Authenticate, rateLimit.LimitClient)
+	{
+		tagsRouter.GET("/", read, handler.GetAllTags)
+		tagsRouter.POST("/", admin, handler.CreateTag)
+		tagsRouter.PUT("/:id", admin, handler.UpdateTag)
+		tagsRouter.DELETE("/:id", admin, handler.DeleteTag)
+	}
+}
@@ -0,0 +1,27 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"subscriptions/rest-service/internal/api/handlers"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/auth"
+)
+
+func tenantRouter(
+	router *gin.RouterGroup,
+	handler handlers.TenantHandler,
+	authMiddleware middleware.AuthMiddleware,
+	rateLimit middleware.RateLimitMiddleware,
+) {
+	admin := authMiddleware.Require(auth.ScopeAdmin)
+
+	tenantsRouter := router.Group("/tenants", authMiddleware.Authenticate, rateLimit.LimitClient)
+	{
+		tenantsRouter.GET("/", admin, handler.GetAllTenants)
+		tenantsRouter.GET("/:id", admin, handler.GetTenantByID)
+		tenantsRouter.POST("/", admin, handler.CreateTenant)
+		tenantsRouter.PUT("/:id", admin, handler.UpdateTenant)
+		tenantsRouter.GET("/:id/stats", admin, handler.GetTenantStats)
+	}
+}
@@ -0,0 +1,33 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"subscriptions/rest-service/internal/api/handlers"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/auth"
+)
+
+func userRouter(
+	router *gin.RouterGroup,
+	handler handlers.UserHandler,
+	budgetHandler handlers.BudgetHandler,
+	authMiddleware middleware.AuthMiddleware,
+	rateLimit middleware.RateLimitMiddleware,
+) {
+	read := authMiddleware.Require(auth.ScopeSubsRead)
+	reports := authMiddleware.Require(auth.ScopeReportsRead)
+	admin := authMiddleware.Require(auth.ScopeAdmin)
+
+	usersRouter := router.Group("/users", authMiddleware.Authenticate, rateLimit.LimitClient)
+	{
+		usersRouter.GET("/", admin, handler.GetAllUsers)
+		usersRouter.GET("/:id", admin, handler.GetUserByID)
+		usersRouter.POST("/", admin, handler.CreateUser)
+		usersRouter.PUT("/:id", admin, handler.UpdateUser)
+		usersRouter.DELETE("/:id", admin, handler.DeleteUser)
+		usersRouter.GET("/:id/subscriptions", read, handler.GetUserSubscriptions)
+		usersRouter.GET("/:id/spending", reports, handler.GetUserSpending)
+		usersRouter.GET("/:id/budget-status", reports, budgetHandler.GetUserBudgetStatus)
+	}
+}
@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"subscriptions/rest-service/internal/api/handlers"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/auth"
+)
+
+func webhookRouter(
+	router *gin.RouterGroup,
+	handler handlers.WebhookHandler,
+	authMiddleware middleware.AuthMiddleware,
+	rateLimit middleware.RateLimitMiddleware,
+) {
+	admin := authMiddleware.Require(auth.ScopeAdmin)
+
+	webhooksRouter := router.Group("/webhooks", authMiddleware.Authenticate, rateLimit.LimitClient)
+	{
+		webhooksRouter.GET("/", admin, handler.GetAllWebhooks)
+		webhooksRouter.GET("/:id", admin, handler.GetWebhookByID)
+		webhooksRouter.POST("/", admin, handler.CreateWebhook)
+		webhooksRouter.PUT("/:id", admin, handler.UpdateWebhook)
+		webhooksRouter.DELETE("/:id", admin, handler.DeleteWebhook)
+		webhooksRouter.GET("/:id/deliveries", admin, handler.GetWebhookDeliveries)
+		webhooksRouter.POST("/:id/deliveries/:delivery_id/redeliver", admin, handler.RedeliverWebhookDelivery)
+	}
+}
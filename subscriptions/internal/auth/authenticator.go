@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key, which tells API keys from JWTs.
+const APIKeyPrefix = "sk_"
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// APIKeyVerifier resolves API keys to principals.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*Principal, error)
+}
+
+// Authenticator checks the credentials of the request, which are either a JWT
+// or an API key.
+type Authenticator struct {
+	jwt     *JWTVerifier
+	apiKeys APIKeyVerifier
+}
+
+func NewAuthenticator(jwt *JWTVerifier, apiKeys APIKeyVerifier) *Authenticator {
+	return &Authenticator{
+		jwt:     jwt,
+		apiKeys: apiKeys,
+	}
+}
+
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+func (a *Authenticator) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if IsAPIKey(credential) {
+		if a.apiKeys == nil {
+			return nil, ErrInvalidCredentials
+		}
+		return a.apiKeys.VerifyAPIKey(ctx, credential)
+	}
+
+	if a.jwt == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return a.jwt.Verify(credential)
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const clockLeeway = 30 * time.Second
+
+var (
+	ErrNoKeys        = errors.New("no keys to verify tokens configured")
+	ErrKeysConflict  = errors.New("the HMAC secret and the public key can't be used together")
+	ErrUnknownKey    = errors.New("unknown signing key")
+	ErrInvalidClaims = errors.New("invalid token claims")
+)
+
+type JWTConfig struct {
+	Issuer   string
+	Audience string
+	// HMACSecret, PublicKeyFile and JWKSFile are the verification keys, at least one is
+	// required. HMACSecret and PublicKeyFile are both the key of the tokens without kid, so
+	// only one of them may be set.
+	HMACSecret    string
+	PublicKeyFile string
+	JWKSFile      string
+	// AdminRole is the role in the roles claim or the scope in the scope claim of admins.
+	AdminRole string
+}
+
+// JWTVerifier checks bearer tokens and converts their claims to principals.
+type JWTVerifier struct {
+	parser *jwt.Parser
+	// keys by kid, the key of the empty kid is used for tokens without kid.
+	keys      map[string]crypto.PublicKey
+	adminRole string
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+	Scope string   `json:"scope,omitempty"`
+	// TenantID binds the token to a tenant.
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+func NewJWTVerifier(config JWTConfig) (*JWTVerifier, error) {
+	if config.HMACSecret != "" && config.PublicKeyFile != "" {
+		return nil, ErrKeysConflict
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+
+	if config.HMACSecret != "" {
+		keys[""] = []byte(config.HMACSecret)
+	}
+
+	if config.PublicKeyFile != "" {
+		key, err := loadPublicKey(config.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys[""] = key
+	}
+
+	if config.JWKSFile != "" {
+		jwks, err := loadJWKS(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		for kid, key := range jwks {
+			keys[kid] = key
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{
+			"HS256", "HS384", "HS512",
+			"RS256", "RS384", "RS512", "PS256", "PS384", "PS512",
+			"ES256", "ES384", "ES512", "EdDSA",
+		}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockLeeway),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &JWTVerifier{
+		parser:    jwt.NewParser(options...),
+		keys:      keys,
+		adminRole: config.AdminRole,
+	}, nil
+}
+
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported public key in %s", path)
+}
+
+// keyFunc returns the key of the token kid, checking that it fits the signing method.
+// A kid without a key is rejected even if only one key is configured, so a token can't
+// be verified with a key it wasn't meant for.
+func (v *JWTVerifier) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	var fits bool
+	switch key.(type) {
+	case []byte:
+		_, fits = token.Method.(*jwt.SigningMethodHMAC)
+	case *rsa.PublicKey:
+		_, fitsRSA := token.Method.(*jwt.SigningMethodRSA)
+		_, fitsPSS := token.Method.(*jwt.SigningMethodRSAPSS)
+		fits = fitsRSA || fitsPSS
+	case *ecdsa.PublicKey:
+		_, fits = token.Method.(*jwt.SigningMethodECDSA)
+	case ed25519.PublicKey:
+		_, fits = token.Method.(*jwt.SigningMethodEd25519)
+	}
+	if !fits {
+		return nil, fmt.Errorf("signing method %s doesn't match the key", token.Method.Alg())
+	}
+
+	return key, nil
+}
+
+// Verify checks the signature and claims of the token and returns its principal.
+func (v *JWTVerifier) Verify(tokenString string) (*Principal, error) {
+	var tokenClaims claims
+
+	if _, err := v.parser.ParseWithClaims(tokenString, &tokenClaims, v.keyFunc); err != nil {
+		return nil, err
+	}
+
+	if tokenClaims.Subject == "" {
+		return nil, ErrInvalidClaims
+	}
+
+	scopes := strings.Fields(tokenClaims.Scope)
+
+	principal := &Principal{
+		Subject:  tokenClaims.Subject,
+		TenantID: tokenClaims.TenantID,
+		Scopes:   knownScopes(scopes),
+	}
+	if len(principal.Scopes) == 0 {
+		principal.Scopes = DefaultScopes
+	}
+
+	if userID, err := uuid.Parse(tokenClaims.Subject); err == nil {
+		principal.UserID = &userID
+	}
+
+	if v.adminRole != "" {
+		for _, role := range tokenClaims.Roles {
+			if role == v.adminRole {
+				principal.Admin = true
+			}
+		}
+		if slices.Contains(scopes, v.adminRole) {
+			principal.Admin = true
+		}
+	}
+
+	if slices.Contains(principal.Scopes, ScopeAdmin) {
+		principal.Admin = true
+	}
+
+	return principal, nil
+}
@@ -0,0 +1,264 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"subscriptions/rest-service/internal/auth"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const secret = "test-secret"
+
+var userID = uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+
+// token signs the claims with the key, kid is set in the header if it isn't empty.
+func token(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+
+	signed, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+// userClaims returns valid claims of the user with the extra claims added.
+func userClaims(extra jwt.MapClaims) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"sub": userID.String(),
+		"iss": "issuer",
+		"aud": "subscriptions",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range extra {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+	return claims
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func rsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestVerify(t *testing.T) {
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{
+		Issuer:     "issuer",
+		Audience:   "subscriptions",
+		HMACSecret: secret,
+		AdminRole:  "admin",
+	})
+	if err != nil {
+		t.Fatalf("create verifier: %v", err)
+	}
+
+	otherKey := rsaKey(t)
+
+	tests := []struct {
+		name  string
+		token string
+		// err is the expected error, any error if errAny is set
+		err    error
+		errAny bool
+		admin  bool
+		user   *uuid.UUID
+	}{
+		{
+			name:  "user",
+			token: token(t, jwt.SigningMethodHS256, []byte(secret), "", userClaims(nil)),
+			user:  &userID,
+		},
+		{
+			name:  "admin by role",
+			token: token(t, jwt.SigningMethodHS256, []byte(secret), "", userClaims(jwt.MapClaims{"roles": []string{"viewer", "admin"}})),
+			admin: true,
+			user:  &userID,
+		},
+		{
+			name:  "admin by scope",
+			token: token(t, jwt.SigningMethodHS512, []byte(secret), "", userClaims(jwt.MapClaims{"scope": "subs:read admin"})),
+			admin: true,
+			user:  &userID,
+		},
+		{
+			name:  "subject which is not a user",
+			token: token(t, jwt.SigningMethodHS256, []byte(secret), "", userClaims(jwt.MapClaims{"sub": "billing-service"})),
+		},
+		{
+			name:  "unknown kid with the only key",
+			token: token(t, jwt.SigningMethodHS256, []byte(secret), "other", userClaims(nil)),
+			err:   auth.ErrUnknownKey,
+		},
+		{
+			name:  "missing subject",
+			token: token(t, jwt.SigningMethodHS256, []byte(secret), "", userClaims(jwt.MapClaims{"sub": nil})),
+			err:   auth.ErrInvalidClaims,
+		},
+		{
+			name:  "expired",
+			token: token(t, jwt.SigningMethodHS256, []byte(secret), "", userClaims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})),
+			err:   jwt.ErrTokenExpired,
+		},
+		{
+			name:  "without expiration",
+			token: token(t, jwt.SigningMethodHS256, []byte(secret), "", userClaims(jwt.MapClaims{"exp": nil})),
+			err:   jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name:  "other issuer",
+			token: token(t, jwt.SigningMethodHS256, []byte(secret), "", userClaims(jwt.MapClaims{"iss": "other"})),
+			err:   jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name:  "other audience",
+			token: token(t, jwt.SigningMethodHS256, []byte(secret), "", userClaims(jwt.MapClaims{"aud": "other"})),
+			err:   jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name:  "other secret",
+			token: token(t, jwt.SigningMethodHS256, []byte("other"), "", userClaims(nil)),
+			err:   jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:   "signing method not matching the key",
+			token:  token(t, jwt.SigningMethodRS256, otherKey, "", userClaims(nil)),
+			errAny: true,
+		},
+		{name: "malformed", token: "token", err: jwt.ErrTokenMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := verifier.Verify(tt.token)
+
+			if tt.errAny || tt.err != nil {
+				if err == nil || (tt.err != nil && !errors.Is(err, tt.err)) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+
+			if principal.Admin != tt.admin {
+				t.Errorf("expected admin %t, got %t", tt.admin, principal.Admin)
+			}
+			if (principal.UserID == nil) != (tt.user == nil) || (tt.user != nil && *principal.UserID != *tt.user) {
+				t.Errorf("expected user %v, got %v", tt.user, principal.UserID)
+			}
+		})
+	}
+}
+
+func TestVerifyKeys(t *testing.T) {
+	rsaSigner := rsaKey(t)
+	pemSigner := rsaKey(t)
+
+	der, err := x509.MarshalPKIXPublicKey(&pemSigner.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemFile := writeFile(t, "key.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	encode := base64.RawURLEncoding.EncodeToString
+	jwks, err := json.Marshal(map[string]any{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encode(rsaSigner.N.Bytes()), "e": encode(big.NewInt(int64(rsaSigner.E)).Bytes())},
+			{"kty": "oct", "kid": "hmac", "k": encode([]byte(secret))},
+			// encryption keys aren't used to verify tokens
+			{"kty": "oct", "kid": "enc", "use": "enc", "k": encode([]byte(secret))},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksFile := writeFile(t, "jwks.json", jwks)
+
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{PublicKeyFile: pemFile, JWKSFile: jwksFile})
+	if err != nil {
+		t.Fatalf("create verifier: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		key    any
+		kid    string
+		err    error
+	}{
+		{name: "rsa key of the kid", method: jwt.SigningMethodRS256, key: rsaSigner, kid: "rsa"},
+		{name: "pss with the rsa key", method: jwt.SigningMethodPS256, key: rsaSigner, kid: "rsa"},
+		{name: "hmac key of the kid", method: jwt.SigningMethodHS256, key: []byte(secret), kid: "hmac"},
+		{name: "public key file without kid", method: jwt.SigningMethodRS256, key: pemSigner},
+		{name: "unknown kid", method: jwt.SigningMethodRS256, key: rsaSigner, kid: "other", err: auth.ErrUnknownKey},
+		{name: "encryption key", method: jwt.SigningMethodHS256, key: []byte(secret), kid: "enc", err: auth.ErrUnknownKey},
+		{name: "key of another kid", method: jwt.SigningMethodRS256, key: pemSigner, kid: "rsa", err: jwt.ErrTokenSignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(token(t, tt.method, tt.key, tt.kid, userClaims(jwt.MapClaims{"iss": nil, "aud": nil})))
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestNewJWTVerifier(t *testing.T) {
+	tests := []struct {
+		name   string
+		config auth.JWTConfig
+		err    error
+	}{
+		{name: "no keys", err: auth.ErrNoKeys},
+		{name: "secret and public key", config: auth.JWTConfig{HMACSecret: secret, PublicKeyFile: "key.pem"}, err: auth.ErrKeysConflict},
+		{name: "missing public key file", config: auth.JWTConfig{PublicKeyFile: filepath.Join(t.TempDir(), "key.pem")}, err: os.ErrNotExist},
+		{name: "secret", config: auth.JWTConfig{HMACSecret: secret}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := auth.NewJWTVerifier(tt.config); !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Package auth authenticates callers and keeps the authenticated principal in
+the request context.
+*/
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Principal is the authenticated caller.
+type Principal struct {
+	Subject string
+	// UserID is the subject parsed as a user id, nil if the subject is not a user.
+	UserID *uuid.UUID
+	Admin  bool
+	// ServiceAccount is set for API keys not bound to a user, they access the data
+	// of all users within their scopes.
+	ServiceAccount bool
+	Scopes         []string
+	// TenantID is the tenant the principal belongs to. Admins without a tenant
+	// manage all tenants.
+	TenantID string
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal of the request, nil if the request is not
+// authenticated because authentication is disabled.
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// SuperAdmin reports whether the principal is an admin not bound to a tenant.
+func (p *Principal) SuperAdmin() bool {
+	return p.Admin && p.TenantID == ""
+}
+
+// HasScope reports whether the principal is granted the scope, admins are granted all scopes.
+func (p *Principal) HasScope(scope string) bool {
+	return p.Admin || slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// HasScope reports whether the caller is granted the scope. Requests are granted
+// all scopes when authentication is disabled.
+func HasScope(ctx context.Context, scope string) bool {
+	principal := FromContext(ctx)
+	return principal == nil || principal.HasScope(scope)
+}
+
+// UserScope returns the only user whose data the caller may access, nil if the
+// caller is not limited. Callers that are not admins, service accounts or users
+// get the nil user id, which matches no data.
+func UserScope(ctx context.Context) *uuid.UUID {
+	principal := FromContext(ctx)
+	if principal == nil || principal.Admin || principal.ServiceAccount {
+		return nil
+	}
+
+	if principal.UserID == nil {
+		return &uuid.Nil
+	}
+
+	return principal.UserID
+}
+
+// CanAccessUser reports whether the caller may access the data of the user.
+func CanAccessUser(ctx context.Context, userID uuid.UUID) bool {
+	scope := UserScope(ctx)
+	return scope == nil || (*scope != uuid.Nil && *scope == userID)
+}
+
+// IsSuperAdmin reports whether the caller manages all tenants. Requests are
+// not limited to a tenant when authentication is disabled.
+func IsSuperAdmin(ctx context.Context) bool {
+	principal := FromContext(ctx)
+	return principal == nil || principal.SuperAdmin()
+}
+
+// CanAccessTenant reports whether the caller may manage the tenant.
+func CanAccessTenant(ctx context.Context, tenantID string) bool {
+	principal := FromContext(ctx)
+	return principal == nil || principal.SuperAdmin() || principal.TenantID == tenantID
+}
@@ -0,0 +1,48 @@
+package auth_test
+
+import (
+	"context"
+	"subscriptions/rest-service/internal/auth"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestUserScope(t *testing.T) {
+	other := uuid.New()
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		// scope is the expected user scope, unlimited if nil
+		scope *uuid.UUID
+		// access is whether userID and other may be accessed
+		access, accessOther bool
+	}{
+		{name: "authentication disabled", access: true, accessOther: true},
+		{name: "admin", principal: &auth.Principal{Subject: "admin", Admin: true}, access: true, accessOther: true},
+		{name: "user", principal: &auth.Principal{Subject: userID.String(), UserID: &userID}, scope: &userID, access: true},
+		{name: "not a user", principal: &auth.Principal{Subject: "billing-service"}, scope: &uuid.Nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+
+			scope := auth.UserScope(ctx)
+			if (scope == nil) != (tt.scope == nil) || (scope != nil && *scope != *tt.scope) {
+				t.Errorf("expected scope %v, got %v", tt.scope, scope)
+			}
+
+			if auth.CanAccessUser(ctx, userID) != tt.access {
+				t.Errorf("expected access to the user %t", tt.access)
+			}
+			if auth.CanAccessUser(ctx, other) != tt.accessOther {
+				t.Errorf("expected access to the other user %t", tt.accessOther)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package auth
+
+import "slices"
+
+const (
+	ScopeSubsRead    = "subs:read"
+	ScopeSubsWrite   = "subs:write"
+	ScopeReportsRead = "reports:read"
+	// ScopeAdmin grants every other scope and access to the data of all users.
+	ScopeAdmin = "admin"
+)
+
+// Scopes are all the scopes known to the service.
+var Scopes = []string{ScopeSubsRead, ScopeSubsWrite, ScopeReportsRead, ScopeAdmin}
+
+// DefaultScopes are granted to tokens that carry none of the known scopes.
+var DefaultScopes = []string{ScopeSubsRead, ScopeSubsWrite, ScopeReportsRead}
+
+func IsScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+// knownScopes returns the known scopes of the list.
+func knownScopes(scopes []string) []string {
+	var result []string
+	for _, scope := range scopes {
+		if IsScope(scope) && !slices.Contains(result, scope) {
+			result = append(result, scope)
+		}
+	}
+
+	return result
+}
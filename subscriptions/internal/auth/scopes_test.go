@@ -0,0 +1,111 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"subscriptions/rest-service/internal/auth"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// apiKeys accepts the only key.
+type apiKeys struct{}
+
+func (apiKeys) VerifyAPIKey(_ context.Context, key string) (*auth.Principal, error) {
+	if key != "sk_0123456789ab_secret" {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return &auth.Principal{Subject: "api-key:1", ServiceAccount: true}, nil
+}
+
+func TestAuthenticator(t *testing.T) {
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: secret})
+	if err != nil {
+		t.Fatalf("create verifier: %v", err)
+	}
+	jwtToken := token(t, jwt.SigningMethodHS256, []byte(secret), "", userClaims(jwt.MapClaims{"iss": nil, "aud": nil}))
+
+	tests := []struct {
+		name          string
+		authenticator *auth.Authenticator
+		credential    string
+		subject       string
+		err           error
+	}{
+		{name: "jwt", authenticator: auth.NewAuthenticator(verifier, apiKeys{}), credential: jwtToken, subject: userID.String()},
+		{name: "api key", authenticator: auth.NewAuthenticator(verifier, apiKeys{}), credential: "sk_0123456789ab_secret", subject: "api-key:1"},
+		{name: "unknown api key", authenticator: auth.NewAuthenticator(verifier, apiKeys{}), credential: "sk_0123456789ab_other", err: auth.ErrInvalidCredentials},
+		{name: "api keys disabled", authenticator: auth.NewAuthenticator(verifier, nil), credential: "sk_0123456789ab_secret", err: auth.ErrInvalidCredentials},
+		{name: "jwts disabled", authenticator: auth.NewAuthenticator(nil, apiKeys{}), credential: jwtToken, err: auth.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := tt.authenticator.Authenticate(context.Background(), tt.credential)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if err == nil && principal.Subject != tt.subject {
+				t.Errorf("expected subject %q, got %q", tt.subject, principal.Subject)
+			}
+		})
+	}
+}
+
+func TestTokenScopes(t *testing.T) {
+	verifier, err := auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: secret})
+	if err != nil {
+		t.Fatalf("create verifier: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		scope  any
+		scopes []string
+	}{
+		{name: "no scope", scopes: auth.DefaultScopes},
+		{name: "unknown scopes only", scope: "openid profile", scopes: auth.DefaultScopes},
+		{name: "known scopes", scope: "openid subs:read subs:read reports:read", scopes: []string{auth.ScopeSubsRead, auth.ScopeReportsRead}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := verifier.Verify(token(t, jwt.SigningMethodHS256, []byte(secret), "", userClaims(jwt.MapClaims{"iss": nil, "aud": nil, "scope": tt.scope})))
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if !slices.Equal(principal.Scopes, tt.scopes) {
+				t.Errorf("expected scopes %v, got %v", tt.scopes, principal.Scopes)
+			}
+		})
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		scope     string
+		granted   bool
+	}{
+		{name: "authentication disabled", scope: auth.ScopeAdmin, granted: true},
+		{name: "granted scope", principal: &auth.Principal{Scopes: []string{auth.ScopeSubsRead}}, scope: auth.ScopeSubsRead, granted: true},
+		{name: "missing scope", principal: &auth.Principal{Scopes: []string{auth.ScopeSubsRead}}, scope: auth.ScopeSubsWrite},
+		{name: "admin scope", principal: &auth.Principal{Scopes: []string{auth.ScopeAdmin}}, scope: auth.ScopeReportsRead, granted: true},
+		{name: "admin", principal: &auth.Principal{Admin: true}, scope: auth.ScopeSubsWrite, granted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+			if auth.HasScope(ctx, tt.scope) != tt.granted {
+				t.Errorf("expected granted %t", tt.granted)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+/*
+Package cache keeps the results of the expensive queries in a pluggable store. The
+results depend on scopes of the data, e.g. the subscriptions of a user, and a write to
+the data invalidates the scope by giving it a new version: the keys of the results
+include the versions of their scopes, so the results read before the write are never
+found again and the TTL only frees the memory of the unreachable results.
+
+A store local to the instance doesn't see the writes of the other instances, so its cache
+broadcasts the new versions to the caches of all instances.
+*/
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"subscriptions/rest-service/internal/health"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/tenant"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store keeps the cached values.
+type Store interface {
+	// Get returns the value of the key, false if there is none or it has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value of the key for ttl, forever if ttl is zero.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Prune removes the values expired before.
+	Prune(ctx context.Context, before time.Time) error
+}
+
+// Broadcaster passes the new versions of the scopes to the caches of all instances.
+type Broadcaster interface {
+	// Broadcast announces the new version of the scope of the tenant.
+	Broadcast(ctx context.Context, tenantID, scope, version string) error
+	// Listen passes the announced versions to handle until ctx is cancelled or the
+	// connection fails. ready is called once the listening has started.
+	Listen(ctx context.Context, ready func(), handle func(tenantID, scope, version string)) error
+}
+
+const listenRetryInterval = 5 * time.Second
+
+// Cache caches the results in the store. A nil *Cache caches nothing, so the services
+// work the same without it.
+type Cache struct {
+	store Store
+	ttl   time.Duration
+
+	// broadcaster is nil when the store is shared by the instances.
+	broadcaster Broadcaster
+	// listening is false while the versions broadcast by the other instances may be
+	// missed, the results aren't cached then.
+	listening atomic.Bool
+	// epoch is a part of the keys of the entries, it changes whenever the listening
+	// restarts, so the results cached before the versions could be missed are never found.
+	epoch atomic.Pointer[string]
+}
+
+// New returns the cache keeping the results in the store shared by the instances for ttl.
+func New(store Store, ttl time.Duration) *Cache {
+	return &Cache{store: store, ttl: ttl}
+}
+
+// NewLocal returns the cache keeping the results in the store of the instance for ttl.
+// It broadcasts its invalidations, and caches nothing until Listen receives the ones of
+// the other instances.
+func NewLocal(store Store, ttl time.Duration, broadcaster Broadcaster) *Cache {
+	return &Cache{store: store, ttl: ttl, broadcaster: broadcaster}
+}
+
+// Entry is the cached result of the query with its parameters and the versions of the
+// scopes it depends on.
+type Entry struct {
+	cache *Cache
+	name  string
+	key   string
+}
+
+// Entry returns the entry of the named query with the params, which are encoded to JSON,
+// depending on the scopes of the tenant of the request. The versions of the scopes are read
+// now, so the entry must be looked up before the query runs. Without the tenant in the
+// context the entry is never cached.
+func (c *Cache) Entry(ctx context.Context, name string, params any, scopes ...string) *Entry {
+	if c == nil {
+		return nil
+	}
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if c.broadcaster != nil && !c.listening.Load() {
+		return nil
+	}
+
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		slog.ErrorContext(ctx, "encode cache key failed", "cache", name, "error", err)
+		return nil
+	}
+
+	hash := sha256.New()
+	hash.Write(encoded)
+	if epoch := c.epoch.Load(); epoch != nil {
+		hash.Write([]byte{0})
+		hash.Write([]byte(*epoch))
+	}
+	for _, scope := range scopes {
+		version, err := c.version(ctx, tenantID, scope)
+		if err != nil {
+			slog.ErrorContext(ctx, "get cache scope version failed", "cache", name, "error", err)
+			metrics.CacheRequest(name, metrics.CacheError)
+			return nil
+		}
+		hash.Write([]byte{0})
+		hash.Write([]byte(version))
+	}
+
+	return &Entry{
+		cache: c,
+		name:  name,
+		key:   "entry:" + tenantID + ":" + name + ":" + hex.EncodeToString(hash.Sum(nil)),
+	}
+}
+
+// Get decodes the cached result into out and reports whether it was found.
+func (e *Entry) Get(ctx context.Context, out any) bool {
+	if e == nil {
+		return false
+	}
+
+	value, ok, err := e.cache.store.Get(ctx, e.key)
+	switch {
+	case err != nil:
+		slog.ErrorContext(ctx, "get cache entry failed", "cache", e.name, "error", err)
+		metrics.CacheRequest(e.name, metrics.CacheError)
+		return false
+	case !ok:
+		metrics.CacheRequest(e.name, metrics.CacheMiss)
+		return false
+	}
+
+	if err := json.Unmarshal(value, out); err != nil {
+		slog.ErrorContext(ctx, "decode cache entry failed", "cache", e.name, "error", err)
+		metrics.CacheRequest(e.name, metrics.CacheError)
+		return false
+	}
+
+	metrics.CacheRequest(e.name, metrics.CacheHit)
+	return true
+}
+
+// Set caches the result, the failures are logged since the result is returned anyway.
+func (e *Entry) Set(ctx context.Context, result any) {
+	if e == nil {
+		return
+	}
+
+	value, err := json.Marshal(result)
+	if err == nil {
+		err = e.cache.store.Set(ctx, e.key, value, e.cache.ttl)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "set cache entry failed", "cache", e.name, "error", err)
+	}
+}
+
+// Invalidate gives new versions to the scopes of the tenant of the request, it must be
+// called after the write is committed. Failures are logged, the results of the scopes
+// may then be stale until they expire.
+//
+// The versions are broadcast to the other instances when the store is local.
+func (c *Cache) Invalidate(ctx context.Context, scopes ...string) {
+	if c == nil {
+		return
+	}
+
+	tenantID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return
+	}
+
+	for _, scope := range scopes {
+		version := uuid.NewString()
+		err := c.store.Set(ctx, versionKey(tenantID, scope), []byte(version), 0)
+		if err == nil && c.broadcaster != nil {
+			err = c.broadcaster.Broadcast(ctx, tenantID, scope, version)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "invalidate cache scope failed", "scope", scope, "error", err)
+			continue
+		}
+		metrics.CacheInvalidated(scopeKind(scope))
+	}
+}
+
+// Listen applies the versions broadcast by the instances until ctx is cancelled,
+// reconnecting on failures. It does nothing when the store is shared.
+func (c *Cache) Listen(ctx context.Context) {
+	if c == nil || c.broadcaster == nil {
+		return
+	}
+
+	for {
+		ready := func() {
+			epoch := uuid.NewString()
+			c.epoch.Store(&epoch)
+			c.listening.Store(true)
+			health.Beat(ctx)
+		}
+
+		err := c.broadcaster.Listen(ctx, ready, func(tenantID, scope, version string) {
+			if err := c.store.Set(ctx, versionKey(tenantID, scope), []byte(version), 0); err != nil {
+				slog.Error("apply cache scope version failed", "scope", scope, "error", err)
+			}
+		})
+		c.listening.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			health.Fail(ctx, err)
+			slog.Error("listen for cache invalidations failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryInterval):
+		}
+	}
+}
+
+// version returns the version of the scope. A scope without version, never invalidated or
+// evicted from the store, gets a new one, since the results of its previous version may
+// still be stored.
+func (c *Cache) version(ctx context.Context, tenantID, scope string) (string, error) {
+	key := versionKey(tenantID, scope)
+
+	version, ok, err := c.store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return string(version), nil
+	}
+
+	version = []byte(uuid.NewString())
+	if err := c.store.Set(ctx, key, version, 0); err != nil {
+		return "", err
+	}
+
+	return string(version), nil
+}
+
+// Run prunes the expired entries every interval until ctx is cancelled.
+func (c *Cache) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// the first prune is due after the interval
+	health.Beat(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := c.store.Prune(ctx, time.Now())
+		if err != nil {
+			slog.Error("prune cache entries failed", "error", err)
+		}
+		health.Update(ctx, err)
+	}
+}
+
+func versionKey(tenantID, scope string) string {
+	return "version:" + tenantID + ":" + scope
+}
+
+// scopeKind is the scope without its ID, e.g. "user" for "user:<id>", it labels the metrics.
+func scopeKind(scope string) string {
+	kind, _, _ := strings.Cut(scope, ":")
+	return kind
+}
@@ -0,0 +1,441 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"subscriptions/rest-service/internal/cache"
+	"subscriptions/rest-service/internal/tenant"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	os.Exit(m.Run())
+}
+
+type params struct {
+	UserID string
+	Page   int
+}
+
+// cached caches the result of the query and returns whether the entry is found afterwards.
+func cached(ctx context.Context, c *cache.Cache, query params, scopes ...string) bool {
+	c.Entry(ctx, "subs", query, scopes...).Set(ctx, []int{1, 2, 3})
+	return found(ctx, c, query, scopes...)
+}
+
+// found reports whether the result of the query is cached.
+func found(ctx context.Context, c *cache.Cache, query params, scopes ...string) bool {
+	var result []int
+	return c.Entry(ctx, "subs", query, scopes...).Get(ctx, &result)
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+	otherTenant := tenant.WithID(context.Background(), "globex")
+	query := params{UserID: "u1", Page: 1}
+
+	tests := []struct {
+		name string
+		// write runs after the result of the query with the scopes "user:u1" and "catalog"
+		// is cached in ctx
+		write func(c *cache.Cache)
+		found bool
+	}{
+		{
+			name:  "no write",
+			write: func(*cache.Cache) {},
+			found: true,
+		},
+		{
+			name:  "write to the scope",
+			write: func(c *cache.Cache) { c.Invalidate(ctx, "user:u1") },
+			found: false,
+		},
+		{
+			name:  "write to the other scope of the result",
+			write: func(c *cache.Cache) { c.Invalidate(ctx, "catalog") },
+			found: false,
+		},
+		{
+			name:  "write to an unrelated scope",
+			write: func(c *cache.Cache) { c.Invalidate(ctx, "user:u2") },
+			found: true,
+		},
+		{
+			name:  "write to the scope of another tenant",
+			write: func(c *cache.Cache) { c.Invalidate(otherTenant, "user:u1") },
+			found: true,
+		},
+		{
+			name:  "write without tenant",
+			write: func(c *cache.Cache) { c.Invalidate(context.Background(), "user:u1") },
+			found: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.New(cache.NewMemoryStore(100), time.Minute)
+
+			if !cached(ctx, c, query, "user:u1", "catalog") {
+				t.Fatal("expected the result cached")
+			}
+
+			tt.write(c)
+
+			if found := found(ctx, c, query, "user:u1", "catalog"); found != tt.found {
+				t.Errorf("expected found %v right after the write, got %v", tt.found, found)
+			}
+		})
+	}
+}
+
+func TestCacheEntries(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+	c := cache.New(cache.NewMemoryStore(100), time.Minute)
+	query := params{UserID: "u1", Page: 1}
+
+	if !cached(ctx, c, query, "user:u1") {
+		t.Fatal("expected the result cached")
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		query  params
+		scopes []string
+		found  bool
+	}{
+		{name: "same query", ctx: ctx, query: query, scopes: []string{"user:u1"}, found: true},
+		{name: "other params", ctx: ctx, query: params{UserID: "u1", Page: 2}, scopes: []string{"user:u1"}, found: false},
+		{name: "other scopes", ctx: ctx, query: query, scopes: []string{"user:u2"}, found: false},
+		{name: "other tenant", ctx: tenant.WithID(context.Background(), "globex"), query: query, scopes: []string{"user:u1"}, found: false},
+		{name: "no tenant", ctx: context.Background(), query: query, scopes: []string{"user:u1"}, found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if found := found(tt.ctx, c, tt.query, tt.scopes...); found != tt.found {
+				t.Errorf("expected found %v, got %v", tt.found, found)
+			}
+		})
+	}
+}
+
+func TestNilCache(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+
+	var c *cache.Cache
+	if cached(ctx, c, params{}, "user:u1") {
+		t.Error("expected the nil cache to cache nothing")
+	}
+	c.Invalidate(ctx, "user:u1")
+}
+
+// failingStore fails every call, the cache must miss without failing the query.
+type failingStore struct{}
+
+func (failingStore) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("store is down")
+}
+
+func (failingStore) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("store is down")
+}
+
+func (failingStore) Prune(context.Context, time.Time) error {
+	return errors.New("store is down")
+}
+
+func TestCacheFailingStore(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+	c := cache.New(failingStore{}, time.Minute)
+
+	if entry := c.Entry(ctx, "subs", params{}, "user:u1"); entry != nil {
+		t.Errorf("expected no entry without the scope version, got %+v", entry)
+	}
+	if cached(ctx, c, params{}) {
+		t.Error("expected nothing cached in the failing store")
+	}
+}
+
+// broadcaster passes the versions between the caches like the Postgres notifications do,
+// synchronously, so the listeners have applied a version when Broadcast returns.
+type broadcaster struct {
+	mu        sync.Mutex
+	listeners map[int]func(tenantID, scope, version string)
+	nextID    int
+	// ready receives once a listener has started
+	ready chan struct{}
+	// disconnect fails the connections of the listeners
+	disconnect chan struct{}
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{
+		listeners:  map[int]func(tenantID, scope, version string){},
+		ready:      make(chan struct{}),
+		disconnect: make(chan struct{}),
+	}
+}
+
+func (b *broadcaster) Broadcast(_ context.Context, tenantID, scope, version string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, handle := range b.listeners {
+		handle(tenantID, scope, version)
+	}
+	return nil
+}
+
+func (b *broadcaster) Listen(ctx context.Context, ready func(), handle func(tenantID, scope, version string)) error {
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.listeners[id] = handle
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.listeners, id)
+		b.mu.Unlock()
+	}()
+
+	ready()
+	b.ready <- struct{}{}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.disconnect:
+		return errors.New("connection lost")
+	}
+}
+
+// listen runs the listener of the cache until the test ends and waits until it has started.
+func listen(t *testing.T, c *cache.Cache, b *broadcaster) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Listen(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	select {
+	case <-b.ready:
+	case <-time.After(time.Second):
+		t.Fatal("the listener hasn't started")
+	}
+}
+
+func TestLocalCacheBroadcast(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+	query := params{UserID: "u1", Page: 1}
+
+	tests := []struct {
+		name string
+		// write runs on the instance after the result of the query with the scope "user:u1"
+		// is cached on the instances a and b
+		write func(a, b, command *cache.Cache)
+		// foundA and foundB are whether the result is found afterwards on a and b
+		foundA, foundB bool
+	}{
+		{
+			name:   "no write",
+			write:  func(a, b, command *cache.Cache) {},
+			foundA: true,
+			foundB: true,
+		},
+		{
+			name:   "write on an instance",
+			write:  func(a, b, command *cache.Cache) { a.Invalidate(ctx, "user:u1") },
+			foundA: false,
+			foundB: false,
+		},
+		{
+			name:   "write on the other instance",
+			write:  func(a, b, command *cache.Cache) { b.Invalidate(ctx, "user:u1") },
+			foundA: false,
+			foundB: false,
+		},
+		{
+			name:   "write of a command",
+			write:  func(a, b, command *cache.Cache) { command.Invalidate(ctx, "user:u1") },
+			foundA: false,
+			foundB: false,
+		},
+		{
+			name:   "write to an unrelated scope",
+			write:  func(a, b, command *cache.Cache) { command.Invalidate(ctx, "user:u2") },
+			foundA: true,
+			foundB: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broadcaster := newBroadcaster()
+			a := cache.NewLocal(cache.NewMemoryStore(100), time.Minute, broadcaster)
+			b := cache.NewLocal(cache.NewMemoryStore(100), time.Minute, broadcaster)
+			// the commands don't listen
+			command := cache.NewLocal(cache.NewMemoryStore(100), time.Minute, broadcaster)
+			listen(t, a, broadcaster)
+			listen(t, b, broadcaster)
+
+			if !cached(ctx, a, query, "user:u1") || !cached(ctx, b, query, "user:u1") {
+				t.Fatal("expected the result cached on both instances")
+			}
+
+			tt.write(a, b, command)
+
+			if found := found(ctx, a, query, "user:u1"); found != tt.foundA {
+				t.Errorf("expected found %v on the instance a right after the write, got %v", tt.foundA, found)
+			}
+			if found := found(ctx, b, query, "user:u1"); found != tt.foundB {
+				t.Errorf("expected found %v on the instance b right after the write, got %v", tt.foundB, found)
+			}
+		})
+	}
+}
+
+func TestLocalCacheNotListening(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), "acme")
+	broadcaster := newBroadcaster()
+	c := cache.NewLocal(cache.NewMemoryStore(100), time.Minute, broadcaster)
+
+	if cached(ctx, c, params{}, "user:u1") {
+		t.Fatal("expected nothing cached before the listener has started")
+	}
+
+	listen(t, c, broadcaster)
+	if !cached(ctx, c, params{}, "user:u1") {
+		t.Fatal("expected the result cached while listening")
+	}
+
+	close(broadcaster.disconnect)
+	deadline := time.Now().Add(time.Second)
+	for found(ctx, c, params{}, "user:u1") {
+		if time.Now().After(deadline) {
+			t.Fatal("expected nothing found after the listener has disconnected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if cached(ctx, c, params{}, "user:u1") {
+		t.Error("expected nothing cached while the listener is disconnected")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// run uses the store of 2 values and returns the key looked up afterwards
+		run   func(t *testing.T, store *cache.MemoryStore) string
+		found bool
+	}{
+		{
+			name: "stored value",
+			run: func(t *testing.T, store *cache.MemoryStore) string {
+				set(t, store, "a", time.Minute)
+				return "a"
+			},
+			found: true,
+		},
+		{
+			name: "expired value",
+			run: func(t *testing.T, store *cache.MemoryStore) string {
+				set(t, store, "a", time.Millisecond)
+				time.Sleep(2 * time.Millisecond)
+				return "a"
+			},
+			found: false,
+		},
+		{
+			name: "value without expiry",
+			run: func(t *testing.T, store *cache.MemoryStore) string {
+				set(t, store, "a", 0)
+				if err := store.Prune(ctx, time.Now().Add(time.Hour)); err != nil {
+					t.Fatalf("prune: %v", err)
+				}
+				return "a"
+			},
+			found: true,
+		},
+		{
+			name: "pruned value",
+			run: func(t *testing.T, store *cache.MemoryStore) string {
+				set(t, store, "a", time.Minute)
+				if err := store.Prune(ctx, time.Now().Add(time.Hour)); err != nil {
+					t.Fatalf("prune: %v", err)
+				}
+				return "a"
+			},
+			found: false,
+		},
+		{
+			name: "least recently used value evicted",
+			run: func(t *testing.T, store *cache.MemoryStore) string {
+				set(t, store, "a", time.Minute)
+				set(t, store, "b", time.Minute)
+				set(t, store, "c", time.Minute)
+				return "a"
+			},
+			found: false,
+		},
+		{
+			name: "read value kept",
+			run: func(t *testing.T, store *cache.MemoryStore) string {
+				set(t, store, "a", time.Minute)
+				set(t, store, "b", time.Minute)
+				if _, ok, _ := store.Get(ctx, "a"); !ok {
+					t.Fatal("expected the value of a")
+				}
+				set(t, store, "c", time.Minute)
+				return "a"
+			},
+			found: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := cache.NewMemoryStore(2)
+			key := tt.run(t, store)
+
+			value, ok, err := store.Get(ctx, key)
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			if ok != tt.found {
+				t.Fatalf("expected found %v, got %v", tt.found, ok)
+			}
+			if ok && string(value) != key {
+				t.Errorf("expected value %q, got %q", key, value)
+			}
+		})
+	}
+}
+
+// set stores the key as its own value.
+func set(t *testing.T, store *cache.MemoryStore, key string, ttl time.Duration) {
+	t.Helper()
+
+	if err := store.Set(context.Background(), key, []byte(key), ttl); err != nil {
+		t.Fatalf("set %q: %v", key, err)
+	}
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore keeps up to a number of the values in the process and evicts the least
+// recently used ones. The writes of the other instances reach it only through the
+// versions broadcast to the cache, see NewLocal.
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	// items are ordered from the most to the least recently used
+	items *list.List
+	index map[string]*list.Element
+}
+
+// NewMemoryStore returns the store of up to capacity values.
+func NewMemoryStore(capacity int) *MemoryStore {
+	return &MemoryStore{
+		capacity: capacity,
+		items:    list.New(),
+		index:    make(map[string]*list.Element),
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.index[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	item := element.Value.(*memoryItem)
+	if !item.expiresAt.IsZero() && !time.Now().Before(item.expiresAt) {
+		s.remove(element)
+		return nil, false, nil
+	}
+
+	s.items.MoveToFront(element)
+	return item.value, true, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if element, ok := s.index[key]; ok {
+		item := element.Value.(*memoryItem)
+		item.value, item.expiresAt = value, expiresAt
+		s.items.MoveToFront(element)
+		return nil
+	}
+
+	s.index[key] = s.items.PushFront(&memoryItem{key: key, value: value, expiresAt: expiresAt})
+	for s.items.Len() > s.capacity {
+		s.remove(s.items.Back())
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Prune(_ context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for element := s.items.Front(); element != nil; {
+		next := element.Next()
+		if item := element.Value.(*memoryItem); !item.expiresAt.IsZero() && item.expiresAt.Before(before) {
+			s.remove(element)
+		}
+		element = next
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) remove(element *list.Element) {
+	s.items.Remove(element)
+	delete(s.index, element.Value.(*memoryItem).key)
+}
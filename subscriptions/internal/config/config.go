@@ -0,0 +1,264 @@
+/*
+Package config loads the settings of the service from the defaults, an optional
+config file (env, YAML or TOML), the environment variables and the command-line
+flags, every next source overriding the previous ones.
+*/
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"subscriptions/rest-service/internal/ratelimit"
+	"subscriptions/rest-service/internal/tracing"
+	"subscriptions/rest-service/pkg/logger"
+	"time"
+)
+
+// The settings are described by the struct tags: mapstructure is the name of the
+// environment variable, the config file key and, in lower case with dashes, the flag,
+// default is the default value, secret settings are redacted when printed and can be
+// read from the file named by the <NAME>_FILE setting.
+type Config struct {
+	App        App        `mapstructure:",squash"`
+	DB         DB         `mapstructure:",squash"`
+	Log        Log        `mapstructure:",squash"`
+	Tracing    Tracing    `mapstructure:",squash"`
+	Metrics    Metrics    `mapstructure:",squash"`
+	Auth       Auth       `mapstructure:",squash"`
+	Tenants    Tenants    `mapstructure:",squash"`
+	RateLimit  RateLimit  `mapstructure:",squash"`
+	Cache      Cache      `mapstructure:",squash"`
+	Users      Users      `mapstructure:",squash"`
+	Webhooks   Webhooks   `mapstructure:",squash"`
+	Outbox     Outbox     `mapstructure:",squash"`
+	Stream     Stream     `mapstructure:",squash"`
+	Reminders  Reminders  `mapstructure:",squash"`
+	Aggregates Aggregates `mapstructure:",squash"`
+}
+
+type App struct {
+	Host     string `mapstructure:"APP_HOST" default:"0.0.0.0" usage:"address the HTTP and gRPC servers listen on"`
+	Port     int    `mapstructure:"APP_PORT" default:"8080" usage:"HTTP port"`
+	GRPCPort int    `mapstructure:"GRPC_PORT" default:"9090" usage:"gRPC port"`
+	// TrustedProxies are the addresses or subnets whose X-Forwarded-For header is trusted.
+	TrustedProxies   []string      `mapstructure:"TRUSTED_PROXIES" usage:"proxies trusted to set X-Forwarded-For"`
+	ReadinessTimeout time.Duration `mapstructure:"READINESS_TIMEOUT" default:"2s" usage:"timeout of each readiness check"`
+	// ShutdownDelay is how long the instance reports it isn't ready before the servers stop.
+	ShutdownDelay time.Duration `mapstructure:"SHUTDOWN_DELAY" default:"0s" usage:"delay between failing readiness and stopping"`
+}
+
+type DB struct {
+	Host     string `mapstructure:"DB_HOST" default:"main_db" usage:"PostgreSQL host"`
+	Port     int    `mapstructure:"DB_PORT" default:"5432" usage:"PostgreSQL port"`
+	User     string `mapstructure:"POSTGRES_USER" usage:"PostgreSQL user"`
+	Password string `mapstructure:"POSTGRES_PASSWORD" secret:"true" usage:"PostgreSQL password"`
+	Name     string `mapstructure:"POSTGRES_DB" default:"test" usage:"database name"`
+	// SlowQueryThreshold is the duration after which the queries are logged as slow.
+	SlowQueryThreshold time.Duration `mapstructure:"DB_SLOW_QUERY_THRESHOLD" default:"200ms" usage:"slow query log threshold"`
+	// AutoMigrate makes serve migrate the database on start, otherwise the migrate command does it.
+	AutoMigrate bool `mapstructure:"DB_AUTO_MIGRATE" default:"true" usage:"migrate the database when the server starts"`
+	// Replicas are the read replicas the reports and lists are read from, "host" or "host:port".
+	Replicas []string `mapstructure:"DB_REPLICAS" usage:"read replicas, host or host:port"`
+	// ReadYourWritesWindow is how long a client reads from the primary after its write.
+	ReadYourWritesWindow time.Duration `mapstructure:"DB_READ_YOUR_WRITES_WINDOW" default:"5s" usage:"reads go to the primary for this long after a write, 0 disables"`
+	ReplicaCheckInterval time.Duration `mapstructure:"DB_REPLICA_CHECK_INTERVAL" default:"5s" usage:"read replica health check interval"`
+}
+
+type Log struct {
+	Format string `mapstructure:"LOG_FORMAT" default:"json" usage:"log format: json or console"`
+	Level  string `mapstructure:"LOG_LEVEL" default:"info" usage:"log level: debug, info, warn or error"`
+}
+
+type Tracing struct {
+	Exporter     string  `mapstructure:"TRACING_EXPORTER" default:"none" usage:"trace exporter: none, otlp or stdout"`
+	ServiceName  string  `mapstructure:"TRACING_SERVICE_NAME" default:"subscriptions" usage:"service name of the traces"`
+	OTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT" default:"localhost:4317" usage:"OTLP gRPC collector address"`
+	OTLPInsecure bool    `mapstructure:"TRACING_OTLP_INSECURE" default:"true" usage:"connect to the collector without TLS"`
+	SampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO" default:"1" usage:"share of the new traces recorded, from 0 to 1"`
+}
+
+type Metrics struct {
+	// Token protects /metrics when it isn't empty.
+	Token           string        `mapstructure:"METRICS_TOKEN" secret:"true" usage:"bearer token required to read /metrics"`
+	RefreshInterval time.Duration `mapstructure:"METRICS_REFRESH_INTERVAL" default:"1m" usage:"active subscriptions refresh interval"`
+}
+
+type Auth struct {
+	Enabled       bool   `mapstructure:"AUTH_ENABLED" default:"true" usage:"require authentication"`
+	Issuer        string `mapstructure:"AUTH_ISSUER" usage:"expected JWT issuer"`
+	Audience      string `mapstructure:"AUTH_AUDIENCE" usage:"expected JWT audience"`
+	HMACSecret    string `mapstructure:"AUTH_HMAC_SECRET" secret:"true" usage:"HS256 JWT secret"`
+	PublicKeyFile string `mapstructure:"AUTH_PUBLIC_KEY_FILE" usage:"PEM public key of the RS256/ES256 JWTs"`
+	JWKSFile      string `mapstructure:"AUTH_JWKS_FILE" usage:"JWKS of the JWT keys"`
+	AdminRole     string `mapstructure:"AUTH_ADMIN_ROLE" default:"admin" usage:"role granting every scope"`
+}
+
+type Tenants struct {
+	Default string `mapstructure:"TENANT_DEFAULT" default:"default" usage:"tenant of the requests without tenant"`
+}
+
+type RateLimit struct {
+	Enabled bool `mapstructure:"RATE_LIMIT_ENABLED" default:"true" usage:"enable rate limiting"`
+	// Store is "memory" or "postgres".
+	Store  string `mapstructure:"RATE_LIMIT_STORE" default:"memory" usage:"rate limit store: memory or postgres"`
+	IP     string `mapstructure:"RATE_LIMIT_IP" default:"600/1m" usage:"limit per IP address, empty disables it"`
+	Client string `mapstructure:"RATE_LIMIT_CLIENT" default:"1200/1m" usage:"limit per client, empty disables it"`
+	// Routes are the comma separated '<method> <route>=<limit>' or '<gRPC method>=<limit>' limits.
+	Routes        string        `mapstructure:"RATE_LIMIT_ROUTES" default:"GET /api/v1/subs/sub_sum=30/1m,GET /api/v1/subs/sub_sum/by_category=30/1m,GET /api/v1/subs/sub_sum/by_month=30/1m,GET /api/v1/users/:id/spending=30/1m,/subscriptions.v1.SubscriptionService/GetSubscriptionSum=30/1m" usage:"limits per client and route"`
+	PruneInterval time.Duration `mapstructure:"RATE_LIMIT_PRUNE_INTERVAL" default:"1m" usage:"unused counters prune interval"`
+}
+
+type Cache struct {
+	Enabled bool `mapstructure:"CACHE_ENABLED" default:"true" usage:"cache the subscription lists and sums"`
+	// Store is "memory" or "postgres", the memory caches of the instances broadcast their
+	// invalidations to each other.
+	Store         string        `mapstructure:"CACHE_STORE" default:"memory" usage:"cache store: memory or postgres"`
+	Size          int           `mapstructure:"CACHE_SIZE" default:"10000" usage:"entries kept by the memory store"`
+	TTL           time.Duration `mapstructure:"CACHE_TTL" default:"10m" usage:"lifetime of the cached entries"`
+	PruneInterval time.Duration `mapstructure:"CACHE_PRUNE_INTERVAL" default:"1m" usage:"expired entries prune interval"`
+}
+
+type Users struct {
+	AutoCreate bool `mapstructure:"USERS_AUTO_CREATE" default:"false" usage:"create unknown users of new subscriptions"`
+}
+
+type Webhooks struct {
+	Timeout      time.Duration `mapstructure:"WEBHOOKS_TIMEOUT" default:"10s" usage:"webhook request timeout"`
+	MaxAttempts  int           `mapstructure:"WEBHOOKS_MAX_ATTEMPTS" default:"8" usage:"delivery attempts before giving up"`
+	Workers      int           `mapstructure:"WEBHOOKS_WORKERS" default:"4" usage:"deliveries sent concurrently"`
+	PollInterval time.Duration `mapstructure:"WEBHOOKS_POLL_INTERVAL" default:"5s" usage:"due deliveries poll interval"`
+}
+
+type Outbox struct {
+	// Publishers are some of bus, notify, log and http.
+	Publishers   []string      `mapstructure:"OUTBOX_PUBLISHERS" default:"bus,notify" usage:"outbox publishers: bus, notify, log, http"`
+	HTTPURL      string        `mapstructure:"OUTBOX_HTTP_URL" usage:"URL the http publisher posts the events to"`
+	HTTPTimeout  time.Duration `mapstructure:"OUTBOX_HTTP_TIMEOUT" default:"10s" usage:"http publisher timeout"`
+	PollInterval time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL" default:"1s" usage:"outbox poll interval"`
+	Retention    time.Duration `mapstructure:"OUTBOX_RETENTION" default:"168h" usage:"how long the published events are kept"`
+	MaxAttempts  int           `mapstructure:"OUTBOX_MAX_ATTEMPTS" default:"10" usage:"publish attempts before the event is dead"`
+}
+
+type Stream struct {
+	ReplaySize        int           `mapstructure:"SSE_REPLAY_SIZE" default:"1000" usage:"events replayed to the reconnecting streams"`
+	HeartbeatInterval time.Duration `mapstructure:"SSE_HEARTBEAT_INTERVAL" default:"15s" usage:"stream heartbeat interval"`
+}
+
+type Reminders struct {
+	Window        time.Duration `mapstructure:"ENDING_SOON_WINDOW" default:"168h" usage:"how long before the end subscriptions are ending soon"`
+	CheckInterval time.Duration `mapstructure:"ENDING_SOON_CHECK_INTERVAL" default:"1h" usage:"ending soon check interval"`
+}
+
+type Aggregates struct {
+	// MonthsAhead is how many months after the current one the monthly charges are built for.
+	MonthsAhead    int           `mapstructure:"AGGREGATES_MONTHS_AHEAD" default:"24" usage:"months after the current one the monthly charges are built for"`
+	ExtendInterval time.Duration `mapstructure:"AGGREGATES_EXTEND_INTERVAL" default:"1h" usage:"monthly charges horizon check interval"`
+}
+
+// Validate returns every invalid setting joined in one error.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.DB.User != "" && c.DB.Password != "", "POSTGRES_USER and POSTGRES_PASSWORD are required")
+	for _, port := range []struct {
+		key   string
+		value int
+	}{{"APP_PORT", c.App.Port}, {"GRPC_PORT", c.App.GRPCPort}, {"DB_PORT", c.DB.Port}} {
+		check(port.value > 0 && port.value < 1<<16, "%s must be a port number, got %d", port.key, port.value)
+	}
+
+	for _, interval := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"DB_REPLICA_CHECK_INTERVAL", c.DB.ReplicaCheckInterval},
+		{"METRICS_REFRESH_INTERVAL", c.Metrics.RefreshInterval},
+		{"RATE_LIMIT_PRUNE_INTERVAL", c.RateLimit.PruneInterval},
+		{"CACHE_TTL", c.Cache.TTL},
+		{"CACHE_PRUNE_INTERVAL", c.Cache.PruneInterval},
+		{"WEBHOOKS_POLL_INTERVAL", c.Webhooks.PollInterval},
+		{"OUTBOX_POLL_INTERVAL", c.Outbox.PollInterval},
+		{"SSE_HEARTBEAT_INTERVAL", c.Stream.HeartbeatInterval},
+		{"ENDING_SOON_CHECK_INTERVAL", c.Reminders.CheckInterval},
+		{"AGGREGATES_EXTEND_INTERVAL", c.Aggregates.ExtendInterval},
+	} {
+		check(interval.value > 0, "%s must be positive, got %s", interval.key, interval.value)
+	}
+	check(c.App.ShutdownDelay >= 0, "SHUTDOWN_DELAY can't be negative")
+	check(c.DB.ReadYourWritesWindow >= 0, "DB_READ_YOUR_WRITES_WINDOW can't be negative")
+	check(c.Webhooks.MaxAttempts > 0, "WEBHOOKS_MAX_ATTEMPTS must be positive")
+	check(c.Webhooks.Workers > 0, "WEBHOOKS_WORKERS must be positive")
+	check(c.Outbox.MaxAttempts > 0, "OUTBOX_MAX_ATTEMPTS must be positive")
+	check(c.Stream.ReplaySize >= 0, "SSE_REPLAY_SIZE can't be negative")
+	check(c.Aggregates.MonthsAhead >= 0, "AGGREGATES_MONTHS_AHEAD can't be negative")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "invalid LOG_LEVEL %q", c.Log.Level)
+	check(c.Log.Format == logger.FormatJSON || c.Log.Format == logger.FormatConsole, "unknown LOG_FORMAT %q", c.Log.Format)
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout:
+	default:
+		check(false, "unknown TRACING_EXPORTER %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1")
+
+	check(c.RateLimit.Store == "memory" || c.RateLimit.Store == "postgres", "unknown RATE_LIMIT_STORE %q", c.RateLimit.Store)
+	if _, err := c.RateLimit.IPLimit(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid RATE_LIMIT_IP: %w", err))
+	}
+	if _, err := c.RateLimit.ClientLimit(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid RATE_LIMIT_CLIENT: %w", err))
+	}
+	if _, err := ratelimit.ParseRouteLimits(c.RateLimit.Routes); err != nil {
+		errs = append(errs, fmt.Errorf("invalid RATE_LIMIT_ROUTES: %w", err))
+	}
+
+	// both keys would be the key of the tokens without kid
+	check(c.Auth.HMACSecret == "" || c.Auth.PublicKeyFile == "",
+		"AUTH_HMAC_SECRET and AUTH_PUBLIC_KEY_FILE can't be set together, put the keys with their kids in AUTH_JWKS_FILE")
+
+	check(c.Cache.Store == "memory" || c.Cache.Store == "postgres", "unknown CACHE_STORE %q", c.Cache.Store)
+	check(c.Cache.Size > 0, "CACHE_SIZE must be positive")
+
+	for _, publisher := range c.Outbox.Publishers {
+		switch publisher {
+		case "bus", "notify", "log":
+		case "http":
+			check(c.Outbox.HTTPURL != "", "OUTBOX_HTTP_URL is required for the http publisher")
+		default:
+			check(false, "unknown outbox publisher %q", publisher)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IPLimit is the parsed RATE_LIMIT_IP, nil if it is empty.
+func (r RateLimit) IPLimit() (*ratelimit.Limit, error) {
+	return parseLimit(r.IP)
+}
+
+// ClientLimit is the parsed RATE_LIMIT_CLIENT, nil if it is empty.
+func (r RateLimit) ClientLimit() (*ratelimit.Limit, error) {
+	return parseLimit(r.Client)
+}
+
+func parseLimit(value string) (*ratelimit.Limit, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	limit, err := ratelimit.ParseLimit(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &limit, nil
+}
@@ -0,0 +1,339 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"subscriptions/rest-service/internal/config"
+	"subscriptions/rest-service/pkg/logger"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// load loads the config with the command-line args.
+func load(t *testing.T, args ...string) (config.Config, error) {
+	t.Helper()
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	config.AddFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+
+	return config.Load(flags)
+}
+
+// writeFile writes the file to the temporary directory of the test and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// chdir makes the empty temporary directory the working directory, so no ./.env is read.
+func chdir(t *testing.T) {
+	t.Helper()
+
+	t.Chdir(t.TempDir())
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		// file is the content of the env config file, none is given if it is empty
+		file string
+		env  map[string]string
+		args []string
+		host string
+		port int
+	}{
+		{
+			name: "defaults",
+			host: "main_db",
+			port: 5432,
+		},
+		{
+			name: "file overrides defaults",
+			file: "DB_HOST=file-host\nDB_PORT=5433\n",
+			host: "file-host",
+			port: 5433,
+		},
+		{
+			name: "env overrides file",
+			file: "DB_HOST=file-host\nDB_PORT=5433\n",
+			env:  map[string]string{"DB_HOST": "env-host"},
+			host: "env-host",
+			port: 5433,
+		},
+		{
+			name: "flags override env",
+			file: "DB_HOST=file-host\nDB_PORT=5433\n",
+			env:  map[string]string{"DB_HOST": "env-host", "DB_PORT": "5434"},
+			args: []string{"--db-host", "flag-host"},
+			host: "flag-host",
+			port: 5434,
+		},
+		{
+			name: "flags override defaults",
+			args: []string{"--db-port", "6432"},
+			host: "main_db",
+			port: 6432,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t)
+			if tt.file != "" {
+				t.Setenv("CONFIG_FILE", writeFile(t, "config.env", tt.file))
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := load(t, tt.args...)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if cfg.DB.Host != tt.host || cfg.DB.Port != tt.port {
+				t.Errorf("expected %s:%d, got %s:%d", tt.host, tt.port, cfg.DB.Host, cfg.DB.Port)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		// flag gives the file with --config instead of CONFIG_FILE
+		flag bool
+	}{
+		{name: "env", file: "config.env", content: "DB_HOST=db\nWEBHOOKS_TIMEOUT=30s\n"},
+		{name: "yaml", file: "config.yaml", content: "DB_HOST: db\nWEBHOOKS_TIMEOUT: 30s\n"},
+		{name: "toml", file: "config.toml", content: "DB_HOST = \"db\"\nWEBHOOKS_TIMEOUT = \"30s\"\n"},
+		{name: "flag", file: "config.env", content: "DB_HOST=db\nWEBHOOKS_TIMEOUT=30s\n", flag: true},
+		{name: "default .env", file: ".env", content: "DB_HOST=db\nWEBHOOKS_TIMEOUT=30s\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t)
+
+			var args []string
+			switch {
+			case tt.file == ".env":
+				if err := os.WriteFile(tt.file, []byte(tt.content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			case tt.flag:
+				args = []string{"--config", writeFile(t, tt.file, tt.content)}
+			default:
+				t.Setenv("CONFIG_FILE", writeFile(t, tt.file, tt.content))
+			}
+
+			cfg, err := load(t, args...)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if cfg.DB.Host != "db" || cfg.Webhooks.Timeout != 30*time.Second {
+				t.Errorf("expected the settings of the file, got DB_HOST %q and WEBHOOKS_TIMEOUT %v", cfg.DB.Host, cfg.Webhooks.Timeout)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		chdir(t)
+		t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.env"))
+
+		if _, err := load(t); err == nil {
+			t.Error("expected error for the missing config file")
+		}
+	})
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		// env and args may name the file "$SECRET", the path of a file with "s3cret\n"
+		env      map[string]string
+		args     []string
+		password string
+		err      string
+	}{
+		{
+			name:     "value",
+			env:      map[string]string{"POSTGRES_PASSWORD": "plain"},
+			password: "plain",
+		},
+		{
+			name:     "file from env",
+			env:      map[string]string{"POSTGRES_PASSWORD_FILE": "$SECRET"},
+			password: "s3cret",
+		},
+		{
+			name:     "file from flag",
+			args:     []string{"--postgres-password-file", "$SECRET"},
+			password: "s3cret",
+		},
+		{
+			name: "value and file",
+			env:  map[string]string{"POSTGRES_PASSWORD": "plain", "POSTGRES_PASSWORD_FILE": "$SECRET"},
+			err:  "both POSTGRES_PASSWORD and POSTGRES_PASSWORD_FILE are set",
+		},
+		{
+			name: "missing file",
+			env:  map[string]string{"POSTGRES_PASSWORD_FILE": "/nonexistent/secret"},
+			err:  "POSTGRES_PASSWORD_FILE: open",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t)
+			secret := writeFile(t, "secret", "s3cret\n")
+			for key, value := range tt.env {
+				t.Setenv(key, strings.ReplaceAll(value, "$SECRET", secret))
+			}
+			args := make([]string, len(tt.args))
+			for i, arg := range tt.args {
+				args[i] = strings.ReplaceAll(arg, "$SECRET", secret)
+			}
+
+			cfg, err := load(t, args...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if cfg.DB.Password != tt.password {
+				t.Errorf("expected password %q, got %q", tt.password, cfg.DB.Password)
+			}
+		})
+	}
+}
+
+func TestLoadLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		proxies []string
+	}{
+		{name: "default", proxies: []string{}},
+		{name: "env", env: map[string]string{"TRUSTED_PROXIES": " 10.0.0.1, 10.1.0.0/16,,"}, proxies: []string{"10.0.0.1", "10.1.0.0/16"}},
+		{name: "flag", args: []string{"--trusted-proxies", "10.0.0.1,10.0.0.2"}, proxies: []string{"10.0.0.1", "10.0.0.2"}},
+		{name: "repeated flag", args: []string{"--trusted-proxies", "10.0.0.1", "--trusted-proxies", "10.0.0.2"}, proxies: []string{"10.0.0.1", "10.0.0.2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := load(t, tt.args...)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if !slices.Equal(cfg.App.TrustedProxies, tt.proxies) {
+				t.Errorf("expected proxies %q, got %q", tt.proxies, cfg.App.TrustedProxies)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		// err is a part of the error, the config is valid if it is empty
+		err string
+	}{
+		{name: "valid"},
+		{name: "no credentials", env: map[string]string{"POSTGRES_PASSWORD": ""}, err: "POSTGRES_USER and POSTGRES_PASSWORD are required"},
+		{name: "invalid port", env: map[string]string{"APP_PORT": "70000"}, err: "APP_PORT must be a port number"},
+		{name: "zero interval", env: map[string]string{"OUTBOX_POLL_INTERVAL": "0s"}, err: "OUTBOX_POLL_INTERVAL must be positive"},
+		{name: "no webhook workers", env: map[string]string{"WEBHOOKS_WORKERS": "0"}, err: "WEBHOOKS_WORKERS must be positive"},
+		{name: "no outbox attempts", env: map[string]string{"OUTBOX_MAX_ATTEMPTS": "0"}, err: "OUTBOX_MAX_ATTEMPTS must be positive"},
+		{name: "unknown rate limit store", env: map[string]string{"RATE_LIMIT_STORE": "redis"}, err: `unknown RATE_LIMIT_STORE "redis"`},
+		{name: "invalid rate limit", env: map[string]string{"RATE_LIMIT_IP": "many"}, err: "invalid RATE_LIMIT_IP"},
+		{
+			name: "HMAC secret and public key",
+			env:  map[string]string{"AUTH_HMAC_SECRET": "secret", "AUTH_PUBLIC_KEY_FILE": "key.pem"},
+			err:  "AUTH_HMAC_SECRET and AUTH_PUBLIC_KEY_FILE can't be set together",
+		},
+		{name: "http publisher without URL", env: map[string]string{"OUTBOX_PUBLISHERS": "bus,http"}, err: "OUTBOX_HTTP_URL is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t)
+			t.Setenv("POSTGRES_USER", "postgres")
+			t.Setenv("POSTGRES_PASSWORD", "postgres")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := load(t)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+
+			err = cfg.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("expected valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	chdir(t)
+	t.Setenv("POSTGRES_PASSWORD", "s3cret")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.1,10.0.0.2")
+
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	var out strings.Builder
+	if err := cfg.Print(&out); err != nil {
+		t.Fatalf("print: %v", err)
+	}
+
+	lines := strings.Split(out.String(), "\n")
+	for _, expected := range []string{
+		"POSTGRES_PASSWORD=" + logger.Redacted,
+		"METRICS_TOKEN=",
+		"TRUSTED_PROXIES=10.0.0.1,10.0.0.2",
+	} {
+		if !slices.Contains(lines, expected) {
+			t.Errorf("expected line %q in\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "s3cret") {
+		t.Error("expected the secret redacted")
+	}
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"subscriptions/rest-service/pkg/logger"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// FileFlag is the flag naming the config file, the CONFIG_FILE variable is used without it.
+const FileFlag = "config"
+
+// defaultFile is read when no config file is given, if it exists.
+const defaultFile = ".env"
+
+// fileSuffix is the suffix of the settings holding the path of the file with a secret.
+const fileSuffix = "_FILE"
+
+// setting is a field of Config described by its tags.
+type setting struct {
+	key    string
+	flag   string
+	value  string
+	usage  string
+	secret bool
+	index  []int
+	kind   reflect.Type
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// settings returns every setting of Config in the order of the fields.
+func settings() []setting {
+	var result []setting
+
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			fieldIndex := append(append([]int(nil), index...), i)
+
+			key := field.Tag.Get("mapstructure")
+			if key == ",squash" {
+				walk(field.Type, fieldIndex)
+				continue
+			}
+
+			result = append(result, setting{
+				key:    key,
+				flag:   flagName(key),
+				value:  field.Tag.Get("default"),
+				usage:  field.Tag.Get("usage"),
+				secret: field.Tag.Get("secret") == "true",
+				index:  fieldIndex,
+				kind:   field.Type,
+			})
+		}
+	}
+	walk(reflect.TypeFor[Config](), nil)
+
+	return result
+}
+
+// flagName returns the flag of the setting, e.g. db-host for DB_HOST.
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// AddFlags adds the --config flag and a flag for every setting, e.g. --db-host for DB_HOST.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.String(FileFlag, "", "config file (env, YAML or TOML), ./.env is read if it exists (env CONFIG_FILE)")
+
+	for _, s := range settings() {
+		usage := fmt.Sprintf("%s (env %s)", s.usage, s.key)
+
+		switch {
+		case s.kind == durationType:
+			value, _ := time.ParseDuration(s.value)
+			flags.Duration(s.flag, value, usage)
+		case s.kind.Kind() == reflect.Int:
+			value, _ := strconv.Atoi(s.value)
+			flags.Int(s.flag, value, usage)
+		case s.kind.Kind() == reflect.Bool:
+			value, _ := strconv.ParseBool(s.value)
+			flags.Bool(s.flag, value, usage)
+		case s.kind.Kind() == reflect.Float64:
+			value, _ := strconv.ParseFloat(s.value, 64)
+			flags.Float64(s.flag, value, usage)
+		case s.kind.Kind() == reflect.Slice:
+			flags.StringSlice(s.flag, splitList(s.value), usage)
+		default:
+			flags.String(s.flag, s.value, usage)
+		}
+
+		if s.secret {
+			flags.String(s.flag+flagName(fileSuffix), "", fmt.Sprintf("file with the %s (env %s%s)", s.usage, s.key, fileSuffix))
+		}
+	}
+}
+
+// Load returns the config from the defaults, the config file, the environment variables
+// and the flags added by AddFlags, in the increasing order of precedence. It isn't validated,
+// so that an invalid config can still be printed.
+func Load(flags *pflag.FlagSet) (Config, error) {
+	v := viper.New()
+
+	for _, s := range settings() {
+		v.SetDefault(s.key, s.value)
+		if err := v.BindPFlag(s.key, flags.Lookup(s.flag)); err != nil {
+			return Config{}, err
+		}
+
+		if s.secret {
+			v.SetDefault(s.key+fileSuffix, "")
+			if err := v.BindPFlag(s.key+fileSuffix, flags.Lookup(s.flag+flagName(fileSuffix))); err != nil {
+				return Config{}, err
+			}
+		}
+	}
+	v.AutomaticEnv()
+
+	if err := readFile(v, flags); err != nil {
+		return Config{}, err
+	}
+
+	var config Config
+	if err := v.Unmarshal(&config); err != nil {
+		return Config{}, fmt.Errorf("decode config: %w", err)
+	}
+
+	if err := readSecrets(v, &config); err != nil {
+		return Config{}, err
+	}
+
+	config.App.TrustedProxies = splitList(strings.Join(config.App.TrustedProxies, ","))
+	config.Outbox.Publishers = splitList(strings.Join(config.Outbox.Publishers, ","))
+	config.DB.Replicas = splitList(strings.Join(config.DB.Replicas, ","))
+
+	return config, nil
+}
+
+// readFile reads the config file given by the flag or CONFIG_FILE, or the optional ./.env.
+func readFile(v *viper.Viper, flags *pflag.FlagSet) error {
+	path, _ := flags.GetString(FileFlag)
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	if path == "" {
+		if _, err := os.Stat(defaultFile); err != nil {
+			return nil
+		}
+		path = defaultFile
+	}
+
+	// the type is taken from the extension, ".env" and "config.env" are env files
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("read config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// readSecrets sets the secrets given as <NAME>_FILE to the contents of the files.
+func readSecrets(v *viper.Viper, config *Config) error {
+	value := reflect.ValueOf(config).Elem()
+
+	for _, s := range settings() {
+		if !s.secret {
+			continue
+		}
+
+		path := v.GetString(s.key + fileSuffix)
+		if path == "" {
+			continue
+		}
+
+		field := value.FieldByIndex(s.index)
+		if field.String() != "" {
+			return fmt.Errorf("both %s and %s%s are set", s.key, s.key, fileSuffix)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s%s: %w", s.key, fileSuffix, err)
+		}
+		field.SetString(strings.TrimRight(string(data), "\r\n"))
+	}
+
+	return nil
+}
+
+// Print writes the settings in the env format, the values of the secrets are redacted.
+func (c Config) Print(w io.Writer) error {
+	value := reflect.ValueOf(c)
+
+	for _, s := range settings() {
+		field := value.FieldByIndex(s.index)
+
+		var text string
+		switch {
+		case s.secret && field.String() != "":
+			text = logger.Redacted
+		case s.kind.Kind() == reflect.Slice:
+			text = strings.Join(field.Interface().([]string), ",")
+		default:
+			text = fmt.Sprint(field.Interface())
+		}
+
+		if _, err := fmt.Fprintf(w, "%s=%s\n", s.key, text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
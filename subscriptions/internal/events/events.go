@@ -0,0 +1,126 @@
+/*
+Package events defines domain events of the service and the in-process bus
+the outbox relay delivers them to.
+*/
+package events
+
+import (
+	"context"
+	"log/slog"
+	"subscriptions/rest-service/internal/models"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	SubscriptionCreated    Type = "subscription.created"
+	SubscriptionUpdated    Type = "subscription.updated"
+	SubscriptionDeleted    Type = "subscription.deleted"
+	SubscriptionEndingSoon Type = "subscription.ending_soon"
+	BudgetExceeded         Type = "budget.exceeded"
+)
+
+// Types lists all event types that can be published.
+var Types = []Type{
+	SubscriptionCreated,
+	SubscriptionUpdated,
+	SubscriptionDeleted,
+	SubscriptionEndingSoon,
+	BudgetExceeded,
+}
+
+type Event struct {
+	// ID is the position of the event in the outbox, it grows with every event.
+	ID uint `json:"id"`
+	// Sequence is the position of the event in the subscription stream, assigned when
+	// the event is published. It is zero for the events which aren't streamed.
+	Sequence uint64 `json:"sequence,omitempty"`
+	// SubscriptionID is the subscription the event happened to.
+	SubscriptionID uint `json:"subscription_id"`
+	// TenantID is the tenant the subscription belongs to.
+	TenantID   string    `json:"tenant_id"`
+	Type       Type      `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// SubscriptionPayload is the data of subscription events.
+type SubscriptionPayload struct {
+	ID          uint       `json:"id"`
+	ServiceName string     `json:"service_name"`
+	Price       uint       `json:"price"`
+	UserID      uuid.UUID  `json:"user_id"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     *time.Time `json:"end_date,omitempty"`
+	CategoryID  *uint      `json:"category_id,omitempty"`
+	Tags        []string   `json:"tags"`
+}
+
+func NewSubscriptionPayload(record models.Subscription) SubscriptionPayload {
+	tags := make([]string, len(record.Tags))
+	for i, tag := range record.Tags {
+		tags[i] = tag.Name
+	}
+
+	return SubscriptionPayload{
+		ID:          record.ID,
+		ServiceName: record.ServiceName,
+		Price:       record.Price,
+		UserID:      record.UserID,
+		StartDate:   record.StartDate,
+		EndDate:     record.EndDate,
+		CategoryID:  record.CategoryID,
+		Tags:        tags,
+	}
+}
+
+// Handler receives published events. Handlers are called synchronously by
+// the publisher, so long running work must be moved to a goroutine. A handler
+// failing to process the event returns an error, so the event is published again.
+type Handler func(Event) error
+
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish passes the event to all handlers and returns the first error of them.
+func (b *Bus) Publish(event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var firstErr error
+	for _, handler := range b.handlers {
+		if err := handler(event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// LogHandler writes every event to the service log.
+func LogHandler(event Event) error {
+	level := slog.LevelInfo
+	if event.Type == BudgetExceeded {
+		level = slog.LevelWarn
+	}
+
+	slog.Log(context.Background(), level, "event", "type", event.Type, "tenant_id", event.TenantID, "data", event.Data)
+	return nil
+}
@@ -0,0 +1,43 @@
+package events_test
+
+import (
+	"errors"
+	"subscriptions/rest-service/internal/events"
+	"testing"
+)
+
+func TestBusPublish(t *testing.T) {
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+
+	tests := []struct {
+		name     string
+		handlers []error
+		expected error
+	}{
+		{name: "no handlers"},
+		{name: "all handled", handlers: []error{nil, nil}},
+		{name: "one failed", handlers: []error{nil, errFirst, nil}, expected: errFirst},
+		{name: "first error returned", handlers: []error{errFirst, errSecond}, expected: errFirst},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := events.NewBus()
+
+			called := 0
+			for _, err := range tt.handlers {
+				bus.Subscribe(func(events.Event) error {
+					called++
+					return err
+				})
+			}
+
+			if err := bus.Publish(events.Event{ID: 1, Type: events.SubscriptionCreated}); err != tt.expected {
+				t.Errorf("expected error %v, got %v", tt.expected, err)
+			}
+			if called != len(tt.handlers) {
+				t.Errorf("expected %d handlers called, got %d", len(tt.handlers), called)
+			}
+		})
+	}
+}
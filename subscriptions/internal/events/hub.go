@@ -0,0 +1,123 @@
+package events
+
+import "sync"
+
+// subscriberBuffer is how many events a stream subscriber may lag behind
+// before it is disconnected.
+const subscriberBuffer = 64
+
+// Hub fans events out to stream subscribers in the order of their sequences and keeps
+// the last events so subscribers can resume after a reconnect.
+type Hub struct {
+	mu     sync.Mutex
+	buffer []Event
+	// start is the position of the oldest event in buffer once it is full.
+	start int
+	size  int
+	// last is the sequence of the last published event.
+	last        uint64
+	subscribers map[chan Event]struct{}
+	closed      bool
+}
+
+func NewHub(size int) *Hub {
+	return &Hub{
+		size:        max(size, 1),
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish stores the event in the replay buffer and passes it to the subscribers.
+// Events with a sequence not greater than the last published one are skipped, so
+// redelivered events are sent once and never out of order. Subscribers that can't
+// keep up are disconnected and have to resume.
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed || event.Sequence <= h.last {
+		return
+	}
+
+	if len(h.buffer) < h.size {
+		h.buffer = append(h.buffer, event)
+	} else {
+		h.buffer[h.start] = event
+		h.start = (h.start + 1) % h.size
+	}
+	h.last = event.Sequence
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// LastSequence returns the sequence of the last published event, zero if there are none.
+func (h *Hub) LastSequence() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.last
+}
+
+// Subscribe returns the buffered events with sequences greater than lastSequence and
+// the channel of the next events. A nil lastSequence replays nothing. The channel is closed when
+// the subscriber falls behind or the hub is closed; cancel must be called once
+// the subscriber is done.
+func (h *Hub) Subscribe(lastSequence *uint64) (replay []Event, stream <-chan Event, cancel func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	if h.closed {
+		close(ch)
+		return nil, ch, func() {}
+	}
+	h.subscribers[ch] = struct{}{}
+
+	if lastSequence != nil {
+		replay = h.replayAfter(*lastSequence)
+	}
+
+	cancel = func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return replay, ch, cancel
+}
+
+// replayAfter returns the buffered events with sequences greater than seq, oldest first.
+func (h *Hub) replayAfter(seq uint64) []Event {
+	replay := make([]Event, 0, len(h.buffer))
+	for i := range h.buffer {
+		event := h.buffer[(h.start+i)%len(h.buffer)]
+		if event.Sequence > seq {
+			replay = append(replay, event)
+		}
+	}
+
+	return replay
+}
+
+// Close disconnects all subscribers and rejects new ones.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for ch := range h.subscribers {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,124 @@
+package events_test
+
+import (
+	"slices"
+	"subscriptions/rest-service/internal/events"
+	"testing"
+)
+
+// publishSequences publishes events with the sequences, their IDs are in the reverse
+// order, so the tests fail if the hub orders the events by IDs.
+func publishSequences(hub *events.Hub, seqs ...uint64) {
+	for _, seq := range seqs {
+		hub.Publish(events.Event{ID: uint(1000 - seq), Sequence: seq, Type: events.SubscriptionUpdated})
+	}
+}
+
+func sequences(published []events.Event) []uint64 {
+	seqs := make([]uint64, len(published))
+	for i, event := range published {
+		seqs[i] = event.Sequence
+	}
+	return seqs
+}
+
+func TestHubReplay(t *testing.T) {
+	seq := func(seq uint64) *uint64 { return &seq }
+
+	tests := []struct {
+		name      string
+		size      int
+		published []uint64
+		// last is the sequence the subscriber resumes after, replayed are the expected
+		// sequences of the replayed events
+		last     *uint64
+		replayed []uint64
+		lastSeq  uint64
+	}{
+		{name: "new subscriber", size: 10, published: []uint64{1, 2, 3}, lastSeq: 3},
+		{name: "resume", size: 10, published: []uint64{1, 2, 3}, last: seq(1), replayed: []uint64{2, 3}, lastSeq: 3},
+		{name: "up to date", size: 10, published: []uint64{1, 2, 3}, last: seq(3), replayed: []uint64{}, lastSeq: 3},
+		{name: "redelivered events skipped", size: 10, published: []uint64{1, 2, 2, 1, 3}, last: seq(0), replayed: []uint64{1, 2, 3}, lastSeq: 3},
+		{name: "late events skipped", size: 10, published: []uint64{2, 5, 4, 6}, last: seq(0), replayed: []uint64{2, 5, 6}, lastSeq: 6},
+		{name: "gaps in the sequences", size: 10, published: []uint64{3, 7, 9}, last: seq(5), replayed: []uint64{7, 9}, lastSeq: 9},
+		{name: "oldest events dropped", size: 2, published: []uint64{1, 2, 3, 4}, last: seq(1), replayed: []uint64{3, 4}, lastSeq: 4},
+		{name: "nothing published", size: 10, last: seq(4), replayed: []uint64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := events.NewHub(tt.size)
+			publishSequences(hub, tt.published...)
+
+			replay, _, cancel := hub.Subscribe(tt.last)
+			defer cancel()
+
+			if got := sequences(replay); !slices.Equal(got, tt.replayed) {
+				t.Errorf("expected replayed sequences %v, got %v", tt.replayed, got)
+			}
+			if hub.LastSequence() != tt.lastSeq {
+				t.Errorf("expected last sequence %d, got %d", tt.lastSeq, hub.LastSequence())
+			}
+		})
+	}
+}
+
+func TestHubSubscribers(t *testing.T) {
+	t.Run("events passed in order", func(t *testing.T) {
+		hub := events.NewHub(10)
+
+		_, stream, cancel := hub.Subscribe(nil)
+		defer cancel()
+
+		publishSequences(hub, 1, 3, 2, 3, 4)
+
+		var got []uint64
+		for range 3 {
+			got = append(got, (<-stream).Sequence)
+		}
+		if !slices.Equal(got, []uint64{1, 3, 4}) {
+			t.Errorf("expected sequences %v, got %v", []uint64{1, 3, 4}, got)
+		}
+		if len(stream) != 0 {
+			t.Errorf("expected no more events, got %d", len(stream))
+		}
+	})
+
+	t.Run("slow subscriber disconnected", func(t *testing.T) {
+		hub := events.NewHub(10)
+
+		_, stream, cancel := hub.Subscribe(nil)
+		defer cancel()
+
+		// the subscriber doesn't read, the events after its buffer close the stream
+		for seq := uint64(1); seq <= 100; seq++ {
+			publishSequences(hub, seq)
+		}
+
+		received := 0
+		for range stream {
+			received++
+		}
+		if received == 0 || received >= 100 {
+			t.Errorf("expected the stream closed after the buffered events, got %d events", received)
+		}
+	})
+
+	t.Run("closed hub", func(t *testing.T) {
+		hub := events.NewHub(10)
+
+		_, stream, cancel := hub.Subscribe(nil)
+		defer cancel()
+
+		hub.Close()
+		if _, ok := <-stream; ok {
+			t.Error("expected the stream closed with the hub")
+		}
+
+		_, stream, cancel = hub.Subscribe(nil)
+		defer cancel()
+		if _, ok := <-stream; ok {
+			t.Error("expected the stream of a new subscriber closed")
+		}
+	})
+}
@@ -0,0 +1,168 @@
+/*
+Package health reports the liveness and readiness of the instance. The instance
+is ready once it has started and until it begins to shut down, while every
+dependency check passes and every background worker is healthy.
+*/
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+var (
+	errStarting = errors.New("instance is starting")
+	errStopping = errors.New("instance is shutting down")
+)
+
+// Check is the result of a single check.
+type Check struct {
+	Status string `json:"status" example:"ok"`
+	Error  string `json:"error,omitempty"`
+	// Duration is how long the check took, in milliseconds.
+	Duration float64 `json:"duration_ms" example:"1.5"`
+}
+
+type Report struct {
+	Status string           `json:"status" example:"ok"`
+	Checks map[string]Check `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+type Checker struct {
+	timeout time.Duration
+
+	started  atomic.Bool
+	stopping atomic.Bool
+
+	mu      sync.Mutex
+	checks  []namedCheck
+	workers map[string]*Worker
+}
+
+// NewChecker returns the checker running every check with the timeout.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		workers: make(map[string]*Worker),
+	}
+}
+
+// AddCheck adds the dependency check, it must return before its context is done.
+func (c *Checker) AddCheck(name string, check func(ctx context.Context) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Worker registers the background worker. The worker is unhealthy until its first beat,
+// after a failure and when it hasn't beaten for maxAge, zero maxAge disables the last check.
+func (c *Checker) Worker(name string, maxAge time.Duration) *Worker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	worker := &Worker{maxAge: maxAge}
+	c.workers[name] = worker
+	return worker
+}
+
+// SetStarted marks the instance started, it is not ready before.
+func (c *Checker) SetStarted() {
+	c.started.Store(true)
+}
+
+// SetStopping marks the instance shutting down, it is not ready after.
+func (c *Checker) SetStopping() {
+	c.stopping.Store(true)
+}
+
+// Live reports that the process serves requests, restarting it wouldn't help
+// with the failures of the dependencies.
+func (c *Checker) Live() Report {
+	return Report{Status: StatusOK}
+}
+
+// Ready runs the checks concurrently and reports whether the instance may receive traffic.
+func (c *Checker) Ready(ctx context.Context) (Report, bool) {
+	c.mu.Lock()
+	checks := append([]namedCheck{}, c.checks...)
+	workers := make(map[string]*Worker, len(c.workers))
+	for name, worker := range c.workers {
+		workers[name] = worker
+	}
+	c.mu.Unlock()
+
+	results := make(map[string]Check, len(checks)+len(workers)+1)
+
+	var lifecycle error
+	switch {
+	case c.stopping.Load():
+		lifecycle = errStopping
+	case !c.started.Load():
+		lifecycle = errStarting
+	}
+	results["lifecycle"] = toCheck(lifecycle, 0)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.check(checkCtx)
+			if err == nil && checkCtx.Err() != nil {
+				err = checkCtx.Err()
+			}
+
+			mu.Lock()
+			results[check.name] = toCheck(err, time.Since(start))
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	now := time.Now()
+	for name, worker := range workers {
+		results["worker:"+name] = toCheck(worker.check(now), 0)
+	}
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	return report, report.Status == StatusOK
+}
+
+func toCheck(err error, duration time.Duration) Check {
+	check := Check{
+		Status:   StatusOK,
+		Duration: float64(duration.Microseconds()) / 1000,
+	}
+	if err != nil {
+		check.Status = StatusFail
+		check.Error = err.Error()
+	}
+
+	return check
+}
@@ -0,0 +1,176 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"subscriptions/rest-service/internal/health"
+	"testing"
+	"time"
+)
+
+var errDown = errors.New("connection refused")
+
+func TestReady(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errDown }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+
+	tests := []struct {
+		name      string
+		started   bool
+		stopping  bool
+		checks    map[string]func(context.Context) error
+		wantReady bool
+		wantFail  []string
+	}{
+		{
+			name:      "started",
+			started:   true,
+			checks:    map[string]func(context.Context) error{"database": ok},
+			wantReady: true,
+		},
+		{
+			name:     "starting",
+			checks:   map[string]func(context.Context) error{"database": ok},
+			wantFail: []string{"lifecycle"},
+		},
+		{
+			name:     "shutting down",
+			started:  true,
+			stopping: true,
+			wantFail: []string{"lifecycle"},
+		},
+		{
+			name:     "failing dependency",
+			started:  true,
+			checks:   map[string]func(context.Context) error{"database": ok, "cache": failing},
+			wantFail: []string{"cache"},
+		},
+		{
+			name:     "check past timeout",
+			started:  true,
+			checks:   map[string]func(context.Context) error{"database": slow},
+			wantFail: []string{"database"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := health.NewChecker(10 * time.Millisecond)
+			for name, check := range tt.checks {
+				checker.AddCheck(name, check)
+			}
+			if tt.started {
+				checker.SetStarted()
+			}
+			if tt.stopping {
+				checker.SetStopping()
+			}
+
+			report, ready := checker.Ready(context.Background())
+			if ready != tt.wantReady {
+				t.Errorf("Ready() = %v, want %v", ready, tt.wantReady)
+			}
+
+			wantStatus := health.StatusOK
+			if !tt.wantReady {
+				wantStatus = health.StatusFail
+			}
+			if report.Status != wantStatus {
+				t.Errorf("report status = %q, want %q", report.Status, wantStatus)
+			}
+
+			for _, name := range tt.wantFail {
+				if check := report.Checks[name]; check.Status != health.StatusFail || check.Error == "" {
+					t.Errorf("check %s = %+v, want failure", name, check)
+				}
+			}
+			if len(report.Checks) != len(tt.checks)+1 {
+				t.Errorf("report has %d checks, want %d", len(report.Checks), len(tt.checks)+1)
+			}
+		})
+	}
+}
+
+func TestWorker(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxAge    time.Duration
+		report    func(ctx context.Context)
+		wantReady bool
+	}{
+		{
+			name:   "no beat",
+			maxAge: time.Minute,
+			report: func(context.Context) {},
+		},
+		{
+			name:      "beat",
+			maxAge:    time.Minute,
+			report:    health.Beat,
+			wantReady: true,
+		},
+		{
+			name:   "failure after beat",
+			maxAge: time.Minute,
+			report: func(ctx context.Context) {
+				health.Beat(ctx)
+				health.Fail(ctx, errDown)
+			},
+		},
+		{
+			name:   "beat after failure",
+			maxAge: time.Minute,
+			report: func(ctx context.Context) {
+				health.Update(ctx, errDown)
+				health.Update(ctx, nil)
+			},
+			wantReady: true,
+		},
+		{
+			name:   "stale beat",
+			maxAge: time.Millisecond,
+			report: func(ctx context.Context) {
+				health.Beat(ctx)
+				time.Sleep(5 * time.Millisecond)
+			},
+		},
+		{
+			name:   "stale beat without max age",
+			maxAge: 0,
+			report: func(ctx context.Context) {
+				health.Beat(ctx)
+				time.Sleep(5 * time.Millisecond)
+			},
+			wantReady: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := health.NewChecker(time.Second)
+			checker.SetStarted()
+
+			worker := checker.Worker("relay", tt.maxAge)
+			tt.report(health.WithWorker(context.Background(), worker))
+
+			report, ready := checker.Ready(context.Background())
+			if ready != tt.wantReady {
+				t.Errorf("Ready() = %v, want %v, checks %+v", ready, tt.wantReady, report.Checks)
+			}
+			if _, ok := report.Checks["worker:relay"]; !ok {
+				t.Error("report has no worker check")
+			}
+		})
+	}
+}
+
+func TestBeatOutsideOfWorkers(t *testing.T) {
+	// the workers report through the context, outside of them nothing happens
+	health.Beat(context.Background())
+	health.Fail(context.Background(), errDown)
+	health.Update(context.Background(), errDown)
+}
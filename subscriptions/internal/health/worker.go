@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var errNotStarted = errors.New("worker hasn't started")
+
+type workerKey struct{}
+
+// Worker is the health of a background worker, the worker reports it with Beat and Fail.
+type Worker struct {
+	maxAge time.Duration
+
+	mu       sync.Mutex
+	lastBeat time.Time
+	lastErr  error
+}
+
+// WithWorker returns the context the worker reports its health through.
+func WithWorker(ctx context.Context, worker *Worker) context.Context {
+	return context.WithValue(ctx, workerKey{}, worker)
+}
+
+// Beat records that the worker of the context is running fine, it does nothing
+// outside of workers.
+func Beat(ctx context.Context) {
+	if worker, ok := ctx.Value(workerKey{}).(*Worker); ok {
+		worker.mu.Lock()
+		worker.lastBeat = time.Now()
+		worker.lastErr = nil
+		worker.mu.Unlock()
+	}
+}
+
+// Fail records that the worker of the context can't do its work until the next beat.
+func Fail(ctx context.Context, err error) {
+	if worker, ok := ctx.Value(workerKey{}).(*Worker); ok {
+		worker.mu.Lock()
+		worker.lastErr = err
+		worker.mu.Unlock()
+	}
+}
+
+func (w *Worker) check(now time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch {
+	case w.lastErr != nil:
+		return w.lastErr
+	case w.lastBeat.IsZero():
+		return errNotStarted
+	case w.maxAge > 0 && now.Sub(w.lastBeat) > w.maxAge:
+		return fmt.Errorf("no progress for %s", now.Sub(w.lastBeat).Round(time.Second))
+	}
+
+	return nil
+}
+
+// Update records the result of an iteration of the worker of the context, see Beat and Fail.
+func Update(ctx context.Context, err error) {
+	if err != nil {
+		Fail(ctx, err)
+		return
+	}
+
+	Beat(ctx)
+}
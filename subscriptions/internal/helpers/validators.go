@@ -3,6 +3,9 @@ package helpers
 import (
 	_ "regexp"
 	_ "strconv"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/tenant"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -17,3 +20,28 @@ func ValidateDateMMYYYYFormat(date string) bool {
 	return err == nil
 }
 
+// CheckStartDateBeforeEndDate reports whether the 'mm-yyyy' end date is not before the start date.
+func CheckStartDateBeforeEndDate(startDate, endDate string) bool {
+	startDateDate, _ := time.Parse("01-2006", startDate)
+	endDateDate, _ := time.Parse("01-2006", endDate)
+
+	return !endDateDate.Before(startDateDate)
+}
+
+
+func ValidateEventTypeValidator(fl validator.FieldLevel) bool {
+	for _, eventType := range events.Types {
+		if string(eventType) == fl.Field().String() {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateScopeValidator(fl validator.FieldLevel) bool {
+	return auth.IsScope(fl.Field().String())
+}
+
+func ValidateTenantIDValidator(fl validator.FieldLevel) bool {
+	return tenant.Valid(fl.Field().String())
+}
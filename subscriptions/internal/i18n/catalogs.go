@@ -0,0 +1,257 @@
+package i18n
+
+// The catalogs must have every message, {0}, {1}... are replaced with the parameters.
+var catalogs = map[string]map[Message]string{
+	English: {
+		InternalError:         "internal server error",
+		RouteNotFound:         "route not found",
+		Forbidden:             "forbidden",
+		InsufficientScope:     "insufficient scope, {0} required",
+		MissingCredentials:    "missing bearer token or api key",
+		InvalidCredentials:    "invalid bearer token or api key",
+		RateLimited:           "rate limit exceeded",
+		InvalidMetricsToken:   "invalid metrics token",
+		InvalidGraphQLRequest: "invalid graphql request",
+		TenantForbidden:       "access to the tenant is forbidden",
+
+		InvalidID:           "invalid id",
+		InvalidUserID:       "invalid user id",
+		InvalidCategoryID:   "invalid category id",
+		InvalidTenantID:     "invalid tenant id",
+		InvalidLastEventID:  "invalid last event id",
+		InvalidPage:         "invalid page number",
+		InvalidPageSize:     "invalid size number",
+		InvalidPrice:        "invalid price",
+		InvalidStartDate:    "invalid start date",
+		InvalidEndDate:      "invalid end date",
+		InvalidMonth:        "invalid month",
+		InvalidPeriod:       "startDate cannot be after endDate",
+		InvalidSubscription: "invalid subscription data",
+		InvalidCategory:     "invalid category data",
+		InvalidTag:          "invalid tag data",
+		InvalidUser:         "invalid user data",
+		InvalidBudget:       "invalid budget data",
+		InvalidWebhook:      "invalid webhook data",
+		InvalidAPIKey:       "invalid api key data",
+		InvalidTenant:       "invalid tenant data",
+
+		UpdateMaskRequired:     "subscription and update mask are required",
+		UnknownUpdatePath:      "unknown update mask path {0}",
+		UpdatePathNotClearable: "{0} can't be cleared",
+
+		FieldType:              "{0} must be {1}",
+		FieldRule:              "{0} breaks the {1} rule",
+		FieldDate:              "{0} must be a date in 'mm-yyyy' format",
+		FieldEventType:         "{0} must be a known event type",
+		FieldScope:             "{0} must be a known scope",
+		FieldTenantID:          "{0} must be up to 64 lowercase letters, digits, dashes and underscores",
+		FieldLanguageTag:       "{0} must be a BCP 47 language tag, e.g. en or ru-RU",
+		FieldCurrency:          "{0} must be an ISO 4217 currency code, e.g. RUB",
+		EndDateBeforeStartDate: "end_date cannot be before start_date",
+
+		TypeString:   "a string",
+		TypeBoolean:  "a boolean",
+		TypeArray:    "an array",
+		TypeObject:   "an object",
+		TypeUnsigned: "a non-negative integer",
+		TypeInteger:  "an integer",
+		TypeNumber:   "a number",
+		TypeValue:    "a valid value",
+
+		SubscriptionNotFound:     "subscription not found",
+		UserNotFound:             "user not found",
+		CategoryNotFound:         "category not found",
+		ParentCategoryNotFound:   "parent category not found",
+		UserOrCategoryNotFound:   "user or category not found",
+		TagNotFound:              "tag not found",
+		BudgetNotFound:           "budget not found",
+		WebhookNotFound:          "webhook not found",
+		DeliveryNotFound:         "delivery not found",
+		APIKeyNotFound:           "api key not found",
+		TenantNotFound:           "tenant not found",
+		UserExists:               "user with this id or email already exists",
+		CategoryExists:           "category with this name already exists",
+		TagExists:                "tag with this name already exists",
+		TenantExists:             "tenant with this id already exists",
+		UserHasSubscriptions:     "user has subscriptions",
+		UserOfOtherTenant:        "user belongs to another tenant",
+		CategoryHasSubcategories: "category has subcategories",
+		CategoryCycle:            "category cannot be moved under itself or its subcategory",
+		APIKeyRevoked:            "api key is revoked",
+		InvalidUpdate:            "invalid update data",
+
+		SumFailed:                   "cannot calculate sum of subscriptions",
+		BudgetStatusFailed:          "cannot calculate budget status",
+		RetrieveSubscriptionsFailed: "failed to retrieve subscriptions",
+		RetrieveSubscriptionFailed:  "failed to retrieve subscription",
+		CreateSubscriptionFailed:    "failed to create subscription",
+		UpdateSubscriptionFailed:    "failed to update subscription",
+		DeleteSubscriptionFailed:    "failed to delete subscription",
+		RetrieveUsersFailed:         "failed to retrieve users",
+		RetrieveUserFailed:          "failed to retrieve user",
+		CreateUserFailed:            "failed to create user",
+		UpdateUserFailed:            "failed to update user",
+		DeleteUserFailed:            "failed to delete user",
+		RetrieveCategoriesFailed:    "failed to retrieve categories",
+		RetrieveCategoryFailed:      "failed to retrieve category",
+		CreateCategoryFailed:        "failed to create category",
+		UpdateCategoryFailed:        "failed to update category",
+		DeleteCategoryFailed:        "failed to delete category",
+		RetrieveTagsFailed:          "failed to retrieve tags",
+		CreateTagFailed:             "failed to create tag",
+		UpdateTagFailed:             "failed to update tag",
+		DeleteTagFailed:             "failed to delete tag",
+		RetrieveBudgetsFailed:       "failed to retrieve budgets",
+		RetrieveBudgetFailed:        "failed to retrieve budget",
+		CreateBudgetFailed:          "failed to create budget",
+		UpdateBudgetFailed:          "failed to update budget",
+		DeleteBudgetFailed:          "failed to delete budget",
+		RetrieveWebhooksFailed:      "failed to retrieve webhooks",
+		RetrieveWebhookFailed:       "failed to retrieve webhook",
+		CreateWebhookFailed:         "failed to create webhook",
+		UpdateWebhookFailed:         "failed to update webhook",
+		DeleteWebhookFailed:         "failed to delete webhook",
+		GenerateSecretFailed:        "failed to generate webhook secret",
+		RetrieveDeliveriesFailed:    "failed to retrieve deliveries",
+		RetrieveDeliveryFailed:      "failed to retrieve delivery",
+		CreateDeliveryFailed:        "failed to create delivery",
+		RetrieveAPIKeysFailed:       "failed to retrieve api keys",
+		RetrieveAPIKeyFailed:        "failed to retrieve api key",
+		GenerateAPIKeyFailed:        "failed to generate api key",
+		CreateAPIKeyFailed:          "failed to create api key",
+		RevokeAPIKeyFailed:          "failed to revoke api key",
+		RotateAPIKeyFailed:          "failed to rotate api key",
+		CheckTenantFailed:           "failed to check tenant",
+		RetrieveTenantsFailed:       "failed to retrieve tenants",
+		RetrieveTenantFailed:        "failed to retrieve tenant",
+		CreateTenantFailed:          "failed to create tenant",
+		UpdateTenantFailed:          "failed to update tenant",
+		RetrieveTenantStatsFailed:   "failed to retrieve tenant stats",
+	},
+	Russian: {
+		InternalError:         "внутренняя ошибка сервера",
+		RouteNotFound:         "маршрут не найден",
+		Forbidden:             "доступ запрещен",
+		InsufficientScope:     "недостаточно прав, требуется scope {0}",
+		MissingCredentials:    "не передан bearer-токен или API-ключ",
+		InvalidCredentials:    "неверный bearer-токен или API-ключ",
+		RateLimited:           "превышен лимит запросов",
+		InvalidMetricsToken:   "неверный токен метрик",
+		InvalidGraphQLRequest: "неверный GraphQL-запрос",
+		TenantForbidden:       "доступ к тенанту запрещен",
+
+		InvalidID:           "неверный ID",
+		InvalidUserID:       "неверный ID пользователя",
+		InvalidCategoryID:   "неверный ID категории",
+		InvalidTenantID:     "неверный ID тенанта",
+		InvalidLastEventID:  "неверный ID последнего события",
+		InvalidPage:         "неверный номер страницы",
+		InvalidPageSize:     "неверный размер страницы",
+		InvalidPrice:        "неверная цена",
+		InvalidStartDate:    "неверная дата начала",
+		InvalidEndDate:      "неверная дата окончания",
+		InvalidMonth:        "неверный месяц",
+		InvalidPeriod:       "дата начала не может быть позже даты окончания",
+		InvalidSubscription: "неверные данные подписки",
+		InvalidCategory:     "неверные данные категории",
+		InvalidTag:          "неверные данные тега",
+		InvalidUser:         "неверные данные пользователя",
+		InvalidBudget:       "неверные данные бюджета",
+		InvalidWebhook:      "неверные данные вебхука",
+		InvalidAPIKey:       "неверные данные API-ключа",
+		InvalidTenant:       "неверные данные тенанта",
+
+		UpdateMaskRequired:     "подписка и маска обновления обязательны",
+		UnknownUpdatePath:      "неизвестный путь маски обновления {0}",
+		UpdatePathNotClearable: "{0} нельзя очистить",
+
+		FieldType:              "{0} должно быть {1}",
+		FieldRule:              "{0} нарушает правило {1}",
+		FieldDate:              "{0} должно быть датой в формате 'mm-yyyy'",
+		FieldEventType:         "{0} должно быть известным типом события",
+		FieldScope:             "{0} должно быть известным scope",
+		FieldTenantID:          "{0} должно содержать до 64 строчных латинских букв, цифр, дефисов и подчеркиваний",
+		FieldLanguageTag:       "{0} должно быть языковым тегом BCP 47, например ru или en-US",
+		FieldCurrency:          "{0} должно быть кодом валюты ISO 4217, например RUB",
+		EndDateBeforeStartDate: "end_date не может быть раньше start_date",
+
+		TypeString:   "строкой",
+		TypeBoolean:  "логическим значением",
+		TypeArray:    "массивом",
+		TypeObject:   "объектом",
+		TypeUnsigned: "неотрицательным целым числом",
+		TypeInteger:  "целым числом",
+		TypeNumber:   "числом",
+		TypeValue:    "допустимым значением",
+
+		SubscriptionNotFound:     "подписка не найдена",
+		UserNotFound:             "пользователь не найден",
+		CategoryNotFound:         "категория не найдена",
+		ParentCategoryNotFound:   "родительская категория не найдена",
+		UserOrCategoryNotFound:   "пользователь или категория не найдены",
+		TagNotFound:              "тег не найден",
+		BudgetNotFound:           "бюджет не найден",
+		WebhookNotFound:          "вебхук не найден",
+		DeliveryNotFound:         "доставка не найдена",
+		APIKeyNotFound:           "API-ключ не найден",
+		TenantNotFound:           "тенант не найден",
+		UserExists:               "пользователь с таким ID или email уже существует",
+		CategoryExists:           "категория с таким названием уже существует",
+		TagExists:                "тег с таким названием уже существует",
+		TenantExists:             "тенант с таким ID уже существует",
+		UserHasSubscriptions:     "у пользователя есть подписки",
+		UserOfOtherTenant:        "пользователь принадлежит другому тенанту",
+		CategoryHasSubcategories: "у категории есть подкатегории",
+		CategoryCycle:            "категорию нельзя переместить в нее саму или в ее подкатегорию",
+		APIKeyRevoked:            "API-ключ отозван",
+		InvalidUpdate:            "неверные данные для обновления",
+
+		SumFailed:                   "не удалось посчитать сумму подписок",
+		BudgetStatusFailed:          "не удалось посчитать статус бюджетов",
+		RetrieveSubscriptionsFailed: "не удалось получить подписки",
+		RetrieveSubscriptionFailed:  "не удалось получить подписку",
+		CreateSubscriptionFailed:    "не удалось создать подписку",
+		UpdateSubscriptionFailed:    "не удалось обновить подписку",
+		DeleteSubscriptionFailed:    "не удалось удалить подписку",
+		RetrieveUsersFailed:         "не удалось получить пользователей",
+		RetrieveUserFailed:          "не удалось получить пользователя",
+		CreateUserFailed:            "не удалось создать пользователя",
+		UpdateUserFailed:            "не удалось обновить пользователя",
+		DeleteUserFailed:            "не удалось удалить пользователя",
+		RetrieveCategoriesFailed:    "не удалось получить категории",
+		RetrieveCategoryFailed:      "не удалось получить категорию",
+		CreateCategoryFailed:        "не удалось создать категорию",
+		UpdateCategoryFailed:        "не удалось обновить категорию",
+		DeleteCategoryFailed:        "не удалось удалить категорию",
+		RetrieveTagsFailed:          "не удалось получить теги",
+		CreateTagFailed:             "не удалось создать тег",
+		UpdateTagFailed:             "не удалось обновить тег",
+		DeleteTagFailed:             "не удалось удалить тег",
+		RetrieveBudgetsFailed:       "не удалось получить бюджеты",
+		RetrieveBudgetFailed:        "не удалось получить бюджет",
+		CreateBudgetFailed:          "не удалось создать бюджет",
+		UpdateBudgetFailed:          "не удалось обновить бюджет",
+		DeleteBudgetFailed:          "не удалось удалить бюджет",
+		RetrieveWebhooksFailed:      "не удалось получить вебхуки",
+		RetrieveWebhookFailed:       "не удалось получить вебхук",
+		CreateWebhookFailed:         "не удалось создать вебхук",
+		UpdateWebhookFailed:         "не удалось обновить вебхук",
+		DeleteWebhookFailed:         "не удалось удалить вебхук",
+		GenerateSecretFailed:        "не удалось сгенерировать секрет вебхука",
+		RetrieveDeliveriesFailed:    "не удалось получить доставки",
+		RetrieveDeliveryFailed:      "не удалось получить доставку",
+		CreateDeliveryFailed:        "не удалось создать доставку",
+		RetrieveAPIKeysFailed:       "не удалось получить API-ключи",
+		RetrieveAPIKeyFailed:        "не удалось получить API-ключ",
+		GenerateAPIKeyFailed:        "не удалось сгенерировать API-ключ",
+		CreateAPIKeyFailed:          "не удалось создать API-ключ",
+		RevokeAPIKeyFailed:          "не удалось отозвать API-ключ",
+		RotateAPIKeyFailed:          "не удалось перевыпустить API-ключ",
+		CheckTenantFailed:           "не удалось проверить тенант",
+		RetrieveTenantsFailed:       "не удалось получить тенанты",
+		RetrieveTenantFailed:        "не удалось получить тенант",
+		CreateTenantFailed:          "не удалось создать тенант",
+		UpdateTenantFailed:          "не удалось обновить тенант",
+		RetrieveTenantStatsFailed:   "не удалось получить статистику тенанта",
+	},
+}
@@ -0,0 +1,103 @@
+/*
+Package i18n translates the error messages of the service to the language of the caller,
+negotiated from the Accept-Language header. The messages are kept in catalogs of
+universal-translator, which also translates the messages of the validator.
+*/
+package i18n
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ru"
+	ut "github.com/go-playground/universal-translator"
+	"golang.org/x/text/language"
+)
+
+// The supported languages, English is used when the caller accepts none of them.
+const (
+	English = "en"
+	Russian = "ru"
+)
+
+var (
+	universal = ut.New(en.New(), en.New(), ru.New())
+	supported = []language.Tag{language.English, language.Russian}
+	matcher   = language.NewMatcher(supported)
+)
+
+type languageKey struct{}
+
+func init() {
+	for lang, catalog := range catalogs {
+		trans, _ := universal.GetTranslator(lang)
+		for message, text := range catalog {
+			if err := trans.Add(message, text, false); err != nil {
+				panic(fmt.Sprintf("i18n: message %s in %s: %v", message, lang, err))
+			}
+		}
+	}
+
+	for message := range catalogs[English] {
+		if _, ok := catalogs[Russian][message]; !ok {
+			panic(fmt.Sprintf("i18n: message %s has no %s text", message, Russian))
+		}
+	}
+}
+
+// Negotiate returns the supported language the Accept-Language header prefers.
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return English
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return English
+	}
+
+	base, _ := supported[index].Base()
+	return base.String()
+}
+
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// FromContext returns the language of the request or English outside of requests.
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok {
+		return lang
+	}
+
+	return English
+}
+
+// Translator returns the translator of the language, or English for unsupported languages.
+func Translator(lang string) ut.Translator {
+	trans, _ := universal.GetTranslator(lang)
+	return trans
+}
+
+// Text returns the message in the language with the params in its placeholders, the code
+// of the message if the catalogs lack it.
+func Text(lang string, message Message, params ...string) string {
+	text, err := Translator(lang).T(message, params...)
+	if err != nil {
+		return string(message)
+	}
+
+	return text
+}
+
+// Translate returns the message in the language of the request.
+func Translate(ctx context.Context, message Message, params ...string) string {
+	return Text(FromContext(ctx), message, params...)
+}
+
+// String returns the message in English.
+func (m Message) String() string {
+	return Text(English, m)
+}
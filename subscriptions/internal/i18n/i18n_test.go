@@ -0,0 +1,66 @@
+package i18n_test
+
+import (
+	"context"
+	"subscriptions/rest-service/internal/i18n"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "missing header", acceptLanguage: "", want: i18n.English},
+		{name: "russian", acceptLanguage: "ru", want: i18n.Russian},
+		{name: "russian region", acceptLanguage: "ru-RU", want: i18n.Russian},
+		{name: "weighted", acceptLanguage: "de;q=0.9, ru;q=0.8, en;q=0.5", want: i18n.Russian},
+		{name: "english preferred", acceptLanguage: "en-US, ru;q=0.5", want: i18n.English},
+		{name: "unsupported", acceptLanguage: "de, fr", want: i18n.English},
+		{name: "malformed", acceptLanguage: "???", want: i18n.English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := i18n.Negotiate(tt.acceptLanguage); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name    string
+		lang    string
+		message i18n.Message
+		params  []string
+		want    string
+	}{
+		{name: "english", lang: i18n.English, message: i18n.SubscriptionNotFound, want: "subscription not found"},
+		{name: "russian", lang: i18n.Russian, message: i18n.InternalError, want: "внутренняя ошибка сервера"},
+		{name: "params", lang: i18n.English, message: i18n.InsufficientScope, params: []string{"subs:write"}, want: "insufficient scope, subs:write required"},
+		{name: "unsupported language", lang: "de", message: i18n.SubscriptionNotFound, want: "subscription not found"},
+		{name: "unknown message", lang: i18n.English, message: "no_such_message", want: "no_such_message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := i18n.Text(tt.lang, tt.message, tt.params...); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := i18n.FromContext(context.Background()); got != i18n.English {
+		t.Errorf("FromContext() outside of requests = %q, want %q", got, i18n.English)
+	}
+
+	ctx := i18n.WithLanguage(context.Background(), i18n.Russian)
+	if got := i18n.Translate(ctx, i18n.InternalError); got != "внутренняя ошибка сервера" {
+		t.Errorf("Translate() = %q, want the russian text", got)
+	}
+}
@@ -0,0 +1,151 @@
+package i18n
+
+// Message identifies the text of an error in the catalogs. Its value is the stable code
+// returned to the clients along with the text, so the clients can localize it themselves.
+type Message string
+
+// General errors.
+const (
+	InternalError         Message = "internal_error"
+	RouteNotFound         Message = "route_not_found"
+	Forbidden             Message = "forbidden"
+	InsufficientScope     Message = "insufficient_scope"
+	MissingCredentials    Message = "missing_credentials"
+	InvalidCredentials    Message = "invalid_credentials"
+	RateLimited           Message = "rate_limited"
+	InvalidMetricsToken   Message = "invalid_metrics_token"
+	InvalidGraphQLRequest Message = "invalid_graphql_request"
+	TenantForbidden       Message = "tenant_forbidden"
+)
+
+// Invalid parameters and bodies of the requests.
+const (
+	InvalidID           Message = "invalid_id"
+	InvalidUserID       Message = "invalid_user_id"
+	InvalidCategoryID   Message = "invalid_category_id"
+	InvalidTenantID     Message = "invalid_tenant_id"
+	InvalidLastEventID  Message = "invalid_last_event_id"
+	InvalidPage         Message = "invalid_page"
+	InvalidPageSize     Message = "invalid_page_size"
+	InvalidPrice        Message = "invalid_price"
+	InvalidStartDate    Message = "invalid_start_date"
+	InvalidEndDate      Message = "invalid_end_date"
+	InvalidMonth        Message = "invalid_month"
+	InvalidPeriod       Message = "invalid_period"
+	InvalidSubscription Message = "invalid_subscription"
+	InvalidCategory     Message = "invalid_category"
+	InvalidTag          Message = "invalid_tag"
+	InvalidUser         Message = "invalid_user"
+	InvalidBudget       Message = "invalid_budget"
+	InvalidWebhook      Message = "invalid_webhook"
+	InvalidAPIKey       Message = "invalid_api_key"
+	InvalidTenant       Message = "invalid_tenant"
+)
+
+// Invalid update masks of the gRPC API, {0} is the path of the mask.
+const (
+	UpdateMaskRequired     Message = "update_mask_required"
+	UnknownUpdatePath      Message = "unknown_update_path"
+	UpdatePathNotClearable Message = "update_path_not_clearable"
+)
+
+// Invalid fields of the request bodies, {0} is the field.
+const (
+	FieldType              Message = "field_type"
+	FieldRule              Message = "field_rule"
+	FieldDate              Message = "field_date"
+	FieldEventType         Message = "field_event_type"
+	FieldScope             Message = "field_scope"
+	FieldTenantID          Message = "field_tenant_id"
+	FieldLanguageTag       Message = "field_language_tag"
+	FieldCurrency          Message = "field_currency"
+	EndDateBeforeStartDate Message = "end_date_before_start_date"
+)
+
+// JSON types completing FieldType.
+const (
+	TypeString   Message = "type_string"
+	TypeBoolean  Message = "type_boolean"
+	TypeArray    Message = "type_array"
+	TypeObject   Message = "type_object"
+	TypeUnsigned Message = "type_unsigned"
+	TypeInteger  Message = "type_integer"
+	TypeNumber   Message = "type_number"
+	TypeValue    Message = "type_value"
+)
+
+// Missing and conflicting resources.
+const (
+	SubscriptionNotFound     Message = "subscription_not_found"
+	UserNotFound             Message = "user_not_found"
+	CategoryNotFound         Message = "category_not_found"
+	ParentCategoryNotFound   Message = "parent_category_not_found"
+	UserOrCategoryNotFound   Message = "user_or_category_not_found"
+	TagNotFound              Message = "tag_not_found"
+	BudgetNotFound           Message = "budget_not_found"
+	WebhookNotFound          Message = "webhook_not_found"
+	DeliveryNotFound         Message = "delivery_not_found"
+	APIKeyNotFound           Message = "api_key_not_found"
+	TenantNotFound           Message = "tenant_not_found"
+	UserExists               Message = "user_exists"
+	CategoryExists           Message = "category_exists"
+	TagExists                Message = "tag_exists"
+	TenantExists             Message = "tenant_exists"
+	UserHasSubscriptions     Message = "user_has_subscriptions"
+	UserOfOtherTenant        Message = "user_of_other_tenant"
+	CategoryHasSubcategories Message = "category_has_subcategories"
+	CategoryCycle            Message = "category_cycle"
+	APIKeyRevoked            Message = "api_key_revoked"
+	InvalidUpdate            Message = "invalid_update"
+)
+
+// Failures of the service.
+const (
+	SumFailed                   Message = "sum_failed"
+	BudgetStatusFailed          Message = "budget_status_failed"
+	RetrieveSubscriptionsFailed Message = "retrieve_subscriptions_failed"
+	RetrieveSubscriptionFailed  Message = "retrieve_subscription_failed"
+	CreateSubscriptionFailed    Message = "create_subscription_failed"
+	UpdateSubscriptionFailed    Message = "update_subscription_failed"
+	DeleteSubscriptionFailed    Message = "delete_subscription_failed"
+	RetrieveUsersFailed         Message = "retrieve_users_failed"
+	RetrieveUserFailed          Message = "retrieve_user_failed"
+	CreateUserFailed            Message = "create_user_failed"
+	UpdateUserFailed            Message = "update_user_failed"
+	DeleteUserFailed            Message = "delete_user_failed"
+	RetrieveCategoriesFailed    Message = "retrieve_categories_failed"
+	RetrieveCategoryFailed      Message = "retrieve_category_failed"
+	CreateCategoryFailed        Message = "create_category_failed"
+	UpdateCategoryFailed        Message = "update_category_failed"
+	DeleteCategoryFailed        Message = "delete_category_failed"
+	RetrieveTagsFailed          Message = "retrieve_tags_failed"
+	CreateTagFailed             Message = "create_tag_failed"
+	UpdateTagFailed             Message = "update_tag_failed"
+	DeleteTagFailed             Message = "delete_tag_failed"
+	RetrieveBudgetsFailed       Message = "retrieve_budgets_failed"
+	RetrieveBudgetFailed        Message = "retrieve_budget_failed"
+	CreateBudgetFailed          Message = "create_budget_failed"
+	UpdateBudgetFailed          Message = "update_budget_failed"
+	DeleteBudgetFailed          Message = "delete_budget_failed"
+	RetrieveWebhooksFailed      Message = "retrieve_webhooks_failed"
+	RetrieveWebhookFailed       Message = "retrieve_webhook_failed"
+	CreateWebhookFailed         Message = "create_webhook_failed"
+	UpdateWebhookFailed         Message = "update_webhook_failed"
+	DeleteWebhookFailed         Message = "delete_webhook_failed"
+	GenerateSecretFailed        Message = "generate_secret_failed"
+	RetrieveDeliveriesFailed    Message = "retrieve_deliveries_failed"
+	RetrieveDeliveryFailed      Message = "retrieve_delivery_failed"
+	CreateDeliveryFailed        Message = "create_delivery_failed"
+	RetrieveAPIKeysFailed       Message = "retrieve_api_keys_failed"
+	RetrieveAPIKeyFailed        Message = "retrieve_api_key_failed"
+	GenerateAPIKeyFailed        Message = "generate_api_key_failed"
+	CreateAPIKeyFailed          Message = "create_api_key_failed"
+	RevokeAPIKeyFailed          Message = "revoke_api_key_failed"
+	RotateAPIKeyFailed          Message = "rotate_api_key_failed"
+	CheckTenantFailed           Message = "check_tenant_failed"
+	RetrieveTenantsFailed       Message = "retrieve_tenants_failed"
+	RetrieveTenantFailed        Message = "retrieve_tenant_failed"
+	CreateTenantFailed          Message = "create_tenant_failed"
+	UpdateTenantFailed          Message = "update_tenant_failed"
+	RetrieveTenantStatsFailed   Message = "retrieve_tenant_stats_failed"
+)
@@ -0,0 +1,58 @@
+package i18n
+
+import (
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	ru_translations "github.com/go-playground/validator/v10/translations/ru"
+)
+
+// fieldRules are the messages of the rules the validator has no translations of.
+var fieldRules = map[string]Message{
+	"mm_yyyy_date":       FieldDate,
+	"event_type":         FieldEventType,
+	"scope":              FieldScope,
+	"tenant_id":          FieldTenantID,
+	"bcp47_language_tag": FieldLanguageTag,
+	"iso4217":            FieldCurrency,
+}
+
+// RegisterValidator registers the translations of the validation errors in every
+// supported language, the errors are translated with FieldError.Translate.
+func RegisterValidator(validate *validator.Validate) error {
+	defaults := map[string]func(*validator.Validate, ut.Translator) error{
+		English: en_translations.RegisterDefaultTranslations,
+		Russian: ru_translations.RegisterDefaultTranslations,
+	}
+
+	for lang, register := range defaults {
+		trans := Translator(lang)
+		if err := register(validate, trans); err != nil {
+			return err
+		}
+
+		for tag, message := range fieldRules {
+			err := validate.RegisterTranslation(tag, trans, registerNothing, translateField(message))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// registerNothing is the registration of the rules translated with the catalogs.
+func registerNothing(ut.Translator) error {
+	return nil
+}
+
+func translateField(message Message) validator.TranslationFunc {
+	return func(trans ut.Translator, fieldErr validator.FieldError) string {
+		text, err := trans.T(message, fieldErr.Field())
+		if err != nil {
+			return string(message)
+		}
+		return text
+	}
+}
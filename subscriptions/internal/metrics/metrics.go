@@ -0,0 +1,199 @@
+/*
+Package metrics defines the Prometheus metrics of the service, they are
+registered in the default registry served on /metrics.
+*/
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "subscriptions"
+
+// maxServiceLabels bounds the service names counted apart, the names are client input,
+// so the subscriptions of the services seen after them are counted as otherService.
+const (
+	maxServiceLabels = 100
+	otherService     = "other"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of HTTP requests by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of repository methods.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"repository", "method"})
+
+	// subsSumDuration is kept apart from the other queries since the sum aggregates
+	// the whole table and takes much longer.
+	subsSumDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "subs_sum_duration_seconds",
+		Help:      "Duration of the sum of subscriptions for a period.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	})
+
+	activeSubscriptions = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active",
+		Help:      "Number of subscriptions active in the current month by tenant.",
+	}, []string{"tenant"})
+
+	subscriptionsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "created_total",
+		Help:      "Number of subscriptions created by service name, the names past the limit are counted as \"other\".",
+	}, []string{"service"})
+
+	outboxEventsDead = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "dead_events_total",
+		Help:      "Number of outbox events given up after the maximum number of publish attempts.",
+	})
+
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Number of cache lookups by cache and result: hit, miss or error.",
+	}, []string{"cache", "result"})
+
+	cacheInvalidations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "invalidations_total",
+		Help:      "Number of invalidated cache scopes by kind of scope.",
+	}, []string{"scope"})
+
+	dbReplicaHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "replica_healthy",
+		Help:      "Whether the read replica is healthy and receives the reads, 1 or 0.",
+	}, []string{"replica"})
+
+	serviceLabelsMu sync.Mutex
+	serviceLabels   = make(map[string]struct{})
+)
+
+// The results of the cache lookups.
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+)
+
+// ObserveHTTPRequest records the request to the route, the route is the pattern
+// the request matched, so paths with ids don't create new series.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	statusLabel := strconv.Itoa(status)
+
+	httpRequests.WithLabelValues(method, route, statusLabel).Inc()
+	httpRequestDuration.WithLabelValues(method, route, statusLabel).Observe(duration.Seconds())
+}
+
+// ObserveQuery starts timing the repository method, the returned function records
+// the duration and is meant to be deferred.
+func ObserveQuery(repository, method string) func() {
+	start := time.Now()
+
+	return func() {
+		dbQueryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveSubsSum is ObserveQuery for the sum of subscriptions.
+func ObserveSubsSum() func() {
+	start := time.Now()
+
+	return func() {
+		subsSumDuration.Observe(time.Since(start).Seconds())
+	}
+}
+
+// SetActiveSubscriptions replaces the numbers of active subscriptions of all tenants.
+func SetActiveSubscriptions(counts map[string]int64) {
+	activeSubscriptions.Reset()
+	for tenantID, count := range counts {
+		activeSubscriptions.WithLabelValues(tenantID).Set(float64(count))
+	}
+}
+
+// SubscriptionCreated counts the created subscription of the service, names are
+// compared case insensitively like in the sum filter.
+func SubscriptionCreated(serviceName string) {
+	subscriptionsCreated.WithLabelValues(serviceLabel(strings.ToLower(serviceName))).Inc()
+}
+
+// serviceLabel returns the label of the service, otherService once maxServiceLabels
+// other names have been seen.
+func serviceLabel(name string) string {
+	serviceLabelsMu.Lock()
+	defer serviceLabelsMu.Unlock()
+
+	if _, ok := serviceLabels[name]; ok {
+		return name
+	}
+	if len(serviceLabels) >= maxServiceLabels {
+		return otherService
+	}
+
+	serviceLabels[name] = struct{}{}
+	return name
+}
+
+// OutboxEventDead counts the outbox event given up by the relay.
+func OutboxEventDead() {
+	outboxEventsDead.Inc()
+}
+
+// CacheRequest counts the lookup of the cache with the result.
+func CacheRequest(cache, result string) {
+	cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// CacheInvalidated counts the invalidated scope of the kind.
+func CacheInvalidated(scope string) {
+	cacheInvalidations.WithLabelValues(scope).Inc()
+}
+
+// SetReplicaHealthy records whether the read replica is healthy.
+func SetReplicaHealthy(replica string, healthy bool) {
+	value := 0.0
+	if healthy {
+		value = 1
+	}
+	dbReplicaHealthy.WithLabelValues(replica).Set(value)
+}
+
+// RegisterDBStats exports the connection pool stats of the database.
+func RegisterDBStats(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
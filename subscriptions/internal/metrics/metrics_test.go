@@ -0,0 +1,68 @@
+package metrics_test
+
+import (
+	"strconv"
+	"subscriptions/rest-service/internal/metrics"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// createdCounts returns the created_total counters by service label.
+func createdCounts(t *testing.T) map[string]float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	counts := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != "subscriptions_created_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "service" {
+					counts[label.GetValue()] = metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return counts
+}
+
+func TestSubscriptionCreated(t *testing.T) {
+	for i := range 99 {
+		metrics.SubscriptionCreated("service " + strconv.Itoa(i))
+	}
+
+	tests := []struct {
+		name    string
+		service string
+		label   string
+		want    float64
+	}{
+		{"new name under the limit", "Yandex Plus", "yandex plus", 1},
+		{"known name in another case", "YANDEX PLUS", "yandex plus", 2},
+		{"new name past the limit", "Netflix", "other", 1},
+		{"another name past the limit", "Spotify", "other", 2},
+		{"known name past the limit", "service 0", "service 0", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics.SubscriptionCreated(tt.service)
+
+			counts := createdCounts(t)
+			if got := counts[tt.label]; got != tt.want {
+				t.Errorf("created_total{service=%q} = %v, want %v", tt.label, got, tt.want)
+			}
+			if len(counts) > 101 {
+				t.Errorf("created_total has %d series, want at most 101", len(counts))
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey is stored as the SHA-256 hash of its secret, the prefix identifies the key.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID   string     `json:"-" gorm:"size:64;not null;default:default;index:idx_api_keys_tenant_id"`
+	Name       string     `json:"name" gorm:"size:128;not null"`
+	Prefix     string     `json:"prefix" gorm:"size:32;not null;uniqueIndex:idx_api_keys_prefix"`
+	Hash       string     `json:"-" gorm:"size:64;not null"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json;type:jsonb;not null"`
+	UserID     *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index:idx_api_keys_user_id"`
+	User       *User      `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
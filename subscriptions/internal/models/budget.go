@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Budget limits the monthly charge of user subscriptions. If CategoryID or
+// ServiceName is set, only the matching subscriptions are counted.
+type Budget struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID     string    `json:"-" gorm:"size:64;not null;default:default;index:idx_budgets_tenant_user,priority:1"`
+	UserID       uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index:idx_budgets_tenant_user,priority:2"`
+	User         *User     `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	CategoryID   *uint     `json:"category_id,omitempty"`
+	Category     *Category `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	ServiceName  *string   `json:"service_name,omitempty" gorm:"size:150"`
+	MonthlyLimit uint      `json:"monthly_limit" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
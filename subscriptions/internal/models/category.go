@@ -0,0 +1,9 @@
+package models
+
+type Category struct {
+	ID       uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID string    `json:"-" gorm:"size:64;not null;default:default"`
+	Name     string    `json:"name" gorm:"size:100;not null"`
+	ParentID *uint     `json:"parent_id,omitempty" gorm:"index:idx_categories_parent_id"`
+	Parent   *Category `json:"-" gorm:"constraint:OnDelete:RESTRICT"`
+}
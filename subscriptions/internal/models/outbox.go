@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// OutboxEvent is a domain event written in the transaction that caused it
+// and published by the outbox relay afterwards. An event that failed the maximum
+// number of attempts is dead, it is kept with DeadAt set and isn't published anymore.
+// StreamSeq is the position of the event in the subscription stream, assigned from
+// the outbox_stream_seq sequence when the event is announced to the stream listeners.
+type OutboxEvent struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement"`
+	TenantID       string     `gorm:"size:64;not null;default:default"`
+	SubscriptionID uint       `gorm:"not null;index:idx_outbox_events_subscription_id"`
+	Type           string     `gorm:"size:64;not null"`
+	Payload        string     `gorm:"type:jsonb;not null"`
+	CreatedAt      time.Time  `gorm:"not null"`
+	PublishedAt    *time.Time `gorm:"index:idx_outbox_events_unpublished,where:published_at IS NULL"`
+	Attempts       int        `gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time
+	LastError      *string `gorm:"size:1024"`
+	DeadAt         *time.Time
+	StreamSeq      *uint64 `gorm:"uniqueIndex:idx_outbox_events_stream_seq"`
+}
@@ -8,9 +8,15 @@ import (
 
 type Subscription struct {
 	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
-	ServiceName string     `json:"service_name" gorm:"size:150;not null;index:idx_subscriptions_service_name"`
+	TenantID    string     `json:"-" gorm:"size:64;not null;default:default;index:idx_subscriptions_tenant_user,priority:1;index:idx_subscriptions_tenant_service,priority:1"`
+	ServiceName string     `json:"service_name" gorm:"size:150;not null;index:idx_subscriptions_tenant_service,priority:2"`
 	Price       uint       `json:"price" gorm:"not null"`
-	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index:idx_subscriptions_user_id"`
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index:idx_subscriptions_tenant_user,priority:2"`
 	StartDate   time.Time  `json:"start_date" gorm:"not null;type:date"`
 	EndDate     *time.Time `json:"end_date,omitempty" gorm:"type:date"`
+	CategoryID  *uint      `json:"category_id,omitempty" gorm:"index:idx_subscriptions_category_id"`
+	Category    *Category  `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+	Tags        []Tag      `json:"tags,omitempty" gorm:"many2many:subscription_tags;constraint:OnDelete:CASCADE"`
+	// EndingSoonNotifiedFor is the end date the ending soon event was already sent for.
+	EndingSoonNotifiedFor *time.Time `json:"-" gorm:"type:date"`
 }
@@ -0,0 +1,7 @@
+package models
+
+type Tag struct {
+	ID       uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID string `json:"-" gorm:"size:64;not null;default:default;uniqueIndex:idx_tags_tenant_name,priority:1"`
+	Name     string `json:"name" gorm:"size:50;not null;uniqueIndex:idx_tags_tenant_name,priority:2"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// Tenant is a business unit whose data is isolated from other tenants.
+type Tenant struct {
+	ID        string    `json:"id" gorm:"primaryKey;size:64"`
+	Name      string    `json:"name" gorm:"size:150;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
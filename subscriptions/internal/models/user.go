@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type User struct {
+	ID                uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	TenantID          string    `json:"-" gorm:"size:64;not null;default:default;uniqueIndex:idx_users_tenant_email,priority:1"`
+	DisplayName       string    `json:"display_name" gorm:"size:150;not null"`
+	Email             *string   `json:"email,omitempty" gorm:"size:254;uniqueIndex:idx_users_tenant_email,priority:2"`
+	Locale            string    `json:"locale" gorm:"size:16;not null;default:ru"`
+	PreferredCurrency string    `json:"preferred_currency" gorm:"size:3;not null;default:RUB"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
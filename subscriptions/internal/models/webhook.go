@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type Webhook struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID   string    `json:"-" gorm:"size:64;not null;default:default;index:idx_webhooks_tenant_id"`
+	URL        string    `json:"url" gorm:"size:2048;not null"`
+	Secret     string    `json:"-" gorm:"size:128;not null"`
+	EventTypes []string  `json:"event_types" gorm:"serializer:json;type:jsonb;not null"`
+	Active     bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+type WebhookDelivery struct {
+	ID            uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	TenantID      string     `json:"-" gorm:"size:64;not null;default:default"`
+	WebhookID     uint       `json:"webhook_id" gorm:"not null;index:idx_webhook_deliveries_webhook_id;uniqueIndex:idx_webhook_deliveries_event,priority:1"`
+	Webhook       *Webhook   `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	EventID       *uint      `json:"event_id,omitempty" gorm:"uniqueIndex:idx_webhook_deliveries_event,priority:2"`
+	EventType     string     `json:"event_type" gorm:"size:64;not null"`
+	Payload       string     `json:"payload" gorm:"type:jsonb;not null"`
+	Status        string     `json:"status" gorm:"size:16;not null;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"not null;index:idx_webhook_deliveries_due,priority:2"`
+	ResponseCode  *int       `json:"response_code,omitempty"`
+	ResponseBody  *string    `json:"response_body,omitempty" gorm:"size:1024"`
+	LastError     *string    `json:"last_error,omitempty" gorm:"size:1024"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
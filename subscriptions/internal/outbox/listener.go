@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/health"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/tenant"
+	"time"
+)
+
+const listenRetryInterval = 5 * time.Second
+
+// StreamTypes lists the event types passed to the stream hub.
+var StreamTypes = []events.Type{
+	events.SubscriptionCreated,
+	events.SubscriptionUpdated,
+	events.SubscriptionDeleted,
+}
+
+// NotifyPublisher announces published events to the listeners of all instances.
+type NotifyPublisher struct {
+	repository repository.OutboxRepo
+}
+
+func NewNotifyPublisher(repo repository.OutboxRepo) *NotifyPublisher {
+	return &NotifyPublisher{repository: repo}
+}
+
+func (p *NotifyPublisher) Publish(ctx context.Context, event events.Event) error {
+	if !slices.Contains(StreamTypes, event.Type) {
+		return nil
+	}
+
+	return p.repository.Notify(ctx, event.ID)
+}
+
+// Listener receives the events announced by NotifyPublisher and passes them
+// to the stream hub of the instance.
+type Listener struct {
+	repository repository.OutboxRepo
+	hub        *events.Hub
+	// bufferSize is how many events are loaded into the hub on (re)connect.
+	bufferSize int
+}
+
+func NewListener(repo repository.OutboxRepo, hub *events.Hub, bufferSize int) *Listener {
+	return &Listener{
+		repository: repo,
+		hub:        hub,
+		bufferSize: bufferSize,
+	}
+}
+
+// toStreamEvent decodes the subscription payload, so subscribers can be filtered by it.
+func toStreamEvent(record models.OutboxEvent) (events.Event, error) {
+	var payload events.SubscriptionPayload
+	if err := json.Unmarshal([]byte(record.Payload), &payload); err != nil {
+		return events.Event{}, err
+	}
+
+	event := toEvent(record)
+	event.Data = payload
+	return event, nil
+}
+
+// Run listens for events until ctx is cancelled, reconnecting on failures. Events
+// published while the listener was disconnected are loaded from the outbox.
+func (l *Listener) Run(ctx context.Context) {
+	// the hub of the instance receives the events of all tenants
+	ctx = tenant.Unscoped(ctx)
+
+	for {
+		ready := func() {
+			health.Beat(ctx)
+			l.catchUp(ctx)
+		}
+
+		err := l.repository.Listen(ctx, ready, func(seq uint64) { l.handle(ctx, seq) })
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			health.Fail(ctx, err)
+			slog.Error("listen for outbox events failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryInterval):
+		}
+	}
+}
+
+func (l *Listener) catchUp(ctx context.Context) {
+	records, err := l.repository.GetLatestEvents(ctx, l.hub.LastSequence(), StreamTypes, l.bufferSize)
+	if err != nil {
+		return
+	}
+
+	for _, record := range records {
+		l.publish(record)
+	}
+}
+
+func (l *Listener) handle(ctx context.Context, seq uint64) {
+	record, err := l.repository.GetStreamEvent(ctx, seq)
+	if err != nil {
+		return
+	}
+
+	l.publish(*record)
+}
+
+func (l *Listener) publish(record models.OutboxEvent) {
+	event, err := toStreamEvent(record)
+	if err != nil {
+		slog.Error("decode outbox event failed", "error", err)
+		return
+	}
+
+	l.hub.Publish(event)
+}
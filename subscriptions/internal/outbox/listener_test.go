@@ -0,0 +1,180 @@
+package outbox_test
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/outbox"
+	"subscriptions/rest-service/internal/repository"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// streamRepo keeps the events announced to the stream by their sequences, Listen loads
+// the missed events and passes the notified sequences once, then stops the listener.
+type streamRepo struct {
+	repository.OutboxRepo
+
+	records  map[uint64]models.OutboxEvent
+	notified []uint64
+	// afterSeq is the sequence the missed events were loaded after.
+	afterSeq uint64
+	cancel   context.CancelFunc
+	notifies []uint
+}
+
+func (r *streamRepo) GetStreamEvent(_ context.Context, seq uint64) (*models.OutboxEvent, error) {
+	record, ok := r.records[seq]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &record, nil
+}
+
+func (r *streamRepo) GetLatestEvents(_ context.Context, afterSeq uint64, _ []events.Type, limit int) ([]models.OutboxEvent, error) {
+	r.afterSeq = afterSeq
+
+	var records []models.OutboxEvent
+	for _, seq := range slices.Sorted(maps.Keys(r.records)) {
+		if seq > afterSeq {
+			records = append(records, r.records[seq])
+		}
+	}
+	return records[max(len(records)-limit, 0):], nil
+}
+
+func (r *streamRepo) Listen(ctx context.Context, ready func(), handle func(seq uint64)) error {
+	ready()
+	for _, seq := range r.notified {
+		handle(seq)
+	}
+
+	r.cancel()
+	return ctx.Err()
+}
+
+func (r *streamRepo) Notify(_ context.Context, id uint) error {
+	r.notifies = append(r.notifies, id)
+	return nil
+}
+
+// streamEvent returns the event with the sequence, its ID is in the reverse order.
+func streamEvent(seq uint64, payload string) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:             uint(1000 - seq),
+		SubscriptionID: 1,
+		Type:           string(events.SubscriptionUpdated),
+		Payload:        payload,
+		StreamSeq:      &seq,
+	}
+}
+
+func TestListenerRun(t *testing.T) {
+	payload := `{"id":1,"service_name":"Netflix"}`
+
+	tests := []struct {
+		name    string
+		records []models.OutboxEvent
+		// hubSeqs are the sequences already in the hub, notified are the sequences
+		// passed by Listen
+		hubSeqs    []uint64
+		notified   []uint64
+		bufferSize int
+		// afterSeq is the expected sequence the missed events are loaded after, expected
+		// are the sequences in the hub
+		afterSeq uint64
+		expected []uint64
+	}{
+		{
+			name:       "missed events and notifications",
+			records:    []models.OutboxEvent{streamEvent(1, payload), streamEvent(2, payload), streamEvent(3, payload)},
+			notified:   []uint64{3},
+			bufferSize: 10,
+			expected:   []uint64{1, 2, 3},
+		},
+		{
+			name:       "resume after the last event of the hub",
+			records:    []models.OutboxEvent{streamEvent(1, payload), streamEvent(2, payload), streamEvent(4, payload)},
+			hubSeqs:    []uint64{2},
+			bufferSize: 10,
+			afterSeq:   2,
+			expected:   []uint64{2, 4},
+		},
+		{
+			name:       "only the last events loaded",
+			records:    []models.OutboxEvent{streamEvent(1, payload), streamEvent(2, payload), streamEvent(3, payload)},
+			bufferSize: 2,
+			expected:   []uint64{2, 3},
+		},
+		{
+			name:       "unknown and invalid events skipped",
+			records:    []models.OutboxEvent{streamEvent(1, payload), streamEvent(2, "{")},
+			notified:   []uint64{2, 5},
+			bufferSize: 10,
+			expected:   []uint64{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			repo := &streamRepo{records: map[uint64]models.OutboxEvent{}, notified: tt.notified, cancel: cancel}
+			for _, record := range tt.records {
+				repo.records[*record.StreamSeq] = record
+			}
+
+			hub := events.NewHub(10)
+			for _, seq := range tt.hubSeqs {
+				hub.Publish(events.Event{Sequence: seq})
+			}
+
+			outbox.NewListener(repo, hub, tt.bufferSize).Run(ctx)
+
+			if repo.afterSeq != tt.afterSeq {
+				t.Errorf("expected events loaded after %d, got %d", tt.afterSeq, repo.afterSeq)
+			}
+
+			var after uint64
+			replay, _, cancelSubscribe := hub.Subscribe(&after)
+			defer cancelSubscribe()
+
+			seqs := make([]uint64, len(replay))
+			for i, event := range replay {
+				seqs[i] = event.Sequence
+			}
+			if !slices.Equal(seqs, tt.expected) {
+				t.Errorf("expected sequences %v, got %v", tt.expected, seqs)
+			}
+		})
+	}
+}
+
+func TestNotifyPublisher(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType events.Type
+		notified  bool
+	}{
+		{name: "subscription event", eventType: events.SubscriptionDeleted, notified: true},
+		{name: "event not streamed", eventType: events.BudgetExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &streamRepo{}
+
+			if err := outbox.NewNotifyPublisher(repo).Publish(context.Background(), events.Event{ID: 7, Type: tt.eventType}); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+
+			if notified := slices.Equal(repo.notifies, []uint{7}); notified != tt.notified {
+				t.Errorf("expected notified %t, got %v", tt.notified, repo.notifies)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Package outbox delivers the events written to the outbox table to publishers.
+*/
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"subscriptions/rest-service/internal/events"
+	"sync"
+	"time"
+)
+
+// Publisher sends an event outside of the service. An event is published at least once,
+// so the receiving side should deduplicate events by their ID.
+type Publisher interface {
+	Publish(ctx context.Context, event events.Event) error
+}
+
+// LogPublisher writes events to the writer as JSON lines.
+type LogPublisher struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func NewLogPublisher(writer io.Writer) *LogPublisher {
+	return &LogPublisher{writer: writer}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, event events.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err = p.writer.Write(append(line, '\n'))
+	return err
+}
+
+// HTTPPublisher posts events as JSON to the URL. Any response status except 2xx
+// is a failed publish.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPPublisher(url string, timeout time.Duration) *HTTPPublisher {
+	return &HTTPPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, event events.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", string(event.Type))
+	req.Header.Set("X-Event-ID", fmt.Sprint(event.ID))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("publish event %d: unexpected status %d", event.ID, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// BusPublisher passes events to the handlers of the in-process bus. A failed handler
+// fails the publish, so the event is retried with all handlers.
+type BusPublisher struct {
+	bus *events.Bus
+}
+
+func NewBusPublisher(bus *events.Bus) *BusPublisher {
+	return &BusPublisher{bus: bus}
+}
+
+func (p *BusPublisher) Publish(_ context.Context, event events.Event) error {
+	return p.bus.Publish(event)
+}
+
+// MultiPublisher publishes events to all publishers. If one of them fails the event
+// is retried with all of them.
+type MultiPublisher []Publisher
+
+func (p MultiPublisher) Publish(ctx context.Context, event events.Event) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,59 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/outbox"
+	"testing"
+	"time"
+)
+
+func TestPublishers(t *testing.T) {
+	failingBus := events.NewBus()
+	failingBus.Subscribe(func(events.Event) error { return errors.New("no database") })
+
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Event-ID") != "5" {
+			t.Errorf("expected event id 5, got %q", r.Header.Get("X-Event-ID"))
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer okServer.Close()
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failingServer.Close()
+
+	tests := []struct {
+		name      string
+		publisher outbox.Publisher
+		fails     bool
+	}{
+		{name: "bus", publisher: outbox.NewBusPublisher(events.NewBus())},
+		{name: "bus with a failed handler", publisher: outbox.NewBusPublisher(failingBus), fails: true},
+		{name: "http", publisher: outbox.NewHTTPPublisher(okServer.URL, time.Second)},
+		{name: "http error status", publisher: outbox.NewHTTPPublisher(failingServer.URL, time.Second), fails: true},
+		{
+			name:      "all of multi",
+			publisher: outbox.MultiPublisher{outbox.NewBusPublisher(events.NewBus()), outbox.NewHTTPPublisher(okServer.URL, time.Second)},
+		},
+		{
+			name:      "one of multi failed",
+			publisher: outbox.MultiPublisher{outbox.NewHTTPPublisher(okServer.URL, time.Second), outbox.NewBusPublisher(failingBus)},
+			fails:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.publisher.Publish(context.Background(), events.Event{ID: 5, Type: events.SubscriptionCreated})
+			if (err != nil) != tt.fails {
+				t.Errorf("expected failure %t, got %v", tt.fails, err)
+			}
+		})
+	}
+}
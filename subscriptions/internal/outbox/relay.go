@@ -0,0 +1,107 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/health"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/tenant"
+	"time"
+)
+
+const (
+	relayBatchSize  = 100
+	cleanupInterval = time.Hour
+)
+
+// Relay moves events from the outbox table to the publisher.
+type Relay struct {
+	repository   repository.OutboxRepo
+	publisher    Publisher
+	pollInterval time.Duration
+	// retention is how long published events are kept, zero keeps them forever.
+	retention time.Duration
+	// maxAttempts is the number of failed publishes after which an event is dead.
+	maxAttempts int
+}
+
+func NewRelay(repo repository.OutboxRepo, publisher Publisher, pollInterval, retention time.Duration, maxAttempts int) *Relay {
+	return &Relay{
+		repository:   repo,
+		publisher:    publisher,
+		pollInterval: pollInterval,
+		retention:    retention,
+		maxAttempts:  maxAttempts,
+	}
+}
+
+func toEvent(record models.OutboxEvent) events.Event {
+	event := events.Event{
+		ID:             record.ID,
+		SubscriptionID: record.SubscriptionID,
+		TenantID:       record.TenantID,
+		Type:           events.Type(record.Type),
+		OccurredAt:     record.CreatedAt,
+		Data:           json.RawMessage(record.Payload),
+	}
+	if record.StreamSeq != nil {
+		event.Sequence = *record.StreamSeq
+	}
+
+	return event
+}
+
+// Run publishes events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	// events of all tenants are published by the same relay
+	ctx = tenant.Unscoped(ctx)
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Time{}
+
+	for {
+		health.Update(ctx, r.publishPending(ctx))
+
+		if r.retention > 0 && time.Since(lastCleanup) >= cleanupInterval {
+			r.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishPending publishes the events until none of them is published. A batch has at
+// most one event of a subscription, so it is repeated while the events are published.
+func (r *Relay) publishPending(ctx context.Context) error {
+	for ctx.Err() == nil {
+		published, err := r.repository.ProcessEvents(ctx, relayBatchSize, r.maxAttempts, func(record models.OutboxEvent) error {
+			return r.publisher.Publish(ctx, toEvent(record))
+		})
+		if err != nil || published == 0 {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	deleted, err := r.repository.DeletePublishedBefore(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		return
+	}
+
+	if deleted > 0 {
+		slog.Info("deleted published outbox events", "count", deleted)
+	}
+}
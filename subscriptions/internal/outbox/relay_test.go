@@ -0,0 +1,151 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/outbox"
+	"subscriptions/rest-service/internal/repository"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchRepo passes the batches of events to publish one batch per call and signals idle
+// when a call publishes nothing.
+type batchRepo struct {
+	repository.OutboxRepo
+
+	batches     [][]models.OutboxEvent
+	calls       int
+	maxAttempts int
+	idle        chan struct{}
+}
+
+func (r *batchRepo) ProcessEvents(_ context.Context, _ int, maxAttempts int, publish func(event models.OutboxEvent) error) (int, error) {
+	r.calls++
+	r.maxAttempts = maxAttempts
+
+	published := 0
+	if len(r.batches) > 0 {
+		for _, record := range r.batches[0] {
+			if publish(record) == nil {
+				published++
+			}
+		}
+		r.batches = r.batches[1:]
+	}
+
+	if published == 0 {
+		r.idle <- struct{}{}
+	}
+	return published, nil
+}
+
+// recordPublisher records the published events and fails the ones in fail.
+type recordPublisher struct {
+	mu        sync.Mutex
+	published []events.Event
+	fail      map[uint]bool
+}
+
+func (p *recordPublisher) Publish(_ context.Context, event events.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.fail[event.ID] {
+		return errors.New("unavailable")
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+func outboxEvent(id, subscriptionID uint) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:             id,
+		SubscriptionID: subscriptionID,
+		Type:           string(events.SubscriptionUpdated),
+		Payload:        `{"id":1}`,
+	}
+}
+
+func TestRelayRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		batches [][]models.OutboxEvent
+		fail    map[uint]bool
+		// expected are the ids of the published events, calls is the expected number of
+		// the batches processed by the first round
+		expected []uint
+		calls    int
+	}{
+		{name: "nothing to publish", calls: 1},
+		{
+			name: "batches published until drained",
+			batches: [][]models.OutboxEvent{
+				{outboxEvent(1, 1), outboxEvent(2, 2)},
+				{outboxEvent(3, 1)},
+			},
+			expected: []uint{1, 2, 3},
+			calls:    3,
+		},
+		{
+			name: "stopped by a batch without published events",
+			batches: [][]models.OutboxEvent{
+				{outboxEvent(1, 1)},
+				{outboxEvent(2, 2)},
+				{outboxEvent(3, 1)},
+			},
+			fail:     map[uint]bool{2: true},
+			expected: []uint{1},
+			calls:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &batchRepo{batches: tt.batches, idle: make(chan struct{}, 1)}
+			publisher := &recordPublisher{fail: tt.fail}
+			// the next round starts after an hour, so only the first round runs
+			relay := outbox.NewRelay(repo, publisher, time.Hour, 0, 5)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				relay.Run(ctx)
+				close(done)
+			}()
+
+			select {
+			case <-repo.idle:
+			case <-time.After(5 * time.Second):
+				t.Fatal("the relay didn't finish the round")
+			}
+			cancel()
+			<-done
+
+			if repo.calls != tt.calls {
+				t.Errorf("expected %d batches, got %d", tt.calls, repo.calls)
+			}
+			if repo.calls > 0 && repo.maxAttempts != 5 {
+				t.Errorf("expected max attempts 5, got %d", repo.maxAttempts)
+			}
+
+			published := publisher.published
+			if len(published) != len(tt.expected) {
+				t.Fatalf("expected events %v, got %v", tt.expected, published)
+			}
+			for i, id := range tt.expected {
+				event := published[i]
+				if event.ID != id {
+					t.Errorf("expected event %d at %d, got %d", id, i, event.ID)
+				}
+				if string(event.Data.(json.RawMessage)) != `{"id":1}` {
+					t.Errorf("expected the payload of the event, got %v", event.Data)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"subscriptions/rest-service/internal/health"
+	"time"
+)
+
+// Config holds the limits of the limiter, nil limits are not applied.
+type Config struct {
+	// IP limits the requests from one address.
+	IP *Limit
+	// Client limits the requests of one API key or user.
+	Client *Limit
+	// Routes limit the requests of one client to the route.
+	Routes map[string]Limit
+}
+
+// Limiter applies the configured limits to the requests.
+type Limiter struct {
+	store  Store
+	config Config
+	// idle is how long unused buckets are kept, they are full afterwards.
+	idle time.Duration
+}
+
+func NewLimiter(store Store, config Config) *Limiter {
+	var idle time.Duration
+	for _, limit := range config.Routes {
+		idle = max(idle, limit.Window())
+	}
+	if config.IP != nil {
+		idle = max(idle, config.IP.Window())
+	}
+	if config.Client != nil {
+		idle = max(idle, config.Client.Window())
+	}
+
+	return &Limiter{
+		store:  store,
+		config: config,
+		idle:   idle,
+	}
+}
+
+// TakeIP takes a token of the address, the result is nil if the address isn't limited.
+func (l *Limiter) TakeIP(ctx context.Context, ip string) *Result {
+	if l.config.IP == nil {
+		return nil
+	}
+
+	return l.take(ctx, "ip:"+ip, *l.config.IP)
+}
+
+// TakeClient takes a token of the client and of its limit on the route and returns
+// the stricter result, nil if neither is limited. A request rejected by the route limit
+// gets its client token back, so a busy route doesn't throttle the other routes.
+func (l *Limiter) TakeClient(ctx context.Context, client, route string) *Result {
+	var result *Result
+
+	clientKey := "client:" + client
+	if l.config.Client != nil {
+		result = l.take(ctx, clientKey, *l.config.Client)
+		if result != nil && !result.Allowed {
+			return result
+		}
+	}
+
+	if limit, ok := l.config.Routes[route]; ok {
+		routeResult := l.take(ctx, "route:"+route+":"+client, limit)
+		if routeResult != nil && !routeResult.Allowed && result != nil {
+			l.refund(ctx, clientKey, *l.config.Client)
+		}
+		result = Stricter(result, routeResult)
+	}
+
+	return result
+}
+
+// take lets the request in when the store fails, the service stays available
+// without the limits.
+func (l *Limiter) take(ctx context.Context, key string, limit Limit) *Result {
+	result, err := l.store.Take(ctx, key, limit)
+	if err != nil {
+		slog.WarnContext(ctx, "rate limit store failed, request allowed", "error", err)
+		return nil
+	}
+
+	return &result
+}
+
+// refund puts back the token of the bucket, a failure only logs since the bucket refills anyway.
+func (l *Limiter) refund(ctx context.Context, key string, limit Limit) {
+	if err := l.store.Refund(ctx, key, limit); err != nil {
+		slog.WarnContext(ctx, "rate limit store failed, token not refunded", "error", err)
+	}
+}
+
+// Run removes unused buckets from the store until ctx is cancelled.
+func (l *Limiter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// the first prune is due after the interval
+	health.Beat(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := l.store.Prune(ctx, time.Now().Add(-l.idle))
+		if err != nil {
+			slog.Error("prune rate limit buckets failed", "error", err)
+		}
+		health.Update(ctx, err)
+	}
+}
+
+// Stricter returns the result that is rejected or has less remaining requests, nil results are ignored.
+func Stricter(a, b *Result) *Result {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.Allowed != b.Allowed:
+		if !a.Allowed {
+			return a
+		}
+		return b
+	case !a.Allowed:
+		if a.RetryAfter >= b.RetryAfter {
+			return a
+		}
+		return b
+	case a.Remaining <= b.Remaining:
+		return a
+	default:
+		return b
+	}
+}
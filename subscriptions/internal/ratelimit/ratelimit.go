@@ -0,0 +1,113 @@
+/*
+Package ratelimit limits the request rate of clients with token buckets kept
+in a pluggable store.
+*/
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Burst requests at once, refilled at Rate requests per second.
+type Limit struct {
+	Burst int
+	Rate  float64
+}
+
+// ParseLimit parses the limit "<requests>/<period>", e.g. "100/1m".
+func ParseLimit(value string) (Limit, error) {
+	count, period, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected <requests>/<period>", value)
+	}
+
+	burst, err := strconv.Atoi(count)
+	if err != nil || burst <= 0 {
+		return Limit{}, fmt.Errorf("invalid number of requests in rate limit %q", value)
+	}
+
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return Limit{}, fmt.Errorf("invalid period in rate limit %q", value)
+	}
+
+	return Limit{
+		Burst: burst,
+		Rate:  float64(burst) / duration.Seconds(),
+	}, nil
+}
+
+// ParseRouteLimits parses the comma separated limits "<method> <route>=<limit>",
+// e.g. "GET /api/v1/subs/sub_sum=10/1m". Routes are gin route patterns or gRPC full method names.
+func ParseRouteLimits(value string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		route, limitValue, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid route rate limit %q, expected <route>=<limit>", item)
+		}
+
+		limit, err := ParseLimit(limitValue)
+		if err != nil {
+			return nil, err
+		}
+
+		limits[strings.Join(strings.Fields(route), " ")] = limit
+	}
+
+	return limits, nil
+}
+
+// Window is how long an empty bucket takes to become full.
+func (l Limit) Window() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+// Result is the state of the bucket after taking a token.
+type Result struct {
+	Allowed bool
+	Limit   Limit
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// Reset is how long the bucket takes to become full again.
+	Reset time.Duration
+	// RetryAfter is how long a rejected request should wait for a token.
+	RetryAfter time.Duration
+}
+
+// NewResult builds the result from the tokens left in the bucket.
+func NewResult(limit Limit, tokens float64, allowed bool) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: max(int(math.Floor(tokens)), 0),
+		Reset:     time.Duration((float64(limit.Burst) - tokens) / limit.Rate * float64(time.Second)),
+	}
+
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+	}
+
+	return result
+}
+
+// Store keeps the token buckets.
+type Store interface {
+	// Take takes a token from the bucket of the key if there is one.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Refund puts back a token taken from the bucket of the key, up to the burst.
+	Refund(ctx context.Context, key string, limit Limit) error
+	// Prune removes the buckets not used since before, which are full by then.
+	Prune(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,373 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"subscriptions/rest-service/internal/ratelimit"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	os.Exit(m.Run())
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		limit ratelimit.Limit
+		ok    bool
+	}{
+		{name: "per minute", value: "120/1m", limit: ratelimit.Limit{Burst: 120, Rate: 2}, ok: true},
+		{name: "spaces", value: " 10/1s ", limit: ratelimit.Limit{Burst: 10, Rate: 10}, ok: true},
+		{name: "no period", value: "10", ok: false},
+		{name: "zero requests", value: "0/1m", ok: false},
+		{name: "negative requests", value: "-1/1m", ok: false},
+		{name: "invalid period", value: "10/minute", ok: false},
+		{name: "zero period", value: "10/0s", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, err := ratelimit.ParseLimit(tt.value)
+			if (err == nil) != tt.ok {
+				t.Fatalf("expected ok %v, got error %v", tt.ok, err)
+			}
+			if limit != tt.limit {
+				t.Errorf("expected limit %+v, got %+v", tt.limit, limit)
+			}
+		})
+	}
+}
+
+func TestParseRouteLimits(t *testing.T) {
+	limits, err := ratelimit.ParseRouteLimits("GET  /api/v1/subs/sub_sum=10/1m, /subscriptions.v1.Subscriptions/List=5/1s,")
+	if err != nil {
+		t.Fatalf("parse route limits: %v", err)
+	}
+
+	expected := map[string]ratelimit.Limit{
+		"GET /api/v1/subs/sub_sum":             {Burst: 10, Rate: 10.0 / 60},
+		"/subscriptions.v1.Subscriptions/List": {Burst: 5, Rate: 5},
+	}
+	if len(limits) != len(expected) {
+		t.Fatalf("expected limits %v, got %v", expected, limits)
+	}
+	for route, limit := range expected {
+		if limits[route] != limit {
+			t.Errorf("expected limit %+v of %q, got %+v", limit, route, limits[route])
+		}
+	}
+
+	if _, err := ratelimit.ParseRouteLimits("GET /api/v1/subs"); err == nil {
+		t.Error("expected error for the route without limit")
+	}
+}
+
+func TestLimitWindow(t *testing.T) {
+	if window := (ratelimit.Limit{Burst: 60, Rate: 1}).Window(); window != time.Minute {
+		t.Errorf("expected window 1m, got %v", window)
+	}
+}
+
+func TestStricter(t *testing.T) {
+	allowed := func(remaining int) *ratelimit.Result {
+		return &ratelimit.Result{Allowed: true, Remaining: remaining}
+	}
+	rejected := func(retryAfter time.Duration) *ratelimit.Result {
+		return &ratelimit.Result{RetryAfter: retryAfter}
+	}
+
+	tests := []struct {
+		name     string
+		a, b     *ratelimit.Result
+		expected int
+	}{
+		{name: "both nil", expected: -1},
+		{name: "first nil", b: allowed(1), expected: 1},
+		{name: "second nil", a: allowed(1), expected: 0},
+		{name: "first rejected", a: rejected(time.Second), b: allowed(0), expected: 0},
+		{name: "second rejected", a: allowed(0), b: rejected(time.Second), expected: 1},
+		{name: "both rejected, first waits longer", a: rejected(2 * time.Second), b: rejected(time.Second), expected: 0},
+		{name: "both rejected, second waits longer", a: rejected(time.Second), b: rejected(2 * time.Second), expected: 1},
+		{name: "both allowed, first has less", a: allowed(1), b: allowed(2), expected: 0},
+		{name: "both allowed, second has less", a: allowed(2), b: allowed(1), expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ratelimit.Stricter(tt.a, tt.b)
+
+			var expected *ratelimit.Result
+			switch tt.expected {
+			case 0:
+				expected = tt.a
+			case 1:
+				expected = tt.b
+			}
+			if result != expected {
+				t.Errorf("expected %+v, got %+v", expected, result)
+			}
+		})
+	}
+}
+
+// slow is a limit which doesn't refill noticeably while a test runs.
+var slow = ratelimit.Limit{Burst: 2, Rate: 2.0 / 3600}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// run takes the tokens of the key "key" and returns the result of the last take
+		run       func(t *testing.T, store *ratelimit.MemoryStore) ratelimit.Result
+		allowed   bool
+		remaining int
+	}{
+		{
+			name: "first take",
+			run: func(t *testing.T, store *ratelimit.MemoryStore) ratelimit.Result {
+				return take(t, store, "key", slow)
+			},
+			allowed:   true,
+			remaining: 1,
+		},
+		{
+			name: "burst exhausted",
+			run: func(t *testing.T, store *ratelimit.MemoryStore) ratelimit.Result {
+				take(t, store, "key", slow)
+				take(t, store, "key", slow)
+				return take(t, store, "key", slow)
+			},
+			allowed:   false,
+			remaining: 0,
+		},
+		{
+			name: "other keys don't share the bucket",
+			run: func(t *testing.T, store *ratelimit.MemoryStore) ratelimit.Result {
+				take(t, store, "other", slow)
+				take(t, store, "other", slow)
+				return take(t, store, "key", slow)
+			},
+			allowed:   true,
+			remaining: 1,
+		},
+		{
+			name: "refund returns the token",
+			run: func(t *testing.T, store *ratelimit.MemoryStore) ratelimit.Result {
+				take(t, store, "key", slow)
+				take(t, store, "key", slow)
+				if err := store.Refund(ctx, "key", slow); err != nil {
+					t.Fatalf("refund: %v", err)
+				}
+				return take(t, store, "key", slow)
+			},
+			allowed:   true,
+			remaining: 0,
+		},
+		{
+			name: "refund doesn't exceed the burst",
+			run: func(t *testing.T, store *ratelimit.MemoryStore) ratelimit.Result {
+				take(t, store, "key", slow)
+				for range 3 {
+					if err := store.Refund(ctx, "key", slow); err != nil {
+						t.Fatalf("refund: %v", err)
+					}
+				}
+				take(t, store, "key", slow)
+				return take(t, store, "key", slow)
+			},
+			allowed:   true,
+			remaining: 0,
+		},
+		{
+			name: "pruned bucket is full",
+			run: func(t *testing.T, store *ratelimit.MemoryStore) ratelimit.Result {
+				take(t, store, "key", slow)
+				take(t, store, "key", slow)
+				if err := store.Prune(ctx, time.Now().Add(time.Second)); err != nil {
+					t.Fatalf("prune: %v", err)
+				}
+				return take(t, store, "key", slow)
+			},
+			allowed:   true,
+			remaining: 1,
+		},
+		{
+			name: "fast limit refills",
+			run: func(t *testing.T, store *ratelimit.MemoryStore) ratelimit.Result {
+				fast := ratelimit.Limit{Burst: 1, Rate: 1000}
+				take(t, store, "key", fast)
+				time.Sleep(5 * time.Millisecond)
+				return take(t, store, "key", fast)
+			},
+			allowed:   true,
+			remaining: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.run(t, ratelimit.NewMemoryStore())
+			if result.Allowed != tt.allowed || result.Remaining != tt.remaining {
+				t.Errorf("expected allowed %v with %d remaining, got %+v", tt.allowed, tt.remaining, result)
+			}
+			if !result.Allowed && result.RetryAfter <= 0 {
+				t.Errorf("expected retry after of the rejected request, got %+v", result)
+			}
+		})
+	}
+}
+
+func take(t *testing.T, store ratelimit.Store, key string, limit ratelimit.Limit) ratelimit.Result {
+	t.Helper()
+
+	result, err := store.Take(context.Background(), key, limit)
+	if err != nil {
+		t.Fatalf("take %q: %v", key, err)
+	}
+
+	return result
+}
+
+// failingStore fails every call, the limiter must let the requests in.
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store is down")
+}
+
+func (failingStore) Refund(context.Context, string, ratelimit.Limit) error {
+	return errors.New("store is down")
+}
+
+func (failingStore) Prune(context.Context, time.Time) error {
+	return errors.New("store is down")
+}
+
+func TestLimiterTakeClient(t *testing.T) {
+	const route = "GET /api/v1/subs"
+	client := &ratelimit.Limit{Burst: 3, Rate: slow.Rate}
+
+	tests := []struct {
+		name   string
+		config ratelimit.Config
+		// requests are the routes requested in turn by the client
+		requests []string
+		// allowed are the expected results of the requests, a nil result counts as allowed
+		allowed []bool
+		// clientRemaining is the number of the client tokens left after the requests
+		clientRemaining int
+	}{
+		{
+			name:            "no limits",
+			requests:        []string{route, route},
+			allowed:         []bool{true, true},
+			clientRemaining: -1,
+		},
+		{
+			name:            "client limit",
+			config:          ratelimit.Config{Client: client},
+			requests:        []string{route, route, route, route},
+			allowed:         []bool{true, true, true, false},
+			clientRemaining: 0,
+		},
+		{
+			name:            "route limit only",
+			config:          ratelimit.Config{Routes: map[string]ratelimit.Limit{route: slow}},
+			requests:        []string{route, route, route, "GET /api/v1/users"},
+			allowed:         []bool{true, true, false, true},
+			clientRemaining: -1,
+		},
+		{
+			name: "route rejection refunds the client token",
+			config: ratelimit.Config{
+				Client: client,
+				Routes: map[string]ratelimit.Limit{route: {Burst: 1, Rate: slow.Rate}},
+			},
+			requests:        []string{route, route, route},
+			allowed:         []bool{true, false, false},
+			clientRemaining: 2,
+		},
+		{
+			name: "other routes keep the client tokens",
+			config: ratelimit.Config{
+				Client: client,
+				Routes: map[string]ratelimit.Limit{route: {Burst: 1, Rate: slow.Rate}},
+			},
+			requests:        []string{route, route, "GET /api/v1/users", "GET /api/v1/users", "GET /api/v1/users"},
+			allowed:         []bool{true, false, true, true, false},
+			clientRemaining: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := ratelimit.NewMemoryStore()
+			limiter := ratelimit.NewLimiter(store, tt.config)
+
+			for i, request := range tt.requests {
+				result := limiter.TakeClient(ctx, "key-1", request)
+				if allowed := result == nil || result.Allowed; allowed != tt.allowed[i] {
+					t.Fatalf("request %d to %q: expected allowed %v, got %+v", i, request, tt.allowed[i], result)
+				}
+			}
+
+			if tt.config.Client == nil {
+				return
+			}
+			// one more take reveals the tokens left
+			result := take(t, store, "client:key-1", *tt.config.Client)
+			left := 0
+			if result.Allowed {
+				left = result.Remaining + 1
+			}
+			if left != tt.clientRemaining {
+				t.Errorf("expected %d client tokens left, got %d", tt.clientRemaining, left)
+			}
+		})
+	}
+}
+
+func TestLimiterTakeIP(t *testing.T) {
+	ctx := context.Background()
+
+	if result := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{}).TakeIP(ctx, "10.0.0.1"); result != nil {
+		t.Errorf("expected no result without the IP limit, got %+v", result)
+	}
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{IP: &ratelimit.Limit{Burst: 1, Rate: slow.Rate}})
+	if result := limiter.TakeIP(ctx, "10.0.0.1"); result == nil || !result.Allowed {
+		t.Fatalf("expected the first request allowed, got %+v", result)
+	}
+	if result := limiter.TakeIP(ctx, "10.0.0.1"); result == nil || result.Allowed {
+		t.Fatalf("expected the second request rejected, got %+v", result)
+	}
+	if result := limiter.TakeIP(ctx, "10.0.0.2"); result == nil || !result.Allowed {
+		t.Fatalf("expected the request of another address allowed, got %+v", result)
+	}
+}
+
+func TestLimiterFailingStore(t *testing.T) {
+	limiter := ratelimit.NewLimiter(failingStore{}, ratelimit.Config{
+		IP:     &slow,
+		Client: &slow,
+		Routes: map[string]ratelimit.Limit{"GET /api/v1/subs": slow},
+	})
+
+	ctx := context.Background()
+	if result := limiter.TakeIP(ctx, "10.0.0.1"); result != nil {
+		t.Errorf("expected the request allowed without result, got %+v", result)
+	}
+	if result := limiter.TakeClient(ctx, "key-1", "GET /api/v1/subs"); result != nil {
+		t.Errorf("expected the request allowed without result, got %+v", result)
+	}
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/tenant"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepo interface {
+	GetAPIKeys(ctx context.Context, userID *uuid.UUID) ([]models.APIKey, error)
+	GetAPIKey(ctx context.Context, id uint) (*models.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	CreateAPIKey(ctx context.Context, key models.APIKey) (*uint, error)
+	RevokeAPIKey(ctx context.Context, id uint) error
+	RotateAPIKey(ctx context.Context, id uint, prefix, hash string) error
+	TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error
+}
+
+type APIKeyRepository struct {
+	DB *gorm.DB
+}
+
+func NewAPIKeyRepository(database *gorm.DB) APIKeyRepo {
+	return &APIKeyRepository{
+		DB: database,
+	}
+}
+
+func (r *APIKeyRepository) GetAPIKeys(ctx context.Context, userID *uuid.UUID) ([]models.APIKey, error) {
+	defer metrics.ObserveQuery("api_key", "GetAPIKeys")()
+
+	var keys []models.APIKey
+
+	query := r.DB.WithContext(ctx).Order("id")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	if err := query.Find(&keys).Error; err != nil {
+		slog.ErrorContext(ctx, "get api keys failed", "error", err)
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepository) GetAPIKey(ctx context.Context, id uint) (*models.APIKey, error) {
+	defer metrics.ObserveQuery("api_key", "GetAPIKey")()
+
+	var key models.APIKey
+
+	if err := r.DB.WithContext(ctx).Take(&key, id).Error; err != nil {
+		slog.ErrorContext(ctx, "get api key failed", "error", err)
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &key, nil
+}
+
+// GetAPIKeyByPrefix looks the key up across tenants, since the key is what tells the
+// tenant of the request.
+func (r *APIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	defer metrics.ObserveQuery("api_key", "GetAPIKeyByPrefix")()
+
+	var key models.APIKey
+
+	if err := r.DB.WithContext(tenant.Unscoped(ctx)).Where("prefix = ?", prefix).Take(&key).Error; err != nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key models.APIKey) (*uint, error) {
+	defer metrics.ObserveQuery("api_key", "CreateAPIKey")()
+
+	if err := r.DB.WithContext(ctx).Omit("id").Create(&key).Error; err != nil {
+		slog.ErrorContext(ctx, "create api key failed", "error", err)
+		return nil, err
+	}
+
+	return &key.ID, nil
+}
+
+// RevokeAPIKey marks the key revoked, revoking a revoked key keeps the first revocation time.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id uint) error {
+	defer metrics.ObserveQuery("api_key", "RevokeAPIKey")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var key models.APIKey
+
+		if err := tx.Take(&key, id).Error; err != nil {
+			slog.ErrorContext(ctx, "revoke api key failed", "error", err)
+			return gorm.ErrRecordNotFound
+		}
+
+		if key.RevokedAt != nil {
+			return nil
+		}
+
+		return tx.Model(&key).Update("revoked_at", time.Now()).Error
+	})
+
+	return err
+}
+
+// RotateAPIKey replaces the secret of the active key, the old secret stops working at once.
+func (r *APIKeyRepository) RotateAPIKey(ctx context.Context, id uint, prefix, hash string) error {
+	defer metrics.ObserveQuery("api_key", "RotateAPIKey")()
+
+	result := r.DB.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{"prefix": prefix, "hash": hash, "last_used_at": nil})
+	if result.Error != nil {
+		slog.ErrorContext(ctx, "rotate api key failed", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	defer metrics.ObserveQuery("api_key", "TouchAPIKey")()
+
+	err := r.DB.WithContext(tenant.Unscoped(ctx)).Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "touch api key failed", "error", err)
+	}
+
+	return err
+}
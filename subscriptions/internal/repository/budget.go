@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/pkg/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BudgetRepo interface {
+	GetBudgets(ctx context.Context, userID *uuid.UUID) ([]models.Budget, error)
+	GetBudget(ctx context.Context, id uint) (*models.Budget, error)
+	CreateBudget(ctx context.Context, userID uuid.UUID, categoryID *uint, serviceName *string, monthlyLimit uint) (*uint, error)
+	UpdateBudget(ctx context.Context, id uint, categoryID *uint, serviceName *string, monthlyLimit uint) error
+	DeleteBudget(ctx context.Context, id uint) error
+}
+
+type BudgetRepository struct {
+	DB *gorm.DB
+}
+
+func NewBudgetRepository(database *gorm.DB) BudgetRepo {
+	return &BudgetRepository{
+		DB: database,
+	}
+}
+
+func (r *BudgetRepository) GetBudgets(ctx context.Context, userID *uuid.UUID) ([]models.Budget, error) {
+	defer metrics.ObserveQuery("budget", "GetBudgets")()
+	ctx = database.ReadOnly(ctx)
+
+	var budgets []models.Budget
+
+	query := r.DB.WithContext(ctx).Order("id")
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	if err := query.Find(&budgets).Error; err != nil {
+		slog.ErrorContext(ctx, "get budgets failed", "error", err)
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+func (r *BudgetRepository) GetBudget(ctx context.Context, id uint) (*models.Budget, error) {
+	defer metrics.ObserveQuery("budget", "GetBudget")()
+	ctx = database.ReadOnly(ctx)
+
+	var budget models.Budget
+
+	if err := r.DB.WithContext(ctx).Take(&budget, id).Error; err != nil {
+		slog.ErrorContext(ctx, "get budget failed", "error", err)
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &budget, nil
+}
+
+func (r *BudgetRepository) CreateBudget(ctx context.Context, userID uuid.UUID, categoryID *uint, serviceName *string, monthlyLimit uint) (*uint, error) {
+	defer metrics.ObserveQuery("budget", "CreateBudget")()
+
+	budget := models.Budget{
+		UserID:       userID,
+		CategoryID:   categoryID,
+		ServiceName:  serviceName,
+		MonthlyLimit: monthlyLimit,
+	}
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkCategory(tx, categoryID); err != nil {
+			return err
+		}
+
+		return tx.Create(&budget).Error
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "create budget failed", "error", err)
+		return nil, err
+	}
+
+	return &budget.ID, nil
+}
+
+func (r *BudgetRepository) UpdateBudget(ctx context.Context, id uint, categoryID *uint, serviceName *string, monthlyLimit uint) error {
+	defer metrics.ObserveQuery("budget", "UpdateBudget")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var budget models.Budget
+
+		if err := tx.Take(&budget, id).Error; err != nil {
+			slog.ErrorContext(ctx, "update budget failed", "error", err)
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := checkCategory(tx, categoryID); err != nil {
+			return err
+		}
+
+		budget.CategoryID = categoryID
+		budget.ServiceName = serviceName
+		budget.MonthlyLimit = monthlyLimit
+
+		if err := tx.Save(&budget).Error; err != nil {
+			slog.ErrorContext(ctx, "update budget failed", "error", err)
+			return err
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (r *BudgetRepository) DeleteBudget(ctx context.Context, id uint) error {
+	defer metrics.ObserveQuery("budget", "DeleteBudget")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Take(&models.Budget{}, id).Error; err != nil {
+			slog.ErrorContext(ctx, "delete budget failed", "error", err)
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Delete(&models.Budget{}, id).Error
+	})
+
+	return err
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"subscriptions/rest-service/internal/cache"
+	"subscriptions/rest-service/internal/metrics"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// cacheNotifyChannel is the Postgres channel the new versions of the cache scopes are
+// sent to.
+const cacheNotifyChannel = "cache_invalidations"
+
+// setCacheEntrySQL replaces the value of the key, the entries without expiry are the
+// versions of the scopes.
+const setCacheEntrySQL = `
+	INSERT INTO cache_entries (key, value, expires_at)
+	VALUES (@key, @value, @expires_at)
+	ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at`
+
+// CacheRepository keeps the cached values in Postgres, so all instances of the service
+// share the values and see the invalidations of each other.
+type CacheRepository struct {
+	DB *gorm.DB
+}
+
+func NewCacheRepository(database *gorm.DB) cache.Store {
+	return &CacheRepository{
+		DB: database,
+	}
+}
+
+func (r *CacheRepository) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	defer metrics.ObserveQuery("cache", "Get")()
+
+	var entry struct {
+		Value []byte
+	}
+
+	res := r.DB.WithContext(ctx).
+		Raw("SELECT value FROM cache_entries WHERE key = ? AND (expires_at IS NULL OR expires_at > now())", key).
+		Scan(&entry)
+	if res.Error != nil {
+		slog.ErrorContext(ctx, "get cache entry failed", "error", res.Error)
+		return nil, false, res.Error
+	}
+
+	return entry.Value, res.RowsAffected > 0, nil
+}
+
+func (r *CacheRepository) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	defer metrics.ObserveQuery("cache", "Set")()
+
+	var expiresAt *time.Time
+	if ttl > 0 {
+		expires := time.Now().Add(ttl)
+		expiresAt = &expires
+	}
+
+	err := r.DB.WithContext(ctx).Exec(setCacheEntrySQL, map[string]any{
+		"key":        key,
+		"value":      value,
+		"expires_at": expiresAt,
+	}).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "set cache entry failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *CacheRepository) Prune(ctx context.Context, before time.Time) error {
+	defer metrics.ObserveQuery("cache", "Prune")()
+
+	if err := r.DB.WithContext(ctx).Exec("DELETE FROM cache_entries WHERE expires_at < ?", before).Error; err != nil {
+		slog.ErrorContext(ctx, "prune cache entries failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// cacheInvalidation is the payload of the notifications of the new scope versions.
+type cacheInvalidation struct {
+	TenantID string `json:"tenant_id"`
+	Scope    string `json:"scope"`
+	Version  string `json:"version"`
+}
+
+// CacheBroadcaster passes the new versions of the cache scopes between the instances
+// through Postgres notifications.
+type CacheBroadcaster struct {
+	DB *gorm.DB
+}
+
+func NewCacheBroadcaster(database *gorm.DB) cache.Broadcaster {
+	return &CacheBroadcaster{
+		DB: database,
+	}
+}
+
+func (b *CacheBroadcaster) Broadcast(ctx context.Context, tenantID, scope, version string) error {
+	defer metrics.ObserveQuery("cache", "Broadcast")()
+
+	payload, err := json.Marshal(cacheInvalidation{TenantID: tenantID, Scope: scope, Version: version})
+	if err != nil {
+		return err
+	}
+
+	err = b.DB.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", cacheNotifyChannel, string(payload)).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "notify cache listeners failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (b *CacheBroadcaster) Listen(ctx context.Context, ready func(), handle func(tenantID, scope, version string)) error {
+	return listen(ctx, b.DB, cacheNotifyChannel, ready, func(payload string) {
+		var invalidation cacheInvalidation
+		if err := json.Unmarshal([]byte(payload), &invalidation); err != nil {
+			slog.WarnContext(ctx, "listen for cache invalidations failed", "error", err)
+			return
+		}
+		handle(invalidation.TenantID, invalidation.Scope, invalidation.Version)
+	})
+}
@@ -0,0 +1,73 @@
+package repository_test
+
+import (
+	"context"
+	"subscriptions/rest-service/internal/cache"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/tenant"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCacheRepositoryInvalidation(t *testing.T) {
+	db := openDB(t)
+	ctx := tenant.WithID(context.Background(), uuid.NewString())
+
+	// the instances share the store
+	a := cache.New(repository.NewCacheRepository(db), time.Minute)
+	b := cache.New(repository.NewCacheRepository(db), time.Minute)
+
+	a.Entry(ctx, "subs", "params", "user:u1").Set(ctx, []int{1})
+
+	var result []int
+	if !b.Entry(ctx, "subs", "params", "user:u1").Get(ctx, &result) {
+		t.Fatal("expected the result cached by a found on b")
+	}
+
+	b.Invalidate(ctx, "user:u1")
+
+	if a.Entry(ctx, "subs", "params", "user:u1").Get(ctx, &result) {
+		t.Error("expected the result invalidated by b missing on a right after the write")
+	}
+}
+
+func TestCacheBroadcaster(t *testing.T) {
+	broadcaster := repository.NewCacheBroadcaster(openDB(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type invalidation struct{ tenantID, scope, version string }
+	ready := make(chan struct{})
+	received := make(chan invalidation, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- broadcaster.Listen(ctx, func() { close(ready) }, func(tenantID, scope, version string) {
+			received <- invalidation{tenantID, scope, version}
+		})
+	}()
+
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("listen: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the listener hasn't started")
+	}
+
+	expected := invalidation{uuid.NewString(), "user:u1", uuid.NewString()}
+	if err := broadcaster.Broadcast(ctx, expected.tenantID, expected.scope, expected.version); err != nil {
+		t.Fatalf("broadcast: %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if got != expected {
+			t.Errorf("expected %+v, got %+v", expected, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the invalidation hasn't been received")
+	}
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryHasChildren = errors.New("category has subcategories")
+	ErrCategoryCycle       = errors.New("category cannot be moved under itself or its subcategory")
+)
+
+type CategoryRepo interface {
+	GetCategories(ctx context.Context) ([]models.Category, error)
+	GetCategory(ctx context.Context, id uint) (*models.Category, error)
+	CreateCategory(ctx context.Context, name string, parentID *uint) (*uint, error)
+	UpdateCategory(ctx context.Context, id uint, name string, parentID *uint) error
+	DeleteCategory(ctx context.Context, id uint) error
+}
+
+type CategoryRepository struct {
+	DB *gorm.DB
+}
+
+func NewCategoryRepository(database *gorm.DB) CategoryRepo {
+	return &CategoryRepository{
+		DB: database,
+	}
+}
+
+func (r *CategoryRepository) GetCategories(ctx context.Context) ([]models.Category, error) {
+	defer metrics.ObserveQuery("category", "GetCategories")()
+	ctx = database.ReadOnly(ctx)
+
+	var categories []models.Category
+
+	if err := r.DB.WithContext(ctx).Order("id").Find(&categories).Error; err != nil {
+		slog.ErrorContext(ctx, "get categories failed", "error", err)
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (r *CategoryRepository) GetCategory(ctx context.Context, id uint) (*models.Category, error) {
+	defer metrics.ObserveQuery("category", "GetCategory")()
+	ctx = database.ReadOnly(ctx)
+
+	var category models.Category
+
+	if err := r.DB.WithContext(ctx).Take(&category, id).Error; err != nil {
+		slog.ErrorContext(ctx, "get category failed", "error", err)
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &category, nil
+}
+
+func (r *CategoryRepository) CreateCategory(ctx context.Context, name string, parentID *uint) (*uint, error) {
+	defer metrics.ObserveQuery("category", "CreateCategory")()
+
+	category := models.Category{
+		Name:     name,
+		ParentID: parentID,
+	}
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkCategory(tx, parentID); err != nil {
+			return err
+		}
+
+		return tx.Create(&category).Error
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "create category failed", "error", err)
+		return nil, err
+	}
+
+	return &category.ID, nil
+}
+
+func (r *CategoryRepository) UpdateCategory(ctx context.Context, id uint, name string, parentID *uint) error {
+	defer metrics.ObserveQuery("category", "UpdateCategory")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var category models.Category
+
+		if err := tx.Take(&category, id).Error; err != nil {
+			slog.ErrorContext(ctx, "update category failed", "error", err)
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := checkCategory(tx, parentID); err != nil {
+			return err
+		}
+
+		if parentID != nil {
+			var inSubtree int64
+			if err := tx.Raw("SELECT COUNT(*) FROM ("+categoryTreeSQL+") AS subtree WHERE id = ?", id, category.TenantID, *parentID).
+				Scan(&inSubtree).Error; err != nil {
+				slog.ErrorContext(ctx, "update category failed", "error", err)
+				return err
+			}
+
+			if inSubtree > 0 {
+				return ErrCategoryCycle
+			}
+		}
+
+		category.Name = name
+		category.ParentID = parentID
+
+		if err := tx.Save(&category).Error; err != nil {
+			slog.ErrorContext(ctx, "update category failed", "error", err)
+			return err
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (r *CategoryRepository) DeleteCategory(ctx context.Context, id uint) error {
+	defer metrics.ObserveQuery("category", "DeleteCategory")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Take(&models.Category{}, id).Error; err != nil {
+			slog.ErrorContext(ctx, "delete category failed", "error", err)
+			return gorm.ErrRecordNotFound
+		}
+
+		var children int64
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			slog.ErrorContext(ctx, "delete category failed", "error", err)
+			return err
+		}
+
+		if children > 0 {
+			return ErrCategoryHasChildren
+		}
+
+		tenantID, err := tenantFromContext(ctx)
+		if err != nil {
+			return err
+		}
+		// the foreign key uncategorizes the subscriptions of the category
+		if err := uncategorizeCharges(tx, tenantID, id); err != nil {
+			slog.ErrorContext(ctx, "delete category failed", "error", err)
+			return err
+		}
+
+		return tx.Delete(&models.Category{}, id).Error
+	})
+
+	return err
+}
@@ -0,0 +1,211 @@
+package repository_test
+
+import (
+	"errors"
+	"subscriptions/rest-service/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestCategoryNames(t *testing.T) {
+	db := openDB(t)
+	categoryRepo := repository.NewCategoryRepository(db)
+	ctx := newTenant(t, db)
+
+	name, other := uniqueName("Streaming"), uniqueName("Cloud")
+
+	// ids are the created categories by the names of the tests
+	ids := map[string]uint{}
+
+	tests := []struct {
+		name     string
+		category string
+		// parent is the test that created the parent, a root category if empty
+		parent string
+		err    error
+	}{
+		{name: "root", category: name},
+		{name: "duplicate root", category: name, err: gorm.ErrDuplicatedKey},
+		{name: "other root", category: other},
+		{name: "child with the name of the root", category: name, parent: "root"},
+		{name: "duplicate child", category: name, parent: "root", err: gorm.ErrDuplicatedKey},
+		{name: "same name under another parent", category: name, parent: "other root"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var parentID *uint
+			if tt.parent != "" {
+				id, ok := ids[tt.parent]
+				if !ok {
+					t.Fatalf("parent %q wasn't created", tt.parent)
+				}
+				parentID = &id
+			}
+
+			id, err := categoryRepo.CreateCategory(ctx, tt.category, parentID)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if err == nil {
+				ids[tt.name] = *id
+			}
+		})
+	}
+
+	t.Run("rename to the name of a sibling", func(t *testing.T) {
+		if err := categoryRepo.UpdateCategory(ctx, ids["other root"], name, nil); !errors.Is(err, gorm.ErrDuplicatedKey) {
+			t.Errorf("expected error %v, got %v", gorm.ErrDuplicatedKey, err)
+		}
+	})
+
+	t.Run("same root in another tenant", func(t *testing.T) {
+		if _, err := categoryRepo.CreateCategory(newTenant(t, db), name, nil); err != nil {
+			t.Errorf("create the root of another tenant: %v", err)
+		}
+	})
+}
+
+func TestCategoryTree(t *testing.T) {
+	db := openDB(t)
+	categoryRepo := repository.NewCategoryRepository(db)
+	ctx := newTenant(t, db)
+
+	create := func(name string, parentID *uint) uint {
+		t.Helper()
+
+		id, err := categoryRepo.CreateCategory(ctx, uniqueName(name), parentID)
+		if err != nil {
+			t.Fatalf("create category: %v", err)
+		}
+		return *id
+	}
+
+	root := create("Media", nil)
+	child := create("Video", &root)
+	grandchild := create("Streaming", &child)
+	other := create("Cloud", nil)
+
+	tests := []struct {
+		name   string
+		id     uint
+		parent *uint
+		err    error
+	}{
+		{name: "under itself", id: root, parent: &root, err: repository.ErrCategoryCycle},
+		{name: "under its child", id: root, parent: &child, err: repository.ErrCategoryCycle},
+		{name: "under its grandchild", id: root, parent: &grandchild, err: repository.ErrCategoryCycle},
+		{name: "under another tree", id: child, parent: &other},
+		{name: "back under the root", id: child, parent: &root},
+		{name: "to the roots", id: grandchild},
+		{name: "missing parent", id: grandchild, parent: new(uint), err: gorm.ErrForeignKeyViolated},
+		{name: "missing category", id: 0, err: gorm.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := categoryRepo.UpdateCategory(ctx, tt.id, uniqueName("Renamed"), tt.parent)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+
+	t.Run("delete with children", func(t *testing.T) {
+		if err := categoryRepo.DeleteCategory(ctx, root); !errors.Is(err, repository.ErrCategoryHasChildren) {
+			t.Errorf("expected error %v, got %v", repository.ErrCategoryHasChildren, err)
+		}
+		if err := categoryRepo.DeleteCategory(ctx, child); err != nil {
+			t.Fatalf("delete the leaf: %v", err)
+		}
+		if err := categoryRepo.DeleteCategory(ctx, root); err != nil {
+			t.Errorf("delete the root without children: %v", err)
+		}
+	})
+}
+
+func TestSubsFilter(t *testing.T) {
+	db := openDB(t)
+	subsRepo := repository.NewRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	ctx := newTenant(t, db)
+
+	rootID, err := categoryRepo.CreateCategory(ctx, uniqueName("Media"), nil)
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	childID, err := categoryRepo.CreateCategory(ctx, uniqueName("Video"), rootID)
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	tag := uuid.NewString()
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	subs := []struct {
+		categoryID *uint
+		tags       []string
+		price      uint
+	}{
+		{categoryID: rootID, tags: []string{tag}, price: 100},
+		// the tags are normalized
+		{categoryID: childID, tags: []string{"  " + tag + "  ", "Other " + tag}, price: 10},
+		{tags: []string{"other " + tag}, price: 1},
+	}
+	ids := make([]uint, len(subs))
+	for i, sub := range subs {
+		id, err := subsRepo.CreateRecord(ctx, "Service", start, sub.price, uuid.New(), &end, sub.categoryID, sub.tags, nil)
+		if err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+		ids[i] = *id
+	}
+
+	tagUpper := "  " + tag + " "
+	otherTag := "OTHER " + tag
+
+	tests := []struct {
+		name   string
+		filter repository.SubsFilter
+		// expected are the indexes of the subscriptions listed
+		expected []int
+		// sum is the sum for the category of the filter from January to March
+		sum uint
+	}{
+		{name: "root category with its subcategories", filter: repository.SubsFilter{CategoryID: rootID}, expected: []int{0, 1}, sum: 3 * 110},
+		{name: "subcategory", filter: repository.SubsFilter{CategoryID: childID}, expected: []int{1}, sum: 3 * 10},
+		{name: "tag", filter: repository.SubsFilter{Tag: &tagUpper}, expected: []int{0, 1}},
+		{name: "other tag", filter: repository.SubsFilter{Tag: &otherTag}, expected: []int{1, 2}},
+		{name: "category and tag", filter: repository.SubsFilter{CategoryID: childID, Tag: &tagUpper}, expected: []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, _, err := subsRepo.GetRecords(ctx, 0, 100, tt.filter)
+			if err != nil {
+				t.Fatalf("get records: %v", err)
+			}
+
+			if len(records) != len(tt.expected) {
+				t.Fatalf("expected %d subscriptions, got %d", len(tt.expected), len(records))
+			}
+			for i, index := range tt.expected {
+				if records[i].ID != ids[index] {
+					t.Errorf("expected subscription %d at %d, got %d", ids[index], i, records[i].ID)
+				}
+			}
+
+			if tt.filter.CategoryID == nil || tt.filter.Tag != nil {
+				return
+			}
+			sum := subsRepo.GetSubsSum(ctx, nil, nil, tt.filter.CategoryID, "2025-01-01", "2025-04-01")
+			if sum == nil || *sum != tt.sum {
+				t.Errorf("expected sum %d, got %v", tt.sum, sum)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"subscriptions/rest-service/internal/metrics"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// The monthly charges are the sums of the prices of the subscriptions per tenant, month,
+// user, service name and category. They are kept up to date by the writes of the
+// subscriptions and categories in the same transactions, so the sums can be read from
+// them instead of being calculated from the subscriptions. The subscriptions without end
+// are charged forever, so the charges are built until the horizon month, which the
+// reports ending later don't use.
+
+type ChargeRepo interface {
+	// ExtendCharges builds the charges of the months before until, all of them if they
+	// aren't built yet.
+	ExtendCharges(ctx context.Context, until time.Time) error
+	// RebuildCharges builds the charges again, until the horizon or until if it is later.
+	RebuildCharges(ctx context.Context, until time.Time) error
+	// CheckCharges returns the charges differing from the ones calculated from the subscriptions.
+	CheckCharges(ctx context.Context) ([]ChargeDiff, error)
+}
+
+// ChargeDiff is a monthly charge differing from the one calculated from the subscriptions.
+type ChargeDiff struct {
+	TenantID    string
+	Month       string
+	UserID      uuid.UUID
+	ServiceName string
+	CategoryID  *uint
+	Expected    int64
+	Actual      int64
+}
+
+type ChargeRepository struct {
+	DB *gorm.DB
+}
+
+func NewChargeRepository(database *gorm.DB) ChargeRepo {
+	return &ChargeRepository{
+		DB: database,
+	}
+}
+
+// chargesSQL expands the subscriptions into their charges of the months from $1 until
+// $2, the months are the first days of the months the subscriptions are paid for, the
+// end date excluded as in the sums. The conditions may use the parameters from $3.
+func chargesSQL(conditions string) string {
+	return `
+		SELECT tenant_id, month::date AS month, user_id, service_name, category_id, SUM(price)::bigint AS amount
+		FROM subscriptions
+		CROSS JOIN LATERAL generate_series(
+			GREATEST(start_date, $1::date)::timestamp,
+			LEAST(COALESCE(end_date, 'infinity'::date), $2::date) - interval '1 month',
+			interval '1 month'
+		) AS month
+		WHERE start_date < $2::date AND (end_date IS NULL OR end_date > $1::date)` + conditions + `
+		GROUP BY tenant_id, month, user_id, service_name, category_id`
+}
+
+// addChargesSQL adds the charges of chargesSQL multiplied by $3 to the built ones, the
+// conditions may use the parameters from $4.
+func addChargesSQL(conditions string) string {
+	return `
+		INSERT INTO monthly_charges AS c (tenant_id, month, user_id, service_name, category_id, amount)
+		SELECT tenant_id, month, user_id, service_name, category_id, $3 * amount
+		FROM (` + chargesSQL(conditions) + `) charges
+		ON CONFLICT (tenant_id, month, user_id, service_name, category_id)
+		DO UPDATE SET amount = c.amount + EXCLUDED.amount`
+}
+
+// noHorizon is the start of the charges of all months.
+const noHorizon = "-infinity"
+
+// chargesHorizon returns the month the charges are built until, nil if they aren't built.
+// With a locking strength the horizon is locked until the end of the transaction: the
+// writes of the charges share it and building the charges updates it.
+func chargesHorizon(db *gorm.DB, strength string) (*string, error) {
+	var horizon sql.NullString
+
+	rawSQL := `SELECT month::text FROM monthly_charges_horizon`
+	if strength != "" {
+		rawSQL += " FOR " + strength
+	}
+	if err := db.Raw(rawSQL).Scan(&horizon).Error; err != nil {
+		return nil, err
+	}
+
+	if !horizon.Valid {
+		return nil, nil
+	}
+
+	return &horizon.String, nil
+}
+
+// applyCharges adds the charges of the subscription multiplied by sign to the built ones,
+// so a write takes them away with -1 before the change and adds them back with 1 after it.
+func applyCharges(tx *gorm.DB, subscriptionID uint, sign int) error {
+	horizon, err := chargesHorizon(tx, "SHARE")
+	if err != nil {
+		slog.ErrorContext(tx.Statement.Context, "apply charges failed", "error", err)
+		return err
+	}
+	// the subscription is charged when the charges are built
+	if horizon == nil {
+		return nil
+	}
+
+	if err := tx.Exec(addChargesSQL(" AND id = $4"), noHorizon, *horizon, sign, subscriptionID).Error; err != nil {
+		slog.ErrorContext(tx.Statement.Context, "apply charges failed", "error", err)
+		return err
+	}
+
+	err = tx.Exec(`
+		DELETE FROM monthly_charges
+		WHERE amount = 0 AND (tenant_id, user_id) IN (SELECT tenant_id, user_id FROM subscriptions WHERE id = ?)`,
+		subscriptionID,
+	).Error
+	if err != nil {
+		slog.ErrorContext(tx.Statement.Context, "apply charges failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// uncategorizeCharges moves the charges of the category of the tenant to the uncategorized
+// ones, as the foreign key does with the subscriptions when the category is deleted.
+func uncategorizeCharges(tx *gorm.DB, tenantID string, categoryID uint) error {
+	if _, err := chargesHorizon(tx, "SHARE"); err != nil {
+		return err
+	}
+
+	err := tx.Exec(`
+		INSERT INTO monthly_charges AS c (tenant_id, month, user_id, service_name, category_id, amount)
+		SELECT tenant_id, month, user_id, service_name, NULL, amount
+		FROM monthly_charges WHERE tenant_id = ? AND category_id = ?
+		ON CONFLICT (tenant_id, month, user_id, service_name, category_id)
+		DO UPDATE SET amount = c.amount + EXCLUDED.amount`,
+		tenantID, categoryID,
+	).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec(`DELETE FROM monthly_charges WHERE tenant_id = ? AND category_id = ?`, tenantID, categoryID).Error
+}
+
+func (r *ChargeRepository) ExtendCharges(ctx context.Context, until time.Time) error {
+	defer metrics.ObserveQuery("charge", "ExtendCharges")()
+
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		horizon, err := chargesHorizon(tx, "UPDATE")
+		if err != nil {
+			slog.ErrorContext(ctx, "extend charges failed", "error", err)
+			return err
+		}
+
+		untilSQL := until.Format(time.DateOnly)
+		if horizon != nil && *horizon >= untilSQL {
+			return nil
+		}
+
+		from := noHorizon
+		if horizon != nil {
+			from = *horizon
+		}
+
+		return buildCharges(tx, from, untilSQL)
+	})
+}
+
+func (r *ChargeRepository) RebuildCharges(ctx context.Context, until time.Time) error {
+	defer metrics.ObserveQuery("charge", "RebuildCharges")()
+
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		horizon, err := chargesHorizon(tx, "UPDATE")
+		if err != nil {
+			slog.ErrorContext(ctx, "rebuild charges failed", "error", err)
+			return err
+		}
+
+		untilSQL := until.Format(time.DateOnly)
+		if horizon != nil && *horizon > untilSQL {
+			untilSQL = *horizon
+		}
+
+		return buildCharges(tx, noHorizon, untilSQL)
+	})
+}
+
+// buildCharges builds the charges of the months from until, the charges of all months
+// replace the built ones. The horizon must be locked for update.
+func buildCharges(tx *gorm.DB, from, until string) error {
+	if from == noHorizon {
+		if err := tx.Exec(`DELETE FROM monthly_charges`).Error; err != nil {
+			slog.ErrorContext(tx.Statement.Context, "build charges failed", "error", err)
+			return err
+		}
+	}
+
+	if err := tx.Exec(addChargesSQL(""), from, until, 1).Error; err != nil {
+		slog.ErrorContext(tx.Statement.Context, "build charges failed", "error", err)
+		return err
+	}
+
+	if err := tx.Exec(`UPDATE monthly_charges_horizon SET month = ?`, until).Error; err != nil {
+		slog.ErrorContext(tx.Statement.Context, "build charges failed", "error", err)
+		return err
+	}
+
+	slog.InfoContext(tx.Statement.Context, "monthly charges built", "from", from, "until", until)
+	return nil
+}
+
+func (r *ChargeRepository) CheckCharges(ctx context.Context) ([]ChargeDiff, error) {
+	defer metrics.ObserveQuery("charge", "CheckCharges")()
+
+	var rows []struct {
+		TenantID    string
+		Month       string
+		UserID      uuid.UUID
+		ServiceName string
+		CategoryID  sql.NullInt64
+		Expected    int64
+		Actual      int64
+	}
+
+	// the charges and the subscriptions are compared in the same snapshot
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		horizon, err := chargesHorizon(tx, "")
+		if err != nil || horizon == nil {
+			return err
+		}
+
+		return tx.Raw(`
+			SELECT
+				COALESCE(e.tenant_id, c.tenant_id) AS tenant_id,
+				COALESCE(e.month, c.month)::text AS month,
+				COALESCE(e.user_id, c.user_id) AS user_id,
+				COALESCE(e.service_name, c.service_name) AS service_name,
+				COALESCE(e.category_id, c.category_id) AS category_id,
+				COALESCE(e.amount, 0) AS expected,
+				COALESCE(c.amount, 0) AS actual
+			FROM (`+chargesSQL("")+`) e
+			FULL JOIN monthly_charges c ON
+				c.tenant_id = e.tenant_id AND c.month = e.month AND c.user_id = e.user_id AND
+				c.service_name = e.service_name AND COALESCE(c.category_id, 0) = COALESCE(e.category_id, 0)
+			WHERE COALESCE(e.amount, 0) <> COALESCE(c.amount, 0)
+			ORDER BY 1, 2, 3, 4, 5`,
+			noHorizon, *horizon,
+		).Scan(&rows).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		slog.ErrorContext(ctx, "check charges failed", "error", err)
+		return nil, err
+	}
+
+	diffs := make([]ChargeDiff, len(rows))
+	for i, row := range rows {
+		diffs[i] = ChargeDiff{
+			TenantID:    row.TenantID,
+			Month:       row.Month,
+			UserID:      row.UserID,
+			ServiceName: row.ServiceName,
+			Expected:    row.Expected,
+			Actual:      row.Actual,
+		}
+		if row.CategoryID.Valid {
+			categoryID := uint(row.CategoryID.Int64)
+			diffs[i].CategoryID = &categoryID
+		}
+	}
+
+	return diffs, nil
+}
@@ -0,0 +1,221 @@
+package repository_test
+
+import (
+	"context"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/tenant"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func month(value string) time.Time {
+	date, err := time.Parse("2006-01", value)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+// subscription is the part of a subscription the charges depend on.
+type subscription struct {
+	id    uint
+	price uint
+	start time.Time
+	end   *time.Time
+}
+
+type period struct {
+	name       string
+	start, end string
+	// sum is the charge of the subscriptions before the writes
+	sum uint
+}
+
+// expectedSum is the charge of the subscriptions for the months of the period, the end
+// months excluded, calculated month by month.
+func expectedSum(subs []subscription, start, end time.Time) uint {
+	var sum uint
+	for _, sub := range subs {
+		for m := start; m.Before(end); m = m.AddDate(0, 1, 0) {
+			if !m.Before(sub.start) && (sub.end == nil || m.Before(*sub.end)) {
+				sum += sub.price
+			}
+		}
+	}
+	return sum
+}
+
+// resetCharges drops the horizon, so the sums are calculated from the subscriptions until
+// the charges are built again.
+func resetCharges(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	if err := db.Exec("UPDATE monthly_charges_horizon SET month = NULL").Error; err != nil {
+		t.Fatalf("reset charges horizon: %v", err)
+	}
+}
+
+func TestChargesMatchSubscriptions(t *testing.T) {
+	db := openDB(t)
+	ctx := newTenant(t, db)
+	tenantID, _ := tenant.FromContext(ctx)
+	userID := newUser(t, ctx, db)
+	subsRepo := repository.NewRepository(db)
+	chargeRepo := repository.NewChargeRepository(db)
+
+	resetCharges(t, db)
+
+	end := func(value string) *time.Time {
+		date := month(value)
+		return &date
+	}
+	subs := []subscription{
+		{price: 100, start: month("2025-01"), end: end("2025-04")},
+		// without end
+		{price: 200, start: month("2024-11")},
+		// across the year
+		{price: 50, start: month("2025-03"), end: end("2026-02")},
+	}
+	for i := range subs {
+		id, err := subsRepo.CreateRecord(ctx, "Service", subs[i].start, subs[i].price, userID, subs[i].end, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+		subs[i].id = *id
+	}
+
+	periods := []period{
+		{name: "half a year", start: "2025-01", end: "2025-07", sum: 300 + 6*200 + 4*50},
+		{name: "across the years", start: "2024-12", end: "2026-03", sum: 300 + 15*200 + 11*50},
+		{name: "one month", start: "2026-01", end: "2026-02", sum: 200 + 50},
+		{name: "end month of a subscription", start: "2025-04", end: "2025-05", sum: 200 + 50},
+		{name: "before the subscriptions", start: "2024-01", end: "2024-06", sum: 0},
+		{name: "far ahead", start: "2026-06", end: "2026-12", sum: 6 * 200},
+	}
+
+	// checkSums compares the sums of the periods with the ones calculated month by month
+	checkSums := func(t *testing.T, source string) {
+		t.Helper()
+
+		for _, p := range periods {
+			start, end := month(p.start), month(p.end)
+			startSQL, endSQL := start.Format(time.DateOnly), end.Format(time.DateOnly)
+			expected := expectedSum(subs, start, end)
+
+			sum := subsRepo.GetSubsSum(ctx, nil, nil, nil, startSQL, endSQL)
+			if sum == nil {
+				t.Fatalf("%s, %s: get subs sum failed", source, p.name)
+			}
+			if *sum != expected {
+				t.Errorf("%s, %s: expected sum %d, got %d", source, p.name, expected, *sum)
+			}
+
+			months, err := subsRepo.GetSubsSumByMonth(ctx, nil, nil, nil, startSQL, endSQL)
+			if err != nil {
+				t.Fatalf("%s, %s: get subs sum by month: %v", source, p.name, err)
+			}
+			var total uint
+			for _, m := range months {
+				if m.TotalSum != expectedSum(subs, m.Month, m.Month.AddDate(0, 1, 0)) {
+					t.Errorf("%s, %s: unexpected sum %d of %s", source, p.name, m.TotalSum, m.Month.Format("2006-01"))
+				}
+				total += m.TotalSum
+			}
+			if total != expected {
+				t.Errorf("%s, %s: expected the months summing up to %d, got %d", source, p.name, expected, total)
+			}
+		}
+	}
+
+	checkCharges := func(t *testing.T) {
+		t.Helper()
+
+		diffs, err := chargeRepo.CheckCharges(context.Background())
+		if err != nil {
+			t.Fatalf("check charges: %v", err)
+		}
+		for _, diff := range diffs {
+			if diff.TenantID == tenantID {
+				t.Errorf("unexpected charge difference %+v", diff)
+			}
+		}
+	}
+
+	for _, p := range periods {
+		if sum := expectedSum(subs, month(p.start), month(p.end)); sum != p.sum {
+			t.Fatalf("%s: expected the month by month sum %d, got %d", p.name, p.sum, sum)
+		}
+	}
+	checkSums(t, "subscriptions")
+
+	if err := chargeRepo.RebuildCharges(context.Background(), month("2027-01")); err != nil {
+		t.Fatalf("rebuild charges: %v", err)
+	}
+	checkSums(t, "built charges")
+	checkCharges(t)
+
+	// the writes keep the charges up to date
+	subs[2].end = end("2025-06")
+	_, err := subsRepo.FullUpdateRecord(ctx, subs[2].id, subs[2].price, "Service", subs[2].start, userID, subs[2].end, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("update subscription: %v", err)
+	}
+	if _, err := subsRepo.DeleteRecord(ctx, subs[0].id); err != nil {
+		t.Fatalf("delete subscription: %v", err)
+	}
+	subs = subs[1:]
+	id, err := subsRepo.CreateRecord(ctx, "Other service", month("2025-12"), 10, userID, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+	subs = append(subs, subscription{id: *id, price: 10, start: month("2025-12")})
+
+	checkSums(t, "updated charges")
+	checkCharges(t)
+
+	// the charges are extended beyond the horizon for the subscriptions without end
+	if err := chargeRepo.ExtendCharges(context.Background(), month("2027-07")); err != nil {
+		t.Fatalf("extend charges: %v", err)
+	}
+	periods = append(periods, period{name: "extended", start: "2026-12", end: "2027-07"})
+	checkSums(t, "extended charges")
+	checkCharges(t)
+
+	resetCharges(t, db)
+	checkSums(t, "subscriptions after the writes")
+}
+
+func TestChargesOfOtherTenants(t *testing.T) {
+	db := openDB(t)
+	subsRepo := repository.NewRepository(db)
+
+	resetCharges(t, db)
+
+	ctx := newTenant(t, db)
+	other := newTenant(t, db)
+	for _, c := range []struct {
+		ctx   context.Context
+		price uint
+	}{{ctx, 100}, {other, 1000}} {
+		_, err := subsRepo.CreateRecord(c.ctx, "Service", month("2025-01"), c.price, newUser(t, c.ctx, db), nil, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+	}
+
+	if err := repository.NewChargeRepository(db).RebuildCharges(context.Background(), month("2027-01")); err != nil {
+		t.Fatalf("rebuild charges: %v", err)
+	}
+
+	sum := subsRepo.GetSubsSum(ctx, nil, nil, nil, "2025-01-01", "2025-04-01")
+	if sum == nil || *sum != 300 {
+		t.Errorf("expected the sum 300 of the tenant, got %v", sum)
+	}
+
+	sum = subsRepo.GetSubsSum(other, nil, nil, nil, "2025-01-01", "2025-04-01")
+	if sum == nil || *sum != 3000 {
+		t.Errorf("expected the sum 3000 of the other tenant, got %v", sum)
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// listen passes the payloads of the notifications of the channel to handle until ctx is
+// cancelled or the connection fails. ready is called once the listening has started.
+func listen(ctx context.Context, db *gorm.DB, channel string, ready func(), handle func(payload string)) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		pgConn := driverConn.(*stdlib.Conn).Conn()
+		// the connection stays subscribed to the channel, so it must not return to the pool
+		defer pgConn.Close(context.Background())
+
+		if _, err := pgConn.Exec(ctx, "LISTEN "+channel); err != nil {
+			return err
+		}
+		ready()
+
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+			handle(notification.Payload)
+		}
+	})
+}
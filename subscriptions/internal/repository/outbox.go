@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strconv"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// outboxLockKey is the advisory lock making only one relay claim events at a time,
+	// which keeps the events of a subscription in order across instances.
+	outboxLockKey = 7305420981
+
+	// outboxClaimLease is how long the claimed events are held back from the other relays
+	// while they are being published.
+	outboxClaimLease = time.Minute
+
+	outboxBaseBackoff = time.Second
+	outboxMaxBackoff  = 5 * time.Minute
+
+	// outboxStreamLockKey is the advisory lock serializing the stream sequence assignment,
+	// so the sequences become visible in the order they were assigned.
+	outboxStreamLockKey = 7305420982
+
+	// outboxNotifyChannel is the Postgres channel the stream sequences of published
+	// events are sent to.
+	outboxNotifyChannel = "outbox_events"
+)
+
+type OutboxRepo interface {
+	AddEvent(ctx context.Context, eventType events.Type, subscriptionID uint, payload any) error
+	ProcessEvents(ctx context.Context, limit, maxAttempts int, publish func(event models.OutboxEvent) error) (int, error)
+	DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error)
+	GetStreamEvent(ctx context.Context, seq uint64) (*models.OutboxEvent, error)
+	GetLatestEvents(ctx context.Context, afterSeq uint64, eventTypes []events.Type, limit int) ([]models.OutboxEvent, error)
+	Notify(ctx context.Context, id uint) error
+	Listen(ctx context.Context, ready func(), handle func(seq uint64)) error
+}
+
+type OutboxRepository struct {
+	DB *gorm.DB
+}
+
+func NewOutboxRepository(database *gorm.DB) OutboxRepo {
+	return &OutboxRepository{
+		DB: database,
+	}
+}
+
+// addOutboxEvent writes the event with the passed transaction.
+func addOutboxEvent(tx *gorm.DB, eventType events.Type, subscriptionID uint, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	event := models.OutboxEvent{
+		SubscriptionID: subscriptionID,
+		Type:           string(eventType),
+		Payload:        string(data),
+	}
+
+	if err := tx.Create(&event).Error; err != nil {
+		slog.ErrorContext(tx.Statement.Context, "add outbox event failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// addSubscriptionEvent writes the event with the current state of the subscription.
+func addSubscriptionEvent(tx *gorm.DB, eventType events.Type, id uint) error {
+	var record models.Subscription
+
+	if err := tx.Preload("Tags").Take(&record, id).Error; err != nil {
+		slog.ErrorContext(tx.Statement.Context, "add subscription event failed", "error", err)
+		return err
+	}
+
+	return addOutboxEvent(tx, eventType, id, events.NewSubscriptionPayload(record))
+}
+
+func (r *OutboxRepository) AddEvent(ctx context.Context, eventType events.Type, subscriptionID uint, payload any) error {
+	defer metrics.ObserveQuery("outbox", "AddEvent")()
+
+	return addOutboxEvent(r.DB.WithContext(ctx), eventType, subscriptionID, payload)
+}
+
+// ProcessEvents passes unpublished events to publish in the order they were written and
+// marks the published ones. After a failed event the following events of the same
+// subscription are held back until it is published, the failed event is retried with
+// exponential backoff. An event failed maxAttempts times is dead and no longer holds
+// back the following events, it is kept with its last error. Returns the number of
+// published events.
+func (r *OutboxRepository) ProcessEvents(ctx context.Context, limit, maxAttempts int, publish func(event models.OutboxEvent) error) (int, error) {
+	defer metrics.ObserveQuery("outbox", "ProcessEvents")()
+
+	records, err := r.claimEvents(ctx, limit)
+	if err != nil || len(records) == 0 {
+		return 0, err
+	}
+
+	// the events are published outside of the transaction, each one is marked as soon
+	// as it is published, so a failure doesn't publish the previous events again
+	var firstErr error
+	published := 0
+
+	for _, record := range records {
+		if err := publish(record); err != nil {
+			if err := r.failEvent(ctx, record, maxAttempts, err); err != nil {
+				firstErr = cmp.Or(firstErr, err)
+			}
+			continue
+		}
+
+		// if the mark fails, the event is published again when its claim expires
+		if err := r.DB.WithContext(ctx).Model(&record).Update("published_at", time.Now()).Error; err != nil {
+			slog.ErrorContext(ctx, "mark outbox event published failed", "event_id", record.ID, "error", err)
+			firstErr = cmp.Or(firstErr, err)
+			continue
+		}
+		published++
+	}
+
+	return published, firstErr
+}
+
+// claimEvents returns up to limit events which may be published now and postpones their
+// next attempt by outboxClaimLease, so the other relays don't publish them while they are
+// being published. An event may be published if it is due and there is no earlier pending
+// event of its subscription, so at most one event of a subscription is claimed at a time.
+func (r *OutboxRepository) claimEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	var claimed []models.OutboxEvent
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", outboxLockKey).Scan(&locked).Error; err != nil {
+			slog.ErrorContext(ctx, "process events failed", "error", err)
+			return err
+		}
+
+		if !locked {
+			return nil
+		}
+
+		err := tx.Raw(`
+			UPDATE outbox_events
+			SET next_attempt_at = now() + make_interval(secs => ?)
+			WHERE id IN (
+				SELECT e.id
+				FROM outbox_events e
+				WHERE
+					e.published_at IS NULL AND
+					e.dead_at IS NULL AND
+					(e.next_attempt_at IS NULL OR e.next_attempt_at <= now()) AND
+					NOT EXISTS (
+						SELECT 1
+						FROM outbox_events p
+						WHERE
+							p.subscription_id = e.subscription_id AND
+							p.id < e.id AND
+							p.published_at IS NULL AND
+							p.dead_at IS NULL
+					)
+				ORDER BY e.id
+				LIMIT ?
+			)
+			RETURNING *`, outboxClaimLease.Seconds(), limit,
+		).Scan(&claimed).Error
+		if err != nil {
+			slog.ErrorContext(ctx, "claim outbox events failed", "error", err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(claimed, func(a, b models.OutboxEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return claimed, nil
+}
+
+// failEvent schedules the next attempt of the failed event or marks it dead once it
+// failed maxAttempts times.
+func (r *OutboxRepository) failEvent(ctx context.Context, record models.OutboxEvent, maxAttempts int, publishErr error) error {
+	attempts := record.Attempts + 1
+	lastError := publishErr.Error()
+
+	fields := map[string]any{
+		"attempts":        attempts,
+		"last_error":      lastError,
+		"next_attempt_at": time.Now().Add(outboxBackoff(attempts)),
+	}
+	if attempts >= maxAttempts {
+		fields["dead_at"] = time.Now()
+		slog.ErrorContext(ctx, "outbox event dead", "event_id", record.ID, "attempts", attempts, "error", lastError)
+	} else {
+		slog.ErrorContext(ctx, "publish outbox event failed", "event_id", record.ID, "attempt", attempts, "error", lastError)
+	}
+
+	if err := r.DB.WithContext(ctx).Model(&record).Updates(fields).Error; err != nil {
+		slog.ErrorContext(ctx, "fail outbox event failed", "event_id", record.ID, "error", err)
+		return err
+	}
+
+	if attempts >= maxAttempts {
+		metrics.OutboxEventDead()
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	defer metrics.ObserveQuery("outbox", "DeletePublishedBefore")()
+
+	res := r.DB.WithContext(ctx).Where("published_at < ?", before).Delete(&models.OutboxEvent{})
+	if res.Error != nil {
+		slog.ErrorContext(ctx, "delete published before failed", "error", res.Error)
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
+func (r *OutboxRepository) GetStreamEvent(ctx context.Context, seq uint64) (*models.OutboxEvent, error) {
+	defer metrics.ObserveQuery("outbox", "GetStreamEvent")()
+
+	var record models.OutboxEvent
+
+	if err := r.DB.WithContext(ctx).Where("stream_seq = ?", seq).Take(&record).Error; err != nil {
+		slog.ErrorContext(ctx, "get event failed", "error", err)
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// GetLatestEvents returns up to limit last events of the types announced to the stream
+// after the stream sequence afterSeq, oldest first.
+func (r *OutboxRepository) GetLatestEvents(ctx context.Context, afterSeq uint64, eventTypes []events.Type, limit int) ([]models.OutboxEvent, error) {
+	defer metrics.ObserveQuery("outbox", "GetLatestEvents")()
+
+	var records []models.OutboxEvent
+
+	err := r.DB.WithContext(ctx).Where("stream_seq > ? AND type IN ?", afterSeq, eventTypes).
+		Order("stream_seq DESC").
+		Limit(limit).
+		Find(&records).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "get latest events failed", "error", err)
+		return nil, err
+	}
+
+	slices.Reverse(records)
+	return records, nil
+}
+
+// Notify assigns the next stream sequence to the published event and tells the listening
+// instances about it. The sequence is assigned once, an event published again isn't
+// announced twice. The sequences are assigned under a lock and the notification is sent
+// on commit, so the listeners receive them in order and a listener loading the events
+// after the last sequence it has seen doesn't skip any.
+func (r *OutboxRepository) Notify(ctx context.Context, id uint) error {
+	defer metrics.ObserveQuery("outbox", "Notify")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", outboxStreamLockKey).Error; err != nil {
+			return err
+		}
+
+		var seqs []uint64
+		err := tx.Raw(`
+			UPDATE outbox_events
+			SET stream_seq = nextval('outbox_stream_seq')
+			WHERE id = ? AND stream_seq IS NULL
+			RETURNING stream_seq`, id,
+		).Scan(&seqs).Error
+		if err != nil || len(seqs) == 0 {
+			return err
+		}
+
+		return tx.Exec("SELECT pg_notify(?, ?)", outboxNotifyChannel, strconv.FormatUint(seqs[0], 10)).Error
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "notify outbox listeners failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Listen passes the stream sequences of published events to handle until ctx is cancelled
+// or the connection fails. ready is called once the listening has started, so events
+// published before can be loaded without gaps.
+func (r *OutboxRepository) Listen(ctx context.Context, ready func(), handle func(seq uint64)) error {
+	return listen(ctx, r.DB, outboxNotifyChannel, ready, func(payload string) {
+		seq, err := strconv.ParseUint(payload, 10, 64)
+		if err != nil {
+			slog.WarnContext(ctx, "listen for outbox events failed", "error", err)
+			return
+		}
+		handle(seq)
+	})
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, outboxMaxBackoff)
+}
@@ -0,0 +1,187 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/tenant"
+	"testing"
+	"time"
+)
+
+func TestOutboxProcessEvents(t *testing.T) {
+	db := openDB(t)
+	outboxRepo := repository.NewOutboxRepository(db)
+	// the events are written in requests and processed by workers of all tenants
+	ctx := newTenant(t, db)
+	unscoped := tenant.Unscoped(context.Background())
+
+	// the subscriptions of the events are random, the events of the other tests are
+	// published along with them
+	first, second := uint(rand.Int32()), uint(rand.Int32())
+	for _, subscriptionID := range []uint{first, first, second} {
+		if err := outboxRepo.AddEvent(ctx, events.SubscriptionUpdated, subscriptionID, map[string]uint{"id": subscriptionID}); err != nil {
+			t.Fatalf("add event: %v", err)
+		}
+	}
+
+	var ids []uint
+	if err := db.WithContext(unscoped).Model(&models.OutboxEvent{}).Where("subscription_id IN ?", []uint{first, second}).Order("id").Pluck("id", &ids).Error; err != nil {
+		t.Fatalf("get events: %v", err)
+	}
+	firstEvent, secondEvent, otherEvent := ids[0], ids[1], ids[2]
+
+	tests := []struct {
+		name string
+		// fail are the events failed by the publish, nextAttempt moves the next attempt
+		// of the first event from now if it isn't zero
+		fail        map[uint]bool
+		nextAttempt time.Duration
+		maxAttempts int
+		// passed are the events passed to the publish
+		passed []uint
+	}{
+		{
+			name:        "first event of a subscription failed",
+			fail:        map[uint]bool{firstEvent: true},
+			maxAttempts: 2,
+			passed:      []uint{firstEvent, otherEvent},
+		},
+		{name: "failed event not due yet", nextAttempt: time.Hour, maxAttempts: 2},
+		{
+			name:        "failed event dead",
+			fail:        map[uint]bool{firstEvent: true},
+			nextAttempt: -time.Second,
+			maxAttempts: 2,
+			passed:      []uint{firstEvent},
+		},
+		{name: "next event after the dead one", maxAttempts: 2, passed: []uint{secondEvent}},
+		{name: "all published", maxAttempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.nextAttempt != 0 {
+				err := db.WithContext(unscoped).Model(&models.OutboxEvent{}).Where("id = ?", firstEvent).Update("next_attempt_at", time.Now().Add(tt.nextAttempt)).Error
+				if err != nil {
+					t.Fatalf("move the next attempt: %v", err)
+				}
+			}
+
+			var passed []uint
+			_, err := outboxRepo.ProcessEvents(unscoped, 1000, tt.maxAttempts, func(event models.OutboxEvent) error {
+				if event.SubscriptionID != first && event.SubscriptionID != second {
+					return nil
+				}
+
+				passed = append(passed, event.ID)
+				if tt.fail[event.ID] {
+					return errors.New("unavailable")
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("process events: %v", err)
+			}
+
+			if len(passed) != len(tt.passed) {
+				t.Fatalf("expected events %v, got %v", tt.passed, passed)
+			}
+			for i, id := range tt.passed {
+				if passed[i] != id {
+					t.Errorf("expected events %v, got %v", tt.passed, passed)
+				}
+			}
+		})
+	}
+
+	var dead models.OutboxEvent
+	if err := db.WithContext(unscoped).Take(&dead, firstEvent).Error; err != nil {
+		t.Fatalf("get event: %v", err)
+	}
+	if dead.DeadAt == nil || dead.PublishedAt != nil || dead.Attempts != 2 || dead.LastError == nil {
+		t.Errorf("expected a dead event after 2 attempts, got %+v", dead)
+	}
+}
+
+func TestOutboxNotify(t *testing.T) {
+	db := openDB(t)
+	outboxRepo := repository.NewOutboxRepository(db)
+	// the events are written in requests and processed by workers of all tenants
+	ctx := newTenant(t, db)
+	unscoped := tenant.Unscoped(context.Background())
+
+	subscriptionID := uint(rand.Int32())
+	for _, eventType := range []events.Type{events.SubscriptionCreated, events.SubscriptionDeleted} {
+		if err := outboxRepo.AddEvent(ctx, eventType, subscriptionID, map[string]uint{"id": subscriptionID}); err != nil {
+			t.Fatalf("add event: %v", err)
+		}
+	}
+
+	var ids []uint
+	if err := db.WithContext(unscoped).Model(&models.OutboxEvent{}).Where("subscription_id = ?", subscriptionID).Order("id").Pluck("id", &ids).Error; err != nil {
+		t.Fatalf("get events: %v", err)
+	}
+	created, deleted := ids[0], ids[1]
+
+	streamSeq := func(id uint) *uint64 {
+		t.Helper()
+
+		var record models.OutboxEvent
+		if err := db.WithContext(unscoped).Take(&record, id).Error; err != nil {
+			t.Fatalf("get event: %v", err)
+		}
+		return record.StreamSeq
+	}
+
+	// the deleted event is published first, so it comes first in the stream
+	for _, id := range []uint{deleted, created, deleted} {
+		if err := outboxRepo.Notify(unscoped, id); err != nil {
+			t.Fatalf("notify: %v", err)
+		}
+	}
+
+	deletedSeq, createdSeq := streamSeq(deleted), streamSeq(created)
+	if deletedSeq == nil || createdSeq == nil || *createdSeq <= *deletedSeq {
+		t.Fatalf("expected the sequence of the deleted event before the created one, got %v and %v", deletedSeq, createdSeq)
+	}
+
+	tests := []struct {
+		name     string
+		afterSeq uint64
+		expected []uint
+	}{
+		{name: "in the order of the sequences", afterSeq: *deletedSeq - 1, expected: []uint{deleted, created}},
+		{name: "after the sequence", afterSeq: *deletedSeq, expected: []uint{created}},
+		{name: "nothing after the last sequence", afterSeq: *createdSeq},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := outboxRepo.GetLatestEvents(unscoped, tt.afterSeq, []events.Type{events.SubscriptionCreated, events.SubscriptionDeleted}, 1000)
+			if err != nil {
+				t.Fatalf("get latest events: %v", err)
+			}
+
+			// the events of the other tests may be announced concurrently
+			var got []uint
+			for _, record := range records {
+				if record.SubscriptionID == subscriptionID {
+					got = append(got, record.ID)
+				}
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected events %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	record, err := outboxRepo.GetStreamEvent(unscoped, *createdSeq)
+	if err != nil || record.ID != created {
+		t.Errorf("expected event %d by its sequence, got %v, %v", created, record, err)
+	}
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/ratelimit"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// refilledTokensSQL is the number of tokens in the bucket refilled for the time passed since its last use.
+const refilledTokensSQL = `LEAST(
+			CAST(@burst AS double precision),
+			b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::double precision * @rate
+		)`
+
+// takeTokenSQL refills the bucket for the time passed since its last use and takes a token
+// if there is one, in one statement, so the instances sharing the bucket don't race.
+const takeTokenSQL = `
+	INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+	VALUES (@key, CAST(@burst AS double precision) - 1, true, now())
+	ON CONFLICT (key) DO UPDATE SET
+		allowed = ` + refilledTokensSQL + ` >= 1,
+		tokens = ` + refilledTokensSQL + ` - CASE WHEN ` + refilledTokensSQL + ` >= 1 THEN 1 ELSE 0 END,
+		updated_at = now()
+	RETURNING tokens, allowed`
+
+// RateLimitRepository keeps the token buckets in Postgres, so all instances
+// of the service share the limits.
+type RateLimitRepository struct {
+	DB *gorm.DB
+}
+
+func NewRateLimitRepository(database *gorm.DB) ratelimit.Store {
+	return &RateLimitRepository{
+		DB: database,
+	}
+}
+
+func (r *RateLimitRepository) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	defer metrics.ObserveQuery("rate_limit", "Take")()
+
+	var bucket struct {
+		Tokens  float64
+		Allowed bool
+	}
+
+	err := r.DB.WithContext(ctx).Raw(takeTokenSQL, map[string]any{
+		"key":   key,
+		"burst": float64(limit.Burst),
+		"rate":  limit.Rate,
+	}).Scan(&bucket).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "take rate limit tokens failed", "error", err)
+		return ratelimit.Result{}, err
+	}
+
+	return ratelimit.NewResult(limit, bucket.Tokens, bucket.Allowed), nil
+}
+
+func (r *RateLimitRepository) Refund(ctx context.Context, key string, limit ratelimit.Limit) error {
+	defer metrics.ObserveQuery("rate_limit", "Refund")()
+
+	err := r.DB.WithContext(ctx).Exec(
+		"UPDATE rate_limit_buckets SET tokens = LEAST(?, tokens + 1) WHERE key = ?", float64(limit.Burst), key,
+	).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "refund rate limit token failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *RateLimitRepository) Prune(ctx context.Context, before time.Time) error {
+	defer metrics.ObserveQuery("rate_limit", "Prune")()
+
+	if err := r.DB.WithContext(ctx).Exec("DELETE FROM rate_limit_buckets WHERE updated_at < ?", before).Error; err != nil {
+		slog.ErrorContext(ctx, "prune rate limit buckets failed", "error", err)
+		return err
+	}
+
+	return nil
+}
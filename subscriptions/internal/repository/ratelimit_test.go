@@ -0,0 +1,105 @@
+package repository_test
+
+import (
+	"context"
+	"subscriptions/rest-service/internal/ratelimit"
+	"subscriptions/rest-service/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRateLimitRepository(t *testing.T) {
+	store := repository.NewRateLimitRepository(openDB(t))
+	ctx := context.Background()
+	// the limit doesn't refill noticeably while the test runs
+	limit := ratelimit.Limit{Burst: 2, Rate: 2.0 / 3600}
+
+	take := func(t *testing.T, key string) ratelimit.Result {
+		t.Helper()
+
+		result, err := store.Take(ctx, key, limit)
+		if err != nil {
+			t.Fatalf("take: %v", err)
+		}
+		return result
+	}
+	refund := func(t *testing.T, key string) {
+		t.Helper()
+
+		if err := store.Refund(ctx, key, limit); err != nil {
+			t.Fatalf("refund: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		// run returns the result of the last take of the key
+		run       func(t *testing.T, key string) ratelimit.Result
+		allowed   bool
+		remaining int
+	}{
+		{
+			name:      "first take",
+			run:       func(t *testing.T, key string) ratelimit.Result { return take(t, key) },
+			allowed:   true,
+			remaining: 1,
+		},
+		{
+			name: "burst exhausted",
+			run: func(t *testing.T, key string) ratelimit.Result {
+				take(t, key)
+				take(t, key)
+				return take(t, key)
+			},
+			allowed:   false,
+			remaining: 0,
+		},
+		{
+			name: "refund returns the token",
+			run: func(t *testing.T, key string) ratelimit.Result {
+				take(t, key)
+				take(t, key)
+				refund(t, key)
+				return take(t, key)
+			},
+			allowed:   true,
+			remaining: 0,
+		},
+		{
+			name: "refund doesn't exceed the burst",
+			run: func(t *testing.T, key string) ratelimit.Result {
+				take(t, key)
+				refund(t, key)
+				refund(t, key)
+				take(t, key)
+				return take(t, key)
+			},
+			allowed:   true,
+			remaining: 0,
+		},
+		{
+			name: "pruned bucket is full",
+			run: func(t *testing.T, key string) ratelimit.Result {
+				take(t, key)
+				take(t, key)
+				if err := store.Prune(ctx, time.Now().Add(time.Second)); err != nil {
+					t.Fatalf("prune: %v", err)
+				}
+				return take(t, key)
+			},
+			allowed:   true,
+			remaining: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.run(t, "test:"+uuid.NewString())
+			if result.Allowed != tt.allowed || result.Remaining != tt.remaining {
+				t.Errorf("expected allowed %v with %d remaining, got %+v", tt.allowed, tt.remaining, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/tenant"
+	"subscriptions/rest-service/pkg/database"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openDB connects to the test database of TEST_DATABASE_DSN and migrates it, the tests
+// using it are skipped without the variable.
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		t.Fatalf("use tenant plugin: %v", err)
+	}
+	if err := database.Migrate(context.Background(), db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	return db
+}
+
+// uniqueName returns the name with a random suffix, so the tests sharing the database
+// don't conflict with each other and with the previous runs.
+func uniqueName(name string) string {
+	return name + " " + uuid.NewString()[:8]
+}
+
+// newTenant creates a tenant of its own for the test and returns the context of its requests.
+func newTenant(t *testing.T, db *gorm.DB) context.Context {
+	t.Helper()
+
+	id := "test-" + uuid.NewString()
+	if err := repository.NewTenantRepository(db).CreateTenant(context.Background(), id, id); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	return tenant.WithID(context.Background(), id)
+}
+
+// newUser creates a user of the tenant of the context.
+func newUser(t *testing.T, ctx context.Context, db *gorm.DB) uuid.UUID {
+	t.Helper()
+
+	id := uuid.New()
+	if err := repository.NewUserRepository(db).CreateUser(ctx, id, "Test user", nil, "en", "RUB"); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	return id
+}
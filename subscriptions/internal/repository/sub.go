@@ -4,26 +4,77 @@ Package repository implements the data access layer for subscription records.
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"strings"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/metrics"
 	"subscriptions/rest-service/internal/models"
-	"subscriptions/rest-service/pkg/logger"
+	"subscriptions/rest-service/pkg/database"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SubscriptionRepo interface {
-	GetRecords(offset, size int) ([]models.Subscription, *int, error)
-	GetRecord(id uint) (*models.Subscription, error)
-	CreateRecord(serviceName string, startDate time.Time, price uint, userID uuid.UUID, endDate *time.Time) (*uint, error)
-	FullUpdateRecord(id, price uint, serviceName string, startDate time.Time, userID uuid.UUID, endDate *time.Time) error
-	UpdateRecord(id uint, fields map[string]any) error
-	DeleteRecord(id uint) error
-	GetSubsSum(userID *uuid.UUID, serviceName *string, startDate, endDate string) *uint
+	GetRecords(ctx context.Context, offset, size int, filter SubsFilter) ([]models.Subscription, *int, error)
+	GetRecord(ctx context.Context, id uint) (*models.Subscription, error)
+	// CreateRecord, FullUpdateRecord and UpdateRecord run the hook, if it isn't nil, in the
+	// transaction of the write.
+	CreateRecord(ctx context.Context, serviceName string, startDate time.Time, price uint, userID uuid.UUID, endDate *time.Time, categoryID *uint, tags []string, hook WriteHook) (*uint, error)
+	// FullUpdateRecord, UpdateRecord and DeleteRecord return the user the subscription belonged to before the write.
+	FullUpdateRecord(ctx context.Context, id, price uint, serviceName string, startDate time.Time, userID uuid.UUID, endDate *time.Time, categoryID *uint, tags []string, hook WriteHook) (uuid.UUID, error)
+	UpdateRecord(ctx context.Context, id uint, fields map[string]any, tags *[]string, hook WriteHook) (uuid.UUID, error)
+	DeleteRecord(ctx context.Context, id uint) (uuid.UUID, error)
+	GetSubsSum(ctx context.Context, userID *uuid.UUID, serviceName *string, categoryID *uint, startDate, endDate string) *uint
+	GetSubsSumByCategory(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate string) ([]CategorySum, error)
+	GetSubsSumByUsers(ctx context.Context, userIDs []uuid.UUID, serviceName *string, categoryID *uint, startDate, endDate string) ([]UserSum, error)
+	GetSubsSumByMonth(ctx context.Context, userID *uuid.UUID, serviceName *string, categoryID *uint, startDate, endDate string) ([]MonthSum, error)
+	GetEndingSoonRecords(ctx context.Context, from, to time.Time) ([]models.Subscription, error)
+	MarkEndingSoonNotified(ctx context.Context, id uint, endDate time.Time) error
+	CountActiveByTenant(ctx context.Context, month time.Time) (map[string]int64, error)
 }
 
+// SubsFilter narrows the list of subscriptions. A category filter also
+// matches subscriptions of all its subcategories.
+type SubsFilter struct {
+	UserID     *uuid.UUID
+	CategoryID *uint
+	Tag        *string
+}
+
+// CategorySum is the charge of subscriptions bound directly to a category
+// (nil CategoryID means uncategorized subscriptions).
+type CategorySum struct {
+	CategoryID *uint
+	TotalSum   uint
+}
+
+type UserSum struct {
+	UserID   uuid.UUID
+	TotalSum uint
+}
+
+// MonthSum is the charge of the month, given by its first day.
+type MonthSum struct {
+	Month    time.Time
+	TotalSum uint
+}
+
+// categoryTreeSQL selects the id of a category of the tenant together with the ids of all
+// its descendants, the parameters are the category id and the tenant id.
+const categoryTreeSQL = `
+	WITH RECURSIVE category_tree AS (
+		SELECT id FROM categories WHERE id = ? AND tenant_id = ?
+		UNION ALL
+		SELECT c.id FROM categories c JOIN category_tree t ON c.parent_id = t.id
+	)
+	SELECT id FROM category_tree`
+
 type SubscriptionRepository struct {
 	DB *gorm.DB
 }
@@ -34,55 +85,144 @@ func NewRepository(database *gorm.DB) SubscriptionRepo {
 	}
 }
 
-func (r *SubscriptionRepository) GetRecords(offset, size int) ([]models.Subscription, *int, error) {
+func (r *SubscriptionRepository) applyFilter(query *gorm.DB, tenantID string, filter SubsFilter) *gorm.DB {
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+
+	if filter.CategoryID != nil {
+		query = query.Where("category_id IN (?)", r.DB.Raw(categoryTreeSQL, *filter.CategoryID, tenantID))
+	}
+
+	if filter.Tag != nil {
+		query = query.Where(
+			"id IN (?)",
+			r.DB.Table("subscription_tags").
+				Select("subscription_tags.subscription_id").
+				Joins("JOIN tags ON tags.id = subscription_tags.tag_id").
+				Where("tags.tenant_id = ? AND tags.name = ?", tenantID, normalizeTag(*filter.Tag)),
+		)
+	}
+
+	return query
+}
+
+func (r *SubscriptionRepository) GetRecords(ctx context.Context, offset, size int, filter SubsFilter) ([]models.Subscription, *int, error) {
+	defer metrics.ObserveQuery("subscription", "GetRecords")()
+	ctx = database.ReadOnly(ctx)
+
 	var records []models.Subscription
 
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var total int64
-	if err := r.DB.Model(&models.Subscription{}).Count(&total).Error; err != nil {
-		logger.PrintLog(err.Error(), "error")
+	if err := r.applyFilter(r.DB.WithContext(ctx).Model(&models.Subscription{}), tenantID, filter).Count(&total).Error; err != nil {
+		slog.ErrorContext(ctx, "get records failed", "error", err)
 		return nil, nil, err
 	}
 
 	totalPages := int((total + int64(size) - 1) / int64(size))
 
-	if err := r.DB.Limit(size).Offset(offset).Find(&records).Error; err != nil {
-		logger.PrintLog(err.Error(), "error")
+	query := r.applyFilter(r.DB.WithContext(ctx).Preload("Tags"), tenantID, filter)
+	if err := query.Order("id").Limit(size).Offset(offset).Find(&records).Error; err != nil {
+		slog.ErrorContext(ctx, "get records failed", "error", err)
 		return nil, nil, err
 	}
 
 	return records, &totalPages, nil
 }
 
-func (r *SubscriptionRepository) GetRecord(id uint) (*models.Subscription, error) {
+func (r *SubscriptionRepository) GetRecord(ctx context.Context, id uint) (*models.Subscription, error) {
+	defer metrics.ObserveQuery("subscription", "GetRecord")()
+	ctx = database.ReadOnly(ctx)
+
 	var record models.Subscription
 
-	if err := r.DB.Take(&record, id).Error; err != nil {
-		logger.PrintLog(err.Error(), "error")
+	if err := r.DB.WithContext(ctx).Preload("Tags").Take(&record, id).Error; err != nil {
+		slog.ErrorContext(ctx, "get record failed", "error", err)
 		return nil, gorm.ErrRecordNotFound
 	}
 
 	return &record, nil
 }
 
-func (r *SubscriptionRepository) CreateRecord(serviceName string, startDate time.Time, price uint, userID uuid.UUID, endDate *time.Time) (*uint, error) {
+// TxRepos are the repositories bound to the transaction of a subscription write.
+type TxRepos struct {
+	Subscriptions SubscriptionRepo
+	Budgets       BudgetRepo
+	Outbox        OutboxRepo
+}
+
+// WriteHook runs in the transaction of a subscription write after it, with the repositories
+// of the transaction and the id of the written subscription, e.g. to write the events
+// depending on the written data. Its error rolls the write back.
+type WriteHook func(repos TxRepos, subscriptionID uint) error
+
+func (h WriteHook) run(tx *gorm.DB, subscriptionID uint) error {
+	if h == nil {
+		return nil
+	}
+
+	return h(TxRepos{
+		Subscriptions: NewRepository(tx),
+		Budgets:       NewBudgetRepository(tx),
+		Outbox:        NewOutboxRepository(tx),
+	}, subscriptionID)
+}
+
+func (r *SubscriptionRepository) CreateRecord(
+	ctx context.Context,
+	serviceName string,
+	startDate time.Time,
+	price uint,
+	userID uuid.UUID,
+	endDate *time.Time,
+	categoryID *uint,
+	tags []string,
+	hook WriteHook,
+) (*uint, error) {
+	defer metrics.ObserveQuery("subscription", "CreateRecord")()
+
 	var newID uint
 
-	err := r.DB.Transaction(func(tx *gorm.DB) error {
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkCategory(tx, categoryID); err != nil {
+			return err
+		}
+
+		tagRecords, err := findOrCreateTags(tx, tags)
+		if err != nil {
+			return err
+		}
+
 		newRecord := models.Subscription{
 			ServiceName: serviceName,
 			Price:       price,
 			UserID:      userID,
 			StartDate:   startDate,
 			EndDate:     endDate,
+			CategoryID:  categoryID,
+			Tags:        tagRecords,
 		}
 
 		if err := tx.Create(&newRecord).Error; err != nil {
-			logger.PrintLog(err.Error(), "error")
+			slog.ErrorContext(ctx, "create record failed", "error", err)
 			return err
 		}
 
 		newID = newRecord.ID
-		return nil
+		if err := applyCharges(tx, newID, 1); err != nil {
+			return err
+		}
+
+		if err := addSubscriptionEvent(tx, events.SubscriptionCreated, newID); err != nil {
+			return err
+		}
+
+		return hook.run(tx, newID)
 	})
 	if err != nil {
 		return nil, err
@@ -92,95 +232,387 @@ func (r *SubscriptionRepository) CreateRecord(serviceName string, startDate time
 }
 
 func (r *SubscriptionRepository) FullUpdateRecord(
+	ctx context.Context,
 	id, price uint,
 	serviceName string,
 	startDate time.Time,
 	userID uuid.UUID,
 	endDate *time.Time,
-) error {
-	err := r.DB.Transaction(func(tx *gorm.DB) error {
+	categoryID *uint,
+	tags []string,
+	hook WriteHook,
+) (uuid.UUID, error) {
+	defer metrics.ObserveQuery("subscription", "FullUpdateRecord")()
+
+	var previousUserID uuid.UUID
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var toUpdateRecord models.Subscription
 
-		if err := r.DB.Take(&toUpdateRecord, id).Error; err != nil {
-			logger.PrintLog(err.Error(), "error")
+		// the row is locked so the previous user isn't changed by a concurrent update
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&toUpdateRecord, id).Error; err != nil {
+			slog.ErrorContext(ctx, "full update record failed", "error", err)
 			return gorm.ErrRecordNotFound
 		}
+		previousUserID = toUpdateRecord.UserID
+
+		if err := checkCategory(tx, categoryID); err != nil {
+			return err
+		}
+
+		if err := applyCharges(tx, id, -1); err != nil {
+			return err
+		}
 
 		toUpdateRecord.ServiceName = serviceName
 		toUpdateRecord.Price = price
 		toUpdateRecord.UserID = userID
 		toUpdateRecord.StartDate = startDate
 		toUpdateRecord.EndDate = endDate
+		toUpdateRecord.CategoryID = categoryID
 
 		if err := tx.Save(&toUpdateRecord).Error; err != nil {
-			logger.PrintLog(err.Error(), "error")
+			slog.ErrorContext(ctx, "full update record failed", "error", err)
 			return err
 		}
 
-		return nil
+		if err := replaceTags(tx, &toUpdateRecord, tags); err != nil {
+			return err
+		}
+
+		if err := applyCharges(tx, id, 1); err != nil {
+			return err
+		}
+
+		if err := addSubscriptionEvent(tx, events.SubscriptionUpdated, id); err != nil {
+			return err
+		}
+
+		return hook.run(tx, id)
 	})
 
-	return err
+	return previousUserID, err
 }
 
-func (r *SubscriptionRepository) UpdateRecord(id uint, fields map[string]any) error {
-	err := r.DB.Transaction(func(tx *gorm.DB) error {
+func (r *SubscriptionRepository) UpdateRecord(ctx context.Context, id uint, fields map[string]any, tags *[]string, hook WriteHook) (uuid.UUID, error) {
+	defer metrics.ObserveQuery("subscription", "UpdateRecord")()
+
+	var previousUserID uuid.UUID
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var record models.Subscription
 
-		if err := r.DB.Take(&record, id).Error; err != nil {
-			logger.PrintLog(err.Error(), "error")
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&record, id).Error; err != nil {
+			slog.ErrorContext(ctx, "update record failed", "error", err)
 			return gorm.ErrRecordNotFound
 		}
+		previousUserID = record.UserID
+
+		if categoryID, ok := fields["category_id"].(float64); ok {
+			id := uint(categoryID)
+			if err := checkCategory(tx, &id); err != nil {
+				return err
+			}
+		}
+
+		if len(fields) > 0 {
+			if err := applyCharges(tx, id, -1); err != nil {
+				return err
+			}
+
+			if err := tx.Model(&record).Updates(fields).Error; err != nil {
+				slog.ErrorContext(ctx, "update record failed", "error", err)
+				return err
+			}
+
+			if err := applyCharges(tx, id, 1); err != nil {
+				return err
+			}
+		}
 
-		if err := tx.Model(&record).Updates(fields).Error; err != nil {
-			logger.PrintLog(err.Error(), "error")
+		if tags != nil {
+			if err := replaceTags(tx, &record, *tags); err != nil {
+				return err
+			}
+		}
+
+		if err := addSubscriptionEvent(tx, events.SubscriptionUpdated, id); err != nil {
 			return err
 		}
 
-		return nil
+		return hook.run(tx, id)
 	})
 
-	return err
+	return previousUserID, err
 }
 
-func (r *SubscriptionRepository) DeleteRecord(id uint) error {
-	err := r.DB.Transaction(func(tx *gorm.DB) error {
-		if err := r.DB.Take(&models.Subscription{}, id).Error; err != nil {
-			logger.PrintLog(err.Error(), "error")
+func (r *SubscriptionRepository) DeleteRecord(ctx context.Context, id uint) (uuid.UUID, error) {
+	defer metrics.ObserveQuery("subscription", "DeleteRecord")()
+
+	var record models.Subscription
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Tags").Take(&record, id).Error; err != nil {
+			slog.ErrorContext(ctx, "delete record failed", "error", err)
 			return gorm.ErrRecordNotFound
 		}
 
+		if err := applyCharges(tx, id, -1); err != nil {
+			return err
+		}
+
 		if err := tx.Delete(&models.Subscription{}, id).Error; err != nil {
 			return err
 		}
 
-		return nil
+		return addOutboxEvent(tx, events.SubscriptionDeleted, id, events.NewSubscriptionPayload(record))
+	})
+
+	return record.UserID, err
+}
+
+// GetEndingSoonRecords returns subscriptions ending between from and to
+// that the ending soon event was not sent for yet.
+func (r *SubscriptionRepository) GetEndingSoonRecords(ctx context.Context, from, to time.Time) ([]models.Subscription, error) {
+	defer metrics.ObserveQuery("subscription", "GetEndingSoonRecords")()
+
+	var records []models.Subscription
+
+	err := r.DB.WithContext(ctx).Preload("Tags").
+		Where("end_date BETWEEN ? AND ?", from, to).
+		Where("ending_soon_notified_for IS DISTINCT FROM end_date").
+		Order("end_date, id").
+		Find(&records).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "get ending soon records failed", "error", err)
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// MarkEndingSoonNotified remembers the end date the ending soon event is sent for
+// and writes the event in the same transaction.
+func (r *SubscriptionRepository) MarkEndingSoonNotified(ctx context.Context, id uint, endDate time.Time) error {
+	defer metrics.ObserveQuery("subscription", "MarkEndingSoonNotified")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Subscription{}).
+			Where("id = ?", id).
+			Update("ending_soon_notified_for", endDate).Error
+		if err != nil {
+			slog.ErrorContext(ctx, "mark ending soon notified failed", "error", err)
+			return err
+		}
+
+		return addSubscriptionEvent(tx, events.SubscriptionEndingSoon, id)
 	})
 
 	return err
 }
 
-func (r *SubscriptionRepository) GetSubsSum(userID *uuid.UUID, serviceName *string, startDate, endDate string) *uint {
+// CountActiveByTenant counts the subscriptions active in the month of every tenant
+// the context has access to.
+func (r *SubscriptionRepository) CountActiveByTenant(ctx context.Context, month time.Time) (map[string]int64, error) {
+	defer metrics.ObserveQuery("subscription", "CountActiveByTenant")()
+	ctx = database.ReadOnly(ctx)
+
+	var rows []struct {
+		TenantID string
+		Count    int64
+	}
+
+	err := r.DB.WithContext(ctx).Model(&models.Subscription{}).
+		Select("tenant_id, count(*) AS count").
+		Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", month, month).
+		Group("tenant_id").
+		Scan(&rows).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "count active by tenant failed", "error", err)
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.TenantID] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (r *SubscriptionRepository) GetSubsSum(ctx context.Context, userID *uuid.UUID, serviceName *string, categoryID *uint, startDate, endDate string) *uint {
+	defer metrics.ObserveSubsSum()()
+	ctx = database.ReadOnly(ctx)
+
 	var totalSum sql.NullInt64
 
-	args := []any{startDate, endDate}
-	nextPlaceholder := 3
-	whereClauses := ""
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "get subs sum failed", "error", err)
+		return nil
+	}
 
-	if userID != nil {
-		whereClauses += fmt.Sprintf(" AND user_id = $%d", nextPlaceholder)
-		args = append(args, *userID)
-		nextPlaceholder++
+	rawSQL, args := r.sumSQL(ctx, "", tenantID, userIDList(userID), serviceName, categoryID, startDate, endDate)
+
+	if err := r.DB.WithContext(ctx).Raw(rawSQL, args...).Scan(&totalSum).Error; err != nil {
+		slog.ErrorContext(ctx, "get subs sum failed", "error", err)
+		return nil
 	}
 
-	if serviceName != nil {
-		whereClauses += fmt.Sprintf(" AND service_name ILIKE $%d", nextPlaceholder)
-		args = append(args, *serviceName)
-		nextPlaceholder++
+	total := uint(totalSum.Int64)
+	return &total
+}
+
+func (r *SubscriptionRepository) GetSubsSumByCategory(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate string) ([]CategorySum, error) {
+	defer metrics.ObserveQuery("subscription", "GetSubsSumByCategory")()
+	ctx = database.ReadOnly(ctx)
+
+	var rows []struct {
+		CategoryID sql.NullInt64
+		TotalSum   sql.NullInt64
+	}
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rawSQL, args := r.sumSQL(ctx, "category_id", tenantID, userIDList(userID), serviceName, nil, startDate, endDate)
+
+	if err := r.DB.WithContext(ctx).Raw(rawSQL, args...).Scan(&rows).Error; err != nil {
+		slog.ErrorContext(ctx, "get subs sum by category failed", "error", err)
+		return nil, err
+	}
+
+	result := make([]CategorySum, len(rows))
+	for i, row := range rows {
+		if row.CategoryID.Valid {
+			categoryID := uint(row.CategoryID.Int64)
+			result[i].CategoryID = &categoryID
+		}
+		result[i].TotalSum = uint(row.TotalSum.Int64)
+	}
+
+	return result, nil
+}
+
+// GetSubsSumByUsers returns the charge for the period per user, users without
+// charge in the period are omitted.
+func (r *SubscriptionRepository) GetSubsSumByUsers(
+	ctx context.Context,
+	userIDs []uuid.UUID,
+	serviceName *string,
+	categoryID *uint,
+	startDate, endDate string,
+) ([]UserSum, error) {
+	defer metrics.ObserveQuery("subscription", "GetSubsSumByUsers")()
+	ctx = database.ReadOnly(ctx)
+
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	var rows []struct {
+		UserID   uuid.UUID
+		TotalSum sql.NullInt64
+	}
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rawSQL, args := r.sumSQL(ctx, "user_id", tenantID, userIDs, serviceName, categoryID, startDate, endDate)
+
+	if err := r.DB.WithContext(ctx).Raw(rawSQL, args...).Scan(&rows).Error; err != nil {
+		slog.ErrorContext(ctx, "get subs sum by users failed", "error", err)
+		return nil, err
+	}
+
+	result := make([]UserSum, len(rows))
+	for i, row := range rows {
+		result[i] = UserSum{UserID: row.UserID, TotalSum: uint(row.TotalSum.Int64)}
+	}
+
+	return result, nil
+}
+
+// GetSubsSumByMonth returns the charge for the period per month, months without charge
+// are omitted.
+func (r *SubscriptionRepository) GetSubsSumByMonth(
+	ctx context.Context,
+	userID *uuid.UUID,
+	serviceName *string,
+	categoryID *uint,
+	startDate, endDate string,
+) ([]MonthSum, error) {
+	defer metrics.ObserveQuery("subscription", "GetSubsSumByMonth")()
+	ctx = database.ReadOnly(ctx)
+
+	var rows []struct {
+		Month    time.Time
+		TotalSum sql.NullInt64
+	}
+
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawSQL string
+	var args []any
+	if r.chargesBuiltUntil(ctx, endDate) {
+		rawSQL, args = chargesSumSQL("month", tenantID, userIDList(userID), serviceName, categoryID, startDate, endDate)
+	} else {
+		// the charges of the period are calculated the same way they are built
+		whereClauses, filterArgs := subsFilterSQL(userIDList(userID), serviceName, categoryID)
+		rawSQL = `SELECT month, SUM(amount) AS total_sum FROM (` +
+			chargesSQL(" AND tenant_id = $3"+whereClauses) + `) charges GROUP BY month`
+		args = append([]any{startDate, endDate, tenantID}, filterArgs...)
+	}
+
+	if err := r.DB.WithContext(ctx).Raw(rawSQL+" ORDER BY month", args...).Scan(&rows).Error; err != nil {
+		slog.ErrorContext(ctx, "get subs sum by month failed", "error", err)
+		return nil, err
+	}
+
+	result := make([]MonthSum, len(rows))
+	for i, row := range rows {
+		result[i] = MonthSum{Month: row.Month, TotalSum: uint(row.TotalSum.Int64)}
+	}
+
+	return result, nil
+}
+
+func userIDList(userID *uuid.UUID) []uuid.UUID {
+	if userID == nil {
+		return nil
+	}
+
+	return []uuid.UUID{*userID}
+}
+
+// subsSumSQL builds the query calculating the charge of subscriptions of the tenant for
+// the period between startDate and endDate, optionally limited to the users. The
+// subscriptions without end are charged until the end of the period. If
+// groupColumn is set, the sum is calculated per value of that column and the
+// column is selected first.
+func (r *SubscriptionRepository) subsSumSQL(
+	groupColumn string,
+	tenantID string,
+	userIDs []uuid.UUID,
+	serviceName *string,
+	categoryID *uint,
+	startDate, endDate string,
+) (string, []any) {
+	whereClauses, filterArgs := subsFilterSQL(userIDs, serviceName, categoryID)
+	args := append([]any{startDate, endDate, tenantID}, filterArgs...)
+
+	groupSelect, groupBy := "", ""
+	if groupColumn != "" {
+		groupSelect = " " + groupColumn + ","
+		groupBy = " GROUP BY " + groupColumn
 	}
 
 	rawSQL := `
-		SELECT
+		SELECT` + groupSelect + `
 			COALESCE(SUM(
 				(
 					EXTRACT(
@@ -196,6 +628,7 @@ func (r *SubscriptionRepository) GetSubsSum(userID *uuid.UUID, serviceName *stri
 			SELECT
 				service_name,
 				user_id,
+				category_id,
 				price, 
 				start_date, 
 				end_date, 
@@ -220,14 +653,100 @@ func (r *SubscriptionRepository) GetSubsSum(userID *uuid.UUID, serviceName *stri
 			
 			FROM subscriptions
 			WHERE
+				tenant_id = $3 AND
 				($1::date, $2::date) OVERLAPS 
-				(start_date::date, end_date::date)` + whereClauses + `);`
+				(start_date::date, COALESCE(end_date, 'infinity')::date)` + whereClauses + `)` + groupBy + `;`
 
-	if err := r.DB.Raw(rawSQL, args...).Scan(&totalSum).Error; err != nil {
-		logger.PrintLog(err.Error(), "error")
-		return nil
+	return rawSQL, args
+}
+
+// sumSQL builds the query of subsSumSQL, reading the sums from the monthly charges if
+// they are built until the end of the period.
+func (r *SubscriptionRepository) sumSQL(
+	ctx context.Context,
+	groupColumn string,
+	tenantID string,
+	userIDs []uuid.UUID,
+	serviceName *string,
+	categoryID *uint,
+	startDate, endDate string,
+) (string, []any) {
+	if r.chargesBuiltUntil(ctx, endDate) {
+		return chargesSumSQL(groupColumn, tenantID, userIDs, serviceName, categoryID, startDate, endDate)
 	}
 
-	total := uint(totalSum.Int64)
-	return &total
+	return r.subsSumSQL(groupColumn, tenantID, userIDs, serviceName, categoryID, startDate, endDate)
+}
+
+// chargesBuiltUntil reports whether the monthly charges are built until the date, the
+// subscriptions are summed instead if the horizon can't be read.
+func (r *SubscriptionRepository) chargesBuiltUntil(ctx context.Context, date string) bool {
+	horizon, err := chargesHorizon(r.DB.WithContext(ctx), "")
+	if err != nil {
+		slog.ErrorContext(ctx, "get charges horizon failed", "error", err)
+		return false
+	}
+
+	return horizon != nil && date <= *horizon
+}
+
+// chargesSumSQL builds the query of subsSumSQL from the monthly charges.
+func chargesSumSQL(
+	groupColumn string,
+	tenantID string,
+	userIDs []uuid.UUID,
+	serviceName *string,
+	categoryID *uint,
+	startDate, endDate string,
+) (string, []any) {
+	whereClauses, filterArgs := subsFilterSQL(userIDs, serviceName, categoryID)
+	args := append([]any{startDate, endDate, tenantID}, filterArgs...)
+
+	groupSelect, groupBy := "", ""
+	if groupColumn != "" {
+		groupSelect = " " + groupColumn + ","
+		groupBy = " GROUP BY " + groupColumn
+	}
+
+	rawSQL := `
+		SELECT` + groupSelect + ` SUM(amount) AS total_sum
+		FROM monthly_charges
+		WHERE tenant_id = $3 AND month >= $1::date AND month < $2::date` + whereClauses + groupBy
+
+	return rawSQL, args
+}
+
+// subsFilterSQL builds the conditions of the sums limiting the subscriptions or the
+// charges to the users, the service name and the category tree of the tenant, which is $3.
+// The parameters of the conditions start from $4.
+func subsFilterSQL(userIDs []uuid.UUID, serviceName *string, categoryID *uint) (string, []any) {
+	var args []any
+	nextPlaceholder := 4
+	whereClauses := ""
+
+	if len(userIDs) > 0 {
+		placeholders := make([]string, len(userIDs))
+		for i, userID := range userIDs {
+			placeholders[i] = fmt.Sprintf("$%d", nextPlaceholder)
+			args = append(args, userID)
+			nextPlaceholder++
+		}
+		whereClauses += fmt.Sprintf(" AND user_id IN (%s)", strings.Join(placeholders, ", "))
+	}
+
+	if serviceName != nil {
+		whereClauses += fmt.Sprintf(" AND service_name ILIKE $%d", nextPlaceholder)
+		args = append(args, *serviceName)
+		nextPlaceholder++
+	}
+
+	if categoryID != nil {
+		categoryTree := strings.Replace(categoryTreeSQL, "?", fmt.Sprintf("$%d", nextPlaceholder), 1)
+		categoryTree = strings.Replace(categoryTree, "?", "$3", 1)
+		whereClauses += fmt.Sprintf(" AND category_id IN (%s)", categoryTree)
+		args = append(args, *categoryID)
+		nextPlaceholder++
+	}
+
+	return whereClauses, args
 }
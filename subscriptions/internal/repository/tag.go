@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+type TagRepo interface {
+	GetTags(ctx context.Context) ([]models.Tag, error)
+	GetTag(ctx context.Context, id uint) (*models.Tag, error)
+	CreateTag(ctx context.Context, name string) (*uint, error)
+	UpdateTag(ctx context.Context, id uint, name string) error
+	DeleteTag(ctx context.Context, id uint) error
+}
+
+type TagRepository struct {
+	DB *gorm.DB
+}
+
+func NewTagRepository(database *gorm.DB) TagRepo {
+	return &TagRepository{
+		DB: database,
+	}
+}
+
+func (r *TagRepository) GetTags(ctx context.Context) ([]models.Tag, error) {
+	defer metrics.ObserveQuery("tag", "GetTags")()
+	ctx = database.ReadOnly(ctx)
+
+	var tags []models.Tag
+
+	if err := r.DB.WithContext(ctx).Order("name").Find(&tags).Error; err != nil {
+		slog.ErrorContext(ctx, "get tags failed", "error", err)
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (r *TagRepository) GetTag(ctx context.Context, id uint) (*models.Tag, error) {
+	defer metrics.ObserveQuery("tag", "GetTag")()
+	ctx = database.ReadOnly(ctx)
+
+	var tag models.Tag
+
+	if err := r.DB.WithContext(ctx).Take(&tag, id).Error; err != nil {
+		slog.ErrorContext(ctx, "get tag failed", "error", err)
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &tag, nil
+}
+
+func (r *TagRepository) CreateTag(ctx context.Context, name string) (*uint, error) {
+	defer metrics.ObserveQuery("tag", "CreateTag")()
+
+	tag := models.Tag{Name: normalizeTag(name)}
+
+	if err := r.DB.WithContext(ctx).Create(&tag).Error; err != nil {
+		slog.ErrorContext(ctx, "create tag failed", "error", err)
+		return nil, err
+	}
+
+	return &tag.ID, nil
+}
+
+func (r *TagRepository) UpdateTag(ctx context.Context, id uint, name string) error {
+	defer metrics.ObserveQuery("tag", "UpdateTag")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tag models.Tag
+
+		if err := tx.Take(&tag, id).Error; err != nil {
+			slog.ErrorContext(ctx, "update tag failed", "error", err)
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Model(&tag).Update("name", normalizeTag(name)).Error; err != nil {
+			slog.ErrorContext(ctx, "update tag failed", "error", err)
+			return err
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (r *TagRepository) DeleteTag(ctx context.Context, id uint) error {
+	defer metrics.ObserveQuery("tag", "DeleteTag")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tag models.Tag
+
+		if err := tx.Take(&tag, id).Error; err != nil {
+			slog.ErrorContext(ctx, "delete tag failed", "error", err)
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Exec("DELETE FROM subscription_tags WHERE tag_id = ?", id).Error; err != nil {
+			slog.ErrorContext(ctx, "delete tag failed", "error", err)
+			return err
+		}
+
+		return tx.Delete(&tag).Error
+	})
+
+	return err
+}
+
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// findOrCreateTags returns tag records for the passed names, creating the missing ones.
+func findOrCreateTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		name = normalizeTag(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		var tag models.Tag
+		if err := tx.Where(models.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			slog.ErrorContext(tx.Statement.Context, "find or create tags failed", "error", err)
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// replaceTags sets the tags of the subscription to the passed names.
+func replaceTags(tx *gorm.DB, record *models.Subscription, names []string) error {
+	tags, err := findOrCreateTags(tx, names)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(record).Association("Tags").Replace(tags); err != nil {
+		slog.ErrorContext(tx.Statement.Context, "replace tags failed", "error", err)
+		return err
+	}
+
+	return nil
+}
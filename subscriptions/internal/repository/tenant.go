@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/tenant"
+
+	"gorm.io/gorm"
+)
+
+type TenantRepo interface {
+	GetTenants(ctx context.Context) ([]models.Tenant, error)
+	GetTenant(ctx context.Context, id string) (*models.Tenant, error)
+	TenantExists(ctx context.Context, id string) (bool, error)
+	CreateTenant(ctx context.Context, id, name string) error
+	UpdateTenant(ctx context.Context, id, name string) error
+	GetTenantStats(ctx context.Context, id string) (*TenantStats, error)
+}
+
+type TenantStats struct {
+	Users         int64
+	Subscriptions int64
+	Categories    int64
+	Webhooks      int64
+	APIKeys       int64
+}
+
+type TenantRepository struct {
+	DB *gorm.DB
+}
+
+func NewTenantRepository(database *gorm.DB) TenantRepo {
+	return &TenantRepository{
+		DB: database,
+	}
+}
+
+func (r *TenantRepository) GetTenants(ctx context.Context) ([]models.Tenant, error) {
+	defer metrics.ObserveQuery("tenant", "GetTenants")()
+
+	var tenants []models.Tenant
+
+	if err := r.DB.WithContext(ctx).Order("id").Find(&tenants).Error; err != nil {
+		slog.ErrorContext(ctx, "get tenants failed", "error", err)
+		return nil, err
+	}
+
+	return tenants, nil
+}
+
+func (r *TenantRepository) GetTenant(ctx context.Context, id string) (*models.Tenant, error) {
+	defer metrics.ObserveQuery("tenant", "GetTenant")()
+
+	var record models.Tenant
+
+	if err := r.DB.WithContext(ctx).Take(&record, "id = ?", id).Error; err != nil {
+		slog.ErrorContext(ctx, "get tenant failed", "error", err)
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &record, nil
+}
+
+func (r *TenantRepository) TenantExists(ctx context.Context, id string) (bool, error) {
+	defer metrics.ObserveQuery("tenant", "TenantExists")()
+
+	var count int64
+
+	if err := r.DB.WithContext(ctx).Model(&models.Tenant{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		slog.ErrorContext(ctx, "tenant exists failed", "error", err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *TenantRepository) CreateTenant(ctx context.Context, id, name string) error {
+	defer metrics.ObserveQuery("tenant", "CreateTenant")()
+
+	if err := r.DB.WithContext(ctx).Create(&models.Tenant{ID: id, Name: name}).Error; err != nil {
+		slog.ErrorContext(ctx, "create tenant failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *TenantRepository) UpdateTenant(ctx context.Context, id, name string) error {
+	defer metrics.ObserveQuery("tenant", "UpdateTenant")()
+
+	result := r.DB.WithContext(ctx).Model(&models.Tenant{}).Where("id = ?", id).Update("name", name)
+	if result.Error != nil {
+		slog.ErrorContext(ctx, "update tenant failed", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetTenantStats counts the records of the tenant through the tenant scope.
+func (r *TenantRepository) GetTenantStats(ctx context.Context, id string) (*TenantStats, error) {
+	defer metrics.ObserveQuery("tenant", "GetTenantStats")()
+
+	ctx = tenant.WithID(ctx, id)
+
+	var stats TenantStats
+	counts := []struct {
+		model any
+		count *int64
+	}{
+		{&models.User{}, &stats.Users},
+		{&models.Subscription{}, &stats.Subscriptions},
+		{&models.Category{}, &stats.Categories},
+		{&models.Webhook{}, &stats.Webhooks},
+		{&models.APIKey{}, &stats.APIKeys},
+	}
+
+	for _, c := range counts {
+		if err := r.DB.WithContext(ctx).Model(c.model).Count(c.count).Error; err != nil {
+			slog.ErrorContext(ctx, "get tenant stats failed", "error", err)
+			return nil, err
+		}
+	}
+
+	return &stats, nil
+}
+
+// tenantFromContext returns the tenant raw SQL must be limited to.
+func tenantFromContext(ctx context.Context) (string, error) {
+	id, ok := tenant.FromContext(ctx)
+	if !ok {
+		return "", tenant.ErrMissing
+	}
+
+	return id, nil
+}
+
+// checkCategory makes sure the category belongs to the tenant of the transaction,
+// since the foreign key alone accepts categories of other tenants.
+func checkCategory(tx *gorm.DB, categoryID *uint) error {
+	if categoryID == nil {
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(&models.Category{}).Where("id = ?", *categoryID).Count(&count).Error; err != nil {
+		slog.ErrorContext(tx.Statement.Context, "check category failed", "error", err)
+		return err
+	}
+
+	if count == 0 {
+		return gorm.ErrForeignKeyViolated
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/tenant"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	db := openDB(t)
+	subsRepo := repository.NewRepository(db)
+	userRepo := repository.NewUserRepository(db)
+
+	owner := newTenant(t, db)
+	other := newTenant(t, db)
+	userID := newUser(t, owner, db)
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	id, err := subsRepo.CreateRecord(owner, "Service", start, 100, userID, nil, nil, []string{"music"}, nil)
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		// run runs the statement in the context of the tenant and returns its error
+		run func(ctx context.Context) error
+		// hidden is the error of the statement of the other tenant, the ones finding nothing
+		// return gorm.ErrRecordNotFound too
+		hidden error
+	}{
+		{
+			name: "get subscription",
+			run: func(ctx context.Context) error {
+				_, err := subsRepo.GetRecord(ctx, *id)
+				return err
+			},
+			hidden: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "list subscriptions",
+			run: func(ctx context.Context) error {
+				records, _, err := subsRepo.GetRecords(ctx, 0, 10, repository.SubsFilter{UserID: &userID})
+				if err == nil && len(records) == 0 {
+					return gorm.ErrRecordNotFound
+				}
+				return err
+			},
+			hidden: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "sum",
+			run: func(ctx context.Context) error {
+				sum := subsRepo.GetSubsSum(ctx, &userID, nil, nil, "2025-01-01", "2025-03-01")
+				if sum == nil || *sum == 0 {
+					return gorm.ErrRecordNotFound
+				}
+				return nil
+			},
+			hidden: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "get user",
+			run: func(ctx context.Context) error {
+				_, err := userRepo.GetUser(ctx, userID)
+				return err
+			},
+			hidden: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "update subscription",
+			run: func(ctx context.Context) error {
+				_, err := subsRepo.UpdateRecord(ctx, *id, map[string]any{"price": 200}, nil, nil)
+				return err
+			},
+			hidden: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "replace subscription",
+			run: func(ctx context.Context) error {
+				_, err := subsRepo.FullUpdateRecord(ctx, *id, 200, "Other", start, userID, nil, nil, nil, nil)
+				return err
+			},
+			hidden: gorm.ErrRecordNotFound,
+		},
+		{
+			name: "delete subscription",
+			run: func(ctx context.Context) error {
+				_, err := subsRepo.DeleteRecord(ctx, *id)
+				return err
+			},
+			hidden: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(other); !errors.Is(err, tt.hidden) {
+				t.Errorf("expected error %v of the other tenant, got %v", tt.hidden, err)
+			}
+
+			// the data of the owner is untouched
+			record, err := subsRepo.GetRecord(owner, *id)
+			if err != nil {
+				t.Fatalf("get subscription of the owner: %v", err)
+			}
+			if record.Price != 100 || record.ServiceName != "Service" {
+				t.Errorf("expected the subscription of the owner unchanged, got %+v", record)
+			}
+		})
+	}
+
+	t.Run("statements without tenant fail", func(t *testing.T) {
+		if _, err := subsRepo.GetRecord(context.Background(), *id); err == nil {
+			t.Error("expected the subscription not found without tenant")
+		}
+		if _, _, err := subsRepo.GetRecords(context.Background(), 0, 10, repository.SubsFilter{}); err == nil {
+			t.Error("expected the list failing without tenant")
+		}
+	})
+
+	t.Run("owner sees the subscription", func(t *testing.T) {
+		records, _, err := subsRepo.GetRecords(owner, 0, 10, repository.SubsFilter{UserID: &userID})
+		if err != nil {
+			t.Fatalf("list subscriptions: %v", err)
+		}
+		if len(records) != 1 || records[0].ID != *id {
+			t.Fatalf("expected the subscription %d, got %+v", *id, records)
+		}
+		ownerID, _ := tenant.FromContext(owner)
+		if records[0].TenantID != ownerID {
+			t.Errorf("expected tenant %q, got %q", ownerID, records[0].TenantID)
+		}
+	})
+
+	t.Run("user ids are unique across tenants", func(t *testing.T) {
+		err := userRepo.CreateUser(other, userID, "Other user", nil, "en", "RUB")
+		if err == nil {
+			t.Error("expected the id of the user of another tenant rejected")
+		}
+		if _, err := userRepo.GetUser(owner, userID); err != nil {
+			t.Errorf("expected the user of the owner kept, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/pkg/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUserHasSubscriptions = errors.New("user has subscriptions")
+	ErrUserOfOtherTenant    = errors.New("user belongs to another tenant")
+)
+
+type UserRepo interface {
+	GetUsers(ctx context.Context, offset, size int) ([]models.User, *int, error)
+	GetUser(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]models.User, error)
+	UserExists(ctx context.Context, id uuid.UUID) (bool, error)
+	CreateUser(ctx context.Context, id uuid.UUID, displayName string, email *string, locale, currency string) error
+	EnsureUser(ctx context.Context, id uuid.UUID) error
+	UpdateUser(ctx context.Context, id uuid.UUID, displayName string, email *string, locale, currency string) error
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+}
+
+type UserRepository struct {
+	DB *gorm.DB
+}
+
+func NewUserRepository(database *gorm.DB) UserRepo {
+	return &UserRepository{
+		DB: database,
+	}
+}
+
+func (r *UserRepository) GetUsers(ctx context.Context, offset, size int) ([]models.User, *int, error) {
+	defer metrics.ObserveQuery("user", "GetUsers")()
+	ctx = database.ReadOnly(ctx)
+
+	var users []models.User
+
+	var total int64
+	if err := r.DB.WithContext(ctx).Model(&models.User{}).Count(&total).Error; err != nil {
+		slog.ErrorContext(ctx, "get users failed", "error", err)
+		return nil, nil, err
+	}
+
+	totalPages := int((total + int64(size) - 1) / int64(size))
+
+	if err := r.DB.WithContext(ctx).Order("created_at, id").Limit(size).Offset(offset).Find(&users).Error; err != nil {
+		slog.ErrorContext(ctx, "get users failed", "error", err)
+		return nil, nil, err
+	}
+
+	return users, &totalPages, nil
+}
+
+func (r *UserRepository) GetUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	defer metrics.ObserveQuery("user", "GetUser")()
+	ctx = database.ReadOnly(ctx)
+
+	var user models.User
+
+	if err := r.DB.WithContext(ctx).Take(&user, "id = ?", id).Error; err != nil {
+		slog.ErrorContext(ctx, "get user failed", "error", err)
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]models.User, error) {
+	defer metrics.ObserveQuery("user", "GetUsersByIDs")()
+	ctx = database.ReadOnly(ctx)
+
+	var users []models.User
+
+	if err := r.DB.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error; err != nil {
+		slog.ErrorContext(ctx, "get users by ids failed", "error", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) UserExists(ctx context.Context, id uuid.UUID) (bool, error) {
+	defer metrics.ObserveQuery("user", "UserExists")()
+
+	var count int64
+
+	if err := r.DB.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		slog.ErrorContext(ctx, "user exists failed", "error", err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, id uuid.UUID, displayName string, email *string, locale, currency string) error {
+	defer metrics.ObserveQuery("user", "CreateUser")()
+
+	user := models.User{
+		ID:                id,
+		DisplayName:       displayName,
+		Email:             email,
+		Locale:            locale,
+		PreferredCurrency: currency,
+	}
+
+	if err := r.DB.WithContext(ctx).Create(&user).Error; err != nil {
+		slog.ErrorContext(ctx, "create user failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// EnsureUser creates a user with default settings if there is no user with this id.
+// It fails with ErrUserOfOtherTenant if the id is taken by a user of another tenant.
+func (r *UserRepository) EnsureUser(ctx context.Context, id uuid.UUID) error {
+	defer metrics.ObserveQuery("user", "EnsureUser")()
+
+	user := models.User{ID: id}
+
+	result := r.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&user)
+	if result.Error != nil {
+		slog.ErrorContext(ctx, "ensure user failed", "error", result.Error)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		exists, err := r.UserExists(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrUserOfOtherTenant
+		}
+	}
+
+	return nil
+}
+
+func (r *UserRepository) UpdateUser(ctx context.Context, id uuid.UUID, displayName string, email *string, locale, currency string) error {
+	defer metrics.ObserveQuery("user", "UpdateUser")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+
+		if err := tx.Take(&user, "id = ?", id).Error; err != nil {
+			slog.ErrorContext(ctx, "update user failed", "error", err)
+			return gorm.ErrRecordNotFound
+		}
+
+		user.DisplayName = displayName
+		user.Email = email
+		user.Locale = locale
+		user.PreferredCurrency = currency
+
+		if err := tx.Save(&user).Error; err != nil {
+			slog.ErrorContext(ctx, "update user failed", "error", err)
+			return err
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (r *UserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	defer metrics.ObserveQuery("user", "DeleteUser")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Take(&models.User{}, "id = ?", id).Error; err != nil {
+			slog.ErrorContext(ctx, "delete user failed", "error", err)
+			return gorm.ErrRecordNotFound
+		}
+
+		var subsCount int64
+		if err := tx.Model(&models.Subscription{}).Where("user_id = ?", id).Count(&subsCount).Error; err != nil {
+			slog.ErrorContext(ctx, "delete user failed", "error", err)
+			return err
+		}
+
+		if subsCount > 0 {
+			return ErrUserHasSubscriptions
+		}
+
+		return tx.Delete(&models.User{}, "id = ?", id).Error
+	})
+
+	return err
+}
@@ -0,0 +1,62 @@
+package repository_test
+
+import (
+	"errors"
+	"subscriptions/rest-service/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestUsers(t *testing.T) {
+	db := openDB(t)
+	userRepo := repository.NewUserRepository(db)
+	subsRepo := repository.NewRepository(db)
+	ctx := newTenant(t, db)
+
+	email := uuid.NewString() + "@example.com"
+	owner, other := uuid.New(), uuid.New()
+
+	if err := userRepo.CreateUser(ctx, owner, "Ivan", &email, "ru", "RUB"); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := subsRepo.CreateRecord(ctx, "Service", start, 100, owner, nil, nil, nil, nil); err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		run  func() error
+		err  error
+	}{
+		{name: "duplicate id", run: func() error { return userRepo.CreateUser(ctx, owner, "Ivan", nil, "ru", "RUB") }, err: gorm.ErrDuplicatedKey},
+		{name: "duplicate email", run: func() error { return userRepo.CreateUser(ctx, other, "Petr", &email, "ru", "RUB") }, err: gorm.ErrDuplicatedKey},
+		{name: "ensure existing user", run: func() error { return userRepo.EnsureUser(ctx, owner) }},
+		{name: "ensure new user", run: func() error { return userRepo.EnsureUser(ctx, other) }},
+		{name: "update missing user", run: func() error { return userRepo.UpdateUser(ctx, uuid.New(), "Anna", nil, "ru", "RUB") }, err: gorm.ErrRecordNotFound},
+		{name: "delete user with subscriptions", run: func() error { return userRepo.DeleteUser(ctx, owner) }, err: repository.ErrUserHasSubscriptions},
+		{name: "delete user without subscriptions", run: func() error { return userRepo.DeleteUser(ctx, other) }},
+		{name: "delete missing user", run: func() error { return userRepo.DeleteUser(ctx, other) }, err: gorm.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+
+	t.Run("subscriptions of the user", func(t *testing.T) {
+		records, _, err := subsRepo.GetRecords(ctx, 0, 100, repository.SubsFilter{UserID: &owner})
+		if err != nil {
+			t.Fatalf("get records: %v", err)
+		}
+		if len(records) != 1 || records[0].UserID != owner {
+			t.Errorf("expected the subscription of %s, got %v", owner, records)
+		}
+	})
+}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepo interface {
+	GetWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, id uint) (*models.Webhook, error)
+	GetActiveWebhooks(ctx context.Context, eventType string) ([]models.Webhook, error)
+	CreateWebhook(ctx context.Context, url, secret string, eventTypes []string, active bool) (*uint, error)
+	UpdateWebhook(ctx context.Context, id uint, url string, secret *string, eventTypes []string, active bool) error
+	DeleteWebhook(ctx context.Context, id uint) error
+	GetDeliveries(ctx context.Context, webhookID uint, offset, size int) ([]models.WebhookDelivery, *int, error)
+	GetDelivery(ctx context.Context, webhookID, id uint) (*models.WebhookDelivery, error)
+	CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	SaveDeliveryResult(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+type WebhookRepository struct {
+	DB *gorm.DB
+}
+
+func NewWebhookRepository(database *gorm.DB) WebhookRepo {
+	return &WebhookRepository{
+		DB: database,
+	}
+}
+
+func (r *WebhookRepository) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	defer metrics.ObserveQuery("webhook", "GetWebhooks")()
+
+	var webhooks []models.Webhook
+
+	if err := r.DB.WithContext(ctx).Order("id").Find(&webhooks).Error; err != nil {
+		slog.ErrorContext(ctx, "get webhooks failed", "error", err)
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	defer metrics.ObserveQuery("webhook", "GetWebhook")()
+
+	var webhook models.Webhook
+
+	if err := r.DB.WithContext(ctx).Take(&webhook, id).Error; err != nil {
+		slog.ErrorContext(ctx, "get webhook failed", "error", err)
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) GetActiveWebhooks(ctx context.Context, eventType string) ([]models.Webhook, error) {
+	defer metrics.ObserveQuery("webhook", "GetActiveWebhooks")()
+
+	var webhooks []models.Webhook
+
+	eventTypeJSON, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.DB.WithContext(ctx).Where("active AND event_types @> ?::jsonb", string(eventTypeJSON)).Find(&webhooks).Error; err != nil {
+		slog.ErrorContext(ctx, "get active webhooks failed", "error", err)
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, url, secret string, eventTypes []string, active bool) (*uint, error) {
+	defer metrics.ObserveQuery("webhook", "CreateWebhook")()
+
+	webhook := models.Webhook{
+		URL:        url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     active,
+	}
+
+	// Select makes GORM write active=false instead of falling back to the column default
+	if err := r.DB.WithContext(ctx).Select("*").Omit("id").Create(&webhook).Error; err != nil {
+		slog.ErrorContext(ctx, "create webhook failed", "error", err)
+		return nil, err
+	}
+
+	return &webhook.ID, nil
+}
+
+func (r *WebhookRepository) UpdateWebhook(ctx context.Context, id uint, url string, secret *string, eventTypes []string, active bool) error {
+	defer metrics.ObserveQuery("webhook", "UpdateWebhook")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var webhook models.Webhook
+
+		if err := tx.Take(&webhook, id).Error; err != nil {
+			slog.ErrorContext(ctx, "update webhook failed", "error", err)
+			return gorm.ErrRecordNotFound
+		}
+
+		webhook.URL = url
+		webhook.EventTypes = eventTypes
+		webhook.Active = active
+		if secret != nil {
+			webhook.Secret = *secret
+		}
+
+		if err := tx.Save(&webhook).Error; err != nil {
+			slog.ErrorContext(ctx, "update webhook failed", "error", err)
+			return err
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, id uint) error {
+	defer metrics.ObserveQuery("webhook", "DeleteWebhook")()
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Take(&models.Webhook{}, id).Error; err != nil {
+			slog.ErrorContext(ctx, "delete webhook failed", "error", err)
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Delete(&models.Webhook{}, id).Error
+	})
+
+	return err
+}
+
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, webhookID uint, offset, size int) ([]models.WebhookDelivery, *int, error) {
+	defer metrics.ObserveQuery("webhook", "GetDeliveries")()
+
+	var deliveries []models.WebhookDelivery
+
+	var total int64
+	if err := r.DB.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID).Count(&total).Error; err != nil {
+		slog.ErrorContext(ctx, "get deliveries failed", "error", err)
+		return nil, nil, err
+	}
+
+	totalPages := int((total + int64(size) - 1) / int64(size))
+
+	if err := r.DB.WithContext(ctx).Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(size).
+		Offset(offset).
+		Find(&deliveries).Error; err != nil {
+		slog.ErrorContext(ctx, "get deliveries failed", "error", err)
+		return nil, nil, err
+	}
+
+	return deliveries, &totalPages, nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, webhookID, id uint) (*models.WebhookDelivery, error) {
+	defer metrics.ObserveQuery("webhook", "GetDelivery")()
+
+	var delivery models.WebhookDelivery
+
+	if err := r.DB.WithContext(ctx).Where("webhook_id = ?", webhookID).Take(&delivery, id).Error; err != nil {
+		slog.ErrorContext(ctx, "get delivery failed", "error", err)
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &delivery, nil
+}
+
+// CreateDeliveries skips the deliveries of an event already scheduled for the same webhook,
+// an event published again doesn't duplicate them.
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	defer metrics.ObserveQuery("webhook", "CreateDeliveries")()
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := r.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+		slog.ErrorContext(ctx, "create deliveries failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// ClaimDueDeliveries returns pending deliveries whose attempt is due and postpones
+// their next attempt by lease, so other instances don't send them concurrently.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	defer metrics.ObserveQuery("webhook", "ClaimDueDeliveries")()
+
+	var ids []uint
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+			Order("id").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			slog.ErrorContext(ctx, "claim due deliveries failed", "error", err)
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := r.DB.WithContext(ctx).Preload("Webhook").Where("id IN ?", ids).Order("id").Find(&deliveries).Error; err != nil {
+		slog.ErrorContext(ctx, "claim due deliveries failed", "error", err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) SaveDeliveryResult(ctx context.Context, delivery *models.WebhookDelivery) error {
+	defer metrics.ObserveQuery("webhook", "SaveDeliveryResult")()
+
+	err := r.DB.WithContext(ctx).Model(delivery).Select(
+		"status", "attempts", "next_attempt_at", "response_code", "response_body", "last_error", "delivered_at",
+	).Updates(delivery).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "save delivery result failed", "error", err)
+		return err
+	}
+
+	return nil
+}
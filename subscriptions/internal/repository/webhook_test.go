@@ -0,0 +1,61 @@
+package repository_test
+
+import (
+	"math/rand/v2"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestCreateDeliveries(t *testing.T) {
+	db := openDB(t)
+	webhookRepo := repository.NewWebhookRepository(db)
+	ctx := newTenant(t, db)
+
+	webhookID, err := webhookRepo.CreateWebhook(ctx, "http://localhost/hook", "secret", []string{"subscription.created"}, true)
+	if err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	t.Cleanup(func() { webhookRepo.DeleteWebhook(ctx, *webhookID) })
+
+	eventID := uint(rand.Int32())
+	delivery := func(eventID *uint) []models.WebhookDelivery {
+		return []models.WebhookDelivery{{
+			WebhookID:     *webhookID,
+			EventID:       eventID,
+			EventType:     "subscription.created",
+			Payload:       `{}`,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		deliveries []models.WebhookDelivery
+		// total is the expected number of the deliveries of the webhook after the write
+		total int64
+	}{
+		{name: "delivery of an event", deliveries: delivery(&eventID), total: 1},
+		{name: "event published again", deliveries: delivery(&eventID), total: 1},
+		{name: "redelivery", deliveries: delivery(nil), total: 2},
+		{name: "one more redelivery", deliveries: delivery(nil), total: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := webhookRepo.CreateDeliveries(ctx, tt.deliveries); err != nil {
+				t.Fatalf("create deliveries: %v", err)
+			}
+
+			var total int64
+			if err := db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("webhook_id = ?", *webhookID).Count(&total).Error; err != nil {
+				t.Fatalf("count deliveries: %v", err)
+			}
+			if total != tt.total {
+				t.Errorf("expected %d deliveries, got %d", tt.total, total)
+			}
+		})
+	}
+}
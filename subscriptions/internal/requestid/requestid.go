@@ -0,0 +1,44 @@
+/*
+Package requestid keeps the ID of the request in the context, the ID is taken
+from the caller or generated and is returned in the responses and logs.
+*/
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Header is the request and response header and gRPC metadata key of the request ID.
+const Header = "X-Request-ID"
+
+// idPattern keeps the IDs of the callers short and free of characters that break logs.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type requestIDKey struct{}
+
+// Valid reports whether the ID of the caller may be used as the request ID.
+func Valid(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// Resolve returns the ID of the caller if it is valid and a new ID otherwise.
+func Resolve(id string) string {
+	if Valid(id) {
+		return id
+	}
+
+	return uuid.NewString()
+}
+
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// FromContext returns the ID of the request or empty string outside of requests.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
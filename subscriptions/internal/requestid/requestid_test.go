@@ -0,0 +1,60 @@
+package requestid_test
+
+import (
+	"context"
+	"strings"
+	"subscriptions/rest-service/internal/requestid"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		keep bool
+	}{
+		{"uuid", "5f0c6a4e-9a4b-4d1e-8f3e-2b7c1d0e9a11", true},
+		{"short id", "req.42:a_b-c", true},
+		{"longest id", strings.Repeat("a", 128), true},
+		{"empty", "", false},
+		{"too long", strings.Repeat("a", 129), false},
+		{"spaces", "req 42", false},
+		{"newline", "req\n42", false},
+		{"quotes", `req"42`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestid.Valid(tt.id); got != tt.keep {
+				t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.keep)
+			}
+
+			got := requestid.Resolve(tt.id)
+			if tt.keep && got != tt.id {
+				t.Errorf("Resolve(%q) = %q, want the same ID", tt.id, got)
+			}
+			if !tt.keep && (got == tt.id || !requestid.Valid(got)) {
+				t.Errorf("Resolve(%q) = %q, want new valid ID", tt.id, got)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"outside of requests", context.Background(), ""},
+		{"request", requestid.WithID(context.Background(), "abc"), "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestid.FromContext(tt.ctx); got != tt.want {
+				t.Errorf("FromContext() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
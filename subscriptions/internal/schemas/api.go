@@ -1,7 +1,27 @@
 package schemas
 
-type APIError struct {
-	Error string `json:"error"`
+// Problem is the RFC 7807 error response of the API, sent as application/problem+json.
+type Problem struct {
+	// Type identifies the kind of the problem, about:blank if the status says it all.
+	Type   string `json:"type" example:"about:blank"`
+	Title  string `json:"title" example:"Not Found"`
+	Status int    `json:"status" example:"404"`
+	// Code is the stable code of the detail, the detail is in the language of Accept-Language.
+	Code   string `json:"code,omitempty" example:"subscription_not_found"`
+	Detail string `json:"detail,omitempty" example:"subscription not found"`
+	// Instance is the path of the failed request.
+	Instance  string `json:"instance,omitempty" example:"/api/v1/subs/42"`
+	RequestID string `json:"request_id,omitempty"`
+	// Errors lists the invalid fields of the request.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	// Field is the JSON path of the field, e.g. tags[1].
+	Field string `json:"field" example:"price"`
+	// Rule is the validation rule the field breaks.
+	Rule    string `json:"rule" example:"gt"`
+	Message string `json:"message" example:"price must be greater than 0"`
 }
 
 type CreateReturn struct {
@@ -14,4 +34,15 @@ type MessageReturn struct {
 
 type SumReturn struct {
 	TotalSum uint `json:"total_sum"`
-}
\ No newline at end of file
+}
+
+type MonthSumInfo struct {
+	// Month is in 'mm-yyyy' format.
+	Month    string `json:"month" example:"01-2025"`
+	TotalSum uint   `json:"total_sum"`
+}
+
+type MonthSumReturn struct {
+	Months   []MonthSumInfo `json:"months"`
+	TotalSum uint           `json:"total_sum"`
+}
@@ -0,0 +1,30 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateAPIKey struct {
+	Name   string   `json:"name" validate:"required,min=1,max=128"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,scope"`
+	// UserID binds the key to the user, keys without user are service accounts.
+	UserID *uuid.UUID `json:"user_id,omitempty" swaggertype:"string" format:"uuid" validate:"omitempty"`
+}
+
+type APIKeyInfo struct {
+	ID         uint       `json:"id" validate:"required"`
+	Name       string     `json:"name" validate:"required"`
+	Prefix     string     `json:"prefix" validate:"required"`
+	Scopes     []string   `json:"scopes" validate:"required"`
+	UserID     *uuid.UUID `json:"user_id,omitempty" swaggertype:"string" format:"uuid"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" swaggertype:"string" format:"nullable"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" swaggertype:"string" format:"nullable"`
+}
+
+type CreateAPIKeyReturn struct {
+	ID  uint   `json:"id"`
+	Key string `json:"key"`
+}
@@ -0,0 +1,44 @@
+package schemas
+
+import "github.com/google/uuid"
+
+type CreateBudget struct {
+	UserID       uuid.UUID `json:"user_id" validate:"required,uuid"`
+	CategoryID   *uint     `json:"category_id,omitempty" swaggertype:"integer" format:"nullable"`
+	ServiceName  *string   `json:"service_name,omitempty" swaggertype:"string" format:"nullable" validate:"omitempty,min=1,max=150"`
+	MonthlyLimit uint      `json:"monthly_limit" validate:"required,numeric,gt=0"`
+}
+
+type UpdateBudget struct {
+	CategoryID   *uint   `json:"category_id,omitempty" swaggertype:"integer" format:"nullable"`
+	ServiceName  *string `json:"service_name,omitempty" swaggertype:"string" format:"nullable" validate:"omitempty,min=1,max=150"`
+	MonthlyLimit uint    `json:"monthly_limit" validate:"required,numeric,gt=0"`
+}
+
+type BudgetInfo struct {
+	ID           uint      `json:"id" validate:"required"`
+	UserID       uuid.UUID `json:"user_id" validate:"required"`
+	CategoryID   *uint     `json:"category_id,omitempty" swaggertype:"integer" format:"nullable"`
+	ServiceName  *string   `json:"service_name,omitempty" swaggertype:"string" format:"nullable"`
+	MonthlyLimit uint      `json:"monthly_limit" validate:"required"`
+}
+
+type BudgetStatus struct {
+	BudgetInfo
+	Charge    uint `json:"charge"`
+	Remaining int  `json:"remaining"`
+	Exceeded  bool `json:"exceeded"`
+}
+
+type BudgetStatusReturn struct {
+	UserID  uuid.UUID      `json:"user_id"`
+	Month   string         `json:"month"`
+	Budgets []BudgetStatus `json:"budgets"`
+}
+
+// BudgetExceededAlert is the payload of the budget exceeded event.
+type BudgetExceededAlert struct {
+	BudgetStatus
+	Month          string `json:"month"`
+	SubscriptionID uint   `json:"subscription_id"`
+}
@@ -0,0 +1,25 @@
+package schemas
+
+type CreateCategory struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	ParentID *uint  `json:"parent_id,omitempty" swaggertype:"integer" format:"nullable"`
+}
+
+type CategoryInfo struct {
+	ID       uint   `json:"id" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	ParentID *uint  `json:"parent_id,omitempty" swaggertype:"integer" format:"nullable"`
+}
+
+type CategorySumInfo struct {
+	CategoryID *uint  `json:"category_id" swaggertype:"integer" format:"nullable"`
+	Name       string `json:"name"`
+	ParentID   *uint  `json:"parent_id,omitempty" swaggertype:"integer" format:"nullable"`
+	OwnSum     uint   `json:"own_sum"`
+	TotalSum   uint   `json:"total_sum"`
+}
+
+type CategorySumReturn struct {
+	Categories []CategorySumInfo `json:"categories"`
+	TotalSum   uint              `json:"total_sum"`
+}
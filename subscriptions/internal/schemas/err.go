@@ -1,10 +1,13 @@
 package schemas
 
-import "fmt"
+import (
+	"fmt"
+	"subscriptions/rest-service/internal/i18n"
+)
 
 type AppError struct {
 	Code    int
-	Message string
+	Message i18n.Message
 	Err     error
 }
 
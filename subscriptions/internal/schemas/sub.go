@@ -12,6 +12,8 @@ type CreateSub struct {
 	UserID      uuid.UUID `json:"user_id" validate:"required,uuid"`
 	StartDate   string    `json:"start_date" validate:"required,mm_yyyy_date"`
 	EndDate     *string   `json:"end_date,omitempty" swaggertype:"string" format:"nullable" validate:"omitempty,mm_yyyy_date"`
+	CategoryID  *uint     `json:"category_id,omitempty" swaggertype:"integer" format:"nullable"`
+	Tags        []string  `json:"tags,omitempty" validate:"omitempty,dive,required,max=50"`
 }
 
 type FullSubInfo struct {
@@ -21,6 +23,8 @@ type FullSubInfo struct {
 	UserID      uuid.UUID  `json:"user_id" validate:"required,uuid"`
 	StartDate   time.Time  `json:"start_date" validate:"required"`
 	EndDate     *time.Time `json:"end_date,omitempty" swaggertype:"string" format:"nullable" validate:"omitempty"`
+	CategoryID  *uint      `json:"category_id,omitempty" swaggertype:"integer" format:"nullable"`
+	Tags        []string   `json:"tags"`
 }
 
 type FullUpdateSub struct {
@@ -29,6 +33,8 @@ type FullUpdateSub struct {
 	UserID      uuid.UUID `json:"user_id" validate:"required,uuid"`
 	StartDate   string    `json:"start_date" validate:"required,mm_yyyy_date"`
 	EndDate     *string   `json:"end_date,omitempty" swaggertype:"string" format:"nullable" validate:"omitempty,mm_yyyy_date"`
+	CategoryID  *uint     `json:"category_id,omitempty" swaggertype:"integer" format:"nullable"`
+	Tags        []string  `json:"tags,omitempty" validate:"omitempty,dive,required,max=50"`
 }
 
 type PatchUpdateSub struct {
@@ -37,6 +43,8 @@ type PatchUpdateSub struct {
 	UserID      *uuid.UUID `json:"user_id,omitempty" swaggertype:"string" format:"nullable"`
 	StartDate   *string    `json:"start_date,omitempty" swaggertype:"string" format:"nullable" validate:"omitempty,mm_yyyy_date"`
 	EndDate     *string    `json:"end_date,omitempty" swaggertype:"string" format:"nullable" validate:"omitempty,mm_yyyy_date"`
+	CategoryID  *uint      `json:"category_id,omitempty" swaggertype:"integer" format:"nullable"`
+	Tags        *[]string  `json:"tags,omitempty" validate:"omitempty,dive,required,max=50"`
 }
 
 type Pagination struct {
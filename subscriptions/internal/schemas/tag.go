@@ -0,0 +1,10 @@
+package schemas
+
+type CreateTag struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+type TagInfo struct {
+	ID   uint   `json:"id" validate:"required"`
+	Name string `json:"name" validate:"required"`
+}
@@ -0,0 +1,30 @@
+package schemas
+
+import "time"
+
+type CreateTenant struct {
+	ID   string `json:"id" validate:"required,tenant_id"`
+	Name string `json:"name" validate:"required,min=1,max=150"`
+}
+
+type UpdateTenant struct {
+	Name string `json:"name" validate:"required,min=1,max=150"`
+}
+
+type TenantInfo struct {
+	ID        string    `json:"id" validate:"required"`
+	Name      string    `json:"name" validate:"required"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TenantStats struct {
+	Users         int64 `json:"users"`
+	Subscriptions int64 `json:"subscriptions"`
+	Categories    int64 `json:"categories"`
+	Webhooks      int64 `json:"webhooks"`
+	APIKeys       int64 `json:"api_keys"`
+}
+
+type CreateTenantReturn struct {
+	ID string `json:"id"`
+}
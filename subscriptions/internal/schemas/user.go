@@ -0,0 +1,47 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateUser struct {
+	ID                *uuid.UUID `json:"id,omitempty" swaggertype:"string" format:"nullable"`
+	DisplayName       string     `json:"display_name" validate:"required,max=150"`
+	Email             *string    `json:"email,omitempty" swaggertype:"string" format:"nullable" validate:"omitempty,email,max=254"`
+	Locale            string     `json:"locale,omitempty" validate:"omitempty,bcp47_language_tag"`
+	PreferredCurrency string     `json:"preferred_currency,omitempty" validate:"omitempty,iso4217"`
+}
+
+type UpdateUser struct {
+	DisplayName       string  `json:"display_name" validate:"required,max=150"`
+	Email             *string `json:"email,omitempty" swaggertype:"string" format:"nullable" validate:"omitempty,email,max=254"`
+	Locale            string  `json:"locale,omitempty" validate:"omitempty,bcp47_language_tag"`
+	PreferredCurrency string  `json:"preferred_currency,omitempty" validate:"omitempty,iso4217"`
+}
+
+type UserInfo struct {
+	ID                uuid.UUID `json:"id" validate:"required"`
+	DisplayName       string    `json:"display_name"`
+	Email             *string   `json:"email,omitempty" swaggertype:"string" format:"nullable"`
+	Locale            string    `json:"locale" validate:"required"`
+	PreferredCurrency string    `json:"preferred_currency" validate:"required"`
+	CreatedAt         time.Time `json:"created_at" validate:"required"`
+}
+
+type UsersPaginationResponse struct {
+	Users      []UserInfo `json:"users" validate:"required"`
+	Pagination Pagination `json:"pagination" validate:"required"`
+}
+
+type CreateUserReturn struct {
+	ID uuid.UUID `json:"id"`
+}
+
+type UserSpendingReturn struct {
+	UserID   uuid.UUID `json:"user_id"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	TotalSum uint      `json:"total_sum"`
+}
@@ -0,0 +1,43 @@
+package schemas
+
+import "time"
+
+type CreateWebhook struct {
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,event_type"`
+	Secret     *string  `json:"secret,omitempty" swaggertype:"string" format:"nullable" validate:"omitempty,min=16,max=128"`
+	Active     *bool    `json:"active,omitempty" swaggertype:"boolean" format:"nullable"`
+}
+
+type WebhookInfo struct {
+	ID         uint      `json:"id" validate:"required"`
+	URL        string    `json:"url" validate:"required"`
+	EventTypes []string  `json:"event_types" validate:"required"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CreateWebhookReturn struct {
+	ID     uint   `json:"id"`
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryInfo struct {
+	ID            uint       `json:"id"`
+	WebhookID     uint       `json:"webhook_id"`
+	EventType     string     `json:"event_type"`
+	Payload       string     `json:"payload"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	ResponseCode  *int       `json:"response_code,omitempty" swaggertype:"integer" format:"nullable"`
+	ResponseBody  *string    `json:"response_body,omitempty" swaggertype:"string" format:"nullable"`
+	LastError     *string    `json:"last_error,omitempty" swaggertype:"string" format:"nullable"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty" swaggertype:"string" format:"nullable"`
+}
+
+type DeliveriesPaginationResponse struct {
+	Deliveries []WebhookDeliveryInfo `json:"deliveries" validate:"required"`
+	Pagination Pagination            `json:"pagination" validate:"required"`
+}
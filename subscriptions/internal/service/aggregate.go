@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"subscriptions/rest-service/internal/health"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/tenant"
+	"subscriptions/rest-service/internal/tracing"
+	"time"
+)
+
+// AggregateService maintains the monthly charges the sums are read from. The writes of
+// the subscriptions update the charges themselves, the service builds the charges of the
+// months coming into the horizon and checks them.
+type AggregateService struct {
+	repository repository.ChargeRepo
+	// monthsAhead is how many months after the current one the charges are built for.
+	monthsAhead int
+}
+
+func NewAggregateService(repo repository.ChargeRepo, monthsAhead int) AggregateService {
+	return AggregateService{
+		repository:  repo,
+		monthsAhead: monthsAhead,
+	}
+}
+
+// Run builds the charges until the horizon every interval until ctx is cancelled. The
+// charges of all months are built on the first run if they have never been built.
+func (s *AggregateService) Run(ctx context.Context, interval time.Duration) {
+	// the charges of all tenants are built by the same worker
+	ctx = tenant.Unscoped(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		health.Update(ctx, s.repository.ExtendCharges(ctx, s.horizon()))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Rebuild builds the charges of all tenants again from the subscriptions.
+func (s *AggregateService) Rebuild(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "AggregateService.Rebuild")
+	defer span.End()
+
+	return s.repository.RebuildCharges(tenant.Unscoped(ctx), s.horizon())
+}
+
+// Check returns the charges of all tenants differing from the ones calculated from the subscriptions.
+func (s *AggregateService) Check(ctx context.Context) ([]repository.ChargeDiff, error) {
+	ctx, span := tracing.Start(ctx, "AggregateService.Check")
+	defer span.End()
+
+	return s.repository.CheckCharges(tenant.Unscoped(ctx))
+}
+
+// horizon is the month the charges are built until.
+func (s *AggregateService) horizon() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month()+time.Month(s.monthsAhead)+1, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// lastUsedPrecision limits the writes of the last used time of busy keys.
+const lastUsedPrecision = time.Minute
+
+type APIKeyService struct {
+	repository repository.APIKeyRepo
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepo) APIKeyService {
+	return APIKeyService{
+		repository: repo,
+	}
+}
+
+func toAPIKeyInfo(record models.APIKey) schemas.APIKeyInfo {
+	return schemas.APIKeyInfo{
+		ID:         record.ID,
+		Name:       record.Name,
+		Prefix:     record.Prefix,
+		Scopes:     record.Scopes,
+		UserID:     record.UserID,
+		CreatedAt:  record.CreatedAt,
+		LastUsedAt: record.LastUsedAt,
+		RevokedAt:  record.RevokedAt,
+	}
+}
+
+func (s *APIKeyService) GetAllAPIKeys(ctx context.Context, userID *uuid.UUID) ([]schemas.APIKeyInfo, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.GetAllAPIKeys")
+	defer span.End()
+
+	records, err := s.repository.GetAPIKeys(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "get all api keys failed", "error", err)
+		return nil, &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: i18n.RetrieveAPIKeysFailed,
+			Err:     err,
+		}
+	}
+
+	result := make([]schemas.APIKeyInfo, len(records))
+	for i, record := range records {
+		result[i] = toAPIKeyInfo(record)
+	}
+
+	return result, nil
+}
+
+func (s *APIKeyService) GetAPIKey(ctx context.Context, id uint) (*schemas.APIKeyInfo, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.GetAPIKey")
+	defer span.End()
+
+	record, err := s.repository.GetAPIKey(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "get api key failed", "error", err)
+		return nil, apiKeyError(err, i18n.RetrieveAPIKeyFailed)
+	}
+
+	info := toAPIKeyInfo(*record)
+	return &info, nil
+}
+
+// CreateAPIKey issues the key and returns it, the key is not stored and can't be shown again.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, data schemas.CreateAPIKey) (*schemas.CreateAPIKeyReturn, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.CreateAPIKey")
+	defer span.End()
+
+	prefix, key, err := generateAPIKey()
+	if err != nil {
+		return nil, apiKeyError(err, i18n.GenerateAPIKeyFailed)
+	}
+
+	id, err := s.repository.CreateAPIKey(ctx, models.APIKey{
+		Name:   data.Name,
+		Prefix: prefix,
+		Hash:   hashAPIKey(key),
+		Scopes: data.Scopes,
+		UserID: data.UserID,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "create api key failed", "error", err)
+		return nil, apiKeyError(err, i18n.CreateAPIKeyFailed)
+	}
+
+	slog.InfoContext(ctx, "aPI key created")
+	return &schemas.CreateAPIKeyReturn{ID: *id, Key: key}, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "APIKeyService.RevokeAPIKey")
+	defer span.End()
+
+	if err := s.repository.RevokeAPIKey(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "revoke api key failed", "error", err)
+		return apiKeyError(err, i18n.RevokeAPIKeyFailed)
+	}
+
+	slog.InfoContext(ctx, "aPI key revoked")
+	return nil
+}
+
+// RotateAPIKey replaces the secret of the key keeping its name and scopes and returns the new key.
+func (s *APIKeyService) RotateAPIKey(ctx context.Context, id uint) (*schemas.CreateAPIKeyReturn, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.RotateAPIKey")
+	defer span.End()
+
+	record, err := s.repository.GetAPIKey(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "rotate api key failed", "error", err)
+		return nil, apiKeyError(err, i18n.RotateAPIKeyFailed)
+	}
+
+	if record.RevokedAt != nil {
+		return nil, &schemas.AppError{
+			Code:    http.StatusConflict,
+			Message: i18n.APIKeyRevoked,
+		}
+	}
+
+	prefix, key, err := generateAPIKey()
+	if err != nil {
+		return nil, apiKeyError(err, i18n.GenerateAPIKeyFailed)
+	}
+
+	if err := s.repository.RotateAPIKey(ctx, id, prefix, hashAPIKey(key)); err != nil {
+		slog.ErrorContext(ctx, "rotate api key failed", "error", err)
+		return nil, apiKeyError(err, i18n.RotateAPIKeyFailed)
+	}
+
+	slog.InfoContext(ctx, "aPI key rotated")
+	return &schemas.CreateAPIKeyReturn{ID: id, Key: key}, nil
+}
+
+// VerifyAPIKey resolves the active key to its principal and records its use.
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.VerifyAPIKey")
+	defer span.End()
+
+	prefix, ok := apiKeyPrefix(key)
+	if !ok {
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	record, err := s.repository.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	if subtle.ConstantTimeCompare([]byte(record.Hash), []byte(hashAPIKey(key))) != 1 || record.RevokedAt != nil {
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= lastUsedPrecision {
+		// failing to record the use doesn't fail the request
+		s.repository.TouchAPIKey(ctx, record.ID, now)
+	}
+
+	return &auth.Principal{
+		Subject:        "api-key:" + strconv.FormatUint(uint64(record.ID), 10),
+		UserID:         record.UserID,
+		Admin:          slices.Contains(record.Scopes, auth.ScopeAdmin),
+		ServiceAccount: record.UserID == nil,
+		Scopes:         record.Scopes,
+		TenantID:       record.TenantID,
+	}, nil
+}
+
+// generateAPIKey returns the key "sk_<id>_<secret>" and its public prefix "sk_<id>".
+func generateAPIKey() (string, string, error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix := auth.APIKeyPrefix + hex.EncodeToString(id)
+	return prefix, prefix + "_" + hex.EncodeToString(secret), nil
+}
+
+func apiKeyPrefix(key string) (string, bool) {
+	id, _, ok := strings.Cut(strings.TrimPrefix(key, auth.APIKeyPrefix), "_")
+	if !ok || id == "" {
+		return "", false
+	}
+
+	return auth.APIKeyPrefix + id, true
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyError(err error, message i18n.Message) error {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		return &schemas.AppError{
+			Code:    http.StatusNotFound,
+			Message: i18n.APIKeyNotFound,
+			Err:     err,
+		}
+	case gorm.ErrForeignKeyViolated:
+		return &schemas.AppError{
+			Code:    http.StatusBadRequest,
+			Message: i18n.UserNotFound,
+			Err:     err,
+		}
+	default:
+		return &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: message,
+			Err:     err,
+		}
+	}
+}
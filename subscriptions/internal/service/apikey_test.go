@@ -0,0 +1,221 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// apiKeyRepo keeps the keys in memory, the writes fail with err if it is set.
+type apiKeyRepo struct {
+	repository.APIKeyRepo
+
+	keys    []models.APIKey
+	err     error
+	touched int
+}
+
+func (r *apiKeyRepo) find(id uint) *models.APIKey {
+	for i := range r.keys {
+		if r.keys[i].ID == id {
+			return &r.keys[i]
+		}
+	}
+	return nil
+}
+
+func (r *apiKeyRepo) GetAPIKey(_ context.Context, id uint) (*models.APIKey, error) {
+	key := r.find(id)
+	if key == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	record := *key
+	return &record, nil
+}
+
+func (r *apiKeyRepo) GetAPIKeyByPrefix(_ context.Context, prefix string) (*models.APIKey, error) {
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			return &key, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *apiKeyRepo) CreateAPIKey(_ context.Context, key models.APIKey) (*uint, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	key.ID = uint(len(r.keys) + 1)
+	r.keys = append(r.keys, key)
+	return &key.ID, nil
+}
+
+func (r *apiKeyRepo) RevokeAPIKey(_ context.Context, id uint) error {
+	key := r.find(id)
+	if key == nil {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return nil
+}
+
+func (r *apiKeyRepo) RotateAPIKey(_ context.Context, id uint, prefix, hash string) error {
+	key := r.find(id)
+	key.Prefix, key.Hash = prefix, hash
+	return nil
+}
+
+func (r *apiKeyRepo) TouchAPIKey(_ context.Context, id uint, usedAt time.Time) error {
+	r.touched++
+	r.find(id).LastUsedAt = &usedAt
+	return nil
+}
+
+func createAPIKey(t *testing.T, apiKeyService service.APIKeyService, data schemas.CreateAPIKey) string {
+	t.Helper()
+
+	created, err := apiKeyService.CreateAPIKey(context.Background(), data)
+	if err != nil {
+		t.Fatalf("create api key: %v", err)
+	}
+	return created.Key
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	ctx := context.Background()
+	repo := &apiKeyRepo{}
+	apiKeyService := service.NewAPIKeyService(repo)
+	userID := uuid.New()
+
+	userKey := createAPIKey(t, apiKeyService, schemas.CreateAPIKey{Name: "user", Scopes: []string{auth.ScopeSubsRead}, UserID: &userID})
+	serviceKey := createAPIKey(t, apiKeyService, schemas.CreateAPIKey{Name: "billing", Scopes: []string{auth.ScopeAdmin}})
+	revokedKey := createAPIKey(t, apiKeyService, schemas.CreateAPIKey{Name: "revoked", Scopes: []string{auth.ScopeSubsRead}})
+	if err := apiKeyService.RevokeAPIKey(ctx, 3); err != nil {
+		t.Fatalf("revoke api key: %v", err)
+	}
+	rotatedKey := createAPIKey(t, apiKeyService, schemas.CreateAPIKey{Name: "rotated", Scopes: []string{auth.ScopeSubsRead}})
+	rotated, err := apiKeyService.RotateAPIKey(ctx, 4)
+	if err != nil {
+		t.Fatalf("rotate api key: %v", err)
+	}
+
+	// the secret differs from the issued one in the last character
+	wrongSecret := userKey[:len(userKey)-1] + "0"
+	if wrongSecret == userKey {
+		wrongSecret = userKey[:len(userKey)-1] + "1"
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		subject string
+		user    *uuid.UUID
+		admin   bool
+		account bool
+	}{
+		{name: "user key", key: userKey, subject: "api-key:1", user: &userID},
+		{name: "service account with admin scope", key: serviceKey, subject: "api-key:2", admin: true, account: true},
+		{name: "new key of the rotated key", key: rotated.Key, subject: "api-key:4", account: true},
+		{name: "wrong secret", key: wrongSecret},
+		{name: "revoked key", key: revokedKey},
+		{name: "old key of the rotated key", key: rotatedKey},
+		{name: "unknown prefix", key: "sk_0123456789ab_secret"},
+		{name: "malformed key", key: "sk_secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := apiKeyService.VerifyAPIKey(ctx, tt.key)
+
+			if tt.subject == "" {
+				if !errors.Is(err, auth.ErrInvalidCredentials) {
+					t.Fatalf("expected error %v, got %v", auth.ErrInvalidCredentials, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verify api key: %v", err)
+			}
+
+			if principal.Subject != tt.subject {
+				t.Errorf("expected subject %q, got %q", tt.subject, principal.Subject)
+			}
+			if (principal.UserID == nil) != (tt.user == nil) || (tt.user != nil && *principal.UserID != *tt.user) {
+				t.Errorf("expected user %v, got %v", tt.user, principal.UserID)
+			}
+			if principal.Admin != tt.admin || principal.ServiceAccount != tt.account {
+				t.Errorf("expected admin %t and service account %t, got %t and %t", tt.admin, tt.account, principal.Admin, principal.ServiceAccount)
+			}
+		})
+	}
+
+	t.Run("use recorded once a minute", func(t *testing.T) {
+		touched := repo.touched
+		for range 3 {
+			if _, err := apiKeyService.VerifyAPIKey(ctx, userKey); err != nil {
+				t.Fatalf("verify api key: %v", err)
+			}
+		}
+		if repo.touched != touched {
+			t.Errorf("expected the recently used key not touched, got %d touches", repo.touched-touched)
+		}
+	})
+}
+
+func TestAPIKeyErrors(t *testing.T) {
+	revokedAt := time.Now()
+	repo := &apiKeyRepo{keys: []models.APIKey{{ID: 1, Prefix: "sk_0123456789ab", RevokedAt: &revokedAt}}}
+	apiKeyService := service.NewAPIKeyService(repo)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+		code int
+	}{
+		{
+			name: "rotate revoked key",
+			call: func() error { _, err := apiKeyService.RotateAPIKey(ctx, 1); return err },
+			code: http.StatusConflict,
+		},
+		{
+			name: "rotate missing key",
+			call: func() error { _, err := apiKeyService.RotateAPIKey(ctx, 2); return err },
+			code: http.StatusNotFound,
+		},
+		{
+			name: "revoke missing key",
+			call: func() error { return apiKeyService.RevokeAPIKey(ctx, 2) },
+			code: http.StatusNotFound,
+		},
+		{
+			name: "create for missing user",
+			call: func() error {
+				failing := service.NewAPIKeyService(&apiKeyRepo{err: gorm.ErrForeignKeyViolated})
+				_, err := failing.CreateAPIKey(ctx, schemas.CreateAPIKey{Name: "user", Scopes: []string{auth.ScopeSubsRead}})
+				return err
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); errorCode(err) != tt.code {
+				t.Errorf("expected status %d, got %v", tt.code, err)
+			}
+		})
+	}
+}
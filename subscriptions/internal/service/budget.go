@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BudgetService struct {
+	repository     repository.BudgetRepo
+	subsRepository repository.SubscriptionRepo
+}
+
+func NewBudgetService(repo repository.BudgetRepo, subsRepo repository.SubscriptionRepo) BudgetService {
+	return BudgetService{
+		repository:     repo,
+		subsRepository: subsRepo,
+	}
+}
+
+func toBudgetInfo(record models.Budget) schemas.BudgetInfo {
+	return schemas.BudgetInfo{
+		ID:           record.ID,
+		UserID:       record.UserID,
+		CategoryID:   record.CategoryID,
+		ServiceName:  record.ServiceName,
+		MonthlyLimit: record.MonthlyLimit,
+	}
+}
+
+func (s *BudgetService) GetBudgets(ctx context.Context, userID *uuid.UUID) ([]schemas.BudgetInfo, error) {
+	ctx, span := tracing.Start(ctx, "BudgetService.GetBudgets")
+	defer span.End()
+
+	records, err := s.repository.GetBudgets(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "get budgets failed", "error", err)
+		return nil, &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: i18n.RetrieveBudgetsFailed,
+			Err:     err,
+		}
+	}
+
+	result := make([]schemas.BudgetInfo, len(records))
+	for i, record := range records {
+		result[i] = toBudgetInfo(record)
+	}
+
+	return result, nil
+}
+
+func (s *BudgetService) GetBudget(ctx context.Context, id uint) (*schemas.BudgetInfo, error) {
+	ctx, span := tracing.Start(ctx, "BudgetService.GetBudget")
+	defer span.End()
+
+	record, err := s.repository.GetBudget(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "get budget failed", "error", err)
+		return nil, budgetError(err, i18n.RetrieveBudgetFailed)
+	}
+
+	slog.InfoContext(ctx, "get budget", "id", id)
+	info := toBudgetInfo(*record)
+	return &info, nil
+}
+
+func (s *BudgetService) CreateBudget(ctx context.Context, data schemas.CreateBudget) (uint, error) {
+	ctx, span := tracing.Start(ctx, "BudgetService.CreateBudget")
+	defer span.End()
+
+	res, err := s.repository.CreateBudget(ctx, data.UserID, data.CategoryID, data.ServiceName, data.MonthlyLimit)
+	if err != nil {
+		slog.ErrorContext(ctx, "create budget failed", "error", err)
+		return 0, budgetError(err, i18n.CreateBudgetFailed)
+	}
+
+	slog.InfoContext(ctx, "budget created")
+	return *res, nil
+}
+
+func (s *BudgetService) UpdateBudget(ctx context.Context, id uint, data schemas.UpdateBudget) error {
+	ctx, span := tracing.Start(ctx, "BudgetService.UpdateBudget")
+	defer span.End()
+
+	if err := s.repository.UpdateBudget(ctx, id, data.CategoryID, data.ServiceName, data.MonthlyLimit); err != nil {
+		slog.ErrorContext(ctx, "update budget failed", "error", err)
+		return budgetError(err, i18n.UpdateBudgetFailed)
+	}
+
+	slog.InfoContext(ctx, "budget updated")
+	return nil
+}
+
+func (s *BudgetService) DeleteBudget(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "BudgetService.DeleteBudget")
+	defer span.End()
+
+	if err := s.repository.DeleteBudget(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "delete budget failed", "error", err)
+		return budgetError(err, i18n.DeleteBudgetFailed)
+	}
+
+	slog.InfoContext(ctx, "budget deleted")
+	return nil
+}
+
+// GetBudgetStatus compares the charge of the month ('mm-yyyy') with every budget of the user.
+func (s *BudgetService) GetBudgetStatus(ctx context.Context, userID uuid.UUID, month string) (*schemas.BudgetStatusReturn, error) {
+	ctx, span := tracing.Start(ctx, "BudgetService.GetBudgetStatus")
+	defer span.End()
+
+	monthStart, err := time.Parse("01-2006", month)
+	if err != nil {
+		return nil, &schemas.AppError{
+			Code:    http.StatusBadRequest,
+			Message: i18n.InvalidMonth,
+			Err:     err,
+		}
+	}
+
+	statuses, err := budgetStatuses(ctx, s.repository, s.subsRepository, userID, monthStart)
+	if err != nil {
+		slog.ErrorContext(ctx, "get budget status failed", "error", err)
+		return nil, &schemas.AppError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: i18n.BudgetStatusFailed,
+			Err:     err,
+		}
+	}
+
+	slog.InfoContext(ctx, "get budget status")
+	return &schemas.BudgetStatusReturn{
+		UserID:  userID,
+		Month:   month,
+		Budgets: statuses,
+	}, nil
+}
+
+// budgetStatuses calculates the charge of the month starting at monthStart for every budget of the user.
+// The charge is calculated the same way as the sum of subscriptions for a one month period.
+func budgetStatuses(
+	ctx context.Context,
+	budgetRepo repository.BudgetRepo,
+	subsRepo repository.SubscriptionRepo,
+	userID uuid.UUID,
+	monthStart time.Time,
+) ([]schemas.BudgetStatus, error) {
+	budgets, err := budgetRepo.GetBudgets(ctx, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	startDateSQL := monthStart.Format("2006-01-02")
+	endDateSQL := monthStart.AddDate(0, 1, 0).Format("2006-01-02")
+
+	statuses := make([]schemas.BudgetStatus, len(budgets))
+	for i, budget := range budgets {
+		charge := subsRepo.GetSubsSum(ctx, &userID, budget.ServiceName, budget.CategoryID, startDateSQL, endDateSQL)
+		if charge == nil {
+			return nil, errors.New("returned nil sum from repo")
+		}
+
+		statuses[i] = schemas.BudgetStatus{
+			BudgetInfo: toBudgetInfo(budget),
+			Charge:     *charge,
+			Remaining:  int(budget.MonthlyLimit) - int(*charge),
+			Exceeded:   *charge > budget.MonthlyLimit,
+		}
+	}
+
+	return statuses, nil
+}
+
+func budgetError(err error, message i18n.Message) error {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		return &schemas.AppError{
+			Code:    http.StatusNotFound,
+			Message: i18n.BudgetNotFound,
+			Err:     err,
+		}
+	case gorm.ErrForeignKeyViolated:
+		return &schemas.AppError{
+			Code:    http.StatusBadRequest,
+			Message: i18n.UserOrCategoryNotFound,
+			Err:     err,
+		}
+	default:
+		return &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: message,
+			Err:     err,
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// budgetRepo returns the budgets and fails the writes with err.
+type budgetRepo struct {
+	repository.BudgetRepo
+
+	budgets []models.Budget
+	err     error
+}
+
+func (r *budgetRepo) GetBudgets(context.Context, *uuid.UUID) ([]models.Budget, error) {
+	return r.budgets, nil
+}
+
+func (r *budgetRepo) GetBudget(context.Context, uint) (*models.Budget, error) {
+	return nil, r.err
+}
+
+func (r *budgetRepo) CreateBudget(context.Context, uuid.UUID, *uint, *string, uint) (*uint, error) {
+	id := uint(1)
+	return &id, r.err
+}
+
+func (r *budgetRepo) UpdateBudget(context.Context, uint, *uint, *string, uint) error {
+	return r.err
+}
+
+func (r *budgetRepo) DeleteBudget(context.Context, uint) error {
+	return r.err
+}
+
+// chargeRepo returns the charges of the month by the service names of the budgets, the
+// charges change to after once a subscription is created and the write hook runs with
+// the budgets and the outbox.
+type chargeRepo struct {
+	repository.SubscriptionRepo
+
+	charges, after map[string]uint
+	budgets        repository.BudgetRepo
+	outbox         repository.OutboxRepo
+}
+
+func (r *chargeRepo) GetSubsSum(_ context.Context, _ *uuid.UUID, serviceName *string, _ *uint, _, _ string) *uint {
+	charge, ok := r.charges[*serviceName]
+	if !ok {
+		return nil
+	}
+	return &charge
+}
+
+func (r *chargeRepo) CreateRecord(_ context.Context, _ string, _ time.Time, _ uint, _ uuid.UUID, _ *time.Time, _ *uint, _ []string, hook repository.WriteHook) (*uint, error) {
+	if r.after != nil {
+		r.charges = r.after
+	}
+
+	id := uint(1)
+	if err := hook(repository.TxRepos{Subscriptions: r, Budgets: r.budgets, Outbox: r.outbox}, id); err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// outboxRepo records the added events and fails them with err.
+type outboxRepo struct {
+	repository.OutboxRepo
+
+	events []events.Type
+	alerts []uint
+	err    error
+}
+
+func (r *outboxRepo) AddEvent(_ context.Context, eventType events.Type, _ uint, payload any) error {
+	r.events = append(r.events, eventType)
+	if alert, ok := payload.(schemas.BudgetExceededAlert); ok {
+		r.alerts = append(r.alerts, alert.ID)
+	}
+	return r.err
+}
+
+func serviceBudget(id uint, serviceName string, limit uint) models.Budget {
+	return models.Budget{ID: id, ServiceName: &serviceName, MonthlyLimit: limit}
+}
+
+func TestBudgetStatus(t *testing.T) {
+	budgets := []models.Budget{
+		serviceBudget(1, "Netflix", 1000),
+		serviceBudget(2, "Spotify", 300),
+	}
+
+	tests := []struct {
+		name    string
+		month   string
+		charges map[string]uint
+		// expected are the remaining sums and the exceeded flags of the budgets
+		remaining []int
+		exceeded  []bool
+		code      int
+	}{
+		{
+			name:      "within the limits",
+			month:     "07-2025",
+			charges:   map[string]uint{"Netflix": 600, "Spotify": 0},
+			remaining: []int{400, 300},
+			exceeded:  []bool{false, false},
+		},
+		{
+			name:      "charge equal to the limit",
+			month:     "07-2025",
+			charges:   map[string]uint{"Netflix": 1000, "Spotify": 300},
+			remaining: []int{0, 0},
+			exceeded:  []bool{false, false},
+		},
+		{
+			name:      "exceeded",
+			month:     "07-2025",
+			charges:   map[string]uint{"Netflix": 1200, "Spotify": 100},
+			remaining: []int{-200, 200},
+			exceeded:  []bool{true, false},
+		},
+		{name: "invalid month", month: "2025-07", code: http.StatusBadRequest},
+		{name: "sum failure", month: "07-2025", charges: map[string]uint{}, code: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budgetService := service.NewBudgetService(&budgetRepo{budgets: budgets}, &chargeRepo{charges: tt.charges})
+
+			res, err := budgetService.GetBudgetStatus(context.Background(), uuid.New(), tt.month)
+			if errorCode(err) != tt.code {
+				t.Fatalf("expected status %d, got %v", tt.code, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(res.Budgets) != len(budgets) {
+				t.Fatalf("expected %d budgets, got %d", len(budgets), len(res.Budgets))
+			}
+			for i, status := range res.Budgets {
+				if status.Remaining != tt.remaining[i] || status.Exceeded != tt.exceeded[i] {
+					t.Errorf("budget %d: expected remaining %d and exceeded %t, got %d and %t",
+						status.ID, tt.remaining[i], tt.exceeded[i], status.Remaining, status.Exceeded)
+				}
+			}
+		})
+	}
+}
+
+func TestBudgetErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "missing budget", err: gorm.ErrRecordNotFound, code: http.StatusNotFound},
+		{name: "missing user or category", err: gorm.ErrForeignKeyViolated, code: http.StatusBadRequest},
+		{name: "other error", err: errors.New("connection refused"), code: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budgetService := service.NewBudgetService(&budgetRepo{err: tt.err}, &chargeRepo{})
+
+			if _, err := budgetService.GetBudget(context.Background(), 1); errorCode(err) != tt.code {
+				t.Errorf("get: expected status %d, got %v", tt.code, err)
+			}
+			if _, err := budgetService.CreateBudget(context.Background(), schemas.CreateBudget{MonthlyLimit: 100}); errorCode(err) != tt.code {
+				t.Errorf("create: expected status %d, got %v", tt.code, err)
+			}
+			if err := budgetService.UpdateBudget(context.Background(), 1, schemas.UpdateBudget{MonthlyLimit: 100}); errorCode(err) != tt.code {
+				t.Errorf("update: expected status %d, got %v", tt.code, err)
+			}
+			if err := budgetService.DeleteBudget(context.Background(), 1); errorCode(err) != tt.code {
+				t.Errorf("delete: expected status %d, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestBudgetAlerts(t *testing.T) {
+	budgets := []models.Budget{
+		serviceBudget(1, "Netflix", 1000),
+		serviceBudget(2, "Spotify", 300),
+	}
+
+	tests := []struct {
+		name          string
+		before, after map[string]uint
+		// alerts are the ids of the budgets expected in the alerts
+		alerts []uint
+		// err fails the write of the alerts, so the subscription isn't created
+		err  error
+		code int
+	}{
+		{
+			name:   "still within the limits",
+			before: map[string]uint{"Netflix": 500, "Spotify": 100},
+			after:  map[string]uint{"Netflix": 900, "Spotify": 100},
+		},
+		{
+			name:   "exceeded by the subscription",
+			before: map[string]uint{"Netflix": 900, "Spotify": 100},
+			after:  map[string]uint{"Netflix": 1300, "Spotify": 100},
+			alerts: []uint{1},
+		},
+		{
+			name:   "exceeded before the subscription",
+			before: map[string]uint{"Netflix": 1100, "Spotify": 200},
+			after:  map[string]uint{"Netflix": 1500, "Spotify": 400},
+			alerts: []uint{2},
+		},
+		{
+			name:   "alert write failure",
+			before: map[string]uint{"Netflix": 900, "Spotify": 100},
+			after:  map[string]uint{"Netflix": 1300, "Spotify": 100},
+			alerts: []uint{1},
+			err:    errors.New("connection refused"),
+			code:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budgets := &budgetRepo{budgets: budgets}
+			outbox := &outboxRepo{err: tt.err}
+
+			subsService := service.NewService(
+				&chargeRepo{charges: tt.before, after: tt.after, budgets: budgets, outbox: outbox},
+				&categoryRepo{},
+				&userRepo{exists: true},
+				budgets,
+				false,
+				nil,
+			)
+
+			_, err := subsService.CreateSub(context.Background(), schemas.CreateSub{
+				ServiceName: "Netflix",
+				Price:       400,
+				UserID:      uuid.New(),
+				StartDate:   "07-2025",
+			})
+			if errorCode(err) != tt.code {
+				t.Fatalf("expected status %d, got %v", tt.code, err)
+			}
+
+			alerts := outbox.alerts
+			if len(alerts) != len(tt.alerts) {
+				t.Fatalf("expected alerts of the budgets %v, got %v", tt.alerts, alerts)
+			}
+			for i, id := range tt.alerts {
+				if alerts[i] != id {
+					t.Errorf("expected alerts of the budgets %v, got %v", tt.alerts, alerts)
+				}
+			}
+		})
+	}
+}
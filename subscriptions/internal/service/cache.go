@@ -0,0 +1,107 @@
+package service
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"subscriptions/rest-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// The scopes of the cached results of the subscriptions, see cache.Cache.
+const (
+	// allSubsScope has the subscriptions of all users of the tenant.
+	allSubsScope = "subs"
+	// catalogScope has the categories and tags, the lists show the tags and the category
+	// filters match the subcategories.
+	catalogScope = "catalog"
+)
+
+// userSubsScope has the subscriptions of the user.
+func userSubsScope(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+// subsScopes are the scopes of the results of the subscriptions of the user, of all
+// users if userID is nil.
+func subsScopes(userID *uuid.UUID) []string {
+	if userID == nil {
+		return []string{allSubsScope}
+	}
+
+	return []string{userSubsScope(*userID)}
+}
+
+// writtenSubsScopes are the scopes changed by a write of the subscriptions of the users.
+func writtenSubsScopes(userIDs ...uuid.UUID) []string {
+	scopes := []string{allSubsScope}
+	for _, userID := range userIDs {
+		scope := userSubsScope(userID)
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
+
+// The names of the cached results in the keys and metrics.
+const (
+	subsListCache          = "subs_list"
+	subsSumCache           = "subs_sum"
+	subsSumByUsersCache    = "subs_sum_by_users"
+	subsSumByCategoryCache = "subs_sum_by_category"
+	subsSumByMonthCache    = "subs_sum_by_month"
+)
+
+// listParams are the parameters of the cached pages of the subscriptions.
+type listParams struct {
+	Page   int
+	Size   int
+	Filter repository.SubsFilter
+}
+
+// sumParams are the parameters of the cached sums, normalized by newSumParams so the
+// same sum has the same key.
+type sumParams struct {
+	UserIDs     []uuid.UUID
+	ServiceName *string
+	CategoryID  *uint
+	StartDate   string
+	EndDate     string
+}
+
+// newSumParams sorts the users and lower cases the service name, which the sum compares
+// case insensitively. The dates are the SQL dates of the period.
+func newSumParams(userIDs []uuid.UUID, serviceName *string, categoryID *uint, startDate, endDate string) sumParams {
+	params := sumParams{
+		UserIDs:    slices.SortedFunc(slices.Values(userIDs), func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) }),
+		CategoryID: categoryID,
+		StartDate:  startDate,
+		EndDate:    endDate,
+	}
+	if serviceName != nil {
+		name := strings.ToLower(*serviceName)
+		params.ServiceName = &name
+	}
+
+	return params
+}
+
+// sumScopes are the scopes of the sum of the subscriptions of the users, of all users
+// if there are none.
+func sumScopes(userIDs []uuid.UUID, categoryID *uint) []string {
+	var scopes []string
+	if len(userIDs) == 0 {
+		scopes = []string{allSubsScope}
+	}
+	for _, userID := range userIDs {
+		scopes = append(scopes, userSubsScope(userID))
+	}
+	if categoryID != nil {
+		scopes = append(scopes, catalogScope)
+	}
+
+	return scopes
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"subscriptions/rest-service/internal/cache"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/tracing"
+
+	"gorm.io/gorm"
+)
+
+type CategoryService struct {
+	repository repository.CategoryRepo
+	// cache has the results of the subscriptions depending on the catalog.
+	cache *cache.Cache
+}
+
+// NewCategoryService returns the service, a nil cache disables caching.
+func NewCategoryService(repo repository.CategoryRepo, subsCache *cache.Cache) CategoryService {
+	return CategoryService{
+		repository: repo,
+		cache:      subsCache,
+	}
+}
+
+func (s *CategoryService) GetAllCategories(ctx context.Context) ([]schemas.CategoryInfo, error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.GetAllCategories")
+	defer span.End()
+
+	records, err := s.repository.GetCategories(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "get all categories failed", "error", err)
+		return nil, &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: i18n.RetrieveCategoriesFailed,
+			Err:     err,
+		}
+	}
+
+	result := make([]schemas.CategoryInfo, len(records))
+	for i, record := range records {
+		result[i] = schemas.CategoryInfo{
+			ID:       record.ID,
+			Name:     record.Name,
+			ParentID: record.ParentID,
+		}
+	}
+
+	return result, nil
+}
+
+func (s *CategoryService) GetCategory(ctx context.Context, id uint) (*schemas.CategoryInfo, error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.GetCategory")
+	defer span.End()
+
+	record, err := s.repository.GetCategory(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "get category failed", "error", err)
+		switch err {
+		case gorm.ErrRecordNotFound:
+			return nil, &schemas.AppError{
+				Code:    http.StatusNotFound,
+				Message: i18n.CategoryNotFound,
+				Err:     err,
+			}
+		default:
+			return nil, &schemas.AppError{
+				Code:    http.StatusInternalServerError,
+				Message: i18n.RetrieveCategoryFailed,
+				Err:     err,
+			}
+		}
+	}
+
+	slog.InfoContext(ctx, "get category", "id", id)
+	return &schemas.CategoryInfo{
+		ID:       record.ID,
+		Name:     record.Name,
+		ParentID: record.ParentID,
+	}, nil
+}
+
+func (s *CategoryService) CreateCategory(ctx context.Context, data schemas.CreateCategory) (uint, error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.CreateCategory")
+	defer span.End()
+
+	res, err := s.repository.CreateCategory(ctx, data.Name, data.ParentID)
+	if err != nil {
+		slog.ErrorContext(ctx, "create category failed", "error", err)
+		return 0, categoryWriteError(err, i18n.CreateCategoryFailed)
+	}
+
+	s.cache.Invalidate(ctx, catalogScope)
+
+	slog.InfoContext(ctx, "category created")
+	return *res, nil
+}
+
+func (s *CategoryService) UpdateCategory(ctx context.Context, id uint, data schemas.CreateCategory) error {
+	ctx, span := tracing.Start(ctx, "CategoryService.UpdateCategory")
+	defer span.End()
+
+	if err := s.repository.UpdateCategory(ctx, id, data.Name, data.ParentID); err != nil {
+		slog.ErrorContext(ctx, "update category failed", "error", err)
+		return categoryWriteError(err, i18n.UpdateCategoryFailed)
+	}
+
+	s.cache.Invalidate(ctx, catalogScope)
+
+	slog.InfoContext(ctx, "category updated")
+	return nil
+}
+
+func (s *CategoryService) DeleteCategory(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "CategoryService.DeleteCategory")
+	defer span.End()
+
+	if err := s.repository.DeleteCategory(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "delete category failed", "error", err)
+		switch err {
+		case repository.ErrCategoryHasChildren:
+			return &schemas.AppError{
+				Code:    http.StatusConflict,
+				Message: i18n.CategoryHasSubcategories,
+				Err:     err,
+			}
+		default:
+			return categoryWriteError(err, i18n.DeleteCategoryFailed)
+		}
+	}
+
+	s.cache.Invalidate(ctx, catalogScope)
+
+	slog.InfoContext(ctx, "category deleted")
+	return nil
+}
+
+func categoryWriteError(err error, message i18n.Message) error {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		return &schemas.AppError{
+			Code:    http.StatusNotFound,
+			Message: i18n.CategoryNotFound,
+			Err:     err,
+		}
+	case gorm.ErrForeignKeyViolated:
+		return &schemas.AppError{
+			Code:    http.StatusBadRequest,
+			Message: i18n.ParentCategoryNotFound,
+			Err:     err,
+		}
+	case gorm.ErrDuplicatedKey:
+		return &schemas.AppError{
+			Code:    http.StatusConflict,
+			Message: i18n.CategoryExists,
+			Err:     err,
+		}
+	case repository.ErrCategoryCycle:
+		return &schemas.AppError{
+			Code:    http.StatusBadRequest,
+			Message: i18n.CategoryCycle,
+			Err:     err,
+		}
+	default:
+		return &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: message,
+			Err:     err,
+		}
+	}
+}
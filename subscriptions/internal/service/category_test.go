@@ -0,0 +1,191 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// categoryRepo returns the categories and fails the writes with err, the methods the tests
+// don't reach panic.
+type categoryRepo struct {
+	repository.CategoryRepo
+
+	categories []models.Category
+	err        error
+}
+
+func (r *categoryRepo) GetCategories(context.Context) ([]models.Category, error) {
+	return r.categories, nil
+}
+
+func (r *categoryRepo) CreateCategory(context.Context, string, *uint) (*uint, error) {
+	id := uint(1)
+	return &id, r.err
+}
+
+func (r *categoryRepo) UpdateCategory(context.Context, uint, string, *uint) error {
+	return r.err
+}
+
+func (r *categoryRepo) DeleteCategory(context.Context, uint) error {
+	return r.err
+}
+
+// sumRepo returns the sums of the subscriptions per category.
+type sumRepo struct {
+	repository.SubscriptionRepo
+
+	sums []repository.CategorySum
+}
+
+func (r *sumRepo) GetSubsSumByCategory(context.Context, *uuid.UUID, *string, string, string) ([]repository.CategorySum, error) {
+	return r.sums, nil
+}
+
+func errorCode(err error) int {
+	var appErr *schemas.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return 0
+}
+
+func TestCategoryWriteErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "duplicate name", err: gorm.ErrDuplicatedKey, code: http.StatusConflict},
+		{name: "missing parent", err: gorm.ErrForeignKeyViolated, code: http.StatusBadRequest},
+		{name: "cycle", err: repository.ErrCategoryCycle, code: http.StatusBadRequest},
+		{name: "missing category", err: gorm.ErrRecordNotFound, code: http.StatusNotFound},
+		{name: "other error", err: errors.New("connection refused"), code: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			categoryService := service.NewCategoryService(&categoryRepo{err: tt.err}, nil)
+			data := schemas.CreateCategory{Name: "Streaming"}
+
+			if _, err := categoryService.CreateCategory(context.Background(), data); errorCode(err) != tt.code {
+				t.Errorf("create: expected status %d, got %v", tt.code, err)
+			}
+			if err := categoryService.UpdateCategory(context.Background(), 1, data); errorCode(err) != tt.code {
+				t.Errorf("update: expected status %d, got %v", tt.code, err)
+			}
+		})
+	}
+
+	t.Run("delete with children", func(t *testing.T) {
+		categoryService := service.NewCategoryService(&categoryRepo{err: repository.ErrCategoryHasChildren}, nil)
+
+		if err := categoryService.DeleteCategory(context.Background(), 1); errorCode(err) != http.StatusConflict {
+			t.Errorf("expected status %d, got %v", http.StatusConflict, err)
+		}
+	})
+}
+
+func TestSubSumByCategory(t *testing.T) {
+	id := func(id uint) *uint { return &id }
+
+	// media > video > streaming, media > music and cloud
+	categories := []models.Category{
+		{ID: 1, Name: "Media"},
+		{ID: 2, Name: "Video", ParentID: id(1)},
+		{ID: 3, Name: "Streaming", ParentID: id(2)},
+		{ID: 4, Name: "Music", ParentID: id(1)},
+		{ID: 5, Name: "Cloud"},
+	}
+
+	tests := []struct {
+		name string
+		sums []repository.CategorySum
+		// own and total are the expected sums by category id, uncategorized is the
+		// expected sum of the subscriptions without category
+		own, total    map[uint]uint
+		uncategorized *uint
+		totalSum      uint
+	}{
+		{
+			name:  "nothing charged",
+			own:   map[uint]uint{},
+			total: map[uint]uint{},
+		},
+		{
+			name: "sums of the subcategories added to the parents",
+			sums: []repository.CategorySum{
+				{CategoryID: id(3), TotalSum: 100},
+				{CategoryID: id(2), TotalSum: 10},
+				{CategoryID: id(4), TotalSum: 1},
+			},
+			own:      map[uint]uint{2: 10, 3: 100, 4: 1},
+			total:    map[uint]uint{1: 111, 2: 110, 3: 100, 4: 1},
+			totalSum: 111,
+		},
+		{
+			name: "uncategorized and roots",
+			sums: []repository.CategorySum{
+				{TotalSum: 7},
+				{CategoryID: id(1), TotalSum: 20},
+				{CategoryID: id(5), TotalSum: 3},
+			},
+			own:           map[uint]uint{1: 20, 5: 3},
+			total:         map[uint]uint{1: 20, 5: 3},
+			uncategorized: id(7),
+			totalSum:      30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subsService := service.NewService(&sumRepo{sums: tt.sums}, &categoryRepo{categories: categories}, nil, nil, false, nil)
+
+			serviceName := ""
+			res, err := subsService.GetSubSumByCategory(context.Background(), nil, &serviceName, "01-2025", "04-2025")
+			if err != nil {
+				t.Fatalf("get sum by category: %v", err)
+			}
+
+			if res.TotalSum != tt.totalSum {
+				t.Errorf("expected total sum %d, got %d", tt.totalSum, res.TotalSum)
+			}
+
+			var uncategorized *uint
+			for _, category := range res.Categories {
+				if category.CategoryID == nil {
+					uncategorized = &category.TotalSum
+					continue
+				}
+				if category.OwnSum != tt.own[*category.CategoryID] {
+					t.Errorf("expected own sum %d of %s, got %d", tt.own[*category.CategoryID], category.Name, category.OwnSum)
+				}
+				if category.TotalSum != tt.total[*category.CategoryID] {
+					t.Errorf("expected total sum %d of %s, got %d", tt.total[*category.CategoryID], category.Name, category.TotalSum)
+				}
+			}
+
+			if (uncategorized == nil) != (tt.uncategorized == nil) || (uncategorized != nil && *uncategorized != *tt.uncategorized) {
+				t.Errorf("expected uncategorized sum %v, got %v", tt.uncategorized, uncategorized)
+			}
+		})
+	}
+
+	t.Run("invalid period", func(t *testing.T) {
+		subsService := service.NewService(&sumRepo{}, &categoryRepo{categories: categories}, nil, nil, false, nil)
+
+		serviceName := ""
+		if _, err := subsService.GetSubSumByCategory(context.Background(), nil, &serviceName, "2025-01", "04-2025"); errorCode(err) != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %v", http.StatusBadRequest, err)
+		}
+	})
+}
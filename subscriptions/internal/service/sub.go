@@ -1,13 +1,23 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"subscriptions/rest-service/internal/cache"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/health"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/metrics"
+	"subscriptions/rest-service/internal/models"
 	"subscriptions/rest-service/internal/repository"
 	"subscriptions/rest-service/internal/schemas"
-	"subscriptions/rest-service/pkg/logger"
+	"subscriptions/rest-service/internal/tenant"
+	"subscriptions/rest-service/internal/tracing"
+	"subscriptions/rest-service/pkg/database"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,23 +25,172 @@ import (
 )
 
 type SubscriptionService struct {
-	repository repository.SubscriptionRepo
+	repository         repository.SubscriptionRepo
+	categoryRepository repository.CategoryRepo
+	userRepository     repository.UserRepo
+	budgetRepository   repository.BudgetRepo
+	// autoCreateUsers makes writes create unknown users instead of rejecting them.
+	autoCreateUsers bool
+	// cache has the lists and sums of the subscriptions.
+	cache *cache.Cache
 }
 
-func NewService(repo repository.SubscriptionRepo) SubscriptionService {
+// NewService returns the service, a nil cache disables caching of the lists and sums.
+func NewService(
+	repo repository.SubscriptionRepo,
+	categoryRepo repository.CategoryRepo,
+	userRepo repository.UserRepo,
+	budgetRepo repository.BudgetRepo,
+	autoCreateUsers bool,
+	subsCache *cache.Cache,
+) SubscriptionService {
 	return SubscriptionService{
-		repository: repo,
+		repository:         repo,
+		categoryRepository: categoryRepo,
+		userRepository:     userRepo,
+		budgetRepository:   budgetRepo,
+		autoCreateUsers:    autoCreateUsers,
+		cache:              subsCache,
 	}
 }
 
-func (s *SubscriptionService) GetAllSubs(pageNumber, pageSize int) (*schemas.PaginationResponse, error) {
+func currentMonthStart() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// exceededBudgets returns the ids of the user budgets exceeded in the current month. They
+// are read from the primary, so the ones exceeded by a write are found right after it.
+func (s *SubscriptionService) exceededBudgets(ctx context.Context, userID uuid.UUID) map[uint]bool {
+	ctx = database.Primary(ctx)
+	statuses, err := budgetStatuses(ctx, s.budgetRepository, s.repository, userID, currentMonthStart())
+	if err != nil {
+		slog.ErrorContext(ctx, "exceeded budgets failed", "error", err)
+		return nil
+	}
+
+	exceeded := make(map[uint]bool, len(statuses))
+	for _, status := range statuses {
+		if status.Exceeded {
+			exceeded[status.ID] = true
+		}
+	}
+
+	return exceeded
+}
+
+// budgetAlerts returns the hook writing a budget exceeded event for every user budget
+// that is exceeded after the write of the subscription but was not exceeded before it.
+// The budgets are read and the events are written in the transaction of the write, so
+// the alerts are committed with it.
+func budgetAlerts(ctx context.Context, userID uuid.UUID, exceededBefore map[uint]bool) repository.WriteHook {
+	return func(repos repository.TxRepos, subID uint) error {
+		monthStart := currentMonthStart()
+
+		statuses, err := budgetStatuses(ctx, repos.Budgets, repos.Subscriptions, userID, monthStart)
+		if err != nil {
+			slog.ErrorContext(ctx, "notify exceeded budgets failed", "error", err)
+			return err
+		}
+
+		for _, status := range statuses {
+			if !status.Exceeded || exceededBefore[status.ID] {
+				continue
+			}
+
+			slog.WarnContext(ctx, "budget exceeded", "budget_id", status.ID, "user_id", userID)
+			err := repos.Outbox.AddEvent(ctx, events.BudgetExceeded, subID, schemas.BudgetExceededAlert{
+				BudgetStatus:   status,
+				Month:          monthStart.Format("01-2006"),
+				SubscriptionID: subID,
+			})
+			if err != nil {
+				slog.ErrorContext(ctx, "notify exceeded budgets failed", "error", err)
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// checkUser makes sure the subscription owner exists, creating it if auto creation is enabled.
+func (s *SubscriptionService) checkUser(ctx context.Context, userID uuid.UUID) error {
+	if s.autoCreateUsers {
+		if err := s.userRepository.EnsureUser(ctx, userID); err != nil {
+			if err == repository.ErrUserOfOtherTenant {
+				return &schemas.AppError{
+					Code:    http.StatusConflict,
+					Message: i18n.UserOfOtherTenant,
+					Err:     err,
+				}
+			}
+
+			return &schemas.AppError{
+				Code:    http.StatusInternalServerError,
+				Message: i18n.CreateUserFailed,
+				Err:     err,
+			}
+		}
+		return nil
+	}
+
+	exists, err := s.userRepository.UserExists(ctx, userID)
+	if err != nil {
+		return &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: i18n.RetrieveUserFailed,
+			Err:     err,
+		}
+	}
+
+	if !exists {
+		return &schemas.AppError{
+			Code:    http.StatusBadRequest,
+			Message: i18n.UserNotFound,
+			Err:     gorm.ErrRecordNotFound,
+		}
+	}
+
+	return nil
+}
+
+func toFullSubInfo(record models.Subscription) schemas.FullSubInfo {
+	tags := make([]string, len(record.Tags))
+	for i, tag := range record.Tags {
+		tags[i] = tag.Name
+	}
+
+	return schemas.FullSubInfo{
+		ID:          record.ID,
+		ServiceName: record.ServiceName,
+		Price:       record.Price,
+		UserID:      record.UserID,
+		StartDate:   record.StartDate,
+		EndDate:     record.EndDate,
+		CategoryID:  record.CategoryID,
+		Tags:        tags,
+	}
+}
+
+func (s *SubscriptionService) GetAllSubs(ctx context.Context, pageNumber, pageSize int, filter repository.SubsFilter) (*schemas.PaginationResponse, error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.GetAllSubs")
+	defer span.End()
+
+	entry := s.cache.Entry(ctx, subsListCache, listParams{Page: pageNumber, Size: pageSize, Filter: filter},
+		append(subsScopes(filter.UserID), catalogScope)...)
+	var cached schemas.PaginationResponse
+	if entry.Get(ctx, &cached) {
+		return &cached, nil
+	}
+
 	offset := (pageNumber - 1) * pageSize
-	records, totalPages, err := s.repository.GetRecords(offset, pageSize)
+	records, totalPages, err := s.repository.GetRecords(ctx, offset, pageSize, filter)
 	if err != nil {
-		logger.PrintLog(err.Error(), "error")
+		slog.ErrorContext(ctx, "get all subs failed", "error", err)
 		return nil, &schemas.AppError{
 			Code:    http.StatusInternalServerError,
-			Message: "failed to retrieve subscriptions",
+			Message: i18n.RetrieveSubscriptionsFailed,
 			Err:     err,
 		}
 	}
@@ -39,14 +198,7 @@ func (s *SubscriptionService) GetAllSubs(pageNumber, pageSize int) (*schemas.Pag
 	result := make([]schemas.FullSubInfo, len(records))
 
 	for i, record := range records {
-		result[i] = schemas.FullSubInfo{
-			ID:          record.ID,
-			ServiceName: record.ServiceName,
-			Price:       record.Price,
-			UserID:      record.UserID,
-			StartDate:   record.StartDate,
-			EndDate:     record.EndDate,
-		}
+		result[i] = toFullSubInfo(record)
 	}
 
 	paginationInfo := schemas.Pagination{
@@ -61,39 +213,51 @@ func (s *SubscriptionService) GetAllSubs(pageNumber, pageSize int) (*schemas.Pag
 		Subscriptions: result,
 		Pagination:    paginationInfo,
 	}
+	entry.Set(ctx, response)
 
 	return &response, nil
 }
 
-func (s *SubscriptionService) GetSub(id uint) (*schemas.FullSubInfo, error) {
-	record, err := s.repository.GetRecord(id)
+func (s *SubscriptionService) GetSub(ctx context.Context, id uint) (*schemas.FullSubInfo, error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.GetSub")
+	defer span.End()
+
+	record, err := s.repository.GetRecord(ctx, id)
 	if err != nil {
-		logger.PrintLog(err.Error(), "error")
+		slog.ErrorContext(ctx, "get sub failed", "error", err)
 		switch err {
 		case gorm.ErrRecordNotFound:
 			return nil, &schemas.AppError{
 				Code:    http.StatusNotFound,
-				Message: "sumbscription not found!",
+				Message: i18n.SubscriptionNotFound,
 				Err:     err,
 			}
 		default:
 			return nil, &schemas.AppError{
 				Code:    http.StatusInternalServerError,
-				Message: "failed to retrieve subscription",
+				Message: i18n.RetrieveSubscriptionFailed,
 				Err:     err,
 			}
 		}
 	}
 
-	logger.PrintLog(fmt.Sprintf("Get record with ID = %d", id))
-	return (*schemas.FullSubInfo)(record), nil
+	slog.InfoContext(ctx, "get subscription", "id", id)
+	info := toFullSubInfo(*record)
+	return &info, nil
 }
 
-func (s *SubscriptionService) CreateSub(data schemas.CreateSub) (uint, error) {
+func (s *SubscriptionService) CreateSub(ctx context.Context, data schemas.CreateSub) (uint, error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.CreateSub")
+	defer span.End()
+
 	startDate, err := time.Parse("01-2006", data.StartDate)
 	if err != nil {
-		logger.PrintLog(err.Error(), "error")
-		return 0, err
+		slog.ErrorContext(ctx, "create sub failed", "error", err)
+		return 0, &schemas.AppError{
+			Code:    http.StatusBadRequest,
+			Message: i18n.InvalidStartDate,
+			Err:     err,
+		}
 	}
 
 	var endDate *time.Time
@@ -101,34 +265,60 @@ func (s *SubscriptionService) CreateSub(data schemas.CreateSub) (uint, error) {
 	if data.EndDate != nil {
 		t, err := time.Parse("01-2006", *data.EndDate)
 		if err != nil {
-			logger.PrintLog(err.Error(), "error")
-			return 0, nil
+			slog.ErrorContext(ctx, "create sub failed", "error", err)
+			return 0, &schemas.AppError{
+				Code:    http.StatusBadRequest,
+				Message: i18n.InvalidEndDate,
+				Err:     err,
+			}
 		}
 		endDate = &t
 	}
 
+	if err := s.checkUser(ctx, data.UserID); err != nil {
+		slog.ErrorContext(ctx, "create sub failed", "error", err)
+		return 0, err
+	}
+
+	exceededBefore := s.exceededBudgets(ctx, data.UserID)
+
 	res, err := s.repository.CreateRecord(
-		data.ServiceName, startDate, data.Price, data.UserID, endDate,
+		ctx, data.ServiceName, startDate, data.Price, data.UserID, endDate, data.CategoryID, data.Tags,
+		budgetAlerts(ctx, data.UserID, exceededBefore),
 	)
 	if err != nil {
-		logger.PrintLog(err.Error(), "error")
+		slog.ErrorContext(ctx, "create sub failed", "error", err)
+		if err == gorm.ErrForeignKeyViolated {
+			return 0, &schemas.AppError{
+				Code:    http.StatusBadRequest,
+				Message: i18n.CategoryNotFound,
+				Err:     err,
+			}
+		}
+
 		return 0, &schemas.AppError{
 			Code:    http.StatusInternalServerError,
-			Message: "failed to create subscription",
+			Message: i18n.CreateSubscriptionFailed,
 			Err:     err,
 		}
 	}
 
-	logger.PrintLog("Subscription record created")
+	s.cache.Invalidate(ctx, writtenSubsScopes(data.UserID)...)
+	metrics.SubscriptionCreated(data.ServiceName)
+
+	slog.InfoContext(ctx, "subscription record created")
 	return *res, nil
 }
 
-func (s *SubscriptionService) FullUpdateSub(id uint, data schemas.FullUpdateSub) error {
+func (s *SubscriptionService) FullUpdateSub(ctx context.Context, id uint, data schemas.FullUpdateSub) error {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.FullUpdateSub")
+	defer span.End()
+
 	startDate, err := time.Parse("01-2006", data.StartDate)
 	if err != nil {
 		return &schemas.AppError{
 			Code:    http.StatusBadRequest,
-			Message: "invalid start date format",
+			Message: i18n.InvalidStartDate,
 			Err:     err,
 		}
 	}
@@ -139,130 +329,188 @@ func (s *SubscriptionService) FullUpdateSub(id uint, data schemas.FullUpdateSub)
 		if err != nil {
 			return &schemas.AppError{
 				Code:    http.StatusBadRequest,
-				Message: "invalid end date format",
+				Message: i18n.InvalidEndDate,
 				Err:     err,
 			}
 		}
 		endDate = &t
 	}
 
-	err = s.repository.FullUpdateRecord(
-		id,
+	if err := s.checkUser(ctx, data.UserID); err != nil {
+		slog.ErrorContext(ctx, "full update sub failed", "error", err)
+		return err
+	}
+
+	exceededBefore := s.exceededBudgets(ctx, data.UserID)
+
+	previousUserID, err := s.repository.FullUpdateRecord(
+		ctx, id,
 		data.Price,
 		data.ServiceName,
 		startDate,
 		data.UserID,
 		endDate,
+		data.CategoryID,
+		data.Tags,
+		budgetAlerts(ctx, data.UserID, exceededBefore),
 	)
 	if err != nil {
-		logger.PrintLog(err.Error(), "error")
+		slog.ErrorContext(ctx, "full update sub failed", "error", err)
 		switch err {
 		case gorm.ErrRecordNotFound:
 			return &schemas.AppError{
 				Code:    http.StatusNotFound,
-				Message: "subscription not found",
+				Message: i18n.SubscriptionNotFound,
+				Err:     err,
+			}
+		case gorm.ErrForeignKeyViolated:
+			return &schemas.AppError{
+				Code:    http.StatusBadRequest,
+				Message: i18n.CategoryNotFound,
 				Err:     err,
 			}
 		default:
 			return &schemas.AppError{
 				Code:    http.StatusInternalServerError,
-				Message: "failed to update subscription",
+				Message: i18n.UpdateSubscriptionFailed,
 				Err:     err,
 			}
 		}
 	}
 
-	logger.PrintLog("Subscription updated")
+	s.cache.Invalidate(ctx, writtenSubsScopes(previousUserID, data.UserID)...)
+
+	slog.InfoContext(ctx, "subscription updated")
 	return nil
 }
 
-func (s *SubscriptionService) PatchUpdateSub(id uint, data schemas.PatchUpdateSub) error {
+func (s *SubscriptionService) PatchUpdateSub(ctx context.Context, id uint, data schemas.PatchUpdateSub) error {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.PatchUpdateSub")
+	defer span.End()
+
+	var userID *uuid.UUID
+	if data.UserID != nil {
+		if err := s.checkUser(ctx, *data.UserID); err != nil {
+			slog.ErrorContext(ctx, "patch update sub failed", "error", err)
+			return err
+		}
+		userID = data.UserID
+	} else if record, err := s.repository.GetRecord(database.Primary(ctx), id); err == nil {
+		userID = &record.UserID
+	}
+
+	var exceededBefore map[uint]bool
+	if userID != nil {
+		exceededBefore = s.exceededBudgets(ctx, *userID)
+	}
+
 	jsonBytes, err := json.Marshal(data)
 	if err != nil {
-		logger.PrintLog(err.Error(), "error")
+		slog.ErrorContext(ctx, "patch update sub failed", "error", err)
 		return &schemas.AppError{
 			Code:    http.StatusBadRequest,
-			Message: "invalid update data",
+			Message: i18n.InvalidUpdate,
 			Err:     err,
 		}
 	}
 
 	var updateFields map[string]any
 	if err = json.Unmarshal(jsonBytes, &updateFields); err != nil {
-		logger.PrintLog(err.Error(), "error")
+		slog.ErrorContext(ctx, "patch update sub failed", "error", err)
 		return &schemas.AppError{
 			Code:    http.StatusBadRequest,
-			Message: "failed to parse update fields",
+			Message: i18n.InvalidUpdate,
 			Err:     err,
 		}
 	}
 
-	err = s.repository.UpdateRecord(id, updateFields)
+	delete(updateFields, "tags")
+
+	var alerts repository.WriteHook
+	if userID != nil {
+		alerts = budgetAlerts(ctx, *userID, exceededBefore)
+	}
+
+	previousUserID, err := s.repository.UpdateRecord(ctx, id, updateFields, data.Tags, alerts)
 	if err != nil {
-		logger.PrintLog(err.Error(), "error")
+		slog.ErrorContext(ctx, "patch update sub failed", "error", err)
 		switch err {
 		case gorm.ErrRecordNotFound:
 			return &schemas.AppError{
 				Code:    http.StatusNotFound,
-				Message: "subscription not found",
+				Message: i18n.SubscriptionNotFound,
+				Err:     err,
+			}
+		case gorm.ErrForeignKeyViolated:
+			return &schemas.AppError{
+				Code:    http.StatusBadRequest,
+				Message: i18n.CategoryNotFound,
 				Err:     err,
 			}
 		default:
 			return &schemas.AppError{
 				Code:    http.StatusInternalServerError,
-				Message: "failed to patch update subscription",
+				Message: i18n.UpdateSubscriptionFailed,
 				Err:     err,
 			}
 		}
 	}
 
-	logger.PrintLog("Subscription updated")
+	writtenUserIDs := []uuid.UUID{previousUserID}
+	if data.UserID != nil {
+		writtenUserIDs = append(writtenUserIDs, *data.UserID)
+	}
+	s.cache.Invalidate(ctx, writtenSubsScopes(writtenUserIDs...)...)
+
+	slog.InfoContext(ctx, "subscription updated")
 	return nil
 }
 
-func (s *SubscriptionService) DeleteSub(id uint) error {
-	err := s.repository.DeleteRecord(id)
+func (s *SubscriptionService) DeleteSub(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.DeleteSub")
+	defer span.End()
+
+	userID, err := s.repository.DeleteRecord(ctx, id)
 	if err != nil {
-		logger.PrintLog(err.Error(), "error")
+		slog.ErrorContext(ctx, "delete sub failed", "error", err)
 		switch err {
 		case gorm.ErrRecordNotFound:
 			return &schemas.AppError{
 				Code:    http.StatusNotFound,
-				Message: "subscription not found",
+				Message: i18n.SubscriptionNotFound,
 				Err:     err,
 			}
 		default:
 			return &schemas.AppError{
 				Code:    http.StatusInternalServerError,
-				Message: "failed to delete subscription",
+				Message: i18n.DeleteSubscriptionFailed,
 				Err:     err,
 			}
 		}
 	}
 
-	logger.PrintLog("Subscription deleted")
+	s.cache.Invalidate(ctx, writtenSubsScopes(userID)...)
+
+	slog.InfoContext(ctx, "subscription deleted")
 	return nil
 }
 
-func (s *SubscriptionService) GetSubSum(userID *uuid.UUID, serviceName *string, startDate, endDate string) (uint, error) {
-	if *serviceName == "" {
-		serviceName = nil
-	}
-
+// sumPeriodToSQL converts the 'mm-yyyy' period bounds to the dates used in sum queries.
+func sumPeriodToSQL(startDate, endDate string) (string, string, error) {
 	startDateParsed, err := time.Parse("01-2006", startDate)
 	if err != nil {
-		return 0, &schemas.AppError{
+		return "", "", &schemas.AppError{
 			Code:    http.StatusBadRequest,
-			Message: "invalid start date format",
+			Message: i18n.InvalidStartDate,
 			Err:     err,
 		}
 	}
 
 	endDateParsed, err := time.Parse("01-2006", endDate)
 	if err != nil {
-		return 0, &schemas.AppError{
+		return "", "", &schemas.AppError{
 			Code:    http.StatusBadRequest,
-			Message: "invalid end date format",
+			Message: i18n.InvalidEndDate,
 			Err:     err,
 		}
 	}
@@ -272,17 +520,311 @@ func (s *SubscriptionService) GetSubSum(userID *uuid.UUID, serviceName *string,
 	startDateSQL := fmt.Sprintf("%04d-%02d-%02d", y1, m1, d1)
 	endDateSQL := fmt.Sprintf("%04d-%02d-%02d", y2, m2, d2)
 
-	totalSum := s.repository.GetSubsSum(userID, serviceName, startDateSQL, endDateSQL)
+	return startDateSQL, endDateSQL, nil
+}
+
+func (s *SubscriptionService) GetSubSum(ctx context.Context, userID *uuid.UUID, serviceName *string, categoryID *uint, startDate, endDate string) (uint, error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.GetSubSum")
+	defer span.End()
+
+	if *serviceName == "" {
+		serviceName = nil
+	}
+
+	startDateSQL, endDateSQL, err := sumPeriodToSQL(startDate, endDate)
+	if err != nil {
+		return 0, err
+	}
+
+	var userIDs []uuid.UUID
+	if userID != nil {
+		userIDs = []uuid.UUID{*userID}
+	}
+	entry := s.cache.Entry(ctx, subsSumCache,
+		newSumParams(userIDs, serviceName, categoryID, startDateSQL, endDateSQL), sumScopes(userIDs, categoryID)...)
+	var cached uint
+	if entry.Get(ctx, &cached) {
+		return cached, nil
+	}
+
+	totalSum := s.repository.GetSubsSum(ctx, userID, serviceName, categoryID, startDateSQL, endDateSQL)
 
 	if totalSum == nil {
-		logger.PrintLog("error get sum with this params", "error")
+		slog.ErrorContext(ctx, "get subscription sum failed")
 		return 0, &schemas.AppError{
 			Code:    http.StatusUnprocessableEntity,
-			Message: "cannot calculate sum of subscriptions",
+			Message: i18n.SumFailed,
 			Err:     errors.New("returned nil sum from repo"),
 		}
 	}
 
-	logger.PrintLog("Get sum")
+	entry.Set(ctx, *totalSum)
+
+	slog.InfoContext(ctx, "get sum")
 	return *totalSum, nil
 }
+
+// GetSubSumByUsers returns the charge for the period of each of the users with one query.
+// Users without subscriptions in the period get a zero sum.
+func (s *SubscriptionService) GetSubSumByUsers(
+	ctx context.Context,
+	userIDs []uuid.UUID,
+	serviceName *string,
+	categoryID *uint,
+	startDate, endDate string,
+) (map[uuid.UUID]uint, error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.GetSubSumByUsers")
+	defer span.End()
+
+	if serviceName != nil && *serviceName == "" {
+		serviceName = nil
+	}
+
+	startDateSQL, endDateSQL, err := sumPeriodToSQL(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := s.cache.Entry(ctx, subsSumByUsersCache,
+		newSumParams(userIDs, serviceName, categoryID, startDateSQL, endDateSQL), sumScopes(userIDs, categoryID)...)
+	var cached map[uuid.UUID]uint
+	if entry.Get(ctx, &cached) {
+		return cached, nil
+	}
+
+	sums, err := s.repository.GetSubsSumByUsers(ctx, userIDs, serviceName, categoryID, startDateSQL, endDateSQL)
+	if err != nil {
+		return nil, &schemas.AppError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: i18n.SumFailed,
+			Err:     err,
+		}
+	}
+
+	result := make(map[uuid.UUID]uint, len(userIDs))
+	for _, userID := range userIDs {
+		result[userID] = 0
+	}
+	for _, sum := range sums {
+		result[sum.UserID] = sum.TotalSum
+	}
+	entry.Set(ctx, result)
+
+	return result, nil
+}
+
+// GetSubSumByMonth returns the charge of each month of the period, the end month excluded
+// as in the sum. Months without charge get a zero sum.
+func (s *SubscriptionService) GetSubSumByMonth(
+	ctx context.Context,
+	userID *uuid.UUID,
+	serviceName *string,
+	categoryID *uint,
+	startDate, endDate string,
+) (*schemas.MonthSumReturn, error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.GetSubSumByMonth")
+	defer span.End()
+
+	if *serviceName == "" {
+		serviceName = nil
+	}
+
+	startDateSQL, endDateSQL, err := sumPeriodToSQL(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs []uuid.UUID
+	if userID != nil {
+		userIDs = []uuid.UUID{*userID}
+	}
+	entry := s.cache.Entry(ctx, subsSumByMonthCache,
+		newSumParams(userIDs, serviceName, categoryID, startDateSQL, endDateSQL), sumScopes(userIDs, categoryID)...)
+	var cached schemas.MonthSumReturn
+	if entry.Get(ctx, &cached) {
+		return &cached, nil
+	}
+
+	sums, err := s.repository.GetSubsSumByMonth(ctx, userID, serviceName, categoryID, startDateSQL, endDateSQL)
+	if err != nil {
+		return nil, &schemas.AppError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: i18n.SumFailed,
+			Err:     err,
+		}
+	}
+
+	monthSums := make(map[string]uint, len(sums))
+	for _, sum := range sums {
+		monthSums[sum.Month.Format("01-2006")] = sum.TotalSum
+	}
+
+	// the dates are validated by sumPeriodToSQL
+	month, _ := time.Parse(time.DateOnly, startDateSQL)
+	end, _ := time.Parse(time.DateOnly, endDateSQL)
+
+	response := schemas.MonthSumReturn{Months: []schemas.MonthSumInfo{}}
+	for ; month.Before(end); month = month.AddDate(0, 1, 0) {
+		monthSum := schemas.MonthSumInfo{Month: month.Format("01-2006")}
+		monthSum.TotalSum = monthSums[monthSum.Month]
+		response.Months = append(response.Months, monthSum)
+		response.TotalSum += monthSum.TotalSum
+	}
+	entry.Set(ctx, response)
+
+	slog.InfoContext(ctx, "get sum by month")
+	return &response, nil
+}
+
+// GetSubSumByCategory returns the charge for the period per category. The sum of
+// a category includes the charge of all its subcategories.
+func (s *SubscriptionService) GetSubSumByCategory(ctx context.Context, userID *uuid.UUID, serviceName *string, startDate, endDate string) (*schemas.CategorySumReturn, error) {
+	ctx, span := tracing.Start(ctx, "SubscriptionService.GetSubSumByCategory")
+	defer span.End()
+
+	if *serviceName == "" {
+		serviceName = nil
+	}
+
+	startDateSQL, endDateSQL, err := sumPeriodToSQL(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs []uuid.UUID
+	if userID != nil {
+		userIDs = []uuid.UUID{*userID}
+	}
+	// the report lists all categories, so it depends on the catalog without a category filter
+	entry := s.cache.Entry(ctx, subsSumByCategoryCache,
+		newSumParams(userIDs, serviceName, nil, startDateSQL, endDateSQL), append(sumScopes(userIDs, nil), catalogScope)...)
+	var cached schemas.CategorySumReturn
+	if entry.Get(ctx, &cached) {
+		return &cached, nil
+	}
+
+	sums, err := s.repository.GetSubsSumByCategory(ctx, userID, serviceName, startDateSQL, endDateSQL)
+	if err != nil {
+		return nil, &schemas.AppError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: i18n.SumFailed,
+			Err:     err,
+		}
+	}
+
+	categories, err := s.categoryRepository.GetCategories(ctx)
+	if err != nil {
+		return nil, &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: i18n.RetrieveCategoriesFailed,
+			Err:     err,
+		}
+	}
+
+	ownSums := make(map[uint]uint, len(sums))
+	response := schemas.CategorySumReturn{Categories: []schemas.CategorySumInfo{}}
+
+	for _, sum := range sums {
+		response.TotalSum += sum.TotalSum
+		if sum.CategoryID == nil {
+			response.Categories = append(response.Categories, schemas.CategorySumInfo{
+				Name:     "uncategorized",
+				OwnSum:   sum.TotalSum,
+				TotalSum: sum.TotalSum,
+			})
+			continue
+		}
+		ownSums[*sum.CategoryID] = sum.TotalSum
+	}
+
+	parents := make(map[uint]*uint, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	totals := make(map[uint]uint, len(categories))
+	for categoryID, sum := range ownSums {
+		for id := &categoryID; id != nil; id = parents[*id] {
+			totals[*id] += sum
+		}
+	}
+
+	for _, category := range categories {
+		response.Categories = append(response.Categories, schemas.CategorySumInfo{
+			CategoryID: &category.ID,
+			Name:       category.Name,
+			ParentID:   category.ParentID,
+			OwnSum:     ownSums[category.ID],
+			TotalSum:   totals[category.ID],
+		})
+	}
+
+	entry.Set(ctx, response)
+
+	slog.InfoContext(ctx, "get sum by category")
+	return &response, nil
+}
+
+// WatchActiveSubs periodically updates the metric of active subscriptions until ctx is cancelled.
+func (s *SubscriptionService) WatchActiveSubs(ctx context.Context, interval time.Duration) {
+	// subscriptions of all tenants are counted by the same worker
+	ctx = tenant.Unscoped(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UTC()
+		counts, err := s.repository.CountActiveByTenant(ctx, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+		if err == nil {
+			metrics.SetActiveSubscriptions(counts)
+		}
+		health.Update(ctx, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// WatchEndingSoon periodically writes the ending soon event for subscriptions
+// ending within the window, once per end date. It returns when ctx is cancelled.
+func (s *SubscriptionService) WatchEndingSoon(ctx context.Context, interval, window time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		health.Update(ctx, s.notifyEndingSoon(ctx, window))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *SubscriptionService) notifyEndingSoon(ctx context.Context, window time.Duration) error {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	// subscriptions of all tenants are watched by the same worker
+	records, err := s.repository.GetEndingSoonRecords(tenant.Unscoped(ctx), today, today.Add(window))
+	if err != nil {
+		slog.ErrorContext(ctx, "notify ending soon failed", "error", err)
+		return err
+	}
+
+	var lastErr error
+	for _, record := range records {
+		tenantCtx := tenant.WithID(ctx, record.TenantID)
+		if err := s.repository.MarkEndingSoonNotified(tenantCtx, record.ID, *record.EndDate); err != nil {
+			slog.ErrorContext(ctx, "notify ending soon failed", "error", err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"subscriptions/rest-service/internal/cache"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/tracing"
+
+	"gorm.io/gorm"
+)
+
+type TagService struct {
+	repository repository.TagRepo
+	// cache has the results of the subscriptions depending on the catalog.
+	cache *cache.Cache
+}
+
+// NewTagService returns the service, a nil cache disables caching.
+func NewTagService(repo repository.TagRepo, subsCache *cache.Cache) TagService {
+	return TagService{
+		repository: repo,
+		cache:      subsCache,
+	}
+}
+
+func (s *TagService) GetAllTags(ctx context.Context) ([]schemas.TagInfo, error) {
+	ctx, span := tracing.Start(ctx, "TagService.GetAllTags")
+	defer span.End()
+
+	records, err := s.repository.GetTags(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "get all tags failed", "error", err)
+		return nil, &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: i18n.RetrieveTagsFailed,
+			Err:     err,
+		}
+	}
+
+	result := make([]schemas.TagInfo, len(records))
+	for i, record := range records {
+		result[i] = schemas.TagInfo{
+			ID:   record.ID,
+			Name: record.Name,
+		}
+	}
+
+	return result, nil
+}
+
+func (s *TagService) CreateTag(ctx context.Context, data schemas.CreateTag) (uint, error) {
+	ctx, span := tracing.Start(ctx, "TagService.CreateTag")
+	defer span.End()
+
+	res, err := s.repository.CreateTag(ctx, data.Name)
+	if err != nil {
+		slog.ErrorContext(ctx, "create tag failed", "error", err)
+		return 0, tagWriteError(err, i18n.CreateTagFailed)
+	}
+
+	s.cache.Invalidate(ctx, catalogScope)
+
+	slog.InfoContext(ctx, "tag created")
+	return *res, nil
+}
+
+func (s *TagService) UpdateTag(ctx context.Context, id uint, data schemas.CreateTag) error {
+	ctx, span := tracing.Start(ctx, "TagService.UpdateTag")
+	defer span.End()
+
+	if err := s.repository.UpdateTag(ctx, id, data.Name); err != nil {
+		slog.ErrorContext(ctx, "update tag failed", "error", err)
+		return tagWriteError(err, i18n.UpdateTagFailed)
+	}
+
+	s.cache.Invalidate(ctx, catalogScope)
+
+	slog.InfoContext(ctx, "tag updated")
+	return nil
+}
+
+func (s *TagService) DeleteTag(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "TagService.DeleteTag")
+	defer span.End()
+
+	if err := s.repository.DeleteTag(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "delete tag failed", "error", err)
+		return tagWriteError(err, i18n.DeleteTagFailed)
+	}
+
+	s.cache.Invalidate(ctx, catalogScope)
+
+	slog.InfoContext(ctx, "tag deleted")
+	return nil
+}
+
+func tagWriteError(err error, message i18n.Message) error {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		return &schemas.AppError{
+			Code:    http.StatusNotFound,
+			Message: i18n.TagNotFound,
+			Err:     err,
+		}
+	case gorm.ErrDuplicatedKey:
+		return &schemas.AppError{
+			Code:    http.StatusConflict,
+			Message: i18n.TagExists,
+			Err:     err,
+		}
+	default:
+		return &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: message,
+			Err:     err,
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"subscriptions/rest-service/internal/auth"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/tenant"
+	"subscriptions/rest-service/internal/tracing"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+type TenantService struct {
+	repository repository.TenantRepo
+	// defaultTenant serves the requests that don't select a tenant.
+	defaultTenant string
+	// known caches the ids of existing tenants, tenants are never deleted.
+	known *sync.Map
+}
+
+func NewTenantService(repo repository.TenantRepo, defaultTenant string) TenantService {
+	return TenantService{
+		repository:    repo,
+		defaultTenant: defaultTenant,
+		known:         &sync.Map{},
+	}
+}
+
+func toTenantInfo(record models.Tenant) schemas.TenantInfo {
+	return schemas.TenantInfo{
+		ID:        record.ID,
+		Name:      record.Name,
+		CreatedAt: record.CreatedAt,
+	}
+}
+
+// EnsureDefaultTenant creates the default tenant if it doesn't exist yet.
+func (s *TenantService) EnsureDefaultTenant(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "TenantService.EnsureDefaultTenant")
+	defer span.End()
+
+	if !tenant.Valid(s.defaultTenant) {
+		return tenant.ErrInvalid
+	}
+
+	exists, err := s.repository.TenantExists(ctx, s.defaultTenant)
+	if err != nil || exists {
+		return err
+	}
+
+	return s.repository.CreateTenant(ctx, s.defaultTenant, s.defaultTenant)
+}
+
+// ResolveTenant returns the tenant the request of the principal works with. The
+// principal is nil when authentication is disabled, requested is the tenant asked
+// for by the caller, empty if none. Principals bound to a tenant and principals
+// of the default tenant may only work with their tenant, super admins and
+// unauthenticated requests may select any tenant.
+func (s *TenantService) ResolveTenant(ctx context.Context, principal *auth.Principal, requested string) (string, error) {
+	ctx, span := tracing.Start(ctx, "TenantService.ResolveTenant")
+	defer span.End()
+
+	tenantID := s.defaultTenant
+	switch {
+	case principal == nil || principal.SuperAdmin():
+		if requested != "" {
+			tenantID = requested
+		}
+	case principal.TenantID != "":
+		tenantID = principal.TenantID
+	}
+
+	if requested != "" && requested != tenantID {
+		return "", &schemas.AppError{
+			Code:    http.StatusForbidden,
+			Message: i18n.TenantForbidden,
+		}
+	}
+
+	if !tenant.Valid(tenantID) {
+		return "", &schemas.AppError{
+			Code:    http.StatusBadRequest,
+			Message: i18n.InvalidTenantID,
+			Err:     tenant.ErrInvalid,
+		}
+	}
+
+	if _, ok := s.known.Load(tenantID); ok {
+		return tenantID, nil
+	}
+
+	exists, err := s.repository.TenantExists(ctx, tenantID)
+	if err != nil {
+		return "", tenantError(err, i18n.CheckTenantFailed)
+	}
+
+	if !exists {
+		return "", tenantError(gorm.ErrRecordNotFound, "")
+	}
+
+	s.known.Store(tenantID, struct{}{})
+	return tenantID, nil
+}
+
+func (s *TenantService) GetAllTenants(ctx context.Context) ([]schemas.TenantInfo, error) {
+	ctx, span := tracing.Start(ctx, "TenantService.GetAllTenants")
+	defer span.End()
+
+	records, err := s.repository.GetTenants(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "get all tenants failed", "error", err)
+		return nil, tenantError(err, i18n.RetrieveTenantsFailed)
+	}
+
+	result := make([]schemas.TenantInfo, len(records))
+	for i, record := range records {
+		result[i] = toTenantInfo(record)
+	}
+
+	return result, nil
+}
+
+func (s *TenantService) GetTenant(ctx context.Context, id string) (*schemas.TenantInfo, error) {
+	ctx, span := tracing.Start(ctx, "TenantService.GetTenant")
+	defer span.End()
+
+	record, err := s.repository.GetTenant(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "get tenant failed", "error", err)
+		return nil, tenantError(err, i18n.RetrieveTenantFailed)
+	}
+
+	result := toTenantInfo(*record)
+	return &result, nil
+}
+
+func (s *TenantService) CreateTenant(ctx context.Context, data schemas.CreateTenant) (string, error) {
+	ctx, span := tracing.Start(ctx, "TenantService.CreateTenant")
+	defer span.End()
+
+	if err := s.repository.CreateTenant(ctx, data.ID, data.Name); err != nil {
+		slog.ErrorContext(ctx, "create tenant failed", "error", err)
+		return "", tenantError(err, i18n.CreateTenantFailed)
+	}
+
+	slog.InfoContext(ctx, "tenant created")
+	return data.ID, nil
+}
+
+func (s *TenantService) UpdateTenant(ctx context.Context, id string, data schemas.UpdateTenant) error {
+	ctx, span := tracing.Start(ctx, "TenantService.UpdateTenant")
+	defer span.End()
+
+	if err := s.repository.UpdateTenant(ctx, id, data.Name); err != nil {
+		slog.ErrorContext(ctx, "update tenant failed", "error", err)
+		return tenantError(err, i18n.UpdateTenantFailed)
+	}
+
+	slog.InfoContext(ctx, "tenant updated")
+	return nil
+}
+
+func (s *TenantService) GetTenantStats(ctx context.Context, id string) (*schemas.TenantStats, error) {
+	ctx, span := tracing.Start(ctx, "TenantService.GetTenantStats")
+	defer span.End()
+
+	if _, err := s.repository.GetTenant(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "get tenant stats failed", "error", err)
+		return nil, tenantError(err, i18n.RetrieveTenantFailed)
+	}
+
+	stats, err := s.repository.GetTenantStats(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "get tenant stats failed", "error", err)
+		return nil, tenantError(err, i18n.RetrieveTenantStatsFailed)
+	}
+
+	return &schemas.TenantStats{
+		Users:         stats.Users,
+		Subscriptions: stats.Subscriptions,
+		Categories:    stats.Categories,
+		Webhooks:      stats.Webhooks,
+		APIKeys:       stats.APIKeys,
+	}, nil
+}
+
+func tenantError(err error, message i18n.Message) error {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		return &schemas.AppError{
+			Code:    http.StatusNotFound,
+			Message: i18n.TenantNotFound,
+			Err:     err,
+		}
+	case gorm.ErrDuplicatedKey:
+		return &schemas.AppError{
+			Code:    http.StatusConflict,
+			Message: i18n.TenantExists,
+			Err:     err,
+		}
+	default:
+		return &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: message,
+			Err:     err,
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/tracing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultUserLocale   = "ru"
+	defaultUserCurrency = "RUB"
+)
+
+type UserService struct {
+	repository repository.UserRepo
+}
+
+func NewUserService(repo repository.UserRepo) UserService {
+	return UserService{
+		repository: repo,
+	}
+}
+
+func toUserInfo(record models.User) schemas.UserInfo {
+	return schemas.UserInfo{
+		ID:                record.ID,
+		DisplayName:       record.DisplayName,
+		Email:             record.Email,
+		Locale:            record.Locale,
+		PreferredCurrency: record.PreferredCurrency,
+		CreatedAt:         record.CreatedAt,
+	}
+}
+
+func (s *UserService) GetAllUsers(ctx context.Context, pageNumber, pageSize int) (*schemas.UsersPaginationResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetAllUsers")
+	defer span.End()
+
+	offset := (pageNumber - 1) * pageSize
+	records, totalPages, err := s.repository.GetUsers(ctx, offset, pageSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "get all users failed", "error", err)
+		return nil, &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: i18n.RetrieveUsersFailed,
+			Err:     err,
+		}
+	}
+
+	result := make([]schemas.UserInfo, len(records))
+	for i, record := range records {
+		result[i] = toUserInfo(record)
+	}
+
+	return &schemas.UsersPaginationResponse{
+		Users: result,
+		Pagination: schemas.Pagination{
+			PageNumber: pageNumber,
+			Size:       pageSize,
+			TotalPages: *totalPages,
+			HasNext:    pageNumber < *totalPages,
+			HasPrev:    pageNumber > 1 && *totalPages > 0,
+		},
+	}, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, id uuid.UUID) (*schemas.UserInfo, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUser")
+	defer span.End()
+
+	record, err := s.repository.GetUser(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "get user failed", "error", err)
+		return nil, userError(err, i18n.RetrieveUserFailed)
+	}
+
+	slog.InfoContext(ctx, "get user", "id", id)
+	info := toUserInfo(*record)
+	return &info, nil
+}
+
+// GetUsersByIDs returns the existing users of the ids, missing users are skipped.
+func (s *UserService) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]schemas.UserInfo, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUsersByIDs")
+	defer span.End()
+
+	records, err := s.repository.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: i18n.RetrieveUsersFailed,
+			Err:     err,
+		}
+	}
+
+	result := make([]schemas.UserInfo, len(records))
+	for i, record := range records {
+		result[i] = toUserInfo(record)
+	}
+
+	return result, nil
+}
+
+func (s *UserService) CreateUser(ctx context.Context, data schemas.CreateUser) (uuid.UUID, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
+	id := uuid.New()
+	if data.ID != nil {
+		id = *data.ID
+	}
+
+	locale, currency := userSettingsOrDefault(data.Locale, data.PreferredCurrency)
+
+	if err := s.repository.CreateUser(ctx, id, data.DisplayName, data.Email, locale, currency); err != nil {
+		slog.ErrorContext(ctx, "create user failed", "error", err)
+		return uuid.Nil, userError(err, i18n.CreateUserFailed)
+	}
+
+	slog.InfoContext(ctx, "user created")
+	return id, nil
+}
+
+func (s *UserService) UpdateUser(ctx context.Context, id uuid.UUID, data schemas.UpdateUser) error {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUser")
+	defer span.End()
+
+	locale, currency := userSettingsOrDefault(data.Locale, data.PreferredCurrency)
+
+	if err := s.repository.UpdateUser(ctx, id, data.DisplayName, data.Email, locale, currency); err != nil {
+		slog.ErrorContext(ctx, "update user failed", "error", err)
+		return userError(err, i18n.UpdateUserFailed)
+	}
+
+	slog.InfoContext(ctx, "user updated")
+	return nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
+	if err := s.repository.DeleteUser(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "delete user failed", "error", err)
+		return userError(err, i18n.DeleteUserFailed)
+	}
+
+	slog.InfoContext(ctx, "user deleted")
+	return nil
+}
+
+func userSettingsOrDefault(locale, currency string) (string, string) {
+	if locale == "" {
+		locale = defaultUserLocale
+	}
+	if currency == "" {
+		currency = defaultUserCurrency
+	}
+
+	return locale, currency
+}
+
+func userError(err error, message i18n.Message) error {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		return &schemas.AppError{
+			Code:    http.StatusNotFound,
+			Message: i18n.UserNotFound,
+			Err:     err,
+		}
+	case gorm.ErrDuplicatedKey:
+		return &schemas.AppError{
+			Code:    http.StatusConflict,
+			Message: i18n.UserExists,
+			Err:     err,
+		}
+	case repository.ErrUserHasSubscriptions:
+		return &schemas.AppError{
+			Code:    http.StatusConflict,
+			Message: i18n.UserHasSubscriptions,
+			Err:     err,
+		}
+	default:
+		return &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: message,
+			Err:     err,
+		}
+	}
+}
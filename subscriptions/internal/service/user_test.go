@@ -0,0 +1,230 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// userRepo fails the calls with err and records the written users, the methods the tests
+// don't reach panic.
+type userRepo struct {
+	repository.UserRepo
+
+	exists bool
+	err    error
+
+	created  *models.User
+	ensured  []uuid.UUID
+	existsID []uuid.UUID
+}
+
+func (r *userRepo) GetUser(_ context.Context, id uuid.UUID) (*models.User, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &models.User{ID: id}, nil
+}
+
+func (r *userRepo) UserExists(_ context.Context, id uuid.UUID) (bool, error) {
+	r.existsID = append(r.existsID, id)
+	return r.exists, r.err
+}
+
+func (r *userRepo) CreateUser(_ context.Context, id uuid.UUID, displayName string, email *string, locale, currency string) error {
+	r.created = &models.User{
+		ID:                id,
+		DisplayName:       displayName,
+		Email:             email,
+		Locale:            locale,
+		PreferredCurrency: currency,
+	}
+	return r.err
+}
+
+func (r *userRepo) EnsureUser(_ context.Context, id uuid.UUID) error {
+	r.ensured = append(r.ensured, id)
+	return r.err
+}
+
+func (r *userRepo) UpdateUser(context.Context, uuid.UUID, string, *string, string, string) error {
+	return r.err
+}
+
+func (r *userRepo) DeleteUser(context.Context, uuid.UUID) error {
+	return r.err
+}
+
+// createRepo creates the subscriptions.
+type createRepo struct {
+	repository.SubscriptionRepo
+}
+
+func (r *createRepo) CreateRecord(_ context.Context, _ string, _ time.Time, _ uint, _ uuid.UUID, _ *time.Time, _ *uint, _ []string, hook repository.WriteHook) (*uint, error) {
+	id := uint(1)
+	return &id, hook(repository.TxRepos{Subscriptions: r, Budgets: &budgetRepo{}}, id)
+}
+
+func (r *createRepo) GetRecord(_ context.Context, id uint) (*models.Subscription, error) {
+	return &models.Subscription{ID: id, ServiceName: "Yandex Plus"}, nil
+}
+
+func TestUserErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "missing user", err: gorm.ErrRecordNotFound, code: http.StatusNotFound},
+		{name: "duplicate id or email", err: gorm.ErrDuplicatedKey, code: http.StatusConflict},
+		{name: "user with subscriptions", err: repository.ErrUserHasSubscriptions, code: http.StatusConflict},
+		{name: "other error", err: errors.New("connection refused"), code: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userService := service.NewUserService(&userRepo{err: tt.err})
+			id := uuid.New()
+
+			if _, err := userService.GetUser(context.Background(), id); errorCode(err) != tt.code {
+				t.Errorf("get: expected status %d, got %v", tt.code, err)
+			}
+			if _, err := userService.CreateUser(context.Background(), schemas.CreateUser{DisplayName: "Ivan"}); errorCode(err) != tt.code {
+				t.Errorf("create: expected status %d, got %v", tt.code, err)
+			}
+			if err := userService.UpdateUser(context.Background(), id, schemas.UpdateUser{DisplayName: "Ivan"}); errorCode(err) != tt.code {
+				t.Errorf("update: expected status %d, got %v", tt.code, err)
+			}
+			if err := userService.DeleteUser(context.Background(), id); errorCode(err) != tt.code {
+				t.Errorf("delete: expected status %d, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name     string
+		data     schemas.CreateUser
+		locale   string
+		currency string
+	}{
+		{name: "default settings", data: schemas.CreateUser{DisplayName: "Ivan"}, locale: "ru", currency: "RUB"},
+		{
+			name:     "own settings",
+			data:     schemas.CreateUser{DisplayName: "John", Locale: "en-US", PreferredCurrency: "USD"},
+			locale:   "en-US",
+			currency: "USD",
+		},
+		{name: "own id", data: schemas.CreateUser{ID: &id, DisplayName: "Ivan"}, locale: "ru", currency: "RUB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &userRepo{}
+			userService := service.NewUserService(repo)
+
+			created, err := userService.CreateUser(context.Background(), tt.data)
+			if err != nil {
+				t.Fatalf("create user: %v", err)
+			}
+
+			if tt.data.ID != nil && created != *tt.data.ID {
+				t.Errorf("expected id %s, got %s", *tt.data.ID, created)
+			}
+			if repo.created.ID != created {
+				t.Errorf("expected the returned id %s to be stored, got %s", created, repo.created.ID)
+			}
+			if repo.created.Locale != tt.locale || repo.created.PreferredCurrency != tt.currency {
+				t.Errorf("expected settings %s %s, got %s %s", tt.locale, tt.currency, repo.created.Locale, repo.created.PreferredCurrency)
+			}
+		})
+	}
+}
+
+func TestSubUserCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		autoCreate bool
+		exists     bool
+		err        error
+		// code is the expected status, 0 if the subscription is created
+		code int
+	}{
+		{name: "existing user", exists: true},
+		{name: "unknown user", code: http.StatusBadRequest},
+		{name: "lookup failure", err: errors.New("connection refused"), code: http.StatusInternalServerError},
+		{name: "unknown user created", autoCreate: true},
+		{name: "creation failure", autoCreate: true, err: errors.New("connection refused"), code: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &userRepo{exists: tt.exists, err: tt.err}
+			subsService := service.NewService(&createRepo{}, &categoryRepo{}, users, &budgetRepo{}, tt.autoCreate, nil)
+
+			userID := uuid.New()
+			_, err := subsService.CreateSub(context.Background(), schemas.CreateSub{
+				ServiceName: "Yandex Plus",
+				Price:       400,
+				UserID:      userID,
+				StartDate:   "07-2025",
+			})
+			if errorCode(err) != tt.code {
+				t.Fatalf("expected status %d, got %v", tt.code, err)
+			}
+
+			checked := users.existsID
+			if tt.autoCreate {
+				checked = users.ensured
+			}
+			if len(checked) != 1 || checked[0] != userID {
+				t.Errorf("expected user %s checked once, got %v", userID, checked)
+			}
+		})
+	}
+}
+
+func TestCreateSubDates(t *testing.T) {
+	invalid := "13-2025"
+
+	tests := []struct {
+		name      string
+		startDate string
+		endDate   *string
+		// code is the expected status, 0 if the subscription is created
+		code int
+	}{
+		{name: "valid dates", startDate: "07-2025"},
+		{name: "invalid start date", startDate: invalid, code: http.StatusBadRequest},
+		{name: "invalid end date", startDate: "07-2025", endDate: &invalid, code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subsService := service.NewService(&createRepo{}, &categoryRepo{}, &userRepo{exists: true}, &budgetRepo{}, false, nil)
+
+			_, err := subsService.CreateSub(context.Background(), schemas.CreateSub{
+				ServiceName: "Yandex Plus",
+				Price:       400,
+				UserID:      uuid.New(),
+				StartDate:   tt.startDate,
+				EndDate:     tt.endDate,
+			})
+			if errorCode(err) != tt.code {
+				t.Fatalf("expected status %d, got %v", tt.code, err)
+			}
+		})
+	}
+}
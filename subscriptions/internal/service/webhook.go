@@ -0,0 +1,459 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/health"
+	"subscriptions/rest-service/internal/i18n"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/schemas"
+	"subscriptions/rest-service/internal/tenant"
+	"subscriptions/rest-service/internal/tracing"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	deliveryLease       = time.Minute
+	deliveryBaseBackoff = 10 * time.Second
+	deliveryMaxBackoff  = 6 * time.Hour
+	deliveryBodyLimit   = 1024
+)
+
+type WebhookService struct {
+	repository  repository.WebhookRepo
+	client      *http.Client
+	maxAttempts int
+	// workers is the number of deliveries sent concurrently.
+	workers int
+	// wakeup notifies the delivery worker about new deliveries.
+	wakeup chan struct{}
+}
+
+func NewWebhookService(repo repository.WebhookRepo, timeout time.Duration, maxAttempts, workers int) WebhookService {
+	return WebhookService{
+		repository:  repo,
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+		workers:     max(workers, 1),
+		wakeup:      make(chan struct{}, 1),
+	}
+}
+
+func toWebhookInfo(record models.Webhook) schemas.WebhookInfo {
+	return schemas.WebhookInfo{
+		ID:         record.ID,
+		URL:        record.URL,
+		EventTypes: record.EventTypes,
+		Active:     record.Active,
+		CreatedAt:  record.CreatedAt,
+	}
+}
+
+func toDeliveryInfo(record models.WebhookDelivery) schemas.WebhookDeliveryInfo {
+	return schemas.WebhookDeliveryInfo{
+		ID:            record.ID,
+		WebhookID:     record.WebhookID,
+		EventType:     record.EventType,
+		Payload:       record.Payload,
+		Status:        record.Status,
+		Attempts:      record.Attempts,
+		NextAttemptAt: record.NextAttemptAt,
+		ResponseCode:  record.ResponseCode,
+		ResponseBody:  record.ResponseBody,
+		LastError:     record.LastError,
+		CreatedAt:     record.CreatedAt,
+		DeliveredAt:   record.DeliveredAt,
+	}
+}
+
+func (s *WebhookService) GetAllWebhooks(ctx context.Context) ([]schemas.WebhookInfo, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetAllWebhooks")
+	defer span.End()
+
+	records, err := s.repository.GetWebhooks(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "get all webhooks failed", "error", err)
+		return nil, &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: i18n.RetrieveWebhooksFailed,
+			Err:     err,
+		}
+	}
+
+	result := make([]schemas.WebhookInfo, len(records))
+	for i, record := range records {
+		result[i] = toWebhookInfo(record)
+	}
+
+	return result, nil
+}
+
+func (s *WebhookService) GetWebhook(ctx context.Context, id uint) (*schemas.WebhookInfo, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetWebhook")
+	defer span.End()
+
+	record, err := s.repository.GetWebhook(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "get webhook failed", "error", err)
+		return nil, webhookError(err, i18n.RetrieveWebhookFailed)
+	}
+
+	info := toWebhookInfo(*record)
+	return &info, nil
+}
+
+// CreateWebhook registers the endpoint and returns its id together with the signing secret.
+func (s *WebhookService) CreateWebhook(ctx context.Context, data schemas.CreateWebhook) (*schemas.CreateWebhookReturn, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.CreateWebhook")
+	defer span.End()
+
+	secret := ""
+	if data.Secret != nil {
+		secret = *data.Secret
+	} else {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			return nil, webhookError(err, i18n.GenerateSecretFailed)
+		}
+		secret = generated
+	}
+
+	active := data.Active == nil || *data.Active
+
+	id, err := s.repository.CreateWebhook(ctx, data.URL, secret, data.EventTypes, active)
+	if err != nil {
+		slog.ErrorContext(ctx, "create webhook failed", "error", err)
+		return nil, webhookError(err, i18n.CreateWebhookFailed)
+	}
+
+	slog.InfoContext(ctx, "webhook created")
+	return &schemas.CreateWebhookReturn{ID: *id, Secret: secret}, nil
+}
+
+func (s *WebhookService) UpdateWebhook(ctx context.Context, id uint, data schemas.CreateWebhook) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.UpdateWebhook")
+	defer span.End()
+
+	active := data.Active == nil || *data.Active
+
+	if err := s.repository.UpdateWebhook(ctx, id, data.URL, data.Secret, data.EventTypes, active); err != nil {
+		slog.ErrorContext(ctx, "update webhook failed", "error", err)
+		return webhookError(err, i18n.UpdateWebhookFailed)
+	}
+
+	slog.InfoContext(ctx, "webhook updated")
+	return nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "WebhookService.DeleteWebhook")
+	defer span.End()
+
+	if err := s.repository.DeleteWebhook(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "delete webhook failed", "error", err)
+		return webhookError(err, i18n.DeleteWebhookFailed)
+	}
+
+	slog.InfoContext(ctx, "webhook deleted")
+	return nil
+}
+
+func (s *WebhookService) GetDeliveries(ctx context.Context, webhookID uint, pageNumber, pageSize int) (*schemas.DeliveriesPaginationResponse, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetDeliveries")
+	defer span.End()
+
+	if _, err := s.repository.GetWebhook(ctx, webhookID); err != nil {
+		return nil, webhookError(err, i18n.RetrieveWebhookFailed)
+	}
+
+	offset := (pageNumber - 1) * pageSize
+	records, totalPages, err := s.repository.GetDeliveries(ctx, webhookID, offset, pageSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "get deliveries failed", "error", err)
+		return nil, &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: i18n.RetrieveDeliveriesFailed,
+			Err:     err,
+		}
+	}
+
+	result := make([]schemas.WebhookDeliveryInfo, len(records))
+	for i, record := range records {
+		result[i] = toDeliveryInfo(record)
+	}
+
+	return &schemas.DeliveriesPaginationResponse{
+		Deliveries: result,
+		Pagination: schemas.Pagination{
+			PageNumber: pageNumber,
+			Size:       pageSize,
+			TotalPages: *totalPages,
+			HasNext:    pageNumber < *totalPages,
+			HasPrev:    pageNumber > 1 && *totalPages > 0,
+		},
+	}, nil
+}
+
+// Redeliver schedules a new delivery with the payload of an existing one.
+func (s *WebhookService) Redeliver(ctx context.Context, webhookID, deliveryID uint) (uint, error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Redeliver")
+	defer span.End()
+
+	original, err := s.repository.GetDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		slog.ErrorContext(ctx, "redeliver failed", "error", err)
+		if err == gorm.ErrRecordNotFound {
+			return 0, &schemas.AppError{
+				Code:    http.StatusNotFound,
+				Message: i18n.DeliveryNotFound,
+				Err:     err,
+			}
+		}
+		return 0, webhookError(err, i18n.RetrieveDeliveryFailed)
+	}
+
+	delivery := []models.WebhookDelivery{{
+		WebhookID:     original.WebhookID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}}
+
+	if err := s.repository.CreateDeliveries(ctx, delivery); err != nil {
+		return 0, webhookError(err, i18n.CreateDeliveryFailed)
+	}
+	s.wake()
+
+	slog.InfoContext(ctx, "delivery scheduled for redelivery", "delivery_id", deliveryID)
+	return delivery[0].ID, nil
+}
+
+// HandleEvent schedules deliveries of the event to every active webhook subscribed to its type.
+// The event is published again if the deliveries couldn't be scheduled, the deliveries
+// scheduled for the event before are not duplicated.
+func (s *WebhookService) HandleEvent(event events.Event) error {
+	ctx := tenant.WithID(context.Background(), event.TenantID)
+
+	webhooks, err := s.repository.GetActiveWebhooks(ctx, string(event.Type))
+	if err != nil {
+		slog.ErrorContext(ctx, "handle event failed", "event_id", event.ID, "error", err)
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.ErrorContext(ctx, "handle event failed", "event_id", event.ID, "error", err)
+		return err
+	}
+
+	deliveries := make([]models.WebhookDelivery, len(webhooks))
+	for i, webhook := range webhooks {
+		deliveries[i] = models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       &event.ID,
+			EventType:     string(event.Type),
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		}
+	}
+
+	if err := s.repository.CreateDeliveries(ctx, deliveries); err != nil {
+		slog.ErrorContext(ctx, "handle event failed", "event_id", event.ID, "error", err)
+		return err
+	}
+	s.wake()
+
+	return nil
+}
+
+func (s *WebhookService) wake() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// RunDeliveries sends due deliveries until ctx is cancelled. Failed deliveries
+// are retried with exponential backoff until the attempts limit is reached.
+func (s *WebhookService) RunDeliveries(ctx context.Context, pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		health.Update(ctx, s.deliverDue(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wakeup:
+		}
+	}
+}
+
+// deliverDue sends the due deliveries in batches of one delivery per worker. The deliveries
+// of a batch are sent concurrently, so a slow endpoint holds back the others for one request
+// timeout at most.
+func (s *WebhookService) deliverDue(ctx context.Context) error {
+	// deliveries of all tenants are sent by the same worker
+	ctx = tenant.Unscoped(ctx)
+
+	for ctx.Err() == nil {
+		deliveries, err := s.repository.ClaimDueDeliveries(ctx, s.workers, deliveryLease)
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(deliveries))
+		for i := range deliveries {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				// the delivery isn't finished until its result is saved, it is sent again
+				// when its lease expires
+				errs[i] = s.deliver(ctx, &deliveries[i])
+			}(i)
+		}
+		wg.Wait()
+
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deliver sends the delivery and saves its result.
+func (s *WebhookService) deliver(ctx context.Context, delivery *models.WebhookDelivery) error {
+	delivery.Attempts++
+	delivery.ResponseCode = nil
+	delivery.ResponseBody = nil
+	delivery.LastError = nil
+
+	code, body, err := s.send(ctx, delivery)
+	if code != 0 {
+		delivery.ResponseCode = &code
+		delivery.ResponseBody = &body
+	}
+
+	switch {
+	case err == nil && code >= 200 && code < 300:
+		now := time.Now()
+		delivery.Status = models.DeliveryDelivered
+		delivery.DeliveredAt = &now
+	default:
+		lastError := fmt.Sprintf("unexpected response status %d", code)
+		if err != nil {
+			lastError = err.Error()
+		}
+		delivery.LastError = &lastError
+
+		if delivery.Attempts >= s.maxAttempts {
+			delivery.Status = models.DeliveryFailed
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(deliveryBackoff(delivery.Attempts))
+		}
+
+		slog.WarnContext(ctx, "webhook delivery failed", "delivery_id", delivery.ID, "attempt", delivery.Attempts, "error", lastError)
+	}
+
+	if err := s.repository.SaveDeliveryResult(ctx, delivery); err != nil {
+		slog.ErrorContext(ctx, "save delivery result failed", "delivery_id", delivery.ID, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *WebhookService) send(ctx context.Context, delivery *models.WebhookDelivery) (int, string, error) {
+	if delivery.Webhook == nil {
+		return 0, "", fmt.Errorf("webhook %d not found", delivery.WebhookID)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+signPayload(delivery.Webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, deliveryBodyLimit))
+	return resp.StatusCode, string(body), nil
+}
+
+// signPayload returns the hex encoded HMAC-SHA256 of "<timestamp>.<payload>".
+func signPayload(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func deliveryBackoff(attempts int) time.Duration {
+	backoff := deliveryBaseBackoff
+	for i := 1; i < attempts && backoff < deliveryMaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, deliveryMaxBackoff)
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+func webhookError(err error, message i18n.Message) error {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		return &schemas.AppError{
+			Code:    http.StatusNotFound,
+			Message: i18n.WebhookNotFound,
+			Err:     err,
+		}
+	default:
+		return &schemas.AppError{
+			Code:    http.StatusInternalServerError,
+			Message: message,
+			Err:     err,
+		}
+	}
+}
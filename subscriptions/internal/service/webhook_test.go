@@ -0,0 +1,217 @@
+package service_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/service"
+	"sync"
+	"testing"
+	"time"
+)
+
+const webhookSecret = "whsec_test"
+
+// deliveryRepo hands out the due deliveries and sends the saved results to saved.
+type deliveryRepo struct {
+	repository.WebhookRepo
+
+	mu       sync.Mutex
+	due      []models.WebhookDelivery
+	webhooks []models.Webhook
+	created  []models.WebhookDelivery
+	saved    chan models.WebhookDelivery
+}
+
+func (r *deliveryRepo) GetActiveWebhooks(context.Context, string) ([]models.Webhook, error) {
+	return r.webhooks, nil
+}
+
+func (r *deliveryRepo) CreateDeliveries(_ context.Context, deliveries []models.WebhookDelivery) error {
+	r.created = append(r.created, deliveries...)
+	return nil
+}
+
+func (r *deliveryRepo) ClaimDueDeliveries(_ context.Context, limit int, _ time.Duration) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := min(limit, len(r.due))
+	claimed := r.due[:n]
+	r.due = r.due[n:]
+	return claimed, nil
+}
+
+func (r *deliveryRepo) SaveDeliveryResult(_ context.Context, delivery *models.WebhookDelivery) error {
+	r.saved <- *delivery
+	return nil
+}
+
+// runDeliveries runs the delivery worker until n results are saved.
+func runDeliveries(t *testing.T, webhookService service.WebhookService, repo *deliveryRepo, n int) []models.WebhookDelivery {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go webhookService.RunDeliveries(ctx, time.Hour)
+
+	results := make([]models.WebhookDelivery, 0, n)
+	for range n {
+		select {
+		case delivery := <-repo.saved:
+			results = append(results, delivery)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected %d delivery results, got %d", n, len(results))
+		}
+	}
+
+	return results
+}
+
+// signedServer responds with status to the requests with a valid signature.
+func signedServer(t *testing.T, status int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mac := hmac.New(sha256.New, []byte(webhookSecret))
+		mac.Write([]byte(r.Header.Get("X-Webhook-Timestamp") + "." + string(body)))
+		if r.Header.Get("X-Webhook-Signature") != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("invalid signature %q", r.Header.Get("X-Webhook-Signature"))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		attempts int
+		// expected is the status of the delivery, backoff is the expected delay of the
+		// next attempt of a pending delivery
+		expected string
+		backoff  time.Duration
+	}{
+		{name: "delivered", status: http.StatusNoContent, expected: models.DeliveryDelivered},
+		{name: "first failure", status: http.StatusInternalServerError, expected: models.DeliveryPending, backoff: 10 * time.Second},
+		{name: "fourth failure", status: http.StatusBadGateway, attempts: 3, expected: models.DeliveryPending, backoff: 80 * time.Second},
+		{name: "last attempt", status: http.StatusInternalServerError, attempts: 7, expected: models.DeliveryFailed},
+		{name: "delivered at the last attempt", status: http.StatusOK, attempts: 7, expected: models.DeliveryDelivered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := signedServer(t, tt.status)
+			repo := &deliveryRepo{
+				due: []models.WebhookDelivery{{
+					ID:       1,
+					Webhook:  &models.Webhook{URL: server.URL, Secret: webhookSecret},
+					Payload:  `{"type":"subscription.created"}`,
+					Status:   models.DeliveryPending,
+					Attempts: tt.attempts,
+				}},
+				saved: make(chan models.WebhookDelivery, 1),
+			}
+			webhookService := service.NewWebhookService(repo, time.Second, 8, 1)
+
+			delivery := runDeliveries(t, webhookService, repo, 1)[0]
+
+			if delivery.Status != tt.expected {
+				t.Errorf("expected status %s, got %s", tt.expected, delivery.Status)
+			}
+			if delivery.Attempts != tt.attempts+1 {
+				t.Errorf("expected %d attempts, got %d", tt.attempts+1, delivery.Attempts)
+			}
+			if delivery.ResponseCode == nil || *delivery.ResponseCode != tt.status {
+				t.Errorf("expected response code %d, got %v", tt.status, delivery.ResponseCode)
+			}
+			if tt.expected != models.DeliveryPending {
+				return
+			}
+			if delay := time.Until(delivery.NextAttemptAt); delay > tt.backoff || delay < tt.backoff-2*time.Second {
+				t.Errorf("expected the next attempt in %s, got %s", tt.backoff, delay)
+			}
+		})
+	}
+}
+
+func TestWebhookWorkers(t *testing.T) {
+	const workers = 3
+
+	// the endpoint responds once all the workers are sending to it, so the deliveries
+	// succeed only if they are sent concurrently
+	var arrived sync.WaitGroup
+	arrived.Add(workers)
+	allArrived := make(chan struct{})
+	go func() {
+		arrived.Wait()
+		close(allArrived)
+	}()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived.Done()
+
+		select {
+		case <-allArrived:
+			w.WriteHeader(http.StatusOK)
+		case <-time.After(2 * time.Second):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	repo := &deliveryRepo{saved: make(chan models.WebhookDelivery, workers)}
+	for i := range workers {
+		repo.due = append(repo.due, models.WebhookDelivery{
+			ID:      uint(i + 1),
+			Webhook: &models.Webhook{URL: server.URL, Secret: webhookSecret},
+			Payload: `{}`,
+			Status:  models.DeliveryPending,
+		})
+	}
+	webhookService := service.NewWebhookService(repo, 5*time.Second, 8, workers)
+
+	for _, delivery := range runDeliveries(t, webhookService, repo, workers) {
+		if delivery.Status != models.DeliveryDelivered {
+			t.Errorf("expected delivery %d delivered, got %s", delivery.ID, delivery.Status)
+		}
+	}
+}
+
+func TestWebhookHandleEvent(t *testing.T) {
+	repo := &deliveryRepo{webhooks: []models.Webhook{{ID: 1}, {ID: 2}}}
+	webhookService := service.NewWebhookService(repo, time.Second, 8, 1)
+
+	err := webhookService.HandleEvent(events.Event{ID: 7, Type: events.BudgetExceeded, Data: map[string]int{"id": 1}})
+	if err != nil {
+		t.Fatalf("handle event: %v", err)
+	}
+
+	if len(repo.created) != len(repo.webhooks) {
+		t.Fatalf("expected %d deliveries, got %d", len(repo.webhooks), len(repo.created))
+	}
+	for i, delivery := range repo.created {
+		if delivery.WebhookID != repo.webhooks[i].ID || delivery.Status != models.DeliveryPending {
+			t.Errorf("expected a pending delivery to webhook %d, got %+v", repo.webhooks[i].ID, delivery)
+		}
+		if delivery.EventID == nil || *delivery.EventID != 7 {
+			t.Errorf("expected the delivery of event 7, got %v", delivery.EventID)
+		}
+		if delivery.EventType != string(events.BudgetExceeded) {
+			t.Errorf("expected event type %s, got %s", events.BudgetExceeded, delivery.EventType)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package tenant
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// fieldName is the field marking the models that belong to a tenant.
+const fieldName = "TenantID"
+
+// Plugin limits every query, update and delete of the models with the TenantID field
+// to the tenant of the statement context and sets the tenant of created records.
+// Statements without tenant fail unless the context is unscoped, so a forgotten
+// filter can't read the data of another tenant. Raw SQL must filter by tenant itself.
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "tenant"
+}
+
+func (p Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:row", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scope); err != nil {
+		return err
+	}
+
+	return callbacks.Create().Before("gorm:create").Register("tenant:create", assign)
+}
+
+func tenantField(stmt *gorm.Statement) *schema.Field {
+	if stmt.Schema == nil {
+		return nil
+	}
+
+	return stmt.Schema.LookUpField(fieldName)
+}
+
+func scope(db *gorm.DB) {
+	stmt := db.Statement
+
+	field := tenantField(stmt)
+	// raw statements already have their SQL
+	if field == nil || stmt.SQL.Len() > 0 || IsUnscoped(stmt.Context) {
+		return
+	}
+
+	id, ok := FromContext(stmt.Context)
+	if !ok {
+		db.AddError(ErrMissing)
+		return
+	}
+
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: id},
+	}})
+}
+
+func assign(db *gorm.DB) {
+	stmt := db.Statement
+
+	field := tenantField(stmt)
+	if field == nil {
+		return
+	}
+
+	id, ok := FromContext(stmt.Context)
+	if !ok {
+		// workers keep the tenant of the records they write
+		if !IsUnscoped(stmt.Context) {
+			db.AddError(ErrMissing)
+		}
+		return
+	}
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			if err := field.Set(stmt.Context, reflect.Indirect(stmt.ReflectValue.Index(i)), id); err != nil {
+				db.AddError(err)
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(stmt.Context, stmt.ReflectValue, id); err != nil {
+			db.AddError(err)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package tenant_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"subscriptions/rest-service/internal/tenant"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type record struct {
+	ID       uint
+	TenantID string
+	Name     string
+}
+
+// shared is a model of all tenants.
+type shared struct {
+	ID   uint
+	Name string
+}
+
+// newDB returns the dry run database with the plugin, the statements are built but not sent.
+func newDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	pool, err := sql.Open("pgx", "host=127.0.0.1 port=1 user=test dbname=test connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{
+		Logger:                 logger.Discard,
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestPluginScope(t *testing.T) {
+	acme := tenant.WithID(context.Background(), "acme")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		run  func(db *gorm.DB) *gorm.DB
+		// scoped is whether the statement is limited to the tenant acme
+		scoped bool
+		err    error
+	}{
+		{
+			name:   "query",
+			ctx:    acme,
+			run:    func(db *gorm.DB) *gorm.DB { return db.Where("name = ?", "a").Find(&[]record{}) },
+			scoped: true,
+		},
+		{
+			name:   "query by primary key",
+			ctx:    acme,
+			run:    func(db *gorm.DB) *gorm.DB { return db.Take(&record{}, 1) },
+			scoped: true,
+		},
+		{
+			name: "count",
+			ctx:  acme,
+			run: func(db *gorm.DB) *gorm.DB {
+				var count int64
+				return db.Model(&record{}).Count(&count)
+			},
+			scoped: true,
+		},
+		{
+			name:   "update",
+			ctx:    acme,
+			run:    func(db *gorm.DB) *gorm.DB { return db.Model(&record{ID: 1}).Update("name", "b") },
+			scoped: true,
+		},
+		{
+			name: "update where",
+			ctx:  acme,
+			run: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&record{}).Where("id > ?", 0).Updates(map[string]any{"name": "b"})
+			},
+			scoped: true,
+		},
+		{
+			name:   "delete",
+			ctx:    acme,
+			run:    func(db *gorm.DB) *gorm.DB { return db.Delete(&record{}, 1) },
+			scoped: true,
+		},
+		{
+			name: "query without tenant",
+			ctx:  context.Background(),
+			run:  func(db *gorm.DB) *gorm.DB { return db.Find(&[]record{}) },
+			err:  tenant.ErrMissing,
+		},
+		{
+			name: "update without tenant",
+			ctx:  context.Background(),
+			run:  func(db *gorm.DB) *gorm.DB { return db.Model(&record{ID: 1}).Update("name", "b") },
+			err:  tenant.ErrMissing,
+		},
+		{
+			name: "delete without tenant",
+			ctx:  context.Background(),
+			run:  func(db *gorm.DB) *gorm.DB { return db.Delete(&record{}, 1) },
+			err:  tenant.ErrMissing,
+		},
+		{
+			name: "empty tenant",
+			ctx:  tenant.WithID(context.Background(), ""),
+			run:  func(db *gorm.DB) *gorm.DB { return db.Find(&[]record{}) },
+			err:  tenant.ErrMissing,
+		},
+		{
+			name: "unscoped query",
+			ctx:  tenant.Unscoped(context.Background()),
+			run:  func(db *gorm.DB) *gorm.DB { return db.Find(&[]record{}) },
+		},
+		{
+			name: "model of all tenants",
+			ctx:  context.Background(),
+			run:  func(db *gorm.DB) *gorm.DB { return db.Find(&[]shared{}) },
+		},
+		{
+			name: "raw query",
+			ctx:  context.Background(),
+			run:  func(db *gorm.DB) *gorm.DB { return db.Raw("SELECT * FROM records").Find(&[]record{}) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := tt.run(newDB(t).WithContext(tt.ctx))
+			if !errors.Is(tx.Error, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, tx.Error)
+			}
+			if tt.err != nil {
+				return
+			}
+
+			query := tx.Statement.SQL.String()
+			scoped := strings.Contains(query, `"records"."tenant_id" = $`) && slices.Contains(tx.Statement.Vars, any("acme"))
+			if scoped != tt.scoped {
+				t.Errorf("expected scoped %v, got %s with %v", tt.scoped, query, tx.Statement.Vars)
+			}
+		})
+	}
+}
+
+func TestPluginAssign(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		records []record
+		// tenants are the expected tenants of the created records
+		tenants []string
+		err     error
+	}{
+		{
+			name:    "tenant of the context",
+			ctx:     tenant.WithID(context.Background(), "acme"),
+			records: []record{{Name: "a"}},
+			tenants: []string{"acme"},
+		},
+		{
+			name:    "tenant of another record replaced",
+			ctx:     tenant.WithID(context.Background(), "acme"),
+			records: []record{{Name: "a", TenantID: "globex"}},
+			tenants: []string{"acme"},
+		},
+		{
+			name:    "batch",
+			ctx:     tenant.WithID(context.Background(), "acme"),
+			records: []record{{Name: "a"}, {Name: "b", TenantID: "globex"}},
+			tenants: []string{"acme", "acme"},
+		},
+		{
+			name:    "without tenant",
+			ctx:     context.Background(),
+			records: []record{{Name: "a", TenantID: "globex"}},
+			err:     tenant.ErrMissing,
+		},
+		{
+			name:    "unscoped keeps the tenant of the record",
+			ctx:     tenant.Unscoped(context.Background()),
+			records: []record{{Name: "a", TenantID: "globex"}},
+			tenants: []string{"globex"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t).WithContext(tt.ctx)
+
+			var err error
+			if len(tt.records) == 1 {
+				err = db.Create(&tt.records[0]).Error
+			} else {
+				err = db.Create(&tt.records).Error
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if tt.err != nil {
+				return
+			}
+
+			for i, record := range tt.records {
+				if record.TenantID != tt.tenants[i] {
+					t.Errorf("expected tenant %q of record %d, got %q", tt.tenants[i], i, record.TenantID)
+				}
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{id: "default", valid: true},
+		{id: "acme-1_eu", valid: true},
+		{id: "0", valid: true},
+		{id: strings.Repeat("a", 64), valid: true},
+		{id: strings.Repeat("a", 65), valid: false},
+		{id: "", valid: false},
+		{id: "Acme", valid: false},
+		{id: "-acme", valid: false},
+		{id: "acme corp", valid: false},
+		{id: "acme'--", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if valid := tenant.Valid(tt.id); valid != tt.valid {
+				t.Errorf("expected valid %v, got %v", tt.valid, valid)
+			}
+		})
+	}
+}
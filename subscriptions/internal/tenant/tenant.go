@@ -0,0 +1,50 @@
+/*
+Package tenant keeps the tenant of the request in the context and isolates the
+data of tenants in the database.
+*/
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// Header is the request header and gRPC metadata key selecting the tenant.
+const Header = "X-Tenant-ID"
+
+var (
+	ErrMissing = errors.New("tenant is not set in the context")
+	ErrInvalid = errors.New("invalid tenant id")
+)
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+type tenantKey struct{}
+
+type unscopedKey struct{}
+
+// Valid reports whether the id may be used as a tenant id.
+func Valid(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Unscoped marks the context of background workers that process the data of all
+// tenants. Requests must never get such a context.
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, true)
+}
+
+func IsUnscoped(ctx context.Context) bool {
+	unscoped, _ := ctx.Value(unscopedKey{}).(bool)
+	return unscoped
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// GormPlugin starts a span for every statement. The span has the SQL with placeholders
+// only, the values of the statement are not recorded.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	if err := callbacks.Create().Before("*").Register("tracing:before_create", before("create")); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("*").Register("tracing:after_create", after); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("*").Register("tracing:before_query", before("query")); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("*").Register("tracing:after_query", after); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("*").Register("tracing:before_update", before("update")); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("*").Register("tracing:after_update", after); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("*").Register("tracing:before_delete", before("delete")); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("*").Register("tracing:after_delete", after); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("*").Register("tracing:before_row", before("row")); err != nil {
+		return err
+	}
+	if err := callbacks.Row().After("*").Register("tracing:after_row", after); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().Before("*").Register("tracing:before_raw", before("raw")); err != nil {
+		return err
+	}
+
+	return callbacks.Raw().After("*").Register("tracing:after_raw", after)
+}
+
+func before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := tracer.Start(db.Statement.Context, "gorm."+operation, trace.WithSpanKind(trace.SpanKindClient))
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	stmt := db.Statement
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.statement", stmt.SQL.String()),
+		attribute.String("db.sql.table", stmt.Table),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
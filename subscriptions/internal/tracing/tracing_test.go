@@ -0,0 +1,67 @@
+package tracing_test
+
+import (
+	"context"
+	"subscriptions/rest-service/internal/tracing"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+		wantErr  bool
+	}{
+		{"no exporter", tracing.ExporterNone, false},
+		{"empty exporter", "", false},
+		{"stdout", tracing.ExporterStdout, false},
+		{"unknown exporter", "jaeger", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+				Exporter:    tt.exporter,
+				ServiceName: "subscriptions-test",
+				SampleRatio: 1,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Setup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if err := shutdown(context.Background()); err != nil {
+				t.Errorf("shutdown() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTraceID(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	defer provider.Shutdown(context.Background())
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"outside of traces", context.Background(), ""},
+		{"inside of span", ctx, span.SpanContext().TraceID().String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tracing.TraceID(tt.ctx); got != tt.want {
+				t.Errorf("TraceID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// ListAPIKeys returns the API keys, of the user if userID isn't nil.
+func (c *Client) ListAPIKeys(ctx context.Context, userID *uuid.UUID) ([]APIKey, error) {
+	query := url.Values{}
+	if userID != nil {
+		query.Set("user_id", userID.String())
+	}
+
+	var keys []APIKey
+	if err := c.do(ctx, http.MethodGet, apiURL("api-keys")+"/", query, nil, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (c *Client) GetAPIKey(ctx context.Context, id uint) (*APIKey, error) {
+	var key APIKey
+	if err := c.do(ctx, http.MethodGet, apiURL("api-keys", id), nil, nil, &key); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// CreateAPIKey creates the key, the returned key isn't available afterwards.
+func (c *Client) CreateAPIKey(ctx context.Context, data CreateAPIKey) (*CreatedAPIKey, error) {
+	var result CreatedAPIKey
+	if err := c.do(ctx, http.MethodPost, apiURL("api-keys")+"/", nil, data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// RotateAPIKey replaces the secret of the key keeping its name and scopes and returns the new key.
+func (c *Client) RotateAPIKey(ctx context.Context, id uint) (*CreatedAPIKey, error) {
+	var result CreatedAPIKey
+	if err := c.do(ctx, http.MethodPost, apiURL("api-keys", id, "rotate"), nil, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) RevokeAPIKey(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, apiURL("api-keys", id), nil, nil, nil)
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// ListBudgets returns the budgets, of the user if userID isn't nil.
+func (c *Client) ListBudgets(ctx context.Context, userID *uuid.UUID) ([]Budget, error) {
+	query := url.Values{}
+	if userID != nil {
+		query.Set("user_id", userID.String())
+	}
+
+	var budgets []Budget
+	if err := c.do(ctx, http.MethodGet, apiURL("budgets")+"/", query, nil, &budgets); err != nil {
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+func (c *Client) GetBudget(ctx context.Context, id uint) (*Budget, error) {
+	var budget Budget
+	if err := c.do(ctx, http.MethodGet, apiURL("budgets", id), nil, nil, &budget); err != nil {
+		return nil, err
+	}
+
+	return &budget, nil
+}
+
+func (c *Client) CreateBudget(ctx context.Context, data CreateBudget) (uint, error) {
+	var result createReturn
+	if err := c.do(ctx, http.MethodPost, apiURL("budgets")+"/", nil, data, &result); err != nil {
+		return 0, err
+	}
+
+	return result.ID, nil
+}
+
+func (c *Client) UpdateBudget(ctx context.Context, id uint, data UpdateBudget) error {
+	return c.do(ctx, http.MethodPut, apiURL("budgets", id), nil, data, nil)
+}
+
+func (c *Client) DeleteBudget(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, apiURL("budgets", id), nil, nil, nil)
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+func (c *Client) ListCategories(ctx context.Context) ([]Category, error) {
+	var categories []Category
+	if err := c.do(ctx, http.MethodGet, apiURL("categories")+"/", nil, nil, &categories); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (c *Client) GetCategory(ctx context.Context, id uint) (*Category, error) {
+	var category Category
+	if err := c.do(ctx, http.MethodGet, apiURL("categories", id), nil, nil, &category); err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (c *Client) CreateCategory(ctx context.Context, data CreateCategory) (uint, error) {
+	var result createReturn
+	if err := c.do(ctx, http.MethodPost, apiURL("categories")+"/", nil, data, &result); err != nil {
+		return 0, err
+	}
+
+	return result.ID, nil
+}
+
+func (c *Client) UpdateCategory(ctx context.Context, id uint, data CreateCategory) error {
+	return c.do(ctx, http.MethodPut, apiURL("categories", id), nil, data, nil)
+}
+
+func (c *Client) DeleteCategory(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, apiURL("categories", id), nil, nil, nil)
+}
+
+func (c *Client) ListTags(ctx context.Context) ([]Tag, error) {
+	var tags []Tag
+	if err := c.do(ctx, http.MethodGet, apiURL("tags")+"/", nil, nil, &tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (c *Client) CreateTag(ctx context.Context, data CreateTag) (uint, error) {
+	var result createReturn
+	if err := c.do(ctx, http.MethodPost, apiURL("tags")+"/", nil, data, &result); err != nil {
+		return 0, err
+	}
+
+	return result.ID, nil
+}
+
+func (c *Client) UpdateTag(ctx context.Context, id uint, data CreateTag) error {
+	return c.do(ctx, http.MethodPut, apiURL("tags", id), nil, data, nil)
+}
+
+func (c *Client) DeleteTag(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, apiURL("tags", id), nil, nil, nil)
+}
@@ -0,0 +1,322 @@
+/*
+Package client is the Go client of the subscriptions REST API. Every method takes
+a context, idempotent requests are retried with exponential backoff on network
+errors, 429 and 5xx gateway responses, and error responses are returned as *APIError.
+
+	c, err := client.New("http://localhost:8080", client.Config{Token: token})
+	for sub, err := range c.Subscriptions(ctx, client.SubscriptionFilter{Tag: "music"}) {
+		...
+	}
+*/
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiPath is the prefix of the REST endpoints.
+const apiPath = "/api/v1"
+
+const (
+	defaultMaxRetries = 3
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+)
+
+type Config struct {
+	// HTTPClient sends the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// Token is the JWT or API key sent as 'Authorization: Bearer <token>'.
+	Token string
+	// Tenant selects the tenant of the requests, the tenant of the token by default.
+	Tenant string
+	// MaxRetries is the number of retries of the idempotent requests, 3 if zero, negative disables retries.
+	MaxRetries int
+	// MinBackoff and MaxBackoff bound the randomized exponential delay between the retries,
+	// 100ms and 5s if zero. A longer Retry-After of the response is respected.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// UserAgent is sent in the User-Agent header if set.
+	UserAgent string
+	// Language is sent in the Accept-Language header if set, the error messages are in
+	// English by default and in Russian for "ru".
+	Language string
+}
+
+type Client struct {
+	baseURL *url.URL
+	config  Config
+}
+
+// New returns the client of the API served at baseURL, e.g. "http://localhost:8080".
+func New(baseURL string, config Config) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base url %q, expected http or https scheme", baseURL)
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = defaultMinBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaultMaxBackoff
+	}
+
+	return &Client{baseURL: parsed, config: config}, nil
+}
+
+// idempotent reports whether repeating the request has the same effect as sending it once.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryable reports whether the request may succeed if it is sent again.
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// do sends the request with the JSON body, if not nil, and decodes the JSON response into out, if not nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+	}
+
+	resp, err := c.send(ctx, method, path, query, payload, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return nil
+}
+
+// send sends the request, retrying the idempotent ones, and returns the successful response.
+// The caller closes the body of the response.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, payload []byte, header http.Header) (*http.Response, error) {
+	target, err := url.Parse(c.baseURL.String() + path)
+	if err != nil {
+		return nil, err
+	}
+	target.RawQuery = query.Encode()
+
+	retries := 0
+	if idempotent(method) && c.config.MaxRetries > 0 {
+		retries = c.config.MaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		if payload == nil {
+			req.Body = nil
+		} else {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Accept", "application/json, application/problem+json")
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		if c.config.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.config.Token)
+		}
+		if c.config.Tenant != "" {
+			req.Header.Set("X-Tenant-ID", c.config.Tenant)
+		}
+		if c.config.UserAgent != "" {
+			req.Header.Set("User-Agent", c.config.UserAgent)
+		}
+		if c.config.Language != "" {
+			req.Header.Set("Accept-Language", c.config.Language)
+		}
+
+		resp, err := c.config.HTTPClient.Do(req)
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			return resp, nil
+		}
+
+		var retryAfter time.Duration
+		if err != nil {
+			// the request was canceled by the caller
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+		} else {
+			err = newAPIError(resp)
+			resp.Body.Close()
+
+			if !retryable(resp.StatusCode) {
+				return nil, err
+			}
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		}
+
+		if attempt >= retries {
+			return nil, err
+		}
+
+		if err := sleep(ctx, max(c.backoff(attempt), retryAfter)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns the randomized delay before the retry, doubling with every attempt.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.config.MaxBackoff
+	if attempt < 30 {
+		delay = min(c.config.MinBackoff<<attempt, c.config.MaxBackoff)
+	}
+
+	// full jitter spreads the retries of the clients failed at the same time
+	return c.config.MinBackoff + rand.N(delay-c.config.MinBackoff+1)
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// apiURL returns the path of the REST endpoint, joining the escaped segments.
+func apiURL(segments ...any) string {
+	var path strings.Builder
+	path.WriteString(apiPath)
+	for _, segment := range segments {
+		path.WriteByte('/')
+		switch segment := segment.(type) {
+		case uint:
+			path.WriteString(strconv.FormatUint(uint64(segment), 10))
+		case string:
+			path.WriteString(url.PathEscape(segment))
+		default:
+			path.WriteString(url.PathEscape(fmt.Sprint(segment)))
+		}
+	}
+
+	return path.String()
+}
+
+// GraphQL sends the query to POST /graphql and decodes its data into out. The errors of the
+// response are returned as *GraphQLError along with the partial data.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]any, out any) error {
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	body := map[string]any{"query": query, "variables": variables}
+	if err := c.do(ctx, http.MethodPost, "/graphql", nil, body, &response); err != nil {
+		return err
+	}
+
+	if out != nil && len(response.Data) > 0 && string(response.Data) != "null" {
+		if err := json.Unmarshal(response.Data, out); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+	}
+
+	if len(response.Errors) > 0 {
+		messages := make([]string, len(response.Errors))
+		for i, graphqlErr := range response.Errors {
+			messages[i] = graphqlErr.Message
+		}
+		return &GraphQLError{Messages: messages}
+	}
+
+	return nil
+}
+
+// Live checks GET /livez, the process is alive if it returns nil.
+func (c *Client) Live(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/livez", nil, nil, nil)
+}
+
+// Ready returns the report of GET /readyz. The report of an instance that isn't
+// ready is returned along with an *APIError with status 503.
+func (c *Client) Ready(ctx context.Context) (*HealthReport, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL.String()+"/readyz", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var report HealthReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return &report, &APIError{StatusCode: resp.StatusCode, Message: "instance isn't ready"}
+	}
+
+	return &report, nil
+}
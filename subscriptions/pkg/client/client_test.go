@@ -0,0 +1,663 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"subscriptions/rest-service/internal/api/gql"
+	"subscriptions/rest-service/internal/api/handlers"
+	"subscriptions/rest-service/internal/api/middleware"
+	"subscriptions/rest-service/internal/api/routers"
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/health"
+	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/repository"
+	"subscriptions/rest-service/internal/service"
+	"subscriptions/rest-service/pkg/client"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	os.Exit(m.Run())
+}
+
+// subsRepo keeps the subscriptions in memory, the methods the tests don't reach panic.
+type subsRepo struct {
+	repository.SubscriptionRepo
+
+	mu      sync.Mutex
+	nextID  uint
+	records map[uint]models.Subscription
+}
+
+func newSubsRepo() *subsRepo {
+	return &subsRepo{records: map[uint]models.Subscription{}}
+}
+
+func (r *subsRepo) GetRecords(_ context.Context, offset, size int, filter repository.SubsFilter) ([]models.Subscription, *int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var records []models.Subscription
+	for _, record := range r.records {
+		hasTag := filter.Tag == nil || slices.ContainsFunc(record.Tags, func(tag models.Tag) bool {
+			return tag.Name == *filter.Tag
+		})
+		if hasTag && (filter.UserID == nil || record.UserID == *filter.UserID) {
+			records = append(records, record)
+		}
+	}
+	slices.SortFunc(records, func(a, b models.Subscription) int { return int(a.ID) - int(b.ID) })
+
+	totalPages := (len(records) + size - 1) / size
+	records = records[min(offset, len(records)):min(offset+size, len(records))]
+
+	return records, &totalPages, nil
+}
+
+func (r *subsRepo) GetRecord(_ context.Context, id uint) (*models.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &record, nil
+}
+
+func (r *subsRepo) CreateRecord(_ context.Context, serviceName string, startDate time.Time, price uint, userID uuid.UUID, endDate *time.Time, categoryID *uint, tags []string, _ repository.WriteHook) (*uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	r.records[r.nextID] = newRecord(r.nextID, serviceName, startDate, price, userID, endDate, categoryID, tags)
+
+	id := r.nextID
+	return &id, nil
+}
+
+func (r *subsRepo) FullUpdateRecord(_ context.Context, id, price uint, serviceName string, startDate time.Time, userID uuid.UUID, endDate *time.Time, categoryID *uint, tags []string, _ repository.WriteHook) (uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[id]
+	if !ok {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	r.records[id] = newRecord(id, serviceName, startDate, price, userID, endDate, categoryID, tags)
+
+	return record.UserID, nil
+}
+
+func (r *subsRepo) DeleteRecord(_ context.Context, id uint) (uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[id]
+	if !ok {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	delete(r.records, id)
+
+	return record.UserID, nil
+}
+
+// GetSubsSum sums the prices of the user's subscriptions, ignoring the period.
+func (r *subsRepo) GetSubsSum(_ context.Context, userID *uuid.UUID, _ *string, _ *uint, _, _ string) *uint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sum uint
+	for _, record := range r.records {
+		if userID == nil || record.UserID == *userID {
+			sum += record.Price
+		}
+	}
+
+	return &sum
+}
+
+// GetSubsSumByMonth charges the user's subscriptions every month of the period from their start.
+func (r *subsRepo) GetSubsSumByMonth(_ context.Context, userID *uuid.UUID, _ *string, _ *uint, startDate, endDate string) ([]repository.MonthSum, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	start, _ := time.Parse(time.DateOnly, startDate)
+	end, _ := time.Parse(time.DateOnly, endDate)
+
+	var sums []repository.MonthSum
+	for month := start; month.Before(end); month = month.AddDate(0, 1, 0) {
+		sum := repository.MonthSum{Month: month}
+		for _, record := range r.records {
+			if (userID == nil || record.UserID == *userID) && !record.StartDate.After(month) {
+				sum.TotalSum += record.Price
+			}
+		}
+		if sum.TotalSum > 0 {
+			sums = append(sums, sum)
+		}
+	}
+
+	return sums, nil
+}
+
+func newRecord(id uint, serviceName string, startDate time.Time, price uint, userID uuid.UUID, endDate *time.Time, categoryID *uint, tags []string) models.Subscription {
+	record := models.Subscription{
+		ID:          id,
+		ServiceName: serviceName,
+		Price:       price,
+		UserID:      userID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		CategoryID:  categoryID,
+	}
+	for _, tag := range tags {
+		record.Tags = append(record.Tags, models.Tag{Name: tag})
+	}
+
+	return record
+}
+
+type usersRepo struct {
+	repository.UserRepo
+
+	mu    sync.Mutex
+	users map[uuid.UUID]models.User
+}
+
+func (r *usersRepo) UserExists(_ context.Context, id uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.users[id]
+	return ok, nil
+}
+
+func (r *usersRepo) CreateUser(_ context.Context, id uuid.UUID, displayName string, email *string, locale, currency string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[id] = models.User{ID: id, DisplayName: displayName, Email: email, Locale: locale, PreferredCurrency: currency}
+	return nil
+}
+
+type budgetsRepo struct {
+	repository.BudgetRepo
+}
+
+func (budgetsRepo) GetBudgets(context.Context, *uuid.UUID) ([]models.Budget, error) {
+	return nil, nil
+}
+
+type tenantsRepo struct {
+	repository.TenantRepo
+}
+
+func (tenantsRepo) TenantExists(_ context.Context, id string) (bool, error) {
+	return id == "default", nil
+}
+
+// newServer serves the API router with the in-memory repositories, authentication
+// and rate limiting disabled.
+func newServer(t *testing.T) *client.Client {
+	t.Helper()
+
+	return newServerWithConfig(t, client.Config{})
+}
+
+// newServerWithConfig is newServer with the config of the client, the HTTP client is set to the server's.
+func newServerWithConfig(t *testing.T, config client.Config) *client.Client {
+	t.Helper()
+
+	subs := newSubsRepo()
+	users := &usersRepo{users: map[uuid.UUID]models.User{}}
+
+	subsService := service.NewService(subs, nil, users, budgetsRepo{}, false, nil)
+	userService := service.NewUserService(users)
+	tenantService := service.NewTenantService(tenantsRepo{}, "default")
+
+	router := routers.SetupRouter(
+		handlers.NewHandler(subsService),
+		handlers.NewCategoryHandler(service.NewCategoryService(nil, nil)),
+		handlers.NewTagHandler(service.NewTagService(nil, nil)),
+		handlers.NewUserHandler(userService, subsService),
+		handlers.NewBudgetHandler(service.NewBudgetService(budgetsRepo{}, subs)),
+		handlers.NewWebhookHandler(service.NewWebhookService(nil, time.Second, 1, 1)),
+		handlers.NewAPIKeyHandler(service.NewAPIKeyService(nil)),
+		handlers.NewTenantHandler(tenantService),
+		handlers.NewStreamHandler(events.NewHub(10), time.Minute),
+		gql.NewHandler(subsService, userService),
+		handlers.NewMetricsHandler(""),
+		handlers.NewHealthHandler(health.NewChecker(time.Second)),
+		middleware.NewAuthMiddleware(nil, tenantService),
+		middleware.NewRateLimitMiddleware(nil),
+	)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	config.HTTPClient = server.Client()
+	c, err := client.New(server.URL, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func createUser(t *testing.T, c *client.Client) uuid.UUID {
+	t.Helper()
+
+	userID, err := c.CreateUser(context.Background(), client.CreateUser{DisplayName: "Test user"})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	return userID
+}
+
+func TestSubscriptionLifecycle(t *testing.T) {
+	c := newServer(t)
+	ctx := context.Background()
+	userID := createUser(t, c)
+
+	id, err := c.CreateSubscription(ctx, client.CreateSubscription{
+		ServiceName: "Yandex Plus",
+		Price:       400,
+		UserID:      userID,
+		StartDate:   "07-2025",
+		Tags:        []string{"music"},
+	})
+	if err != nil {
+		t.Fatalf("create subscription: %v", err)
+	}
+
+	sub, err := c.GetSubscription(ctx, id)
+	if err != nil {
+		t.Fatalf("get subscription: %v", err)
+	}
+	if sub.ServiceName != "Yandex Plus" || sub.Price != 400 || sub.UserID != userID || !slices.Equal(sub.Tags, []string{"music"}) {
+		t.Errorf("unexpected subscription %+v", sub)
+	}
+
+	endDate := "12-2025"
+	err = c.UpdateSubscription(ctx, id, client.UpdateSubscription{
+		ServiceName: "Yandex Plus",
+		Price:       450,
+		UserID:      userID,
+		StartDate:   "07-2025",
+		EndDate:     &endDate,
+	})
+	if err != nil {
+		t.Fatalf("update subscription: %v", err)
+	}
+
+	sub, err = c.GetSubscription(ctx, id)
+	if err != nil {
+		t.Fatalf("get subscription: %v", err)
+	}
+	if sub.Price != 450 || sub.EndDate == nil || sub.EndDate.Month() != time.December {
+		t.Errorf("subscription not updated: %+v", sub)
+	}
+
+	if err := c.DeleteSubscription(ctx, id); err != nil {
+		t.Fatalf("delete subscription: %v", err)
+	}
+
+	_, err = c.GetSubscription(ctx, id)
+	if !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Message == "" || apiErr.RequestID == "" {
+		t.Errorf("unexpected api error %+v", apiErr)
+	}
+}
+
+func TestSubscriptionsIterator(t *testing.T) {
+	c := newServer(t)
+	ctx := context.Background()
+	userID := createUser(t, c)
+
+	const total = 2*client.DefaultPageSize + 17
+	for i := range total {
+		tags := []string{"video"}
+		if i%2 == 0 {
+			tags = []string{"music"}
+		}
+
+		_, err := c.CreateSubscription(ctx, client.CreateSubscription{
+			ServiceName: "Okko",
+			Price:       uint(i + 1),
+			UserID:      userID,
+			StartDate:   "01-2025",
+			Tags:        tags,
+		})
+		if err != nil {
+			t.Fatalf("create subscription %d: %v", i, err)
+		}
+	}
+
+	var ids []uint
+	for sub, err := range c.Subscriptions(ctx, client.SubscriptionFilter{}) {
+		if err != nil {
+			t.Fatalf("iterate subscriptions: %v", err)
+		}
+		ids = append(ids, sub.ID)
+	}
+	if len(ids) != total || !slices.IsSorted(ids) || len(slices.Compact(slices.Clone(ids))) != total {
+		t.Fatalf("expected %d ordered subscriptions, got %d", total, len(ids))
+	}
+
+	music := 0
+	for sub, err := range c.Subscriptions(ctx, client.SubscriptionFilter{Tag: "music"}) {
+		if err != nil {
+			t.Fatalf("iterate subscriptions: %v", err)
+		}
+		if !slices.Equal(sub.Tags, []string{"music"}) {
+			t.Fatalf("subscription %d doesn't match the tag filter: %v", sub.ID, sub.Tags)
+		}
+		music++
+	}
+	if music != (total+1)/2 {
+		t.Errorf("expected %d music subscriptions, got %d", (total+1)/2, music)
+	}
+
+	count := 0
+	for _, err := range c.Subscriptions(ctx, client.SubscriptionFilter{}) {
+		if err != nil {
+			t.Fatalf("iterate subscriptions: %v", err)
+		}
+		if count++; count == 3 {
+			break
+		}
+	}
+
+	page, err := c.ListSubscriptions(ctx, 2, 10, client.SubscriptionFilter{})
+	if err != nil {
+		t.Fatalf("list subscriptions: %v", err)
+	}
+	if len(page.Subscriptions) != 10 || page.Subscriptions[0].ID != ids[10] || !page.Pagination.HasPrev || !page.Pagination.HasNext {
+		t.Errorf("unexpected second page %+v", page.Pagination)
+	}
+}
+
+func TestSubscriptionSum(t *testing.T) {
+	c := newServer(t)
+	ctx := context.Background()
+	userID := createUser(t, c)
+	otherUserID := createUser(t, c)
+
+	for _, sub := range []client.CreateSubscription{
+		{ServiceName: "Okko", Price: 350, UserID: userID, StartDate: "01-2025"},
+		{ServiceName: "Spotify", Price: 200, UserID: userID, StartDate: "03-2025"},
+		{ServiceName: "iCloud", Price: 150, UserID: otherUserID, StartDate: "01-2025"},
+	} {
+		if _, err := c.CreateSubscription(ctx, sub); err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+	}
+
+	sum, err := c.SubscriptionSum(ctx, client.SumFilter{StartDate: "01-2025", EndDate: "12-2025", UserID: &userID})
+	if err != nil {
+		t.Fatalf("subscription sum: %v", err)
+	}
+	if sum != 550 {
+		t.Errorf("expected sum 550, got %d", sum)
+	}
+
+	_, err = c.SubscriptionSum(ctx, client.SumFilter{StartDate: "12-2025", EndDate: "01-2025"})
+	if !errors.Is(err, client.ErrBadRequest) {
+		t.Errorf("expected bad request error for the reversed period, got %v", err)
+	}
+}
+
+func TestSubscriptionSumByMonth(t *testing.T) {
+	c := newServer(t)
+	ctx := context.Background()
+	userID := createUser(t, c)
+
+	for _, sub := range []client.CreateSubscription{
+		{ServiceName: "Okko", Price: 350, UserID: userID, StartDate: "02-2025"},
+		{ServiceName: "Spotify", Price: 200, UserID: userID, StartDate: "03-2025"},
+	} {
+		if _, err := c.CreateSubscription(ctx, sub); err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+	}
+
+	report, err := c.SubscriptionSumByMonth(ctx, client.SumFilter{StartDate: "01-2025", EndDate: "04-2025", UserID: &userID})
+	if err != nil {
+		t.Fatalf("subscription sum by month: %v", err)
+	}
+
+	expected := []client.MonthSum{
+		{Month: "01-2025", TotalSum: 0},
+		{Month: "02-2025", TotalSum: 350},
+		{Month: "03-2025", TotalSum: 550},
+	}
+	if !slices.Equal(report.Months, expected) || report.TotalSum != 900 {
+		t.Errorf("expected months %v with total 900, got %v with total %d", expected, report.Months, report.TotalSum)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	c := newServer(t)
+	ctx := context.Background()
+	userID := createUser(t, c)
+
+	tests := []struct {
+		name   string
+		data   client.CreateSubscription
+		fields []client.FieldError
+	}{
+		{
+			name: "invalid fields",
+			data: client.CreateSubscription{UserID: userID, StartDate: "2025-01", Tags: []string{"music", ""}},
+			fields: []client.FieldError{
+				{Field: "service_name", Rule: "required", Message: "service_name is a required field"},
+				{Field: "price", Rule: "required", Message: "price is a required field"},
+				{Field: "start_date", Rule: "mm_yyyy_date", Message: "start_date must be a date in 'mm-yyyy' format"},
+				{Field: "tags[1]", Rule: "required", Message: "tags[1] is a required field"},
+			},
+		},
+		{
+			name: "end before start",
+			data: client.CreateSubscription{ServiceName: "Okko", Price: 350, UserID: userID, StartDate: "05-2025", EndDate: ptr("01-2025")},
+			fields: []client.FieldError{
+				{Field: "end_date", Rule: "gtefield", Message: "end_date cannot be before start_date"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.CreateSubscription(ctx, tt.data)
+
+			var apiErr *client.APIError
+			if !errors.As(err, &apiErr) || !errors.Is(err, client.ErrBadRequest) {
+				t.Fatalf("expected bad request error, got %v", err)
+			}
+			if apiErr.Type != "/problems/validation-error" || apiErr.Title == "" || apiErr.RequestID == "" {
+				t.Errorf("unexpected problem %+v", apiErr)
+			}
+			if !slices.Equal(apiErr.Fields, tt.fields) {
+				t.Errorf("expected fields %+v, got %+v", tt.fields, apiErr.Fields)
+			}
+		})
+	}
+
+	// the user exists check runs after the body validation
+	_, err := c.CreateSubscription(ctx, client.CreateSubscription{
+		ServiceName: "Okko",
+		Price:       350,
+		UserID:      uuid.New(),
+		StartDate:   "01-2025",
+	})
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "user not found" {
+		t.Fatalf("expected user not found error, got %v", err)
+	}
+	if apiErr.Type != "about:blank" || apiErr.Title != "Bad Request" || apiErr.Code != "user_not_found" || len(apiErr.Fields) != 0 {
+		t.Errorf("unexpected problem %+v", apiErr)
+	}
+}
+
+func TestLocalizedErrors(t *testing.T) {
+	c := newServerWithConfig(t, client.Config{Language: "ru-RU,ru;q=0.9,en;q=0.5"})
+	ctx := context.Background()
+	userID := createUser(t, c)
+
+	_, err := c.CreateSubscription(ctx, client.CreateSubscription{
+		Price:     350,
+		UserID:    userID,
+		StartDate: "01-2025",
+		EndDate:   ptr("2025-12"),
+	})
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected api error, got %v", err)
+	}
+	if apiErr.Code != "invalid_subscription" || apiErr.Message != "неверные данные подписки" {
+		t.Errorf("unexpected problem %+v", apiErr)
+	}
+	expected := []client.FieldError{
+		{Field: "service_name", Rule: "required", Message: "service_name обязательное поле"},
+		{Field: "end_date", Rule: "mm_yyyy_date", Message: "end_date должно быть датой в формате 'mm-yyyy'"},
+	}
+	if !slices.Equal(apiErr.Fields, expected) {
+		t.Errorf("expected fields %+v, got %+v", expected, apiErr.Fields)
+	}
+
+	_, err = c.GetSubscription(ctx, 42)
+	if !errors.As(err, &apiErr) || apiErr.Code != "subscription_not_found" || apiErr.Message != "подписка не найдена" {
+		t.Errorf("expected localized not found error, got %v", err)
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+// flakyServer fails the first requests with 503 and counts the requests.
+func flakyServer(t *testing.T, failures int32) (*client.Client, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.Header().Set("X-Request-ID", "flaky")
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, `{"type":"about:blank","title":"Service Unavailable","status":503,"detail":"try again later"}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":7,"service_name":"Okko","price":350,"user_id":"`+uuid.Nil.String()+`","start_date":"2025-01-01T00:00:00Z","tags":[]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := client.New(server.URL, client.Config{
+		HTTPClient: server.Client(),
+		MaxRetries: 3,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c, &requests
+}
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("idempotent request is retried", func(t *testing.T) {
+		c, requests := flakyServer(t, 2)
+
+		sub, err := c.GetSubscription(ctx, 7)
+		if err != nil {
+			t.Fatalf("get subscription: %v", err)
+		}
+		if sub.ID != 7 || requests.Load() != 3 {
+			t.Errorf("expected subscription 7 after 3 requests, got %d after %d", sub.ID, requests.Load())
+		}
+	})
+
+	t.Run("retries are limited", func(t *testing.T) {
+		c, requests := flakyServer(t, 10)
+
+		_, err := c.GetSubscription(ctx, 7)
+		var apiErr *client.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected service unavailable error, got %v", err)
+		}
+		if apiErr.Message != "try again later" || apiErr.RequestID != "flaky" {
+			t.Errorf("unexpected api error %+v", apiErr)
+		}
+		if requests.Load() != 4 {
+			t.Errorf("expected 4 requests, got %d", requests.Load())
+		}
+	})
+
+	t.Run("create isn't retried", func(t *testing.T) {
+		c, requests := flakyServer(t, 1)
+
+		_, err := c.CreateSubscription(ctx, client.CreateSubscription{ServiceName: "Okko"})
+		if err == nil || requests.Load() != 1 {
+			t.Errorf("expected a failed single request, got %v after %d requests", err, requests.Load())
+		}
+	})
+}
+
+func TestContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	c, err := client.New(server.URL, client.Config{HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err = c.GetSubscription(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("request returned after %s, expected to stop with the context", elapsed)
+	}
+}
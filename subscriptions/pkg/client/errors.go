@@ -0,0 +1,121 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBody limits the error response read by the client.
+const maxErrorBody = 64 << 10
+
+// The sentinel errors match the *APIError with the status with errors.Is.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// ErrStreamClosed ends the event stream closed by the server.
+var ErrStreamClosed = errors.New("event stream closed")
+
+// APIError is the RFC 7807 problem returned by the API.
+type APIError struct {
+	StatusCode int
+	// Type identifies the kind of the problem, e.g. about:blank or /problems/validation-error.
+	Type  string
+	Title string
+	// Code is the stable code of the message, e.g. subscription_not_found, the same in
+	// every language of the messages.
+	Code string
+	// Message is the detail of the problem, the status text if the body has none.
+	Message string
+	// Fields lists the invalid fields of the request.
+	Fields []FieldError
+	// RequestID identifies the request in the logs of the service.
+	RequestID string
+	// RetryAfter is the delay requested by the 429 and 503 responses.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	for _, field := range e.Fields {
+		message += "; " + field.Message
+	}
+
+	if e.RequestID == "" {
+		return fmt.Sprintf("%d: %s", e.StatusCode, message)
+	}
+
+	return fmt.Sprintf("%d: %s (request id %s)", e.StatusCode, message, e.RequestID)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
+
+// newAPIError reads the error of the response, the body is left to the caller to close.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-ID"),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+
+	var problem Problem
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err := json.Unmarshal(data, &problem); err == nil {
+		apiErr.Type = problem.Type
+		apiErr.Title = problem.Title
+		apiErr.Code = problem.Code
+		apiErr.Message = problem.Detail
+		apiErr.Fields = problem.Errors
+		if problem.RequestID != "" {
+			apiErr.RequestID = problem.RequestID
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = strings.ToLower(apiErr.Title)
+	}
+
+	if apiErr.Message == "" {
+		apiErr.Message = strings.ToLower(http.StatusText(resp.StatusCode))
+	}
+
+	return apiErr
+}
+
+// GraphQLError holds the errors of the GraphQL response.
+type GraphQLError struct {
+	Messages []string
+}
+
+func (e *GraphQLError) Error() string {
+	return "graphql: " + strings.Join(e.Messages, "; ")
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"iter"
+)
+
+// DefaultPageSize is the page size of the iterators.
+const DefaultPageSize = 100
+
+// fetchPage returns the items of the page and its pagination.
+type fetchPage[T any] func(ctx context.Context, page, size int) ([]T, Pagination, error)
+
+// paginate iterates over the items of all pages, fetching the next page when the previous
+// one is consumed. The iteration stops after the first error.
+func paginate[T any](ctx context.Context, size int, fetch fetchPage[T]) iter.Seq2[T, error] {
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+
+	return func(yield func(T, error) bool) {
+		for page := 1; ; page++ {
+			items, pagination, err := fetch(ctx, page, size)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if !pagination.HasNext || len(items) == 0 {
+				return
+			}
+		}
+	}
+}
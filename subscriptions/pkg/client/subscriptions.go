@@ -0,0 +1,203 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxEventSize limits the line of the event stream.
+const maxEventSize = 1 << 20
+
+func (f SubscriptionFilter) query(page, size int) url.Values {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("size", strconv.Itoa(size))
+	if f.CategoryID != nil {
+		query.Set("category_id", strconv.FormatUint(uint64(*f.CategoryID), 10))
+	}
+	if f.Tag != "" {
+		query.Set("tag", f.Tag)
+	}
+
+	return query
+}
+
+func (f SumFilter) query() url.Values {
+	query := url.Values{}
+	query.Set("startDate", f.StartDate)
+	query.Set("endDate", f.EndDate)
+	if f.UserID != nil {
+		query.Set("userID", f.UserID.String())
+	}
+	if f.ServiceName != "" {
+		query.Set("serviceName", f.ServiceName)
+	}
+	if f.CategoryID != nil {
+		query.Set("categoryID", strconv.FormatUint(uint64(*f.CategoryID), 10))
+	}
+
+	return query
+}
+
+// ListSubscriptions returns the page of the subscriptions, pages start at 1.
+func (c *Client) ListSubscriptions(ctx context.Context, page, size int, filter SubscriptionFilter) (*SubscriptionsPage, error) {
+	var result SubscriptionsPage
+	if err := c.do(ctx, http.MethodGet, apiURL("subs")+"/", filter.query(page, size), nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Subscriptions iterates over the subscriptions of all pages.
+func (c *Client) Subscriptions(ctx context.Context, filter SubscriptionFilter) iter.Seq2[Subscription, error] {
+	return paginate(ctx, DefaultPageSize, func(ctx context.Context, page, size int) ([]Subscription, Pagination, error) {
+		result, err := c.ListSubscriptions(ctx, page, size, filter)
+		if err != nil {
+			return nil, Pagination{}, err
+		}
+		return result.Subscriptions, result.Pagination, nil
+	})
+}
+
+func (c *Client) GetSubscription(ctx context.Context, id uint) (*Subscription, error) {
+	var sub Subscription
+	if err := c.do(ctx, http.MethodGet, apiURL("subs", id), nil, nil, &sub); err != nil {
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+// CreateSubscription creates the subscription and returns its ID. It isn't retried.
+func (c *Client) CreateSubscription(ctx context.Context, data CreateSubscription) (uint, error) {
+	var result createReturn
+	if err := c.do(ctx, http.MethodPost, apiURL("subs")+"/", nil, data, &result); err != nil {
+		return 0, err
+	}
+
+	return result.ID, nil
+}
+
+func (c *Client) UpdateSubscription(ctx context.Context, id uint, data UpdateSubscription) error {
+	return c.do(ctx, http.MethodPut, apiURL("subs", id), nil, data, nil)
+}
+
+// PatchSubscription updates the fields set in data. It isn't retried.
+func (c *Client) PatchSubscription(ctx context.Context, id uint, data PatchSubscription) error {
+	return c.do(ctx, http.MethodPatch, apiURL("subs", id), nil, data, nil)
+}
+
+func (c *Client) DeleteSubscription(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, apiURL("subs", id), nil, nil, nil)
+}
+
+// SubscriptionSum returns the total price of the subscriptions for the period.
+func (c *Client) SubscriptionSum(ctx context.Context, filter SumFilter) (uint, error) {
+	var result struct {
+		TotalSum uint `json:"total_sum"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiURL("subs", "sub_sum"), filter.query(), nil, &result); err != nil {
+		return 0, err
+	}
+
+	return result.TotalSum, nil
+}
+
+// SubscriptionSumByCategory returns the sums of the period grouped by the category tree.
+func (c *Client) SubscriptionSumByCategory(ctx context.Context, filter SumFilter) (*CategorySumReport, error) {
+	query := filter.query()
+	query.Del("categoryID")
+
+	var report CategorySumReport
+	if err := c.do(ctx, http.MethodGet, apiURL("subs", "sub_sum", "by_category"), query, nil, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// SubscriptionSumByMonth returns the sums of each month of the period, the end month excluded.
+func (c *Client) SubscriptionSumByMonth(ctx context.Context, filter SumFilter) (*MonthSumReport, error) {
+	var report MonthSumReport
+	if err := c.do(ctx, http.MethodGet, apiURL("subs", "sub_sum", "by_month"), filter.query(), nil, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// SubscriptionEvents streams the subscription events until ctx is done or the connection
+// is closed, then yields the error. Pass the ID of the last received event in
+// filter.LastEventID to resume the stream after a reconnect.
+func (c *Client) SubscriptionEvents(ctx context.Context, filter EventFilter) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		query := url.Values{}
+		if filter.UserID != nil {
+			query.Set("user_id", filter.UserID.String())
+		}
+		if filter.ServiceName != "" {
+			query.Set("service_name", filter.ServiceName)
+		}
+		header := http.Header{}
+		header.Set("Accept", "text/event-stream")
+		if filter.LastEventID != nil {
+			header.Set("Last-Event-ID", strconv.FormatUint(uint64(*filter.LastEventID), 10))
+		}
+
+		resp, err := c.send(ctx, http.MethodGet, apiURL("subs", "events"), query, nil, header)
+		if err != nil {
+			yield(Event{}, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 4096), maxEventSize)
+
+		var data strings.Builder
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			switch {
+			case line == "":
+				// a blank line ends the event, the lines without data are the retry hint and heartbeats
+				if data.Len() == 0 {
+					continue
+				}
+
+				var event Event
+				err := json.Unmarshal([]byte(data.String()), &event)
+				data.Reset()
+				if err != nil {
+					yield(Event{}, fmt.Errorf("decode event: %w", err))
+					return
+				}
+				if !yield(event, nil) {
+					return
+				}
+			case strings.HasPrefix(line, "data:"):
+				if data.Len() > 0 {
+					data.WriteByte('\n')
+				}
+				data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			}
+		}
+
+		err = scanner.Err()
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if err == nil {
+			err = ErrStreamClosed
+		}
+		yield(Event{}, err)
+	}
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+func (c *Client) ListTenants(ctx context.Context) ([]Tenant, error) {
+	var tenants []Tenant
+	if err := c.do(ctx, http.MethodGet, apiURL("tenants")+"/", nil, nil, &tenants); err != nil {
+		return nil, err
+	}
+
+	return tenants, nil
+}
+
+func (c *Client) GetTenant(ctx context.Context, id string) (*Tenant, error) {
+	var tenant Tenant
+	if err := c.do(ctx, http.MethodGet, apiURL("tenants", id), nil, nil, &tenant); err != nil {
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+func (c *Client) CreateTenant(ctx context.Context, data CreateTenant) (string, error) {
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, apiURL("tenants")+"/", nil, data, &result); err != nil {
+		return "", err
+	}
+
+	return result.ID, nil
+}
+
+func (c *Client) UpdateTenant(ctx context.Context, id string, data UpdateTenant) error {
+	return c.do(ctx, http.MethodPut, apiURL("tenants", id), nil, data, nil)
+}
+
+func (c *Client) GetTenantStats(ctx context.Context, id string) (*TenantStats, error) {
+	var stats TenantStats
+	if err := c.do(ctx, http.MethodGet, apiURL("tenants", id, "stats"), nil, nil, &stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"subscriptions/rest-service/internal/events"
+	"subscriptions/rest-service/internal/health"
+	"subscriptions/rest-service/internal/schemas"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The request and response bodies are the schemas of the API.
+type (
+	Pagination = schemas.Pagination
+	Problem    = schemas.Problem
+	FieldError = schemas.FieldError
+
+	CreateSubscription    = schemas.CreateSub
+	UpdateSubscription    = schemas.FullUpdateSub
+	PatchSubscription     = schemas.PatchUpdateSub
+	Subscription          = schemas.FullSubInfo
+	SubscriptionsPage     = schemas.PaginationResponse
+	CategorySum           = schemas.CategorySumInfo
+	CategorySumReport     = schemas.CategorySumReturn
+	MonthSum              = schemas.MonthSumInfo
+	MonthSumReport        = schemas.MonthSumReturn
+	SubscriptionEventData = events.SubscriptionPayload
+	CreateCategory        = schemas.CreateCategory
+	Category              = schemas.CategoryInfo
+	CreateTag             = schemas.CreateTag
+	Tag                   = schemas.TagInfo
+	CreateUser            = schemas.CreateUser
+	UpdateUser            = schemas.UpdateUser
+	User                  = schemas.UserInfo
+	UsersPage             = schemas.UsersPaginationResponse
+	UserSpending          = schemas.UserSpendingReturn
+	CreateBudget          = schemas.CreateBudget
+	UpdateBudget          = schemas.UpdateBudget
+	Budget                = schemas.BudgetInfo
+	BudgetStatus          = schemas.BudgetStatus
+	BudgetStatusReport    = schemas.BudgetStatusReturn
+	CreateWebhook         = schemas.CreateWebhook
+	Webhook               = schemas.WebhookInfo
+	CreatedWebhook        = schemas.CreateWebhookReturn
+	WebhookDelivery       = schemas.WebhookDeliveryInfo
+	WebhookDeliveriesPage = schemas.DeliveriesPaginationResponse
+	CreateAPIKey          = schemas.CreateAPIKey
+	APIKey                = schemas.APIKeyInfo
+	CreatedAPIKey         = schemas.CreateAPIKeyReturn
+	CreateTenant          = schemas.CreateTenant
+	UpdateTenant          = schemas.UpdateTenant
+	Tenant                = schemas.TenantInfo
+	TenantStats           = schemas.TenantStats
+	HealthReport          = health.Report
+	HealthCheck           = health.Check
+)
+
+// SubscriptionFilter narrows the list of subscriptions.
+type SubscriptionFilter struct {
+	// CategoryID includes the subcategories of the category.
+	CategoryID *uint
+	Tag        string
+}
+
+// SumFilter selects the subscriptions of the sum reports.
+type SumFilter struct {
+	// StartDate and EndDate bound the period, in 'mm-yyyy' format.
+	StartDate   string
+	EndDate     string
+	UserID      *uuid.UUID
+	ServiceName string
+	// CategoryID includes the subcategories of the category, the sum by category ignores it.
+	CategoryID *uint
+}
+
+// EventFilter selects the events of the stream.
+type EventFilter struct {
+	UserID      *uuid.UUID
+	ServiceName string
+	// LastEventID resumes the stream after the event.
+	LastEventID *uint
+}
+
+// Event is a created, updated or deleted subscription received from the stream.
+type Event struct {
+	ID             uint                  `json:"id"`
+	SubscriptionID uint                  `json:"subscription_id"`
+	TenantID       string                `json:"tenant_id"`
+	Type           string                `json:"type"`
+	OccurredAt     time.Time             `json:"occurred_at"`
+	Data           SubscriptionEventData `json:"data"`
+}
+
+type createReturn struct {
+	ID uint `json:"id"`
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+func pageQuery(page, size int) url.Values {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("size", strconv.Itoa(size))
+
+	return query
+}
+
+// ListUsers returns the page of the users, pages start at 1.
+func (c *Client) ListUsers(ctx context.Context, page, size int) (*UsersPage, error) {
+	var result UsersPage
+	if err := c.do(ctx, http.MethodGet, apiURL("users")+"/", pageQuery(page, size), nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Users iterates over the users of all pages.
+func (c *Client) Users(ctx context.Context) iter.Seq2[User, error] {
+	return paginate(ctx, DefaultPageSize, func(ctx context.Context, page, size int) ([]User, Pagination, error) {
+		result, err := c.ListUsers(ctx, page, size)
+		if err != nil {
+			return nil, Pagination{}, err
+		}
+		return result.Users, result.Pagination, nil
+	})
+}
+
+func (c *Client) GetUser(ctx context.Context, id uuid.UUID) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodGet, apiURL("users", id), nil, nil, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// CreateUser creates the user and returns its ID, generated unless set in data.
+func (c *Client) CreateUser(ctx context.Context, data CreateUser) (uuid.UUID, error) {
+	var result struct {
+		ID uuid.UUID `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, apiURL("users")+"/", nil, data, &result); err != nil {
+		return uuid.Nil, err
+	}
+
+	return result.ID, nil
+}
+
+func (c *Client) UpdateUser(ctx context.Context, id uuid.UUID, data UpdateUser) error {
+	return c.do(ctx, http.MethodPut, apiURL("users", id), nil, data, nil)
+}
+
+func (c *Client) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, apiURL("users", id), nil, nil, nil)
+}
+
+// ListUserSubscriptions returns the page of the subscriptions of the user, pages start at 1.
+func (c *Client) ListUserSubscriptions(ctx context.Context, id uuid.UUID, page, size int) (*SubscriptionsPage, error) {
+	var result SubscriptionsPage
+	if err := c.do(ctx, http.MethodGet, apiURL("users", id, "subscriptions"), pageQuery(page, size), nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UserSubscriptions iterates over the subscriptions of the user of all pages.
+func (c *Client) UserSubscriptions(ctx context.Context, id uuid.UUID) iter.Seq2[Subscription, error] {
+	return paginate(ctx, DefaultPageSize, func(ctx context.Context, page, size int) ([]Subscription, Pagination, error) {
+		result, err := c.ListUserSubscriptions(ctx, id, page, size)
+		if err != nil {
+			return nil, Pagination{}, err
+		}
+		return result.Subscriptions, result.Pagination, nil
+	})
+}
+
+// UserSpending returns the spending of the user for the period, from and to in 'mm-yyyy' format.
+func (c *Client) UserSpending(ctx context.Context, id uuid.UUID, from, to string) (*UserSpending, error) {
+	query := url.Values{}
+	query.Set("from", from)
+	query.Set("to", to)
+
+	var spending UserSpending
+	if err := c.do(ctx, http.MethodGet, apiURL("users", id, "spending"), query, nil, &spending); err != nil {
+		return nil, err
+	}
+
+	return &spending, nil
+}
+
+// UserBudgetStatus returns the budgets of the user for the month in 'mm-yyyy' format,
+// the current month if empty.
+func (c *Client) UserBudgetStatus(ctx context.Context, id uuid.UUID, month string) (*BudgetStatusReport, error) {
+	query := url.Values{}
+	if month != "" {
+		query.Set("month", month)
+	}
+
+	var report BudgetStatusReport
+	if err := c.do(ctx, http.MethodGet, apiURL("users", id, "budget-status"), query, nil, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	if err := c.do(ctx, http.MethodGet, apiURL("webhooks")+"/", nil, nil, &webhooks); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id uint) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.do(ctx, http.MethodGet, apiURL("webhooks", id), nil, nil, &webhook); err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// CreateWebhook creates the webhook and returns its ID and the secret signing the deliveries.
+func (c *Client) CreateWebhook(ctx context.Context, data CreateWebhook) (*CreatedWebhook, error) {
+	var result CreatedWebhook
+	if err := c.do(ctx, http.MethodPost, apiURL("webhooks")+"/", nil, data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, id uint, data CreateWebhook) error {
+	return c.do(ctx, http.MethodPut, apiURL("webhooks", id), nil, data, nil)
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, apiURL("webhooks", id), nil, nil, nil)
+}
+
+// ListWebhookDeliveries returns the page of the deliveries of the webhook, pages start at 1.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id uint, page, size int) (*WebhookDeliveriesPage, error) {
+	var result WebhookDeliveriesPage
+	if err := c.do(ctx, http.MethodGet, apiURL("webhooks", id, "deliveries"), pageQuery(page, size), nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// RedeliverWebhookDelivery schedules the delivery again and returns the ID of the new delivery.
+func (c *Client) RedeliverWebhookDelivery(ctx context.Context, id, deliveryID uint) (uint, error) {
+	var result createReturn
+	path := apiURL("webhooks", id, "deliveries", deliveryID, "redeliver")
+	if err := c.do(ctx, http.MethodPost, path, nil, nil, &result); err != nil {
+		return 0, err
+	}
+
+	return result.ID, nil
+}
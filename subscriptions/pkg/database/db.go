@@ -1,43 +1,107 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"subscriptions/rest-service/internal/models"
+	"subscriptions/rest-service/internal/tenant"
+	"subscriptions/rest-service/internal/tracing"
+	"subscriptions/rest-service/pkg/logger"
+	"time"
 
-	"github.com/spf13/viper"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
-func GetDBConnect() (db *gorm.DB, err error) {
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		viper.GetString("DB_HOST"),
-		viper.GetString("POSTGRES_USER"),
-		viper.GetString("POSTGRES_PASSWORD"),
-		viper.GetString("POSTGRES_DB"),
-		viper.GetString("DB_PORT"),
+// migratedModels are the models whose tables are created and updated on start.
+var migratedModels = []any{
+	&models.Tenant{},
+	&models.User{},
+	&models.Category{},
+	&models.Tag{},
+	&models.Subscription{},
+	&models.Budget{},
+	&models.Webhook{},
+	&models.WebhookDelivery{},
+	&models.OutboxEvent{},
+	&models.APIKey{},
+}
+
+type Config struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+	// SlowQueryThreshold is the duration after which the queries are logged as slow.
+	SlowQueryThreshold time.Duration
+	// Replicas are the read replicas, "host" or "host:port" with the port of the primary by
+	// default. They have the user, the password and the database of the primary.
+	Replicas []string
+	// ReadYourWrites is how long the reads of a session go to the primary after its write,
+	// zero disables it.
+	ReadYourWrites time.Duration
+	// Session identifies the session of the statement context for ReadYourWrites, the
+	// statements without one are never sent to the primary for it.
+	Session func(context.Context) string
+}
+
+func dsn(config Config, host string, port int) string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable",
+		host,
+		config.User,
+		config.Password,
+		config.Name,
+		port,
 	)
+}
+
+// Connect opens the database, creating it if it doesn't exist. The schema is
+// updated separately by Migrate. With replicas the reads of the ReadOnly contexts go to
+// them, see Replicas.
+func Connect(config Config) (*gorm.DB, error) {
+	dsn := dsn(config, config.Host, config.Port)
+
+	gormLogger := logger.NewGormLogger(slog.Default(), config.SlowQueryThreshold)
 
-	conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormLogger})
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		return nil, err
 	}
 
 	var isDBExist bool
-	t := conn.Raw(fmt.Sprintf("SELECT datname FROM pg_catalog.pg_database WHERE lower(datname) = lower('%s');", viper.GetString("POSTGRES_DB")))
+	t := conn.Raw(fmt.Sprintf("SELECT datname FROM pg_catalog.pg_database WHERE lower(datname) = lower('%s');", config.Name))
 
 	if err = t.Row().Scan(&isDBExist); err != nil {
-		conn.Exec(fmt.Sprintf("CREATE DATABASE %s", viper.GetString("POSTGRES_DB")))
+		conn.Exec(fmt.Sprintf("CREATE DATABASE %s", config.Name))
 	}
 
-	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:         gormLogger,
+		TranslateError: true,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, err
+	}
 
-	db.AutoMigrate(&models.Subscription{})
+	if len(config.Replicas) > 0 {
+		replicas, err := openReplicas(config, gormLogger)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Use(replicas); err != nil {
+			return nil, err
+		}
+	}
 
-	return db, err
+	return db, nil
 }
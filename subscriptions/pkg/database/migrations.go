@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"subscriptions/rest-service/internal/tenant"
+
+	"gorm.io/gorm"
+)
+
+// Migrate creates and updates the tables of the models and moves the data of the
+// previous versions of the schema.
+func Migrate(ctx context.Context, db *gorm.DB) error {
+	// the migrations change the data of all tenants
+	db = db.WithContext(tenant.Unscoped(ctx))
+
+	// unique constraints and indexes became per tenant
+	err := db.Exec(`DROP INDEX IF EXISTS idx_categories_parent_name, idx_tags_name, idx_users_email,
+		idx_subscriptions_user_id, idx_subscriptions_service_name, idx_budgets_user_id`).Error
+	if err != nil {
+		return fmt.Errorf("drop old indexes: %w", err)
+	}
+
+	if err := db.AutoMigrate(migratedModels...); err != nil {
+		return err
+	}
+
+	// records created before tenants existed belong to the default tenant
+	err = db.Exec(`INSERT INTO tenants (id, name, created_at) VALUES ('default', 'default', NOW()) ON CONFLICT DO NOTHING`).Error
+	if err != nil {
+		return fmt.Errorf("create default tenant: %w", err)
+	}
+
+	// subscriptions created before the users table existed reference users by id only
+	err = db.Exec(`
+		INSERT INTO users (id, tenant_id, display_name, created_at, updated_at)
+		SELECT DISTINCT ON (user_id) user_id, tenant_id, '', NOW(), NOW() FROM subscriptions
+		ON CONFLICT DO NOTHING`).Error
+	if err != nil {
+		return fmt.Errorf("create users of subscriptions: %w", err)
+	}
+
+	// the names of the root categories are unique too, their parent_id is null
+	err = db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_tenant_parent_name
+		ON categories (tenant_id, parent_id, name) NULLS NOT DISTINCT`).Error
+	if err != nil {
+		return fmt.Errorf("create categories index: %w", err)
+	}
+
+	if err := db.Exec("CREATE SEQUENCE IF NOT EXISTS outbox_stream_seq").Error; err != nil {
+		return fmt.Errorf("create outbox stream sequence: %w", err)
+	}
+
+	// buckets of the shared rate limit store, losing them on a crash only resets the limits
+	err = db.Exec(`
+		CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+			key text PRIMARY KEY,
+			tokens double precision NOT NULL,
+			allowed boolean NOT NULL,
+			updated_at timestamptz NOT NULL
+		)`).Error
+	if err != nil {
+		return fmt.Errorf("create rate limit buckets: %w", err)
+	}
+
+	// entries of the shared cache, losing them on a crash only makes the queries run again
+	err = db.Exec(`
+		CREATE UNLOGGED TABLE IF NOT EXISTS cache_entries (
+			key text PRIMARY KEY,
+			value bytea NOT NULL,
+			expires_at timestamptz
+		)`).Error
+	if err != nil {
+		return fmt.Errorf("create cache entries: %w", err)
+	}
+
+	// charges of the subscriptions per month the reports are served from, the category of
+	// a deleted category is cleared by the repository, which merges the charges
+	err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS monthly_charges (
+			tenant_id varchar(64) NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+			month date NOT NULL,
+			user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+			service_name varchar(150) NOT NULL,
+			category_id bigint,
+			amount bigint NOT NULL
+		)`).Error
+	if err != nil {
+		return fmt.Errorf("create monthly charges: %w", err)
+	}
+	err = db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_monthly_charges_key
+		ON monthly_charges (tenant_id, month, user_id, service_name, category_id) NULLS NOT DISTINCT`).Error
+	if err != nil {
+		return fmt.Errorf("create monthly charges index: %w", err)
+	}
+
+	// the month the charges are built until, null until they are built the first time
+	err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS monthly_charges_horizon (
+			id boolean PRIMARY KEY DEFAULT true CHECK (id),
+			month date
+		)`).Error
+	if err != nil {
+		return fmt.Errorf("create monthly charges horizon: %w", err)
+	}
+	err = db.Exec(`INSERT INTO monthly_charges_horizon (id) VALUES (true) ON CONFLICT DO NOTHING`).Error
+	if err != nil {
+		return fmt.Errorf("create monthly charges horizon: %w", err)
+	}
+
+	return nil
+}
+
+// rawTables are the tables created with SQL rather than from models.
+var rawTables = map[string][]string{
+	"rate_limit_buckets":      {"key", "tokens", "allowed", "updated_at"},
+	"cache_entries":           {"key", "value", "expires_at"},
+	"monthly_charges":         {"tenant_id", "month", "user_id", "service_name", "category_id", "amount"},
+	"monthly_charges_horizon": {"id", "month"},
+}
+
+// CheckMigrations reports the tables and columns of the current schema missing from the
+// database, e.g. when the migrations of a newer version haven't run yet.
+func CheckMigrations(ctx context.Context, db *gorm.DB) error {
+	expected := make(map[string][]string, len(migratedModels)+len(rawTables))
+	for table, columns := range rawTables {
+		expected[table] = columns
+	}
+
+	for _, model := range migratedModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !field.IgnoreMigration {
+				expected[stmt.Schema.Table] = append(expected[stmt.Schema.Table], field.DBName)
+			}
+		}
+	}
+
+	var existing []struct {
+		TableName  string
+		ColumnName string
+	}
+	// the catalog has no tenants
+	err := db.WithContext(tenant.Unscoped(ctx)).Raw(`
+		SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = CURRENT_SCHEMA()`).Scan(&existing).Error
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]bool, len(existing))
+	for _, column := range existing {
+		found[column.TableName+"."+column.ColumnName] = true
+	}
+
+	var missing []string
+	for table, columns := range expected {
+		for _, column := range columns {
+			if !found[table+"."+column] {
+				missing = append(missing, table+"."+column)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return fmt.Errorf("missing columns %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net"
+	"strconv"
+	"subscriptions/rest-service/internal/health"
+	"subscriptions/rest-service/internal/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const replicasPluginName = "replicas"
+
+type readOnlyKey struct{}
+
+type primaryKey struct{}
+
+// readOnlyRoute pins the replica the statements of a read-only context go to, so they
+// read the same state of the data.
+type readOnlyRoute struct {
+	mu      sync.Mutex
+	replica *replica
+}
+
+// ReadOnly marks the statements of the context as reads which may go to a read replica.
+// The statements of transactions and the locking reads stay on the primary anyway.
+func ReadOnly(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(readOnlyKey{}).(*readOnlyRoute); ok {
+		return ctx
+	}
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return ctx
+	}
+	return context.WithValue(ctx, readOnlyKey{}, &readOnlyRoute{})
+}
+
+// Primary makes the reads of the context go to the primary, ReadOnly doesn't mark it. The
+// reads checking a write use it since they must see the latest data.
+func Primary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// Replicas is the plugin sending the reads of the read-only contexts to the healthy read
+// replicas in turn and all other statements to the primary. The replicas lag behind the
+// primary, so the reads of a session which has just written go to the primary too.
+type Replicas struct {
+	primary  gorm.ConnPool
+	replicas []*replica
+	next     atomic.Uint64
+	// window is how long the reads of a session go to the primary after its write.
+	window  time.Duration
+	session func(context.Context) string
+	// writes are the times of the last writes of the sessions
+	writes sync.Map
+}
+
+// openReplicas opens the pools of the replicas of the config, without connecting to them:
+// the replicas get no reads until Run finds them healthy.
+func openReplicas(config Config, gormLogger logger.Interface) (*Replicas, error) {
+	replicas := &Replicas{
+		window:  config.ReadYourWrites,
+		session: config.Session,
+	}
+
+	for _, address := range config.Replicas {
+		host, port := address, config.Port
+		if h, p, err := net.SplitHostPort(address); err == nil {
+			host = h
+			if port, err = strconv.Atoi(p); err != nil {
+				return nil, err
+			}
+		}
+
+		conn, err := gorm.Open(postgres.Open(dsn(config, host, port)), &gorm.Config{
+			Logger:               gormLogger,
+			DisableAutomaticPing: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		db, err := conn.DB()
+		if err != nil {
+			return nil, err
+		}
+
+		replicas.replicas = append(replicas.replicas, &replica{name: address, db: db})
+	}
+
+	return replicas, nil
+}
+
+// ReplicasOf returns the replicas of the database, nil if none are configured.
+func ReplicasOf(db *gorm.DB) *Replicas {
+	replicas, _ := db.Config.Plugins[replicasPluginName].(*Replicas)
+	return replicas
+}
+
+func (*Replicas) Name() string {
+	return replicasPluginName
+}
+
+func (r *Replicas) Initialize(db *gorm.DB) error {
+	r.primary = db.ConnPool
+
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("replicas:route_query", r.route); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("replicas:route_row", r.route); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:create").Register("replicas:record_create", r.recordWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("replicas:record_update", r.recordWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("replicas:record_delete", r.recordWrite); err != nil {
+		return err
+	}
+	return callbacks.Raw().After("gorm:raw").Register("replicas:record_raw", r.recordWrite)
+}
+
+// Pools returns the connection pools of the replicas by their names.
+func (r *Replicas) Pools() map[string]*sql.DB {
+	pools := make(map[string]*sql.DB, len(r.replicas))
+	for _, replica := range r.replicas {
+		pools[replica.name] = replica.db
+	}
+	return pools
+}
+
+// route sends the statement of a read-only context to a healthy replica.
+func (r *Replicas) route(db *gorm.DB) {
+	stmt := db.Statement
+
+	route, ok := stmt.Context.Value(readOnlyKey{}).(*readOnlyRoute)
+	if !ok {
+		return
+	}
+	// the statements of transactions run on their connection to the primary
+	if stmt.ConnPool != r.primary {
+		return
+	}
+	if _, locking := stmt.Clauses["FOR"]; locking {
+		return
+	}
+	if r.wroteRecently(stmt.Context) {
+		return
+	}
+
+	if replica := r.pick(route); replica != nil {
+		stmt.ConnPool = replica.db
+	}
+}
+
+// pick returns the replica of the route, the one it is pinned to while it is healthy.
+func (r *Replicas) pick(route *readOnlyRoute) *replica {
+	route.mu.Lock()
+	defer route.mu.Unlock()
+
+	if route.replica == nil || !route.replica.healthy.Load() {
+		route.replica = r.healthyReplica()
+	}
+	return route.replica
+}
+
+// healthyReplica returns the next healthy replica in turn, nil if none is healthy.
+func (r *Replicas) healthyReplica() *replica {
+	start := int(r.next.Add(1) % uint64(len(r.replicas)))
+	for i := range r.replicas {
+		if replica := r.replicas[(start+i)%len(r.replicas)]; replica.healthy.Load() {
+			return replica
+		}
+	}
+	return nil
+}
+
+func (r *Replicas) recordWrite(db *gorm.DB) {
+	if db.Error != nil || r.window <= 0 || r.session == nil {
+		return
+	}
+
+	if session := r.session(db.Statement.Context); session != "" {
+		r.writes.Store(session, time.Now())
+	}
+}
+
+func (r *Replicas) wroteRecently(ctx context.Context) bool {
+	if r.window <= 0 || r.session == nil {
+		return false
+	}
+
+	session := r.session(ctx)
+	if session == "" {
+		return false
+	}
+
+	wrote, ok := r.writes.Load(session)
+	return ok && time.Since(wrote.(time.Time)) < r.window
+}
+
+// Run checks the replicas every interval until ctx is cancelled. The unhealthy replicas
+// don't fail the worker, their reads go to the primary until they recover.
+func (r *Replicas) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.check(ctx, interval)
+		r.pruneWrites()
+		health.Beat(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Replicas) check(ctx context.Context, timeout time.Duration) {
+	for _, replica := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := replica.db.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if replica.healthy.Swap(healthy) != healthy {
+			if healthy {
+				slog.Info("read replica is healthy", "replica", replica.name)
+			} else {
+				slog.Warn("read replica is unhealthy, reading from the primary", "replica", replica.name, "error", err)
+			}
+		}
+		metrics.SetReplicaHealthy(replica.name, healthy)
+	}
+}
+
+// pruneWrites forgets the writes older than the window.
+func (r *Replicas) pruneWrites() {
+	r.writes.Range(func(session, wrote any) bool {
+		if time.Since(wrote.(time.Time)) >= r.window {
+			r.writes.Delete(session)
+		}
+		return true
+	})
+}
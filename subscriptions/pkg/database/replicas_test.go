@@ -0,0 +1,339 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// unreachableDSN is a server nothing listens on, the pools are opened without connecting
+// and the statements of the dry run aren't sent.
+const unreachableDSN = "host=127.0.0.1 port=1 user=test dbname=test connect_timeout=1"
+
+type record struct {
+	ID   uint
+	Name string
+}
+
+type sessionKey struct{}
+
+func withSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// routing is the dry run database with two replicas, it records the pool each statement
+// was sent to.
+type routing struct {
+	db       *gorm.DB
+	replicas *Replicas
+	primary  *sql.DB
+	// last is the pool of the last query
+	last gorm.ConnPool
+}
+
+func openPool(t *testing.T) *sql.DB {
+	t.Helper()
+
+	pool, err := sql.Open("pgx", unreachableDSN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Close() })
+
+	return pool
+}
+
+func newRouting(t *testing.T, window time.Duration) *routing {
+	t.Helper()
+
+	r := &routing{primary: openPool(t)}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: r.primary}), &gorm.Config{
+		Logger:                 logger.Discard,
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.replicas = &Replicas{
+		window: window,
+		session: func(ctx context.Context) string {
+			session, _ := ctx.Value(sessionKey{}).(string)
+			return session
+		},
+	}
+	for _, name := range []string{"replica-1", "replica-2"} {
+		replica := &replica{name: name, db: openPool(t)}
+		replica.healthy.Store(true)
+		r.replicas.replicas = append(r.replicas.replicas, replica)
+	}
+	if err := db.Use(r.replicas); err != nil {
+		t.Fatal(err)
+	}
+
+	capture := func(db *gorm.DB) { r.last = db.Statement.ConnPool }
+	if err := db.Callback().Query().After("replicas:route_query").Register("test:capture_query", capture); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Row().After("replicas:route_row").Register("test:capture_row", capture); err != nil {
+		t.Fatal(err)
+	}
+
+	r.db = db
+	return r
+}
+
+// find runs the query in the context and returns the name of the pool it was sent to.
+func (r *routing) find(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+
+	var records []record
+	if err := db.Find(&records).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+
+	return r.poolName(r.last)
+}
+
+func (r *routing) poolName(pool gorm.ConnPool) string {
+	if pool == r.primary {
+		return "primary"
+	}
+	for _, replica := range r.replicas.replicas {
+		if pool == replica.db {
+			return replica.name
+		}
+	}
+	return "other"
+}
+
+// txPool stands for the connection of a transaction.
+type txPool struct {
+	gorm.ConnPool
+}
+
+func TestReplicasRouting(t *testing.T) {
+	readOnly := func(session string) context.Context {
+		return ReadOnly(withSession(context.Background(), session))
+	}
+
+	tests := []struct {
+		name string
+		// window is the read-your-writes window, a minute if it is zero
+		window time.Duration
+		// run runs the statements and returns the pool of the last one
+		run  func(t *testing.T, r *routing) string
+		pool string
+	}{
+		{
+			name: "not read-only",
+			run: func(t *testing.T, r *routing) string {
+				return r.find(t, r.db.WithContext(context.Background()))
+			},
+			pool: "primary",
+		},
+		{
+			name: "read-only",
+			run: func(t *testing.T, r *routing) string {
+				return r.find(t, r.db.WithContext(readOnly("s1")))
+			},
+			pool: "replica",
+		},
+		{
+			name: "read-only row",
+			run: func(t *testing.T, r *routing) string {
+				r.db.WithContext(readOnly("s1")).Model(&record{}).Select("count(*)").Row()
+				return r.poolName(r.last)
+			},
+			pool: "replica",
+		},
+		{
+			name: "primary context",
+			run: func(t *testing.T, r *routing) string {
+				return r.find(t, r.db.WithContext(ReadOnly(Primary(context.Background()))))
+			},
+			pool: "primary",
+		},
+		{
+			name: "locking read",
+			run: func(t *testing.T, r *routing) string {
+				return r.find(t, r.db.WithContext(readOnly("s1")).Clauses(clause.Locking{Strength: "UPDATE"}))
+			},
+			pool: "primary",
+		},
+		{
+			name: "transaction",
+			run: func(t *testing.T, r *routing) string {
+				tx := r.db.WithContext(readOnly("s1")).Session(&gorm.Session{})
+				tx.Statement.ConnPool = txPool{}
+				return r.find(t, tx)
+			},
+			pool: "other",
+		},
+		{
+			name: "no healthy replica",
+			run: func(t *testing.T, r *routing) string {
+				for _, replica := range r.replicas.replicas {
+					replica.healthy.Store(false)
+				}
+				return r.find(t, r.db.WithContext(readOnly("s1")))
+			},
+			pool: "primary",
+		},
+		{
+			name: "read after a write of the session",
+			run: func(t *testing.T, r *routing) string {
+				if err := r.db.WithContext(withSession(context.Background(), "s1")).Create(&record{Name: "a"}).Error; err != nil {
+					t.Fatalf("create: %v", err)
+				}
+				return r.find(t, r.db.WithContext(readOnly("s1")))
+			},
+			pool: "primary",
+		},
+		{
+			name: "read after an update of the session",
+			run: func(t *testing.T, r *routing) string {
+				err := r.db.WithContext(withSession(context.Background(), "s1")).Model(&record{ID: 1}).Update("name", "b").Error
+				if err != nil {
+					t.Fatalf("update: %v", err)
+				}
+				return r.find(t, r.db.WithContext(readOnly("s1")))
+			},
+			pool: "primary",
+		},
+		{
+			name: "read after a write of another session",
+			run: func(t *testing.T, r *routing) string {
+				if err := r.db.WithContext(withSession(context.Background(), "s2")).Create(&record{Name: "a"}).Error; err != nil {
+					t.Fatalf("create: %v", err)
+				}
+				return r.find(t, r.db.WithContext(readOnly("s1")))
+			},
+			pool: "replica",
+		},
+		{
+			name: "read after a write without session",
+			run: func(t *testing.T, r *routing) string {
+				if err := r.db.WithContext(context.Background()).Create(&record{Name: "a"}).Error; err != nil {
+					t.Fatalf("create: %v", err)
+				}
+				return r.find(t, r.db.WithContext(readOnly("")))
+			},
+			pool: "replica",
+		},
+		{
+			name:   "read after the window",
+			window: time.Millisecond,
+			run: func(t *testing.T, r *routing) string {
+				if err := r.db.WithContext(withSession(context.Background(), "s1")).Create(&record{Name: "a"}).Error; err != nil {
+					t.Fatalf("create: %v", err)
+				}
+				time.Sleep(2 * time.Millisecond)
+				return r.find(t, r.db.WithContext(readOnly("s1")))
+			},
+			pool: "replica",
+		},
+		{
+			name:   "read-your-writes disabled",
+			window: -1,
+			run: func(t *testing.T, r *routing) string {
+				if err := r.db.WithContext(withSession(context.Background(), "s1")).Create(&record{Name: "a"}).Error; err != nil {
+					t.Fatalf("create: %v", err)
+				}
+				return r.find(t, r.db.WithContext(readOnly("s1")))
+			},
+			pool: "replica",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := tt.window
+			switch {
+			case window == 0:
+				window = time.Minute
+			case window < 0:
+				window = 0
+			}
+			r := newRouting(t, window)
+
+			pool := tt.run(t, r)
+			if tt.pool == "replica" && (pool == "replica-1" || pool == "replica-2") {
+				return
+			}
+			if pool != tt.pool {
+				t.Errorf("expected the statement sent to the %s, got %s", tt.pool, pool)
+			}
+		})
+	}
+}
+
+func TestReplicasPinnedRoute(t *testing.T) {
+	r := newRouting(t, time.Minute)
+
+	ctx := ReadOnly(context.Background())
+	first := r.find(t, r.db.WithContext(ctx))
+	for range 3 {
+		if pool := r.find(t, r.db.WithContext(ctx)); pool != first {
+			t.Fatalf("expected the reads of the context pinned to %s, got %s", first, pool)
+		}
+	}
+
+	// the reads of the other contexts go to the replicas in turn
+	used := map[string]bool{}
+	for range 4 {
+		used[r.find(t, r.db.WithContext(ReadOnly(context.Background())))] = true
+	}
+	if !used["replica-1"] || !used["replica-2"] {
+		t.Errorf("expected the reads spread over both replicas, got %v", used)
+	}
+
+	// a pinned replica becoming unhealthy moves the reads of the context to the other one
+	for _, replica := range r.replicas.replicas {
+		if replica.name == first {
+			replica.healthy.Store(false)
+		}
+	}
+	if pool := r.find(t, r.db.WithContext(ctx)); pool == first || pool == "primary" {
+		t.Errorf("expected the reads moved to the healthy replica, got %s", pool)
+	}
+}
+
+func TestReplicasCheck(t *testing.T) {
+	r := newRouting(t, time.Minute)
+
+	// nothing listens on the replicas, so the check finds them unhealthy
+	r.replicas.check(context.Background(), time.Second)
+	for _, replica := range r.replicas.replicas {
+		if replica.healthy.Load() {
+			t.Errorf("expected the unreachable %s unhealthy", replica.name)
+		}
+	}
+
+	if pool := r.find(t, r.db.WithContext(ReadOnly(context.Background()))); pool != "primary" {
+		t.Errorf("expected the reads sent to the primary, got %s", pool)
+	}
+}
+
+func TestReplicasPruneWrites(t *testing.T) {
+	r := newRouting(t, time.Millisecond)
+
+	if err := r.db.WithContext(withSession(context.Background(), "s1")).Create(&record{Name: "a"}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	r.replicas.pruneWrites()
+
+	if _, ok := r.replicas.writes.Load("s1"); ok {
+		t.Error("expected the write older than the window pruned")
+	}
+}
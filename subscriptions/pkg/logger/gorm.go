@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger writes the logs of GORM to the structured logger. Every statement is
+// logged at debug level, the statements slower than the threshold at warn level and
+// the failed ones at error level. The values of the statements are never logged.
+type GormLogger struct {
+	logger        *slog.Logger
+	slowThreshold time.Duration
+}
+
+func NewGormLogger(logger *slog.Logger, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{
+		logger:        logger,
+		slowThreshold: slowThreshold,
+	}
+}
+
+// LogMode is ignored, the level of the structured logger filters the logs.
+func (l *GormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+func (l *GormLogger) Info(ctx context.Context, message string, args ...any) {
+	l.logger.InfoContext(ctx, fmt.Sprintf(message, args...))
+}
+
+func (l *GormLogger) Warn(ctx context.Context, message string, args ...any) {
+	l.logger.WarnContext(ctx, fmt.Sprintf(message, args...))
+}
+
+func (l *GormLogger) Error(ctx context.Context, message string, args ...any) {
+	l.logger.ErrorContext(ctx, fmt.Sprintf(message, args...))
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+
+	level := slog.LevelDebug
+	message := "query"
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level, message = slog.LevelError, "query failed"
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold:
+		level, message = slog.LevelWarn, "slow query"
+	}
+
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Duration("elapsed", elapsed),
+	}
+	if level == slog.LevelError {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+
+	record := slog.NewRecord(time.Now(), level, message, callerPC())
+	record.AddAttrs(attrs...)
+	l.logger.Handler().Handle(ctx, record)
+}
+
+// callerPC returns the caller of GORM, so the source of the records is the repository
+// running the statement rather than this file.
+func callerPC() uintptr {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.File, "gorm.io/") {
+			return frame.PC
+		}
+		if !more {
+			return 0
+		}
+	}
+}
+
+// ParamsFilter replaces the values of the statement with Redacted, so no personal
+// data or secrets get to the logs.
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	redacted := make([]any, len(params))
+	for i := range redacted {
+		redacted[i] = Redacted
+	}
+
+	return sql, redacted
+}
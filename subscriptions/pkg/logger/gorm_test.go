@@ -0,0 +1,77 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"subscriptions/rest-service/pkg/logger"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestGormLoggerTrace(t *testing.T) {
+	tests := []struct {
+		name        string
+		level       string
+		elapsed     time.Duration
+		err         error
+		wantLevel   string
+		wantMessage string
+	}{
+		{"statement", "debug", 0, nil, "DEBUG", "query"},
+		{"statement above debug", "info", 0, nil, "", ""},
+		{"slow statement", "info", time.Second, nil, "WARN", "slow query"},
+		{"failed statement", "info", 0, errors.New("syntax error"), "ERROR", "query failed"},
+		{"record not found", "info", 0, gorm.ErrRecordNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log, err := logger.New(&buf, logger.Config{Format: logger.FormatJSON, Level: tt.level})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			gormLogger := logger.NewGormLogger(log, 100*time.Millisecond)
+			gormLogger.Trace(context.Background(), time.Now().Add(-tt.elapsed), func() (string, int64) {
+				return "SELECT * FROM users WHERE id = $1", 1
+			}, tt.err)
+
+			if tt.wantLevel == "" {
+				if buf.Len() > 0 {
+					t.Fatalf("logged %q, want nothing", buf.String())
+				}
+				return
+			}
+
+			var fields map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+				t.Fatalf("decode record %q: %v", buf.String(), err)
+			}
+			if fields["level"] != tt.wantLevel || fields["msg"] != tt.wantMessage {
+				t.Errorf("record = %v %v, want %v %v", fields["level"], fields["msg"], tt.wantLevel, tt.wantMessage)
+			}
+			if fields["sql"] != "SELECT * FROM users WHERE id = $1" {
+				t.Errorf("sql = %v", fields["sql"])
+			}
+		})
+	}
+}
+
+func TestGormLoggerParamsFilter(t *testing.T) {
+	gormLogger := logger.NewGormLogger(nil, 0)
+
+	sql, params := gormLogger.ParamsFilter(context.Background(), "SELECT $1, $2", "user@example.com", 42)
+	if sql != "SELECT $1, $2" {
+		t.Errorf("sql = %q", sql)
+	}
+	for i, param := range params {
+		if param != logger.Redacted {
+			t.Errorf("params[%d] = %v, want %v", i, param, logger.Redacted)
+		}
+	}
+}
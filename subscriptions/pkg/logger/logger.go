@@ -1,45 +1,110 @@
+/*
+Package logger configures the structured logger of the service. Every record
+gets the fields put to its context with WithAttrs and the ID of the trace of
+the context, and the values of sensitive keys are redacted.
+*/
 package logger
 
 import (
+	"context"
 	"fmt"
-	"path"
-	"runtime"
+	"io"
+	"log/slog"
 	"strings"
-	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
 )
 
-func PrintLog(message string, level ...string) {
-	color := "\033[32m"
-	logLevel := ""
-	if len(level) > 0 {
-		logLevel = level[0]
+// Redacted replaces the values of the sensitive keys.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are the parts of the keys whose values are never logged.
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "api_key", "apikey", "hash", "dsn"}
+
+type Config struct {
+	// Format is FormatJSON or FormatConsole.
+	Format string
+	// Level is one of debug, info, warn and error.
+	Level string
+}
+
+type attrsKey struct{}
+
+// New returns the logger writing to w, it doesn't change the default logger.
+func New(w io.Writer, config Config) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(config.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", config.Level)
 	}
 
-	switch strings.ToUpper(logLevel) {
-	case "ERROR":
-		color = "\033[31m"
-	case "WARN":
-		color = "\033[33m"
+	options := &slog.HandlerOptions{
+		AddSource:   true,
+		Level:       level,
+		ReplaceAttr: redact,
+	}
+
+	var handler slog.Handler
+	switch config.Format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	case FormatConsole:
+		handler = slog.NewTextHandler(w, options)
 	default:
+		return nil, fmt.Errorf("unknown log format %q", config.Format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+// WithAttrs returns the context whose log records get the attributes,
+// e.g. the route and the user of the request.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	parent, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+
+	merged := make([]slog.Attr, 0, len(parent)+len(attrs))
+	merged = append(merged, parent...)
+	merged = append(merged, attrs...)
 
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, Redacted)
+		}
+	}
+
+	return attr
+}
+
+// contextHandler adds the attributes of the context to the records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		record.AddAttrs(attrs...)
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
 	}
 
-	pc, file, line, _ := runtime.Caller(1)
-
-	now := time.Now()
-	output := fmt.Sprintf(
-		"\n[LOGGER] %s%d/%d/%d - %d:%d:%d %s %s:%d %s\x1b[0m\n",
-		color,
-		now.Year(),
-		now.Month(),
-		now.Day(),
-		now.Hour(),
-		now.Minute(),
-		now.Second(),
-		runtime.FuncForPC(pc).Name(),
-		path.Base(file),
-		line,
-		strings.ToUpper(message),
-	)
-	fmt.Printf("%s\n", output)
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
 }
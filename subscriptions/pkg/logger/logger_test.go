@@ -0,0 +1,158 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"subscriptions/rest-service/pkg/logger"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// record logs the message with the logger writing JSON and returns the decoded record.
+func record(t *testing.T, ctx context.Context, args ...any) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log, err := logger.New(&buf, logger.Config{Format: logger.FormatJSON, Level: "debug"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	log.InfoContext(ctx, "message", args...)
+
+	var fields map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+		t.Fatalf("decode record %q: %v", buf.String(), err)
+	}
+
+	return fields
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  logger.Config
+		wantErr bool
+	}{
+		{"json", logger.Config{Format: logger.FormatJSON, Level: "info"}, false},
+		{"console", logger.Config{Format: logger.FormatConsole, Level: "debug"}, false},
+		{"upper case level", logger.Config{Format: logger.FormatJSON, Level: "WARN"}, false},
+		{"unknown format", logger.Config{Format: "xml", Level: "info"}, true},
+		{"unknown level", logger.Config{Format: logger.FormatJSON, Level: "verbose"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := logger.New(&bytes.Buffer{}, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := logger.New(&buf, logger.Config{Format: logger.FormatJSON, Level: "warn"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		level  slog.Level
+		logged bool
+	}{
+		{"below level", slog.LevelInfo, false},
+		{"at level", slog.LevelWarn, true},
+		{"above level", slog.LevelError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			log.Log(context.Background(), tt.level, "message")
+
+			if got := buf.Len() > 0; got != tt.logged {
+				t.Errorf("logged = %v, want %v", got, tt.logged)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{"password", "password", logger.Redacted},
+		{"secret part of key", "hmac_secret", logger.Redacted},
+		{"upper case key", "Authorization", logger.Redacted},
+		{"api key", "api_key", logger.Redacted},
+		{"dsn", "database_dsn", logger.Redacted},
+		{"plain key", "user_id", "value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := record(t, context.Background(), tt.key, "value")
+
+			if got := fields[tt.key]; got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextAttrs(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	defer provider.Shutdown(context.Background())
+
+	spanCtx, span := provider.Tracer("test").Start(context.Background(), "test")
+	defer span.End()
+
+	routeCtx := logger.WithAttrs(context.Background(), slog.String("route", "GET /subs"))
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[string]any
+	}{
+		{
+			name: "no attributes",
+			ctx:  context.Background(),
+			want: map[string]any{"route": nil, "request_id": nil, "trace_id": nil},
+		},
+		{
+			name: "attributes",
+			ctx:  routeCtx,
+			want: map[string]any{"route": "GET /subs", "request_id": nil},
+		},
+		{
+			name: "attributes of parent",
+			ctx:  logger.WithAttrs(routeCtx, slog.String("request_id", "42")),
+			want: map[string]any{"route": "GET /subs", "request_id": "42"},
+		},
+		{
+			name: "trace",
+			ctx:  spanCtx,
+			want: map[string]any{"trace_id": span.SpanContext().TraceID().String()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := record(t, tt.ctx)
+
+			for key, want := range tt.want {
+				if got := fields[key]; got != want {
+					t.Errorf("%s = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}